@@ -1,24 +1,53 @@
 package main
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
+	"os"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"strings"
 
+	"github.com/aquasecurity/starboard-operator/pkg/admission"
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/aquasecurity/starboard-operator/pkg/controller/configaudit"
 	"github.com/aquasecurity/starboard-operator/pkg/controller/job"
+	"github.com/aquasecurity/starboard-operator/pkg/controller/once"
 	"github.com/aquasecurity/starboard-operator/pkg/controller/pod"
-
+	"github.com/aquasecurity/starboard-operator/pkg/controller/schedule"
+	"github.com/aquasecurity/starboard-operator/pkg/debug"
+	"github.com/aquasecurity/starboard-operator/pkg/docker"
+	"github.com/aquasecurity/starboard-operator/pkg/health"
+	"github.com/aquasecurity/starboard-operator/pkg/metrics"
+	"github.com/aquasecurity/starboard-operator/pkg/notify"
+	"github.com/aquasecurity/starboard-operator/pkg/policy"
+
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/aquasecurity/starboard-operator/pkg/logs"
 	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
-	"github.com/aquasecurity/starboard-operator/pkg/aqua"
+	"github.com/aquasecurity/starboard-operator/pkg/polaris"
 
 	"github.com/aquasecurity/starboard-operator/pkg/scanner"
-	"github.com/aquasecurity/starboard-operator/pkg/trivy"
+
+	// Vulnerability scanners register themselves in the scanner registry.
+	_ "github.com/aquasecurity/starboard-operator/pkg/aqua"
+	_ "github.com/aquasecurity/starboard-operator/pkg/grype"
+	_ "github.com/aquasecurity/starboard-operator/pkg/scout"
+	_ "github.com/aquasecurity/starboard-operator/pkg/trivy"
 
 	appsv1 "k8s.io/api/apps/v1"
 
@@ -27,10 +56,15 @@ import (
 	"github.com/aquasecurity/starboard-operator/pkg/etc"
 	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
 	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -58,16 +92,52 @@ var (
 func init() {
 	_ = corev1.AddToScheme(scheme)
 	_ = batchv1.AddToScheme(scheme)
+	_ = batchv1beta1.AddToScheme(scheme)
 	_ = appsv1.AddToScheme(scheme)
 	_ = starboardv1alpha1.AddToScheme(scheme)
 }
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "Print the effective configuration with secrets redacted and exit")
+	runOnceFlag := flag.Bool("once", false, "Scan Pods in target namespaces once, wait for scan jobs to complete, and exit")
+	flag.Parse()
+
+	if *printConfig {
+		if err := printEffectiveConfig(os.Stdout); err != nil {
+			setupLog.Error(err, "Unable to print config")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *runOnceFlag {
+		if err := runOnce(); err != nil {
+			setupLog.Error(err, "Unable to scan once")
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(); err != nil {
 		setupLog.Error(err, "Unable to run manager")
 	}
 }
 
+// printEffectiveConfig writes the configuration parsed from environment variables as JSON
+// object keyed by environment variable names. Values of secrets are redacted.
+func printEffectiveConfig(out io.Writer) error {
+	config, err := etc.GetOperatorConfig()
+	if err != nil {
+		return fmt.Errorf("getting operator config: %w", err)
+	}
+	data, err := json.MarshalIndent(etc.RedactEnv(config.GetEnv()), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
 func run() error {
 	setupLog.Info("Starting operator", "version", versionInfo)
 	config, err := etc.GetOperatorConfig()
@@ -75,7 +145,30 @@ func run() error {
 		return fmt.Errorf("getting operator config: %w", err)
 	}
 
-	log.SetLogger(zap.New(zap.UseDevMode(config.Operator.LogDevMode)))
+	loggerOptions, err := newLoggerOptions(config.Operator)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	log.SetLogger(zap.New(loggerOptions...))
+
+	kubernetesConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return fmt.Errorf("getting kube client config: %w", err)
+	}
+
+	// The only reason we're using kubernetes.Clientset is that we need it to read Pod logs,
+	// which is not supported by the client returned by the ctrl.Manager.
+	kubernetesClientset, err := kubernetes.NewForConfig(kubernetesConfig)
+	if err != nil {
+		return fmt.Errorf("constructing kube client: %w", err)
+	}
+
+	if config.Operator.DetectInstallMode {
+		config.Operator, err = detectInstallMode(context.Background(), kubernetesClientset, config.Operator)
+		if err != nil {
+			return fmt.Errorf("detecting install mode: %w", err)
+		}
+	}
 
 	// Validate configured namespaces to resolve install mode.
 	operatorNamespace, err := config.Operator.GetOperatorNamespace()
@@ -91,23 +184,429 @@ func run() error {
 	}
 	setupLog.Info("Resolving install mode", "install mode", installMode,
 		"operator namespace", operatorNamespace,
-		"target namespaces", targetNamespaces)
+		"target namespaces", targetNamespaces,
+		"target namespace selector", config.Operator.TargetNamespaceSelector)
+
+	if warning := config.Operator.GetScanJobTTLWarning(); warning != "" {
+		setupLog.Info("WARNING: " + warning)
+	}
+
+	options, err := newManagerOptions(config.Operator, installMode)
+	if err != nil {
+		return err
+	}
+
+	mgr, err := ctrl.NewManager(kubernetesConfig, options)
+	if err != nil {
+		return fmt.Errorf("constructing controllers manager: %w", err)
+	}
+
+	reportTypes := []runtime.Object{&starboardv1alpha1.VulnerabilityReport{}}
+	if config.ConfigAuditPolaris.Enabled {
+		reportTypes = append(reportTypes, &starboardv1alpha1.ConfigAuditReport{})
+	}
+	err = reports.CheckCRDs(mgr.GetScheme(), mgr.GetRESTMapper(), reportTypes...)
+	if err != nil {
+		return fmt.Errorf("checking report CRDs: %w", err)
+	}
+
+	err = mgr.AddReadyzCheck("ping", healthz.Ping)
+	if err != nil {
+		return err
+	}
+
+	err = mgr.AddReadyzCheck("cache-and-config", health.NewReadyzCheck(mgr.GetCache(), config))
+	if err != nil {
+		return err
+	}
+
+	err = mgr.AddHealthzCheck("ping", healthz.Ping)
+	if err != nil {
+		return err
+	}
+
+	podController, jobController, store, err := newScanControllers(config, mgr.GetClient(), kubernetesClientset,
+		mgr.GetEventRecorderFor("starboard-operator"))
+	if err != nil {
+		return err
+	}
+
+	if config.Operator.DebugEndpointEnabled {
+		setupLog.Info("Serving debug endpoint", "address", config.Operator.DebugBindAddress, "path", debug.Path)
+		tracker := &controller.ReconcileTracker{}
+		podController.Tracker = tracker
+		jobController.Tracker = tracker
+		err = mgr.Add(&debug.Server{
+			BindAddress: config.Operator.DebugBindAddress,
+			Handler: &debug.Handler{
+				Config:  config.Operator,
+				Client:  mgr.GetClient(),
+				Tracker: tracker,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("unable to add debug server: %w", err)
+		}
+	}
+
+	if err = podController.SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create pod controller: %w", err)
+	}
+
+	inFlight := &controller.InFlight{}
+	jobController.InFlight = inFlight
+	if err = jobController.SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create job controller: %w", err)
+	}
+
+	if config.Operator.AdmissionEnabled {
+		setupLog.Info("Registering admission webhook", "path", admission.WebhookPath,
+			"port", config.Operator.AdmissionPort)
+		mgr.GetWebhookServer().Register(admission.WebhookPath, &webhook.Admission{
+			Handler: &admission.PodValidator{
+				Config:        config.Operator,
+				DigestReader:  store,
+				ImageIDReader: podController,
+			},
+		})
+	}
+
+	if config.ConfigAuditPolaris.Enabled {
+		setupLog.Info("Using Polaris as config audit scanner", "version", config.ConfigAuditPolaris.Version)
+		configAuditScanner := polaris.NewScanner(config.ConfigAuditPolaris)
+		if err = (&configaudit.ConfigAuditController{
+			Config:     config.Operator,
+			Client:     mgr.GetClient(),
+			LogsReader: logs.NewReader(kubernetesClientset, config.Operator.LogReadTimeout, configAuditScanner.GetContainerName()),
+			Scheme:     mgr.GetScheme(),
+			Scanner:    configAuditScanner,
+			Store:      store,
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create config audit controller: %w", err)
+		}
+	}
+
+	if config.Operator.IsMetricsTLSEnabled() {
+		secureServer := &metrics.SecureServer{
+			BindAddress: config.Operator.MetricsBindAddress,
+			CertFile:    config.Operator.MetricsCertFile,
+			KeyFile:     config.Operator.MetricsKeyFile,
+			Gatherer:    ctrlmetrics.Registry,
+		}
+		if config.Operator.MetricsAuthEnabled {
+			secureServer.Clientset = kubernetesClientset
+		}
+		if err = mgr.Add(secureServer); err != nil {
+			return fmt.Errorf("unable to add secure metrics server: %w", err)
+		}
+	}
+
+	scanSchedule, err := config.Operator.GetScanSchedule()
+	if err != nil {
+		return err
+	}
+	if scanSchedule != nil {
+		setupLog.Info("Scheduling rescans", "schedule", config.Operator.ScanCron)
+		err = mgr.Add(&schedule.Scheduler{
+			Config:   config.Operator,
+			Client:   mgr.GetClient(),
+			Clock:    clock.RealClock{},
+			Schedule: scanSchedule,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to add scheduler: %w", err)
+		}
+	}
+
+	setupLog.Info("Starting controllers manager")
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		return fmt.Errorf("starting controllers manager: %w", err)
+	}
+
+	// The manager does not wait for reconcile requests in progress, so let the JobController persist
+	// reports of completed scan Jobs before the process exits.
+	if gracePeriod := config.Operator.ShutdownGracePeriod; gracePeriod > 0 {
+		setupLog.Info("Draining in-flight scans", "gracePeriod", gracePeriod)
+		if remaining := inFlight.Drain(clock.RealClock{}, gracePeriod); remaining > 0 {
+			setupLog.Info("Grace period elapsed before in-flight scans were drained", "remaining", remaining)
+		}
+	}
+
+	return nil
+}
+
+// runOnce scans Pods in target namespaces once, waits for scan Jobs to complete, and returns without
+// running the controllers manager. Config audits are not run in this mode.
+func runOnce() error {
+	setupLog.Info("Starting one-shot scan", "version", versionInfo)
+	config, err := etc.GetOperatorConfig()
+	if err != nil {
+		return fmt.Errorf("getting operator config: %w", err)
+	}
+
+	loggerOptions, err := newLoggerOptions(config.Operator)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	log.SetLogger(zap.New(loggerOptions...))
+
+	kubernetesConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return fmt.Errorf("getting kube client config: %w", err)
+	}
+
+	kubernetesClientset, err := kubernetes.NewForConfig(kubernetesConfig)
+	if err != nil {
+		return fmt.Errorf("constructing kube client: %w", err)
+	}
+
+	mapper, err := apiutil.NewDynamicRESTMapper(kubernetesConfig)
+	if err != nil {
+		return fmt.Errorf("constructing REST mapper: %w", err)
+	}
+
+	err = reports.CheckCRDs(scheme, mapper, &starboardv1alpha1.VulnerabilityReport{})
+	if err != nil {
+		return fmt.Errorf("checking report CRDs: %w", err)
+	}
+
+	// There's no manager, hence no cache. Objects are read directly from the API server.
+	kubernetesClient, err := client.New(kubernetesConfig, client.Options{Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		return fmt.Errorf("constructing kube client: %w", err)
+	}
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubernetesClientset.CoreV1().Events("")})
+	defer broadcaster.Shutdown()
+	recorder := broadcaster.NewRecorder(scheme, corev1.EventSource{Component: "starboard-operator"})
+
+	podController, jobController, _, err := newScanControllers(config, kubernetesClient, kubernetesClientset, recorder)
+	if err != nil {
+		return err
+	}
+
+	stop := ctrl.SetupSignalHandler()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	return (&once.Runner{
+		Config:        config.Operator,
+		Client:        kubernetesClient,
+		PodReconciler: podController,
+		JobReconciler: jobController,
+	}).Run(ctx)
+}
+
+// newScanControllers constructs the PodController and the JobController, which scan workloads for
+// vulnerabilities, with the specified client and event recorder. Also returns the reports Store.
+func newScanControllers(config etc.Config, c client.Client, clientset kubernetes.Interface, recorder record.EventRecorder) (*pod.PodController, *job.JobController, *reports.Store, error) {
+	_, err := reports.ParseSeverities(config.Operator.ReportSeverities)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("parsing report severities: %w", err)
+	}
+
+	scanner, err := getEnabledScanner(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	reportAnnotations, err := config.Operator.GetReportAnnotations()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	store := reports.NewStore(c, scheme, clock.RealClock{}, reports.Compression{
+		Enabled:   config.Operator.CompressReports,
+		Threshold: config.Operator.CompressReportsThreshold,
+	}, config.Operator.MaxReportItems, config.Operator.ReportHistoryLimit, config.Operator.ServerSideApply, reportAnnotations,
+		config.Operator.InstanceID)
+
+	writer, err := getReportWriter(config.Operator, store)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	notifier, err := getNotifier(config.Operator)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	registryRateLimit, err := config.Operator.GetRegistryRateLimit()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	podController := &pod.PodController{
+		Config:            config.Operator,
+		Client:            c,
+		Writer:            writer,
+		DigestReader:      store,
+		IncrementalWriter: store,
+		Scanner:           scanner,
+		Scheme:            scheme,
+		Clock:             clock.RealClock{},
+		Recorder:          recorder,
+	}
+	if registryRateLimit != nil {
+		setupLog.Info("Limiting rate of scan jobs per registry", "count", registryRateLimit.Count,
+			"interval", registryRateLimit.Interval)
+		podController.RegistryRateLimiter = pod.NewRegistryRateLimiter(*registryRateLimit)
+	}
+	if config.Operator.ResolveImageDigests {
+		podController.DigestResolver = docker.NewRegistryDigestResolver(nil)
+	}
+
+	jobController := &job.JobController{
+		Config:     config.Operator,
+		LogsReader: logs.NewReader(clientset, config.Operator.LogReadTimeout, ""),
+		Client:     c,
+		Writer:     writer,
+		SBOMWriter: store,
+		Scanner:    scanner,
+		Scheme:     scheme,
+		Clock:      clock.RealClock{},
+		Recorder:   recorder,
+		Notifier:   notifier,
+	}
+	if config.Operator.VulnPolicyConfigMap != "" {
+		setupLog.Info("Filtering vulnerabilities by policy", "configMap", config.Operator.VulnPolicyConfigMap)
+		jobController.Policy = policy.NewLoader(c, types.NamespacedName{
+			Namespace: config.Operator.Namespace,
+			Name:      config.Operator.VulnPolicyConfigMap,
+		})
+	}
+	if config.Operator.FailureThreshold > 0 {
+		setupLog.Info("Suspending scans of images which repeatedly fail", "threshold", config.Operator.FailureThreshold,
+			"cooldown", config.Operator.FailureCooldown)
+		circuitBreaker := controller.NewCircuitBreaker(config.Operator.FailureThreshold, config.Operator.FailureCooldown)
+		podController.CircuitBreaker = circuitBreaker
+		jobController.CircuitBreaker = circuitBreaker
+	}
+	if config.Operator.ScannedDigestCacheSize > 0 {
+		setupLog.Info("Caching scanned image digests", "size", config.Operator.ScannedDigestCacheSize,
+			"file", config.Operator.ScannedDigestCacheFile)
+		digestCache, err := controller.NewDigestCache(config.Operator.ScannedDigestCacheSize,
+			config.Operator.ScannedDigestCacheFile)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		podController.DigestCache = digestCache
+		podController.StartTime = podController.Clock.Now()
+		jobController.DigestCache = digestCache
+	}
+
+	return podController, jobController, store, nil
+}
+
+// newLoggerOptions maps the log settings of the specified operator config to zap options. The format and
+// the level default to the ones of the development mode, i.e. console and debug, if it's enabled, and to json
+// and info otherwise.
+func newLoggerOptions(config etc.Operator) ([]zap.Opts, error) {
+	opts := []zap.Opts{zap.UseDevMode(config.LogDevMode)}
+
+	switch config.LogFormat {
+	case "":
+	case "json":
+		opts = append(opts, zap.Encoder(zapcore.NewJSONEncoder(uberzap.NewProductionEncoderConfig())))
+	case "console":
+		opts = append(opts, zap.Encoder(zapcore.NewConsoleEncoder(uberzap.NewDevelopmentEncoderConfig())))
+	default:
+		return nil, fmt.Errorf("%s must be one of json or console but got %q", "OPERATOR_LOG_FORMAT", config.LogFormat)
+	}
+
+	if config.LogLevel != "" {
+		var level zapcore.Level
+		err := level.UnmarshalText([]byte(config.LogLevel))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_LOG_LEVEL", err)
+		}
+		atomicLevel := uberzap.NewAtomicLevelAt(level)
+		opts = append(opts, zap.Level(&atomicLevel))
+	}
+
+	return opts, nil
+}
+
+// detectInstallMode checks with SelfSubjectAccessReviews whether the operator is allowed to list and watch Pods
+// in all namespaces. If it's not, target namespaces of the returned config are set to the operator namespace,
+// which resolves to the OwnNamespace install mode. Otherwise they're left blank, which resolves to the
+// AllNamespaces install mode. The config is returned as is when target namespaces or the target namespace
+// selector are set explicitly.
+func detectInstallMode(ctx context.Context, clientset kubernetes.Interface, config etc.Operator) (etc.Operator, error) {
+	if strings.TrimSpace(config.TargetNamespaces) != "" || config.TargetNamespaceSelector != "" {
+		setupLog.Info("Skipping install mode detection as target namespaces are set explicitly")
+		return config, nil
+	}
+	operatorNamespace, err := config.GetOperatorNamespace()
+	if err != nil {
+		return config, fmt.Errorf("getting operator namespace: %w", err)
+	}
+	for _, verb := range []string{"list", "watch"} {
+		review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				// The blank namespace stands for all namespaces.
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:     verb,
+					Resource: "pods",
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return config, fmt.Errorf("creating self subject access review: %w", err)
+		}
+		if !review.Status.Allowed {
+			setupLog.Info("Detected namespace-scoped permissions", "verb", verb, "resource", "pods",
+				"reason", review.Status.Reason)
+			config.TargetNamespaces = operatorNamespace
+			return config, nil
+		}
+	}
+	setupLog.Info("Detected cluster-scoped permissions")
+	return config, nil
+}
+
+// newManagerOptions constructs the controllers manager options based on the specified
+// operator config and the install mode resolved from that config.
+func newManagerOptions(config etc.Operator, installMode etc.InstallMode) (manager.Options, error) {
+	operatorNamespace, err := config.GetOperatorNamespace()
+	if err != nil {
+		return manager.Options{}, fmt.Errorf("getting operator namespace: %w", err)
+	}
+
+	targetNamespaces := config.GetTargetNamespaces()
+	scanJobsNamespace := config.GetScanJobsNamespace()
 
 	// Set the default manager options.
 	options := manager.Options{
 		Scheme:                 scheme,
-		MetricsBindAddress:     config.Operator.MetricsBindAddress,
-		HealthProbeBindAddress: config.Operator.HealthProbeBindAddress,
+		MetricsBindAddress:     config.MetricsBindAddress,
+		HealthProbeBindAddress: config.HealthProbeBindAddress,
+	}
+
+	// Disable the plain HTTP metrics server of the manager when metrics are served over HTTPS.
+	if config.IsMetricsTLSEnabled() {
+		options.MetricsBindAddress = "0"
 	}
 
 	switch installMode {
 	case etc.InstallModeOwnNamespace:
 		// Add support for OwnNamespace set in STARBOARD_NAMESPACE (e.g. marketplace) and STARBOARD_TARGET_NAMESPACES (e.g. marketplace)
-		setupLog.Info("Constructing single-namespaced cache", "namespace", targetNamespaces[0])
 		options.Namespace = targetNamespaces[0]
+		if scanJobsNamespace == operatorNamespace {
+			setupLog.Info("Constructing single-namespaced cache", "namespace", targetNamespaces[0])
+		} else {
+			cachedNamespaces := []string{operatorNamespace, scanJobsNamespace}
+			setupLog.Info("Constructing multi-namespaced cache", "namespaces", cachedNamespaces)
+			options.NewCache = cache.MultiNamespacedCacheBuilder(cachedNamespaces)
+		}
 	case etc.InstallModeSingleNamespace:
 		// Add support for SingleNamespace set in STARBOARD_NAMESPACE (e.g. marketplace) and STARBOARD_TARGET_NAMESPACES (e.g. foo)
-		cachedNamespaces := append(targetNamespaces, operatorNamespace)
+		cachedNamespaces := appendNamespaces(targetNamespaces, operatorNamespace, scanJobsNamespace)
 		setupLog.Info("Constructing multi-namespaced cache", "namespaces", cachedNamespaces)
 		options.Namespace = targetNamespaces[0]
 		options.NewCache = cache.MultiNamespacedCacheBuilder(cachedNamespaces)
@@ -115,7 +614,7 @@ func run() error {
 		// Add support for MultiNamespace set in STARBOARD_NAMESPACE (e.g. marketplace) and STARBOARD_TARGET_NAMESPACES (e.g. foo,bar).
 		// Note that we may face performance issues when using this with a high number of namespaces.
 		// More: https://godoc.org/github.com/kubernetes-sigs/controller-runtime/pkg/cache#MultiNamespacedCacheBuilder
-		cachedNamespaces := append(targetNamespaces, operatorNamespace)
+		cachedNamespaces := appendNamespaces(targetNamespaces, operatorNamespace, scanJobsNamespace)
 		setupLog.Info("Constructing multi-namespaced cache", "namespaces", cachedNamespaces)
 		options.Namespace = ""
 		options.NewCache = cache.MultiNamespacedCacheBuilder(cachedNamespaces)
@@ -124,86 +623,108 @@ func run() error {
 		setupLog.Info("Watching all namespaces")
 		options.Namespace = ""
 	default:
-		return fmt.Errorf("unrecognized install mode: %v", installMode)
+		return manager.Options{}, fmt.Errorf("unrecognized install mode: %v", installMode)
 	}
 
-	kubernetesConfig, err := ctrl.GetConfig()
-	if err != nil {
-		return fmt.Errorf("getting kube client config: %w", err)
+	if config.AdmissionEnabled {
+		options.Port = config.AdmissionPort
+		options.CertDir = config.AdmissionCertDir
 	}
 
-	// The only reason we're using kubernetes.Clientset is that we need it to read Pod logs,
-	// which is not supported by the client returned by the ctrl.Manager.
-	kubernetesClientset, err := kubernetes.NewForConfig(kubernetesConfig)
-	if err != nil {
-		return fmt.Errorf("constructing kube client: %w", err)
+	if config.LeaderElectionEnabled {
+		// Only the elected leader runs the controllers, which prevents duplicate scan Jobs
+		// when the operator is deployed with multiple replicas.
+		options.LeaderElection = true
+		options.LeaderElectionID = config.LeaderElectionID
+		options.LeaderElectionNamespace = config.LeaderElectionNamespace
+		if options.LeaderElectionNamespace == "" {
+			options.LeaderElectionNamespace = operatorNamespace
+		}
 	}
 
-	mgr, err := ctrl.NewManager(kubernetesConfig, options)
-	if err != nil {
-		return fmt.Errorf("constructing controllers manager: %w", err)
-	}
+	return options, nil
+}
 
-	err = mgr.AddReadyzCheck("ping", healthz.Ping)
-	if err != nil {
-		return err
+// appendNamespaces appends the specified namespaces to the given ones unless they're already listed.
+func appendNamespaces(namespaces []string, others ...string) []string {
+	result := append([]string{}, namespaces...)
+	for _, namespace := range others {
+		if !pod.SliceContainsString(result, namespace) {
+			result = append(result, namespace)
+		}
 	}
+	return result
+}
 
-	err = mgr.AddHealthzCheck("ping", healthz.Ping)
-	if err != nil {
-		return err
+// getNotifier returns the notifier of the configured webhooks, i.e. the generic webhook, Slack, or both.
+// Returns nil if no webhook URL is configured.
+func getNotifier(config etc.Operator) (notify.Notifier, error) {
+	var notifiers []notify.Notifier
+	if config.NotifyWebhookURL != "" {
+		notifiers = append(notifiers, notify.NewWebhookNotifier(config.NotifyWebhookURL, config.NotifyWebhookSecret))
 	}
-
-	scanner, err := getEnabledScanner(config)
+	if config.NotifySlackWebhookURL != "" {
+		notifiers = append(notifiers, notify.NewSlackNotifier(config.NotifySlackWebhookURL))
+	}
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+	_, err := notify.ParseSeverity(config.NotifySeverityThreshold)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
-
-	store := reports.NewStore(mgr.GetClient(), scheme)
-
-	if err = (&pod.PodController{
-		Config:  config.Operator,
-		Client:  mgr.GetClient(),
-		Store:   store,
-		Scanner: scanner,
-		Scheme:  mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		return fmt.Errorf("unable to create pod controller: %w", err)
+	setupLog.Info("Sending notifications", "webhook", config.NotifyWebhookURL != "",
+		"slack", config.NotifySlackWebhookURL != "", "severityThreshold", config.NotifySeverityThreshold)
+	if len(notifiers) == 1 {
+		return notifiers[0], nil
 	}
+	return notify.NewMultiNotifier(notifiers...), nil
+}
 
-	if err = (&job.JobController{
-		Config:     config.Operator,
-		LogsReader: logs.NewReader(kubernetesClientset),
-		Client:     mgr.GetClient(),
-		Store:      store,
-		Scanner:    scanner,
-		Scheme:     mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		return fmt.Errorf("unable to create job controller: %w", err)
+// getReportWriter returns the Writer of the configured report backend. The specified Store is
+// returned for the default CRD backend. If the S3 bucket is configured, reports are also uploaded to it.
+func getReportWriter(config etc.Operator, store *reports.Store) (reports.Writer, error) {
+	var writer reports.Writer
+	switch config.ReportBackend {
+	case etc.ReportBackendCRD:
+		setupLog.Info("Writing reports as custom resources")
+		writer = store
+	default:
+		return nil, fmt.Errorf("invalid configuration: unsupported report backend: %q", config.ReportBackend)
 	}
-
-	setupLog.Info("Starting controllers manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
-		return fmt.Errorf("starting controllers manager: %w", err)
+	if config.ReportS3Bucket == "" {
+		return writer, nil
 	}
-
-	return nil
+	setupLog.Info("Uploading reports to S3", "endpoint", config.ReportS3Endpoint, "bucket", config.ReportS3Bucket)
+	s3Writer, err := reports.NewS3Writer(writer, clock.RealClock{}, reports.S3Options{
+		Endpoint:  config.ReportS3Endpoint,
+		Bucket:    config.ReportS3Bucket,
+		Region:    config.ReportS3Region,
+		AccessKey: config.ReportS3AccessKey,
+		SecretKey: config.ReportS3SecretKey,
+		Insecure:  config.ReportS3Insecure,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return s3Writer, nil
 }
 
 func getEnabledScanner(config etc.Config) (scanner.VulnerabilityScanner, error) {
-	if config.ScannerTrivy.Enabled && config.ScannerAquaCSP.Enabled {
-		return nil, fmt.Errorf("invalid configuration: multiple vulnerability scanners enabled")
+	if err := config.ValidateScanners(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
-	if !config.ScannerTrivy.Enabled && !config.ScannerAquaCSP.Enabled {
-		return nil, fmt.Errorf("invalid configuration: none vulnerability scanner enabled")
+	name, err := config.GetScannerName()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
-	if config.ScannerTrivy.Enabled {
-		setupLog.Info("Using Trivy as vulnerability scanner", "version", config.ScannerTrivy.Version)
-		return trivy.NewScanner(config.ScannerTrivy), nil
+	vulnerabilityScanner, err := scanner.New(name, config, versionInfo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
-	if config.ScannerAquaCSP.Enabled {
-		setupLog.Info("Using Aqua CSP as vulnerability scanner", "version", config.ScannerAquaCSP.Version)
-		return aqua.NewScanner(versionInfo, config.ScannerAquaCSP), nil
+	setupLog.Info("Using vulnerability scanner", "name", vulnerabilityScanner.GetName())
+	if name == etc.ScannerNameTrivy && config.ScannerTrivy.GenerateSBOM {
+		setupLog.Info("Generating SBOMs", "format", config.ScannerTrivy.SBOMFormat)
 	}
-	return nil, errors.New("invalid configuration: unhandled vulnerability scanners config")
+	return vulnerabilityScanner, nil
 }
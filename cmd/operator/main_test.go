@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func TestNewManagerOptions(t *testing.T) {
+	testCases := []struct {
+		name                            string
+		config                          etc.Operator
+		expectedLeaderElection          bool
+		expectedLeaderElectionID        string
+		expectedLeaderElectionNamespace string
+		expectedMetricsBindAddress      string
+	}{
+		{
+			name: "Should disable leader election by default",
+			config: etc.Operator{
+				Namespace:        "starboard-operator",
+				TargetNamespaces: "default",
+				LeaderElectionID: "starboard-operator",
+			},
+			expectedLeaderElection: false,
+		},
+		{
+			name: "Should enable leader election in operator namespace",
+			config: etc.Operator{
+				Namespace:             "starboard-operator",
+				TargetNamespaces:      "default",
+				LeaderElectionEnabled: true,
+				LeaderElectionID:      "starboard-operator",
+			},
+			expectedLeaderElection:          true,
+			expectedLeaderElectionID:        "starboard-operator",
+			expectedLeaderElectionNamespace: "starboard-operator",
+		},
+		{
+			name: "Should enable leader election in configured namespace",
+			config: etc.Operator{
+				Namespace:               "starboard-operator",
+				TargetNamespaces:        "default",
+				LeaderElectionEnabled:   true,
+				LeaderElectionID:        "starboard-operator-lock",
+				LeaderElectionNamespace: "kube-system",
+			},
+			expectedLeaderElection:          true,
+			expectedLeaderElectionID:        "starboard-operator-lock",
+			expectedLeaderElectionNamespace: "kube-system",
+		},
+		{
+			name: "Should serve metrics over plain HTTP by manager",
+			config: etc.Operator{
+				Namespace:          "starboard-operator",
+				TargetNamespaces:   "default",
+				MetricsBindAddress: ":8080",
+			},
+			expectedMetricsBindAddress: ":8080",
+		},
+		{
+			name: "Should disable metrics server of manager when metrics are served over HTTPS",
+			config: etc.Operator{
+				Namespace:          "starboard-operator",
+				TargetNamespaces:   "default",
+				MetricsBindAddress: ":8443",
+				MetricsCertFile:    "/etc/metrics/tls.crt",
+				MetricsKeyFile:     "/etc/metrics/tls.key",
+			},
+			expectedMetricsBindAddress: "0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			installMode, err := tc.config.GetInstallMode()
+			require.NoError(t, err)
+
+			options, err := newManagerOptions(tc.config, installMode)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedLeaderElection, options.LeaderElection)
+			assert.Equal(t, tc.expectedLeaderElectionID, options.LeaderElectionID)
+			assert.Equal(t, tc.expectedLeaderElectionNamespace, options.LeaderElectionNamespace)
+			assert.Equal(t, tc.expectedMetricsBindAddress, options.MetricsBindAddress)
+			assert.Equal(t, "default", options.Namespace)
+		})
+	}
+}
+
+func TestDetectInstallMode(t *testing.T) {
+	// newClientset returns the clientset which allows the operator to list and watch Pods in all namespaces
+	// if the corresponding verb is allowed.
+	newClientset := func(allowed ...string) *fake.Clientset {
+		clientset := fake.NewSimpleClientset()
+		clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			attributes := review.Spec.ResourceAttributes
+			for _, verb := range allowed {
+				if attributes.Namespace == "" && attributes.Resource == "pods" && attributes.Verb == verb {
+					review.Status.Allowed = true
+				}
+			}
+			return true, review, nil
+		})
+		return clientset
+	}
+
+	testCases := []struct {
+		name                string
+		config              etc.Operator
+		clientset           *fake.Clientset
+		expectedInstallMode etc.InstallMode
+	}{
+		{
+			name: "Should detect AllNamespaces when operator is allowed to list and watch Pods in all namespaces",
+			config: etc.Operator{
+				Namespace: "starboard-operator",
+			},
+			clientset:           newClientset("list", "watch"),
+			expectedInstallMode: etc.InstallModeAllNamespaces,
+		},
+		{
+			name: "Should detect OwnNamespace when operator is not allowed to list Pods in all namespaces",
+			config: etc.Operator{
+				Namespace: "starboard-operator",
+			},
+			clientset:           newClientset(),
+			expectedInstallMode: etc.InstallModeOwnNamespace,
+		},
+		{
+			name: "Should detect OwnNamespace when operator is not allowed to watch Pods in all namespaces",
+			config: etc.Operator{
+				Namespace: "starboard-operator",
+			},
+			clientset:           newClientset("list"),
+			expectedInstallMode: etc.InstallModeOwnNamespace,
+		},
+		{
+			name: "Should fall back to explicitly set target namespaces",
+			config: etc.Operator{
+				Namespace:        "starboard-operator",
+				TargetNamespaces: "foo,bar",
+			},
+			clientset:           newClientset(),
+			expectedInstallMode: etc.InstallModeMultiNamespace,
+		},
+		{
+			name: "Should fall back to explicitly set all namespaces",
+			config: etc.Operator{
+				Namespace:        "starboard-operator",
+				TargetNamespaces: "*",
+			},
+			clientset:           newClientset(),
+			expectedInstallMode: etc.InstallModeAllNamespaces,
+		},
+		{
+			name: "Should fall back to explicitly set target namespace selector",
+			config: etc.Operator{
+				Namespace:               "starboard-operator",
+				TargetNamespaceSelector: "scan=true",
+			},
+			clientset:           newClientset(),
+			expectedInstallMode: etc.InstallModeAllNamespaces,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := detectInstallMode(context.TODO(), tc.clientset, tc.config)
+			require.NoError(t, err)
+			installMode, err := config.GetInstallMode()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedInstallMode, installMode)
+		})
+	}
+
+	t.Run("Should return error when access review fails", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("connection refused")
+		})
+		_, err := detectInstallMode(context.TODO(), clientset, etc.Operator{Namespace: "starboard-operator"})
+		require.EqualError(t, err, "creating self subject access review: connection refused")
+	})
+}
+
+func TestGetNotifier(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        etc.Operator
+		expectedNil   bool
+		expectedError string
+	}{
+		{
+			name:        "Should return nil when no webhook is configured",
+			config:      etc.Operator{NotifySeverityThreshold: "CRITICAL"},
+			expectedNil: true,
+		},
+		{
+			name: "Should return notifier for generic webhook",
+			config: etc.Operator{
+				NotifyWebhookURL:        "http://webhook.example.com",
+				NotifySeverityThreshold: "CRITICAL",
+			},
+		},
+		{
+			name: "Should return notifier for Slack",
+			config: etc.Operator{
+				NotifySlackWebhookURL:   "https://hooks.slack.com/services/T0/B0/s3cret",
+				NotifySeverityThreshold: "HIGH",
+			},
+		},
+		{
+			name: "Should return notifier for both generic webhook and Slack",
+			config: etc.Operator{
+				NotifyWebhookURL:        "http://webhook.example.com",
+				NotifySlackWebhookURL:   "https://hooks.slack.com/services/T0/B0/s3cret",
+				NotifySeverityThreshold: "HIGH",
+			},
+		},
+		{
+			name: "Should return error when severity threshold is invalid",
+			config: etc.Operator{
+				NotifySlackWebhookURL:   "https://hooks.slack.com/services/T0/B0/s3cret",
+				NotifySeverityThreshold: "SEVERE",
+			},
+			expectedError: `invalid configuration: unrecognized severity: "SEVERE"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifier, err := getNotifier(tc.config)
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedNil, notifier == nil)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestGetReportWriter(t *testing.T) {
+	store := reports.NewStore(nil, nil, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+
+	t.Run("Should return store for CRD backend", func(t *testing.T) {
+		writer, err := getReportWriter(etc.Operator{ReportBackend: etc.ReportBackendCRD}, store)
+		require.NoError(t, err)
+		assert.Equal(t, store, writer)
+	})
+
+	t.Run("Should return S3 writer wrapping store when bucket is configured", func(t *testing.T) {
+		writer, err := getReportWriter(etc.Operator{
+			ReportBackend:    etc.ReportBackendCRD,
+			ReportS3Endpoint: "minio.minio:9000",
+			ReportS3Bucket:   "reports",
+		}, store)
+		require.NoError(t, err)
+		require.IsType(t, &reports.S3Writer{}, writer)
+		assert.Equal(t, store, writer.(*reports.S3Writer).Writer)
+	})
+
+	t.Run("Should return error for unsupported backend", func(t *testing.T) {
+		_, err := getReportWriter(etc.Operator{ReportBackend: "S3"}, store)
+		assert.EqualError(t, err, `invalid configuration: unsupported report backend: "S3"`)
+	})
+}
+
+func TestGetEnabledScanner(t *testing.T) {
+	trivy := etc.ScannerTrivy{Enabled: true, Mode: etc.TrivyModeStandalone}
+
+	testCases := []struct {
+		name          string
+		config        etc.Config
+		expectedName  string
+		expectedError string
+	}{
+		{
+			name:         "Should return scanner enabled with flag",
+			config:       etc.Config{ScannerTrivy: trivy},
+			expectedName: "Trivy",
+		},
+		{
+			name: "Should return scanner selected by name",
+			config: etc.Config{
+				Operator:     etc.Operator{Scanner: "grype"},
+				ScannerTrivy: trivy,
+			},
+			expectedName: "Grype",
+		},
+		{
+			name:          "Should return error when scanner name is unknown",
+			config:        etc.Config{Operator: etc.Operator{Scanner: "clair"}},
+			expectedError: `invalid configuration: unknown vulnerability scanner "clair", registered scanners: aqua-csp, grype, scout, trivy`,
+		},
+		{
+			name:          "Should return error when multiple scanners are selected by name",
+			config:        etc.Config{Operator: etc.Operator{Scanner: "trivy,grype"}},
+			expectedError: "invalid configuration: multiple vulnerability scanners enabled: trivy,grype",
+		},
+		{
+			name: "Should return error when multiple scanners are enabled with flags",
+			config: etc.Config{
+				ScannerTrivy: trivy,
+				ScannerGrype: etc.ScannerGrype{Enabled: true},
+			},
+			expectedError: "invalid configuration: multiple vulnerability scanners enabled: trivy,grype",
+		},
+		{
+			name:          "Should return error when none scanner is enabled",
+			config:        etc.Config{},
+			expectedError: "invalid configuration: none vulnerability scanner enabled",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vulnerabilityScanner, err := getEnabledScanner(tc.config)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedName, vulnerabilityScanner.GetName())
+		})
+	}
+}
+
+func TestNewLoggerOptions(t *testing.T) {
+	testCases := []struct {
+		name                string
+		config              etc.Operator
+		expectedJSON        bool
+		expectedDebug       bool
+		expectedDevelopment bool
+		expectedError       string
+	}{
+		{
+			name:          "Should default to json and info",
+			config:        etc.Operator{},
+			expectedJSON:  true,
+			expectedDebug: false,
+		},
+		{
+			name:                "Should default to console and debug in development mode",
+			config:              etc.Operator{LogDevMode: true},
+			expectedJSON:        false,
+			expectedDebug:       true,
+			expectedDevelopment: true,
+		},
+		{
+			name:          "Should use configured console format and debug level",
+			config:        etc.Operator{LogFormat: "console", LogLevel: "debug"},
+			expectedJSON:  false,
+			expectedDebug: true,
+		},
+		{
+			name:                "Should use configured json format and error level in development mode",
+			config:              etc.Operator{LogDevMode: true, LogFormat: "json", LogLevel: "error"},
+			expectedJSON:        true,
+			expectedDebug:       false,
+			expectedDevelopment: true,
+		},
+		{
+			name:          "Should return error for unrecognized format",
+			config:        etc.Operator{LogFormat: "text"},
+			expectedError: `OPERATOR_LOG_FORMAT must be one of json or console but got "text"`,
+		},
+		{
+			name:          "Should return error for unrecognized level",
+			config:        etc.Operator{LogLevel: "verbose"},
+			expectedError: `parsing OPERATOR_LOG_LEVEL: unrecognized level: "verbose"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, err := newLoggerOptions(tc.config)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			options := &zap.Options{}
+			for _, opt := range opts {
+				opt(options)
+			}
+			assert.Equal(t, tc.expectedDevelopment, options.Development)
+			if tc.config.LogLevel != "" {
+				require.NotNil(t, options.Level)
+				assert.Equal(t, tc.expectedDebug, options.Level.Enabled(zapcore.DebugLevel))
+			} else {
+				assert.Nil(t, options.Level, "Level defaults to the one of the mode")
+			}
+			if tc.config.LogFormat != "" {
+				require.NotNil(t, options.Encoder)
+				buf, err := options.Encoder.EncodeEntry(zapcore.Entry{Message: "test"}, nil)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedJSON, strings.HasPrefix(buf.String(), "{"))
+			} else {
+				assert.Nil(t, options.Encoder, "Encoder defaults to the one of the mode")
+			}
+		})
+	}
+}
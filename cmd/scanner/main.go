@@ -1,9 +1,12 @@
 package main
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"log"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -14,14 +17,19 @@ import (
 )
 
 const (
-	hostFlag     = "host"
-	userFlag     = "user"
-	passwordFlag = "password"
+	hostFlag           = "host"
+	userFlag           = "user"
+	passwordFlag       = "password"
+	scannerVersionFlag = "scanner-version"
+	registerFlag       = "register"
+	registryFlag       = "registry"
+	insecureFlag       = "insecure"
 )
 
 type options struct {
 	baseURL     string
 	credentials client.UsernameAndPassword
+	cli         cli.Options
 }
 
 func main() {
@@ -51,6 +59,11 @@ func run() error {
 	rootCmd.Flags().StringVarP(&opt.credentials.Username, userFlag, "U", "", "Aqua management console username (required)")
 	rootCmd.Flags().StringVarP(&opt.credentials.Password, passwordFlag, "P", "", "Aqua management console password (required)")
 
+	rootCmd.Flags().StringVar(&opt.cli.Version, scannerVersionFlag, "", "Aqua CSP version reported as the scanner version")
+	rootCmd.Flags().BoolVar(&opt.cli.Register, registerFlag, false, "Register scanned image in Aqua registry")
+	rootCmd.Flags().StringVar(&opt.cli.Registry, registryFlag, "", "Aqua registry name to register scanned image in")
+	rootCmd.Flags().BoolVar(&opt.cli.Insecure, insecureFlag, false, "Skip verification of Aqua management console TLS certificate")
+
 	_ = rootCmd.MarkFlagRequired(hostFlag)
 	_ = rootCmd.MarkFlagRequired(userFlag)
 	_ = rootCmd.MarkFlagRequired(passwordFlag)
@@ -61,18 +74,27 @@ func run() error {
 // scan scans the specified image reference. Firstly, attempt to download a vulnerability
 // report with Aqua REST API call. If the report is not found, execute the `scannercli scan` command.
 func scan(opt options, imageRef string) (report v1alpha1.VulnerabilityScanResult, err error) {
-	clientset := client.NewClient(opt.baseURL, client.Authorization{
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	if opt.cli.Insecure {
+		httpClient.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+	clientset := client.NewClientWithHTTPClient(opt.baseURL, client.Authorization{
 		Basic: &opt.credentials,
-	})
+	}, httpClient)
 
-	report, err = api.NewScanner(clientset).Scan(imageRef)
+	report, err = api.NewScanner(clientset, opt.cli.Version).Scan(imageRef)
 	if err == nil {
 		return
 	}
 	if err != client.ErrNotFound {
 		return
 	}
-	report, err = cli.NewScanner(opt.baseURL, opt.credentials).Scan(imageRef)
+	report, err = cli.NewScanner(opt.baseURL, opt.credentials, opt.cli).Scan(imageRef)
 	if err != nil {
 		return
 	}
@@ -9,10 +9,16 @@ require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/google/go-containerregistry v0.1.1
 	github.com/google/uuid v1.1.1
+	github.com/minio/minio-go/v7 v7.0.5
 	github.com/onsi/ginkgo v1.14.0
 	github.com/onsi/gomega v1.10.1
+	github.com/open-policy-agent/opa v0.24.0
+	github.com/prometheus/client_golang v1.0.0
+	github.com/prometheus/client_model v0.2.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/spf13/cobra v1.0.0
 	github.com/stretchr/testify v1.5.1
+	go.uber.org/zap v1.10.0
 	k8s.io/api v0.19.0-alpha.3
 	k8s.io/apimachinery v0.19.0-alpha.3
 	k8s.io/client-go v0.19.0-alpha.3
@@ -0,0 +1,143 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	"github.com/aquasecurity/starboard-operator/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var (
+	log = ctrl.Log.WithName("webhook").WithName("pod")
+)
+
+// WebhookPath is the path which the validating webhook of Pods is served at.
+const WebhookPath = "/validate-v1-pod"
+
+// Policy holds settings which decide whether Pods are admitted.
+type Policy struct {
+	// CriticalThreshold is the maximum number of critical vulnerabilities of an image run by an admitted Pod.
+	CriticalThreshold int
+	// AllowUnscanned allows Pods which run images without reports, e.g. not scanned yet.
+	AllowUnscanned bool
+}
+
+// ContainerReport holds the report of the image run by a container, if any.
+type ContainerReport struct {
+	Container string
+	Image     string
+	// Report is nil if there's no report of the image.
+	Report *reports.DigestReport
+}
+
+// Decision represents the outcome of validating a Pod.
+type Decision struct {
+	Allowed bool
+	Reason  string
+}
+
+// Decide returns the Decision on the admission of a Pod whose containers run images with the specified reports.
+// The Pod is denied if any of its images has more critical vulnerabilities than the threshold, or if any of its
+// images has no report and unscanned images are not allowed. The reason lists all offending containers.
+func Decide(policy Policy, containerReports []ContainerReport) Decision {
+	var reasons []string
+	for _, cr := range containerReports {
+		if cr.Report == nil {
+			if !policy.AllowUnscanned {
+				reasons = append(reasons, fmt.Sprintf("image %s of container %s has not been scanned", cr.Image, cr.Container))
+			}
+			continue
+		}
+		if critical := cr.Report.Report.Summary.CriticalCount; critical > policy.CriticalThreshold {
+			reasons = append(reasons, fmt.Sprintf("image %s of container %s has %d critical vulnerabilities, which exceeds the threshold of %d",
+				cr.Image, cr.Container, critical, policy.CriticalThreshold))
+		}
+	}
+	if len(reasons) > 0 {
+		return Decision{Allowed: false, Reason: strings.Join(reasons, "; ")}
+	}
+	return Decision{Allowed: true}
+}
+
+// ImageIDReader returns IDs of images run by containers of a Pod, e.g. pod.PodController. IDs of images
+// which are not known, e.g. because they're referenced by tags and not pulled yet, are omitted.
+type ImageIDReader interface {
+	GetContainerImageIDs(ctx context.Context, pod *corev1.Pod) (map[string]string, error)
+}
+
+// PodValidator is the validating webhook which denies the creation of Pods running images with known
+// critical vulnerabilities, as reported by VulnerabilityReports of images with the same digests.
+type PodValidator struct {
+	Config        etc.Operator
+	DigestReader  reports.DigestReader
+	ImageIDReader ImageIDReader
+	decoder       *admission.Decoder
+}
+
+func (v *PodValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	err := v.decoder.Decode(req, pod)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// The namespace of Pods created from templates is not set in the object.
+	if pod.Namespace == "" {
+		pod.Namespace = req.Namespace
+	}
+	log := log.WithValues("pod", fmt.Sprintf("%s/%s", pod.Namespace, pod.GetName()+pod.GetGenerateName()))
+
+	// Scan Job Pods run in the operator namespace, or in the scan Jobs namespace, and must never be blocked.
+	if pod.Namespace == v.Config.Namespace || pod.Namespace == v.Config.GetScanJobsNamespace() ||
+		v.Config.IsNamespaceExcluded(pod.Namespace) {
+		return admission.Allowed("")
+	}
+
+	containerReports, err := v.GetContainerReports(ctx, pod)
+	if err != nil {
+		log.Error(err, "Unable to get vulnerability reports")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	decision := Decide(Policy{
+		CriticalThreshold: v.Config.AdmissionMaxCritical,
+		AllowUnscanned:    v.Config.AdmissionAllowUnscanned,
+	}, containerReports)
+	if !decision.Allowed {
+		log.Info("Denying Pod", "reason", decision.Reason)
+		return admission.Denied(decision.Reason)
+	}
+	return admission.Allowed("")
+}
+
+// GetContainerReports returns reports of images run by init containers and containers of the specified Pod.
+// Reports are looked up by image digests, hence images with unknown digests have no reports.
+func (v *PodValidator) GetContainerReports(ctx context.Context, pod *corev1.Pod) ([]ContainerReport, error) {
+	imageIDs, err := v.ImageIDReader.GetContainerImageIDs(ctx, pod)
+	if err != nil {
+		return nil, fmt.Errorf("getting container image ids: %w", err)
+	}
+	var containerReports []ContainerReport
+	for _, container := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		containerReport := ContainerReport{Container: container.Name, Image: container.Image}
+		if digest := resources.GetDigestFromImageID(imageIDs[container.Name]); digest != "" {
+			containerReport.Report, err = v.DigestReader.FindByDigest(ctx, digest)
+			if err != nil {
+				return nil, err
+			}
+		}
+		containerReports = append(containerReports, containerReport)
+	}
+	return containerReports, nil
+}
+
+// InjectDecoder injects the decoder of admission requests.
+func (v *PodValidator) InjectDecoder(decoder *admission.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
@@ -0,0 +1,186 @@
+package admission_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/admission"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrladmission "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const nginxDigest = "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c"
+
+func newReport(critical int) *reports.DigestReport {
+	return &reports.DigestReport{
+		Report: v1alpha1.VulnerabilityScanResult{
+			Summary: v1alpha1.VulnerabilitySummary{CriticalCount: critical},
+		},
+	}
+}
+
+func TestDecide(t *testing.T) {
+	testCases := []struct {
+		name             string
+		policy           admission.Policy
+		containerReports []admission.ContainerReport
+		expectedDecision admission.Decision
+	}{
+		{
+			name:   "Should allow pod with critical vulnerabilities at threshold",
+			policy: admission.Policy{CriticalThreshold: 1},
+			containerReports: []admission.ContainerReport{
+				{Container: "nginx", Image: "nginx:1.16", Report: newReport(1)},
+			},
+			expectedDecision: admission.Decision{Allowed: true},
+		},
+		{
+			name:   "Should deny pod with critical vulnerabilities above threshold",
+			policy: admission.Policy{CriticalThreshold: 0},
+			containerReports: []admission.ContainerReport{
+				{Container: "init", Image: "busybox:1.32", Report: newReport(0)},
+				{Container: "nginx", Image: "nginx:1.16", Report: newReport(3)},
+			},
+			expectedDecision: admission.Decision{
+				Allowed: false,
+				Reason:  "image nginx:1.16 of container nginx has 3 critical vulnerabilities, which exceeds the threshold of 0",
+			},
+		},
+		{
+			name:   "Should list all containers with critical vulnerabilities above threshold",
+			policy: admission.Policy{CriticalThreshold: 1},
+			containerReports: []admission.ContainerReport{
+				{Container: "nginx", Image: "nginx:1.16", Report: newReport(2)},
+				{Container: "redis", Image: "redis:5", Report: newReport(4)},
+			},
+			expectedDecision: admission.Decision{
+				Allowed: false,
+				Reason: "image nginx:1.16 of container nginx has 2 critical vulnerabilities, which exceeds the threshold of 1; " +
+					"image redis:5 of container redis has 4 critical vulnerabilities, which exceeds the threshold of 1",
+			},
+		},
+		{
+			name:   "Should allow pod with unscanned image when unscanned images are allowed",
+			policy: admission.Policy{AllowUnscanned: true},
+			containerReports: []admission.ContainerReport{
+				{Container: "nginx", Image: "nginx:1.16"},
+			},
+			expectedDecision: admission.Decision{Allowed: true},
+		},
+		{
+			name:   "Should deny pod with unscanned image when unscanned images are not allowed",
+			policy: admission.Policy{AllowUnscanned: false},
+			containerReports: []admission.ContainerReport{
+				{Container: "nginx", Image: "nginx:1.16"},
+			},
+			expectedDecision: admission.Decision{
+				Allowed: false,
+				Reason:  "image nginx:1.16 of container nginx has not been scanned",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedDecision, admission.Decide(tc.policy, tc.containerReports))
+		})
+	}
+}
+
+type fakeDigestReader struct {
+	reports map[string]*reports.DigestReport
+}
+
+func (r *fakeDigestReader) FindByDigest(_ context.Context, digest string) (*reports.DigestReport, error) {
+	return r.reports[digest], nil
+}
+
+type fakeImageIDReader struct {
+	imageIDs map[string]string
+}
+
+func (r *fakeImageIDReader) GetContainerImageIDs(_ context.Context, _ *corev1.Pod) (map[string]string, error) {
+	return r.imageIDs, nil
+}
+
+func newRequest(t *testing.T, namespace string) ctrladmission.Request {
+	t.Helper()
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "nginx", Image: "nginx@" + nginxDigest}},
+		},
+	}
+	raw, err := json.Marshal(pod)
+	require.NoError(t, err)
+	return ctrladmission.Request{
+		AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Namespace: namespace,
+			Operation: admissionv1beta1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+func TestPodValidator_Handle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	decoder, err := ctrladmission.NewDecoder(scheme)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name            string
+		namespace       string
+		reports         map[string]*reports.DigestReport
+		expectedAllowed bool
+	}{
+		{
+			name:            "Should deny pod running image with critical vulnerabilities",
+			namespace:       "default",
+			reports:         map[string]*reports.DigestReport{nginxDigest: newReport(1)},
+			expectedAllowed: false,
+		},
+		{
+			name:            "Should allow pod running image without critical vulnerabilities",
+			namespace:       "default",
+			reports:         map[string]*reports.DigestReport{nginxDigest: newReport(0)},
+			expectedAllowed: true,
+		},
+		{
+			name:            "Should allow pod in operator namespace",
+			namespace:       "starboard-operator",
+			reports:         map[string]*reports.DigestReport{nginxDigest: newReport(1)},
+			expectedAllowed: true,
+		},
+		{
+			name:            "Should allow pod in scan jobs namespace",
+			namespace:       "starboard-scans",
+			reports:         map[string]*reports.DigestReport{nginxDigest: newReport(1)},
+			expectedAllowed: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := &admission.PodValidator{
+				Config:        etc.Operator{Namespace: "starboard-operator", ScanJobsNamespace: "starboard-scans"},
+				DigestReader:  &fakeDigestReader{reports: tc.reports},
+				ImageIDReader: &fakeImageIDReader{imageIDs: map[string]string{"nginx": "nginx@" + nginxDigest}},
+			}
+			require.NoError(t, validator.InjectDecoder(decoder))
+
+			response := validator.Handle(context.Background(), newRequest(t, tc.namespace))
+			assert.Equal(t, tc.expectedAllowed, response.Allowed)
+		})
+	}
+}
@@ -60,9 +60,14 @@ type Client struct {
 
 // NewClient constructs a new API client with the specified base URL and authorization details.
 func NewClient(baseURL string, authorization Authorization) *Client {
-	httpClient := &http.Client{
+	return NewClientWithHTTPClient(baseURL, authorization, &http.Client{
 		Timeout: defaultTimeout,
-	}
+	})
+}
+
+// NewClientWithHTTPClient constructs a new API client which sends requests with the specified HTTP client,
+// e.g. to skip verification of the TLS certificate of the management console.
+func NewClientWithHTTPClient(baseURL string, authorization Authorization, httpClient *http.Client) *Client {
 	client := &client{
 		baseURL:       baseURL,
 		authorization: authorization,
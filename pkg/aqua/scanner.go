@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"io"
 
-	"k8s.io/apimachinery/pkg/api/resource"
-
 	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
 	"github.com/aquasecurity/starboard/pkg/scanners"
 
@@ -21,15 +19,19 @@ import (
 	"k8s.io/utils/pointer"
 )
 
-const (
-	secretName = "starboard-operator"
-)
+func init() {
+	scanner.Register(etc.ScannerNameAquaCSP, func(config etc.Config, version etc.VersionInfo) scanner.VulnerabilityScanner {
+		return NewScanner(version, config.ScannerAquaCSP)
+	})
+}
 
 type aquaScanner struct {
 	version etc.VersionInfo
 	config  etc.ScannerAquaCSP
 }
 
+// NewScanner constructs the Aqua CSP scanner with the specified config, which must be valid according to
+// etc.ScannerAquaCSP.Validate. The version of the operator is only used to tag the default wrapper image.
 func NewScanner(version etc.VersionInfo, config etc.ScannerAquaCSP) scanner.VulnerabilityScanner {
 	return &aquaScanner{
 		version: version,
@@ -37,13 +39,18 @@ func NewScanner(version etc.VersionInfo, config etc.ScannerAquaCSP) scanner.Vuln
 	}
 }
 
+func (s *aquaScanner) GetName() string {
+	return "Aqua CSP"
+}
+
 func (s *aquaScanner) NewScanJob(meta scanner.JobMeta, options scanner.Options, spec corev1.PodSpec) (*batchv1.Job, error) {
 	jobName := uuid.New().String()
 	initContainerName := jobName
 
-	scanJobContainers := make([]corev1.Container, len(spec.Containers))
-	for i, container := range spec.Containers {
-		scanJobContainers[i] = s.newScanJobContainer(container)
+	containers := scanner.GetContainersToScan(spec)
+	scanJobContainers := make([]corev1.Container, len(containers))
+	for i, container := range containers {
+		scanJobContainers[i] = s.newScanJobContainer(container, options)
 	}
 
 	return &batchv1.Job{
@@ -54,9 +61,10 @@ func (s *aquaScanner) NewScanJob(meta scanner.JobMeta, options scanner.Options,
 			Annotations: meta.Annotations,
 		},
 		Spec: batchv1.JobSpec{
-			BackoffLimit:          pointer.Int32Ptr(0),
-			Completions:           pointer.Int32Ptr(1),
-			ActiveDeadlineSeconds: scanners.GetActiveDeadlineSeconds(options.ScanJobTimeout),
+			BackoffLimit:            pointer.Int32Ptr(options.ScanJobBackoffLimit),
+			Completions:             pointer.Int32Ptr(1),
+			ActiveDeadlineSeconds:   scanners.GetActiveDeadlineSeconds(options.ScanJobTimeout),
+			TTLSecondsAfterFinished: options.ScanJobTTLSecondsAfterFinished,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      meta.Labels,
@@ -65,9 +73,16 @@ func (s *aquaScanner) NewScanJob(meta scanner.JobMeta, options scanner.Options,
 				Spec: corev1.PodSpec{
 					RestartPolicy:                corev1.RestartPolicyNever,
 					ServiceAccountName:           options.ServiceAccountName,
+					PriorityClassName:            options.PriorityClassName,
 					AutomountServiceAccountToken: pointer.BoolPtr(false),
-					NodeName:                     spec.NodeName,
-					Volumes: []corev1.Volume{
+					// The scan Job must run on the same node as the scanned workload to access
+					// its images with the Docker socket. Therefore, the node selector and affinity
+					// are not applied, whereas tolerations are needed to run on tainted nodes.
+					NodeName:    spec.NodeName,
+					Tolerations: options.ScanJobTolerations,
+					HostAliases: options.ScanJobHostAliases,
+					DNSConfig:   options.ScanJobDNSConfig,
+					Volumes: append([]corev1.Volume{
 						{
 							Name: "scannercli",
 							VolumeSource: corev1.VolumeSource{
@@ -82,11 +97,12 @@ func (s *aquaScanner) NewScanJob(meta scanner.JobMeta, options scanner.Options,
 								},
 							},
 						},
-					},
+					}, options.ScanJobVolumes...),
 					InitContainers: []corev1.Container{
 						{
-							Name:  initContainerName,
-							Image: s.config.ImageRef,
+							Name:            initContainerName,
+							Image:           s.config.ImageRef,
+							ImagePullPolicy: options.ScanJobImagePullPolicy,
 							Command: []string{
 								"cp",
 								"/opt/aquasec/scannercli",
@@ -107,64 +123,28 @@ func (s *aquaScanner) NewScanJob(meta scanner.JobMeta, options scanner.Options,
 	}, nil
 }
 
-func (s *aquaScanner) newScanJobContainer(podContainer corev1.Container) corev1.Container {
+// newScanJobContainer constructs a container which scans the image of the specified Pod container.
+//
+// Note that registry credentials passed in Options are not used, because scannercli is run
+// with the --local flag against the Docker daemon of the node, which already pulled the image
+// with the workload's image pull Secrets.
+func (s *aquaScanner) newScanJobContainer(podContainer corev1.Container, options scanner.Options) corev1.Container {
 	return corev1.Container{
 		Name:            podContainer.Name,
-		Image:           fmt.Sprintf("aquasec/starboard-scanner-aqua:%s", s.version.Version),
-		ImagePullPolicy: corev1.PullIfNotPresent,
+		Image:           s.config.GetWrapperImageRef(s.version),
+		ImagePullPolicy: options.ScanJobImagePullPolicy,
 		Command: []string{
 			"/bin/sh",
 			"-c",
-			fmt.Sprintf("/usr/local/bin/scanner --host $(OPERATOR_SCANNER_AQUA_CSP_HOST) --user $(OPERATOR_SCANNER_AQUA_CSP_USERNAME) --password $(OPERATOR_SCANNER_AQUA_CSP_PASSWORD) %s 2> %s",
+			fmt.Sprintf("/usr/local/bin/scanner --host $(OPERATOR_SCANNER_AQUA_CSP_HOST) --user $(OPERATOR_SCANNER_AQUA_CSP_USERNAME) --password $(OPERATOR_SCANNER_AQUA_CSP_PASSWORD)%s %s 2> %s",
+				s.newScannerFlags(),
 				podContainer.Image,
 				corev1.TerminationMessagePathDefault),
 		},
-		Env: []corev1.EnvVar{
-			{
-				Name: "OPERATOR_SCANNER_AQUA_CSP_HOST",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: secretName,
-						},
-						Key: "OPERATOR_SCANNER_AQUA_CSP_HOST",
-					},
-				},
-			},
-			{
-				Name: "OPERATOR_SCANNER_AQUA_CSP_USERNAME",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: secretName,
-						},
-						Key: "OPERATOR_SCANNER_AQUA_CSP_USERNAME",
-					},
-				},
-			},
-			{
-				Name: "OPERATOR_SCANNER_AQUA_CSP_PASSWORD",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: secretName,
-						},
-						Key: "OPERATOR_SCANNER_AQUA_CSP_PASSWORD",
-					},
-				},
-			},
-		},
-		Resources: corev1.ResourceRequirements{
-			Requests: corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse("100m"),
-				corev1.ResourceMemory: resource.MustParse("100M"),
-			},
-			Limits: corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse("500m"),
-				corev1.ResourceMemory: resource.MustParse("500M"),
-			},
-		},
-		VolumeMounts: []corev1.VolumeMount{
+		Env:       append(append(s.newCredentialsEnvVars(), s.newConfigEnvVars()...), scanner.NewProxyEnvVars(options)...),
+		EnvFrom:   options.ScanJobEnvFrom,
+		Resources: options.ScanJobResources,
+		VolumeMounts: append([]corev1.VolumeMount{
 			{
 				Name:      "scannercli",
 				MountPath: "/usr/local/bin/scannercli",
@@ -174,8 +154,72 @@ func (s *aquaScanner) newScanJobContainer(podContainer corev1.Container) corev1.
 				Name:      "dockersock",
 				MountPath: "/var/run/docker.sock",
 			},
+		}, options.ScanJobVolumeMounts...),
+	}
+}
+
+// newScannerFlags returns optional flags of the wrapper, each preceded by a space. The registry name is read
+// from the environment by the shell, so that names with spaces, e.g. Docker Hub, are passed as a single argument.
+func (s *aquaScanner) newScannerFlags() string {
+	var flags string
+	if s.config.Version != "" {
+		flags += " --scanner-version $(OPERATOR_SCANNER_AQUA_CSP_VERSION)"
+	}
+	if s.config.Register {
+		flags += ` --register --registry "$OPERATOR_SCANNER_AQUA_CSP_REGISTRY"`
+	}
+	if s.config.Insecure {
+		flags += " --insecure"
+	}
+	return flags
+}
+
+// newConfigEnvVars returns environment variables holding the configured version of Aqua CSP and the name of
+// the registry which scanned images are registered in.
+func (s *aquaScanner) newConfigEnvVars() []corev1.EnvVar {
+	var envs []corev1.EnvVar
+	if s.config.Version != "" {
+		envs = append(envs, corev1.EnvVar{
+			Name:  "OPERATOR_SCANNER_AQUA_CSP_VERSION",
+			Value: s.config.Version,
+		})
+	}
+	if s.config.Register {
+		envs = append(envs, corev1.EnvVar{
+			Name:  "OPERATOR_SCANNER_AQUA_CSP_REGISTRY",
+			Value: s.config.Registry,
+		})
+	}
+	return envs
+}
+
+// newCredentialsEnvVars returns environment variables holding the address and credentials of the Aqua CSP
+// management console. Credentials are read from the configured Secret so that they never show up in the scan Job
+// spec, whereas the address, which is not sensitive, is set as is.
+func (s *aquaScanner) newCredentialsEnvVars() []corev1.EnvVar {
+	envs := []corev1.EnvVar{
+		{
+			Name:  "OPERATOR_SCANNER_AQUA_CSP_HOST",
+			Value: s.config.Host,
 		},
 	}
+	for _, key := range []string{
+		"OPERATOR_SCANNER_AQUA_CSP_USERNAME",
+		"OPERATOR_SCANNER_AQUA_CSP_PASSWORD",
+	} {
+		envs = append(envs, corev1.EnvVar{
+			Name: key,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: s.config.GetCredentialsSecret(),
+					},
+					Key: key,
+				},
+			},
+		})
+	}
+	return envs
 }
 
 func (s *aquaScanner) ParseVulnerabilityScanResult(_ string, logsReader io.ReadCloser) (v1alpha1.VulnerabilityScanResult, error) {
@@ -14,11 +14,14 @@ const (
 
 type Scanner struct {
 	clientset client.Clientset
+	// version is the version of Aqua CSP reported as the scanner version.
+	version string
 }
 
-func NewScanner(clientset client.Clientset) *Scanner {
+func NewScanner(clientset client.Clientset, version string) *Scanner {
 	return &Scanner{
 		clientset: clientset,
+		version:   version,
 	}
 }
 
@@ -84,7 +87,8 @@ func (s *Scanner) convert(ref name.Reference, response client.VulnerabilitiesRes
 	return v1alpha1.VulnerabilityScanResult{
 		Scanner: v1alpha1.Scanner{
 			Name:    "Aqua CSP",
-			Version: "Aqua Security",
+			Vendor:  "Aqua Security",
+			Version: s.version,
 		},
 		Registry: v1alpha1.Registry{
 			Server: ref.Context().RegistryStr(),
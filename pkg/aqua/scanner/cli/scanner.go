@@ -10,30 +10,33 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 )
 
+// Options are optional settings of scannercli.
+type Options struct {
+	// Version is the version of Aqua CSP reported as the scanner version.
+	Version string
+	// Registry is the name of the Aqua registry which scanned images are registered in if Register is true.
+	Registry string
+	Register bool
+	// Insecure skips verification of the TLS certificate of the management console.
+	Insecure bool
+}
+
 type Scanner struct {
 	baseURL     string
 	credentials client.UsernameAndPassword
+	options     Options
 }
 
-func NewScanner(baseURL string, credentials client.UsernameAndPassword) *Scanner {
+func NewScanner(baseURL string, credentials client.UsernameAndPassword, options Options) *Scanner {
 	return &Scanner{
 		baseURL:     baseURL,
 		credentials: credentials,
+		options:     options,
 	}
 }
 
 func (s *Scanner) Scan(imageRef string) (report v1alpha1.VulnerabilityScanResult, err error) {
-	args := []string{
-		"scan",
-		"--checkonly",
-		"--dockerless",
-		fmt.Sprintf("--host=%s", s.baseURL),
-		fmt.Sprintf("--user=%s", s.credentials.Username),
-		fmt.Sprintf("--password=%s", s.credentials.Password),
-		"--local",
-		imageRef,
-	}
-	command := exec.Command("scannercli", args...)
+	command := exec.Command("scannercli", s.args(imageRef)...)
 
 	out, err := command.Output()
 	if err != nil {
@@ -48,6 +51,27 @@ func (s *Scanner) Scan(imageRef string) (report v1alpha1.VulnerabilityScanResult
 	return s.convert(imageRef, aquaReport)
 }
 
+// args returns arguments of scannercli. Images are only checked against the management console unless they're
+// registered in the configured registry.
+func (s *Scanner) args(imageRef string) []string {
+	args := []string{
+		"scan",
+		"--dockerless",
+		fmt.Sprintf("--host=%s", s.baseURL),
+		fmt.Sprintf("--user=%s", s.credentials.Username),
+		fmt.Sprintf("--password=%s", s.credentials.Password),
+	}
+	if s.options.Register {
+		args = append(args, "--register", fmt.Sprintf("--registry=%s", s.options.Registry))
+	} else {
+		args = append(args, "--checkonly")
+	}
+	if s.options.Insecure {
+		args = append(args, "--no-verify")
+	}
+	return append(args, "--local", imageRef)
+}
+
 func (s *Scanner) convert(imageRef string, aquaReport ScanReport) (report v1alpha1.VulnerabilityScanResult, err error) {
 	items := make([]v1alpha1.Vulnerability, 0)
 
@@ -91,9 +115,9 @@ func (s *Scanner) convert(imageRef string, aquaReport ScanReport) (report v1alph
 
 	report = v1alpha1.VulnerabilityScanResult{
 		Scanner: v1alpha1.Scanner{
-			Name:   "Aqua CSP",
-			Vendor: "Aqua Security",
-			//Version: c.config.Version,
+			Name:    "Aqua CSP",
+			Vendor:  "Aqua Security",
+			Version: s.options.Version,
 		},
 		Registry: v1alpha1.Registry{
 			Server: ref.Context().RegistryStr(),
@@ -0,0 +1,165 @@
+package aqua_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/aqua"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestAquaScanner_NewScanJob(t *testing.T) {
+	version := etc.VersionInfo{Version: "0.5.0"}
+	options := scanner.Options{
+		Namespace:          "starboard-operator",
+		ServiceAccountName: "starboard-operator",
+		ScanJobTimeout:     5 * time.Minute,
+	}
+	spec := corev1.PodSpec{
+		NodeName: "worker-1",
+		Containers: []corev1.Container{
+			{Name: "nginx", Image: "nginx:1.16"},
+		},
+	}
+
+	t.Run("Should use default wrapper image tagged with operator version", func(t *testing.T) {
+		job, err := aqua.NewScanner(version, etc.ScannerAquaCSP{
+			ImageRef: "aquasec/scanner:5.0",
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		require.Len(t, job.Spec.Template.Spec.InitContainers, 1)
+		assert.Equal(t, "aquasec/scanner:5.0", job.Spec.Template.Spec.InitContainers[0].Image)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, "aquasec/starboard-scanner-aqua:0.5.0", job.Spec.Template.Spec.Containers[0].Image)
+	})
+
+	t.Run("Should read credentials from secret", func(t *testing.T) {
+		testCases := []struct {
+			name           string
+			config         etc.ScannerAquaCSP
+			expectedSecret string
+		}{
+			{
+				name:           "Should read credentials from default secret",
+				config:         etc.ScannerAquaCSP{ImageRef: "aquasec/scanner:5.0", Host: "https://aqua.example.com"},
+				expectedSecret: "starboard-operator",
+			},
+			{
+				name: "Should read credentials from configured secret",
+				config: etc.ScannerAquaCSP{
+					ImageRef:          "aquasec/scanner:5.0",
+					Host:              "https://aqua.example.com",
+					CredentialsSecret: "aqua-credentials",
+				},
+				expectedSecret: "aqua-credentials",
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				job, err := aqua.NewScanner(version, tc.config).NewScanJob(scanner.JobMeta{}, options, spec)
+				require.NoError(t, err)
+				require.Len(t, job.Spec.Template.Spec.Containers, 1)
+
+				envs := job.Spec.Template.Spec.Containers[0].Env
+				require.NotEmpty(t, envs)
+				assert.Equal(t, corev1.EnvVar{Name: "OPERATOR_SCANNER_AQUA_CSP_HOST", Value: "https://aqua.example.com"}, envs[0],
+					"Host is not sensitive, hence it's set as is")
+
+				var names []string
+				for _, env := range envs[1:] {
+					names = append(names, env.Name)
+					assert.Empty(t, env.Value)
+					require.NotNil(t, env.ValueFrom)
+					require.NotNil(t, env.ValueFrom.SecretKeyRef)
+					assert.Equal(t, tc.expectedSecret, env.ValueFrom.SecretKeyRef.Name)
+					assert.Equal(t, env.Name, env.ValueFrom.SecretKeyRef.Key)
+				}
+				assert.Equal(t, []string{
+					"OPERATOR_SCANNER_AQUA_CSP_USERNAME",
+					"OPERATOR_SCANNER_AQUA_CSP_PASSWORD",
+				}, names)
+			})
+		}
+	})
+
+	t.Run("Should set environment variables from configmaps and secrets", func(t *testing.T) {
+		options := options
+		options.ScanJobEnvFrom = []corev1.EnvFromSource{
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "scanner-env"}}},
+		}
+		job, err := aqua.NewScanner(version, etc.ScannerAquaCSP{
+			ImageRef: "aquasec/scanner:5.0",
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, options.ScanJobEnvFrom, job.Spec.Template.Spec.Containers[0].EnvFrom)
+	})
+
+	t.Run("Should pass configured options to scanner", func(t *testing.T) {
+		job, err := aqua.NewScanner(version, etc.ScannerAquaCSP{
+			ImageRef: "aquasec/scanner:5.0",
+			Version:  "5.0",
+			Host:     "https://aqua.example.com",
+			Registry: "Docker Hub",
+			Register: true,
+			Insecure: true,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		container := job.Spec.Template.Spec.Containers[0]
+		assert.Equal(t, []string{
+			"/bin/sh",
+			"-c",
+			"/usr/local/bin/scanner --host $(OPERATOR_SCANNER_AQUA_CSP_HOST) --user $(OPERATOR_SCANNER_AQUA_CSP_USERNAME) --password $(OPERATOR_SCANNER_AQUA_CSP_PASSWORD)" +
+				` --scanner-version $(OPERATOR_SCANNER_AQUA_CSP_VERSION) --register --registry "$OPERATOR_SCANNER_AQUA_CSP_REGISTRY" --insecure` +
+				" nginx:1.16 2> /dev/termination-log",
+		}, container.Command)
+		assert.Equal(t, []corev1.EnvVar{
+			{Name: "OPERATOR_SCANNER_AQUA_CSP_HOST", Value: "https://aqua.example.com"},
+			{
+				Name: "OPERATOR_SCANNER_AQUA_CSP_USERNAME",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "starboard-operator"},
+						Key:                  "OPERATOR_SCANNER_AQUA_CSP_USERNAME",
+					},
+				},
+			},
+			{
+				Name: "OPERATOR_SCANNER_AQUA_CSP_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "starboard-operator"},
+						Key:                  "OPERATOR_SCANNER_AQUA_CSP_PASSWORD",
+					},
+				},
+			},
+			{Name: "OPERATOR_SCANNER_AQUA_CSP_VERSION", Value: "5.0"},
+			{Name: "OPERATOR_SCANNER_AQUA_CSP_REGISTRY", Value: "Docker Hub"},
+		}, container.Env)
+	})
+
+	t.Run("Should only check images by default", func(t *testing.T) {
+		job, err := aqua.NewScanner(version, etc.ScannerAquaCSP{
+			ImageRef: "aquasec/scanner:5.0",
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, "/usr/local/bin/scanner --host $(OPERATOR_SCANNER_AQUA_CSP_HOST) --user $(OPERATOR_SCANNER_AQUA_CSP_USERNAME) --password $(OPERATOR_SCANNER_AQUA_CSP_PASSWORD) nginx:1.16 2> /dev/termination-log",
+			job.Spec.Template.Spec.Containers[0].Command[2])
+	})
+
+	t.Run("Should use configured images from mirror registry", func(t *testing.T) {
+		job, err := aqua.NewScanner(version, etc.ScannerAquaCSP{
+			ImageRef:        "registry.local:5000/aquasec/scanner:5.0",
+			WrapperImageRef: "registry.local:5000/aquasec/starboard-scanner-aqua:0.5.0",
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		assert.Equal(t, "registry.local:5000/aquasec/scanner:5.0", job.Spec.Template.Spec.InitContainers[0].Image)
+		assert.Equal(t, "registry.local:5000/aquasec/starboard-scanner-aqua:0.5.0", job.Spec.Template.Spec.Containers[0].Image)
+	})
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreaker stops scanning images which failed to be scanned too many times in a row, e.g. because of bad
+// registry credentials, so that the operator does not create scan Jobs which are bound to fail. The circuit of an
+// image opens once the configured number of consecutive scans of the image fail, and closes once the cooldown
+// period elapses, which resets the count of failures. The nil CircuitBreaker never opens.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	mu        sync.Mutex
+	circuits  map[string]*circuit
+}
+
+type circuit struct {
+	failures int
+	openedAt time.Time
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		circuits:  make(map[string]*circuit),
+	}
+}
+
+// RecordFailure records a failed scan of the specified image at the given time. Returns true if the circuit of
+// the image is open, i.e. the image should not be scanned again until the cooldown period elapses.
+func (b *CircuitBreaker) RecordFailure(now time.Time, image string) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.get(now, image)
+	c.failures++
+	if c.failures >= b.threshold && c.openedAt.IsZero() {
+		c.openedAt = now
+	}
+	return !c.openedAt.IsZero()
+}
+
+// RecordSuccess records a successful scan of the specified image, which resets the count of its failures.
+func (b *CircuitBreaker) RecordSuccess(image string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.circuits, image)
+}
+
+// Reset closes circuits of the specified images and resets counts of their failures, e.g. once registry
+// credentials of the images have changed, so that they can be scanned again right away.
+func (b *CircuitBreaker) Reset(images []string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, image := range images {
+		delete(b.circuits, image)
+	}
+}
+
+// Allow checks whether any of the specified images can be scanned at the given time. Returns the delay after
+// which circuits of all the images will be closed, or 0 if they're closed already.
+func (b *CircuitBreaker) Allow(now time.Time, images []string) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var delay time.Duration
+	for _, image := range images {
+		c, ok := b.circuits[image]
+		if !ok || c.openedAt.IsZero() {
+			continue
+		}
+		if b.isCooledDown(now, c) {
+			delete(b.circuits, image)
+			continue
+		}
+		if imageDelay := c.openedAt.Add(b.cooldown).Sub(now); imageDelay > delay {
+			delay = imageDelay
+		}
+	}
+	return delay
+}
+
+// get returns the circuit of the specified image. The circuit is reset if its cooldown period has elapsed.
+func (b *CircuitBreaker) get(now time.Time, image string) *circuit {
+	c, ok := b.circuits[image]
+	if !ok || b.isCooledDown(now, c) {
+		c = &circuit{}
+		b.circuits[image] = c
+	}
+	return c
+}
+
+// isCooledDown returns true if the specified circuit is open, and its cooldown period has elapsed.
+func (b *CircuitBreaker) isCooledDown(now time.Time, c *circuit) bool {
+	return !c.openedAt.IsZero() && !now.Before(c.openedAt.Add(b.cooldown))
+}
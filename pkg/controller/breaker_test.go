@@ -0,0 +1,62 @@
+package controller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("Should open after threshold of consecutive failures", func(t *testing.T) {
+		breaker := controller.NewCircuitBreaker(3, time.Hour)
+
+		assert.False(t, breaker.RecordFailure(now, "nginx:1.16"))
+		assert.False(t, breaker.RecordFailure(now.Add(time.Minute), "nginx:1.16"))
+		assert.Equal(t, time.Duration(0), breaker.Allow(now.Add(time.Minute), []string{"nginx:1.16"}))
+
+		assert.True(t, breaker.RecordFailure(now.Add(2*time.Minute), "nginx:1.16"))
+		assert.Equal(t, time.Hour, breaker.Allow(now.Add(2*time.Minute), []string{"nginx:1.16"}))
+		assert.Equal(t, 30*time.Minute, breaker.Allow(now.Add(32*time.Minute), []string{"redis:5", "nginx:1.16"}))
+		assert.Equal(t, time.Duration(0), breaker.Allow(now.Add(32*time.Minute), []string{"redis:5"}))
+	})
+
+	t.Run("Should reset after cooldown", func(t *testing.T) {
+		breaker := controller.NewCircuitBreaker(2, time.Hour)
+		breaker.RecordFailure(now, "nginx:1.16")
+		assert.True(t, breaker.RecordFailure(now, "nginx:1.16"))
+
+		assert.Equal(t, time.Duration(0), breaker.Allow(now.Add(time.Hour), []string{"nginx:1.16"}))
+		assert.False(t, breaker.RecordFailure(now.Add(time.Hour), "nginx:1.16"))
+		assert.True(t, breaker.RecordFailure(now.Add(time.Hour), "nginx:1.16"))
+	})
+
+	t.Run("Should reset count of failures after success", func(t *testing.T) {
+		breaker := controller.NewCircuitBreaker(2, time.Hour)
+		breaker.RecordFailure(now, "nginx:1.16")
+		breaker.RecordSuccess("nginx:1.16")
+
+		assert.False(t, breaker.RecordFailure(now, "nginx:1.16"))
+	})
+
+	t.Run("Should close circuits of reset images", func(t *testing.T) {
+		breaker := controller.NewCircuitBreaker(1, time.Hour)
+		breaker.RecordFailure(now, "nginx:1.16")
+		breaker.RecordFailure(now, "busybox:1.32")
+		breaker.Reset([]string{"nginx:1.16"})
+
+		assert.Equal(t, time.Duration(0), breaker.Allow(now, []string{"nginx:1.16"}))
+		assert.Equal(t, time.Hour, breaker.Allow(now, []string{"busybox:1.32"}))
+	})
+
+	t.Run("Should never open when nil", func(t *testing.T) {
+		var breaker *controller.CircuitBreaker
+		assert.False(t, breaker.RecordFailure(now, "nginx:1.16"))
+		breaker.RecordSuccess("nginx:1.16")
+		breaker.Reset([]string{"nginx:1.16"})
+		assert.Equal(t, time.Duration(0), breaker.Allow(now, []string{"nginx:1.16"}))
+	})
+}
@@ -0,0 +1,307 @@
+package configaudit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/aquasecurity/starboard-operator/pkg/controller/pod"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/logs"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	"github.com/aquasecurity/starboard-operator/pkg/resources"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	log = ctrl.Log.WithName("controller").WithName("configaudit")
+)
+
+// ConfigAuditController creates ConfigAuditReports for workloads by running config audit
+// Jobs. It reconciles Pods to create config audit Jobs for their owners, and config audit
+// Jobs to save their results as ConfigAuditReports.
+type ConfigAuditController struct {
+	Config     etc.Operator
+	Client     client.Client
+	LogsReader *logs.Reader
+	Scheme     *runtime.Scheme
+	Scanner    scanner.ConfigAuditScanner
+	Store      reports.ConfigAuditStoreInterface
+}
+
+// ReconcilePod ensures that there is a ConfigAuditReport or a pending config audit Job
+// for the workload controlling the given Pod.
+func (r *ConfigAuditController) ReconcilePod(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := log.WithValues("pod", req.NamespacedName)
+
+	if req.Namespace == r.Config.Namespace {
+		log.V(1).Info("Ignoring Pod run in the operator namespace")
+		return ctrl.Result{}, nil
+	}
+
+	if r.Config.IsNamespaceExcluded(req.Namespace) {
+		log.V(1).Info("Ignoring Pod in excluded namespace")
+		return ctrl.Result{}, nil
+	}
+
+	p := &corev1.Pod{}
+	err := r.Client.Get(ctx, req.NamespacedName, p)
+	if err != nil && errors.IsNotFound(err) {
+		log.V(1).Info("Ignoring Pod that must have been deleted")
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting pod from cache: %w", err)
+	}
+
+	if pod.IsPodManagedByStarboardOperator(r.Config, p) {
+		log.V(1).Info("Ignoring Pod managed by this operator")
+		return ctrl.Result{}, nil
+	}
+
+	if p.DeletionTimestamp != nil {
+		log.V(1).Info("Ignoring Pod that is being terminated")
+		return ctrl.Result{}, nil
+	}
+
+	if !resources.HasContainersReadyCondition(p) {
+		log.V(1).Info("Ignoring Pod that is being scheduled")
+		return ctrl.Result{}, nil
+	}
+
+	owner, err := resources.GetOwnerWorkload(ctx, r.Client, p)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("resolving pod owner: %w", err)
+	}
+
+	hash := controller.ComputeHash(p.Spec)
+
+	hasReport, err := r.Store.HasConfigAuditReport(ctx, owner, hash)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting config audit report: %w", err)
+	}
+	if hasReport {
+		log.V(1).Info("Ignoring Pod that already has ConfigAuditReport", "owner", owner)
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{}, r.ensureConfigAuditJob(ctx, owner, hash)
+}
+
+func (r *ConfigAuditController) ensureConfigAuditJob(ctx context.Context, owner kube.Object, hash string) error {
+	log := log.WithValues("owner", owner, "hash", hash)
+
+	labels := map[string]string{
+		kube.LabelResourceKind:      string(owner.Kind),
+		kube.LabelResourceName:      owner.Name,
+		kube.LabelResourceNamespace: owner.Namespace,
+		etc.LabelPodSpecHash:        hash,
+		etc.LabelConfigAudit:        "true",
+	}
+	for key, value := range r.Config.GetScanJobLabels() {
+		labels[key] = value
+	}
+
+	jobList := &batchv1.JobList{}
+	err := r.Client.List(ctx, jobList, client.MatchingLabels(labels), client.InNamespace(r.Config.Namespace))
+	if err != nil {
+		return fmt.Errorf("listing jobs: %w", err)
+	}
+	if len(jobList.Items) > 0 {
+		log.V(1).Info("Config audit job already exists",
+			"job", fmt.Sprintf("%s/%s", jobList.Items[0].Namespace, jobList.Items[0].Name))
+		return nil
+	}
+
+	scanJobResources, err := r.Config.GetScanJobResourceRequirements()
+	if err != nil {
+		return err
+	}
+	imagePullPolicy, err := r.Config.GetScanJobImagePullPolicy()
+	if err != nil {
+		return err
+	}
+	nodeSelector, err := r.Config.GetScanJobNodeSelector()
+	if err != nil {
+		return err
+	}
+	tolerations, err := r.Config.GetScanJobTolerations()
+	if err != nil {
+		return err
+	}
+	affinity, err := r.Config.GetScanJobAffinity()
+	if err != nil {
+		return err
+	}
+	podSecurityContext, securityContext, err := r.Config.GetScanJobSecurityContext()
+	if err != nil {
+		return err
+	}
+
+	jobMeta := scanner.JobMeta{Labels: labels, Annotations: r.Config.GetScanJobPodAnnotations()}
+	job, err := r.Scanner.NewConfigAuditJob(jobMeta, scanner.Options{
+		Namespace:                 r.Config.Namespace,
+		ServiceAccountName:        r.Config.ServiceAccount,
+		ScanJobTimeout:            r.Config.ScanJobTimeout,
+		ScanJobBackoffLimit:       r.Config.ScanJobBackoffLimit,
+		ScanJobResources:          scanJobResources,
+		ScanJobNodeSelector:       nodeSelector,
+		ScanJobTolerations:        tolerations,
+		ScanJobAffinity:           affinity,
+		ScanJobHTTPProxy:          r.Config.ScanJobHTTPProxy,
+		ScanJobHTTPSProxy:         r.Config.ScanJobHTTPSProxy,
+		ScanJobNoProxy:            r.Config.ScanJobNoProxy,
+		ScanJobImagePullPolicy:    imagePullPolicy,
+		ScanJobPodSecurityContext: podSecurityContext,
+		ScanJobSecurityContext:    securityContext,
+	}, owner)
+	if err != nil {
+		return fmt.Errorf("constructing config audit job: %w", err)
+	}
+
+	if r.Config.DryRun {
+		log.Info("Dry run: skipping creation of config audit job", "scanner", r.Scanner.GetName())
+		return nil
+	}
+
+	log.V(1).Info("Creating config audit job", "job", fmt.Sprintf("%s/%s", job.Namespace, job.Name))
+	return r.Client.Create(ctx, job)
+}
+
+// ReconcileJob saves the result of the given config audit Job as a ConfigAuditReport
+// once the Job is complete. Failed Jobs are left in place so that their logs can be inspected.
+func (r *ConfigAuditController) ReconcileJob(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := log.WithValues("job", req.NamespacedName)
+
+	if req.Namespace != r.Config.Namespace {
+		log.V(1).Info("Ignoring Job not managed by this operator")
+		return ctrl.Result{}, nil
+	}
+
+	job := &batchv1.Job{}
+	err := r.Client.Get(ctx, req.NamespacedName, job)
+	if err != nil && errors.IsNotFound(err) {
+		log.V(1).Info("Ignoring Job that must have been deleted")
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting job from cache: %w", err)
+	}
+
+	if !IsConfigAuditJob(job) {
+		return ctrl.Result{}, nil
+	}
+
+	if !r.Config.IsOwnedByInstance(job.Labels) {
+		log.V(1).Info("Ignoring config audit Job created by another operator instance")
+		return ctrl.Result{}, nil
+	}
+
+	if job.DeletionTimestamp != nil || len(job.Status.Conditions) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	switch jobCondition := job.Status.Conditions[0].Type; jobCondition {
+	case batchv1.JobComplete:
+		return ctrl.Result{}, r.processCompleteConfigAuditJob(ctx, job)
+	case batchv1.JobFailed:
+		log.Info("Leaving failed config audit job", "scanner", r.Scanner.GetName())
+		return ctrl.Result{}, nil
+	default:
+		return ctrl.Result{}, fmt.Errorf("unrecognized config audit job condition: %v", jobCondition)
+	}
+}
+
+func (r *ConfigAuditController) processCompleteConfigAuditJob(ctx context.Context, job *batchv1.Job) error {
+	log := log.WithValues("job", fmt.Sprintf("%s/%s", job.Namespace, job.Name))
+
+	workload, err := kube.ObjectFromLabelsSet(job.Labels)
+	if err != nil {
+		return fmt.Errorf("getting workload from config audit job labels set: %w", err)
+	}
+
+	hash, ok := job.Labels[etc.LabelPodSpecHash]
+	if !ok {
+		return fmt.Errorf("expected label %s not set", etc.LabelPodSpecHash)
+	}
+
+	p, err := r.getPodControlledBy(ctx, job)
+	if err != nil {
+		return fmt.Errorf("getting pod controlled by %s/%s: %w", job.Namespace, job.Name, err)
+	}
+
+	logsReader, err := r.LogsReader.GetLogsForPod(ctx, client.ObjectKey{Namespace: p.Namespace, Name: p.Name}, &corev1.PodLogOptions{
+		Follow: true,
+	})
+	if err != nil {
+		return fmt.Errorf("getting logs for pod %s/%s: %w", p.Namespace, p.Name, err)
+	}
+	report, err := r.Scanner.ParseConfigAuditResult(logsReader)
+	_ = logsReader.Close()
+	if err != nil {
+		// Retrying won't help as the logs are not going to change.
+		log.Error(err, "Leaving config audit job with logs that cannot be parsed")
+		return nil
+	}
+
+	log.Info("Writing ConfigAuditReport", "owner", workload)
+	err = r.Store.SaveConfigAuditReport(ctx, workload, hash, report)
+	if err != nil {
+		return fmt.Errorf("writing config audit report: %w", err)
+	}
+
+	if !r.Config.DeleteScanJobs {
+		return nil
+	}
+	log.V(1).Info("Deleting config audit job")
+	err = r.Client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground))
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("deleting config audit job: %w", err)
+	}
+	return nil
+}
+
+func (r *ConfigAuditController) getPodControlledBy(ctx context.Context, job *batchv1.Job) (*corev1.Pod, error) {
+	controllerUID, ok := job.Spec.Selector.MatchLabels["controller-uid"]
+	if !ok {
+		return nil, fmt.Errorf("controller-uid not found for job %s/%s", job.Namespace, job.Name)
+	}
+	podList := &corev1.PodList{}
+	err := r.Client.List(ctx, podList, client.MatchingLabels{"controller-uid": controllerUID})
+	if err != nil {
+		return nil, fmt.Errorf("listing pods controlled by job %s/%s: %w", job.Namespace, job.Name, err)
+	}
+	if len(podList.Items) != 1 {
+		return nil, fmt.Errorf("expected 1 Pod, but got %d", len(podList.Items))
+	}
+	return podList.Items[0].DeepCopy(), nil
+}
+
+// IsConfigAuditJob returns true if the specified Job is labeled with etc.LabelConfigAudit, false otherwise.
+func IsConfigAuditJob(job *batchv1.Job) bool {
+	_, ok := job.Labels[etc.LabelConfigAudit]
+	return ok
+}
+
+func (r *ConfigAuditController) SetupWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		Named("configaudit-pod").
+		For(&corev1.Pod{}).
+		Complete(reconcile.Func(r.ReconcilePod))
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("configaudit-job").
+		For(&batchv1.Job{}).
+		Complete(reconcile.Func(r.ReconcileJob))
+}
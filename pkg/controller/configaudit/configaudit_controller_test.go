@@ -0,0 +1,235 @@
+package configaudit_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/aquasecurity/starboard-operator/pkg/controller/configaudit"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/logs"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeScanner is a ConfigAuditScanner which returns the same result for any workload.
+type fakeScanner struct {
+	result starboardv1alpha1.ConfigAudit
+}
+
+func (s *fakeScanner) GetName() string {
+	return "Fake"
+}
+
+func (s *fakeScanner) GetContainerName() string {
+	return "fake"
+}
+
+func (s *fakeScanner) NewConfigAuditJob(meta scanner.JobMeta, options scanner.Options, _ kube.Object) (*batchv1.Job, error) {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "config-audit-job",
+			Namespace: options.Namespace,
+			Labels:    meta.Labels,
+		},
+	}, nil
+}
+
+func (s *fakeScanner) ParseConfigAuditResult(_ io.ReadCloser) (starboardv1alpha1.ConfigAudit, error) {
+	return s.result, nil
+}
+
+func newPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nginx",
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nginx", Image: "nginx:1.16"},
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.ContainersReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func newConfigAuditJob() *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "config-audit-job",
+			Namespace: "starboard-operator",
+			Labels: map[string]string{
+				kube.LabelResourceKind:         string(kube.KindPod),
+				kube.LabelResourceName:         "nginx",
+				kube.LabelResourceNamespace:    "default",
+				"app.kubernetes.io/managed-by": "starboard-operator",
+				etc.LabelPodSpecHash:           "7f8b9c6d5",
+				etc.LabelConfigAudit:           "true",
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"controller-uid": "a4e3b5c1",
+				},
+			},
+		},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func newConfigAuditJobPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "config-audit-job-x8k2p",
+			Namespace: "starboard-operator",
+			Labels: map[string]string{
+				"controller-uid": "a4e3b5c1",
+			},
+		},
+	}
+}
+
+func newController(t *testing.T, server *httptest.Server, config etc.Operator, objects ...runtime.Object) *configaudit.ConfigAuditController {
+	t.Helper()
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+	fakeClient := fake.NewFakeClientWithScheme(scheme, objects...)
+	return &configaudit.ConfigAuditController{
+		Config:     config,
+		Client:     fakeClient,
+		LogsReader: logs.NewReader(clientset, 0, "fake"),
+		Scheme:     scheme,
+		Scanner: &fakeScanner{
+			result: starboardv1alpha1.ConfigAudit{
+				Summary: starboardv1alpha1.ConfigAuditSummary{DangerCount: 1, WarningCount: 2},
+			},
+		},
+		Store: reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, ""),
+	}
+}
+
+func TestConfigAuditController_ReconcilePod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	config := etc.Operator{Namespace: "starboard-operator", ServiceAccount: "starboard-operator", ScanJobCPURequest: "100m"}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}}
+
+	t.Run("Should create config audit job", func(t *testing.T) {
+		configAuditController := newController(t, server, config, newPod())
+
+		_, err := configAuditController.ReconcilePod(request)
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, configAuditController.Client.List(context.Background(), jobList,
+			client.MatchingLabels{etc.LabelConfigAudit: "true"}))
+		require.Len(t, jobList.Items, 1)
+		assert.Equal(t, "nginx", jobList.Items[0].Labels[kube.LabelResourceName])
+	})
+
+	t.Run("Should not create config audit job in dry run", func(t *testing.T) {
+		dryRunConfig := config
+		dryRunConfig.DryRun = true
+		configAuditController := newController(t, server, dryRunConfig, newPod())
+
+		_, err := configAuditController.ReconcilePod(request)
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, configAuditController.Client.List(context.Background(), jobList))
+		assert.Empty(t, jobList.Items)
+	})
+
+	t.Run("Should not create config audit job when report exists", func(t *testing.T) {
+		configAuditController := newController(t, server, config, newPod())
+		pod := newPod()
+		err := configAuditController.Store.SaveConfigAuditReport(context.Background(),
+			kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"},
+			controller.ComputeHash(pod.Spec), starboardv1alpha1.ConfigAudit{})
+		require.NoError(t, err)
+
+		_, err = configAuditController.ReconcilePod(request)
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, configAuditController.Client.List(context.Background(), jobList))
+		assert.Empty(t, jobList.Items)
+	})
+}
+
+func TestConfigAuditController_ReconcileJob(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "config-audit-job"}}
+
+	t.Run("Should save report and delete complete config audit job", func(t *testing.T) {
+		configAuditController := newController(t, server, etc.Operator{Namespace: "starboard-operator", DeleteScanJobs: true},
+			newPod(), newConfigAuditJob(), newConfigAuditJobPod())
+
+		_, err := configAuditController.ReconcileJob(request)
+		require.NoError(t, err)
+
+		report := &starboardv1alpha1.ConfigAuditReport{}
+		require.NoError(t, configAuditController.Client.Get(context.Background(),
+			types.NamespacedName{Namespace: "default", Name: "pod-nginx"}, report))
+		assert.Equal(t, "7f8b9c6d5", report.Labels[etc.LabelPodSpecHash])
+		assert.Equal(t, 1, report.Report.Summary.DangerCount)
+		assert.Equal(t, 2, report.Report.Summary.WarningCount)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, configAuditController.Client.List(context.Background(), jobList))
+		assert.Empty(t, jobList.Items)
+	})
+
+	t.Run("Should ignore vulnerability scan job", func(t *testing.T) {
+		job := newConfigAuditJob()
+		delete(job.Labels, etc.LabelConfigAudit)
+		configAuditController := newController(t, server, etc.Operator{Namespace: "starboard-operator", DeleteScanJobs: true},
+			newPod(), job, newConfigAuditJobPod())
+
+		_, err := configAuditController.ReconcileJob(request)
+		require.NoError(t, err)
+
+		reportList := &starboardv1alpha1.ConfigAuditReportList{}
+		require.NoError(t, configAuditController.Client.List(context.Background(), reportList))
+		assert.Empty(t, reportList.Items)
+	})
+}
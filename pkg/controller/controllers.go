@@ -4,10 +4,21 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Reasons of Events recorded by the controllers for the scanned workloads.
+const (
+	EventReasonScanJobCreated   = "ScanJobCreated"
+	EventReasonScanCompleted    = "ScanCompleted"
+	EventReasonScanFailed       = "ScanFailed"
+	EventReasonScanSuspended    = "ScanSuspended"
+	EventReasonImagePullBackOff = "ImagePullBackOff"
 )
 
 // ComputeHash returns a hash value calculated from pod spec.
@@ -31,3 +42,23 @@ func DeepHashObject(hasher hash.Hash, objectToWrite interface{}) {
 	}
 	printer.Fprintf(hasher, "%#v", objectToWrite)
 }
+
+// AddJitter returns the specified duration increased by a random duration in the range [0, maxJitter), so that
+// requests requeued at the same time, e.g. once the operator is restarted, are spread out. The duration is
+// returned intact if maxJitter is not positive.
+func AddJitter(duration, maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return duration
+	}
+	return duration + time.Duration(rand.Int63nRange(0, int64(maxJitter)))
+}
+
+// NewDeferredResult returns the Result of a reconcile request whose work is deferred, e.g. because
+// the concurrent scan jobs limit is reached. The request is requeued after the specified interval,
+// or with the rate limited backoff of the controller if the interval is not positive.
+func NewDeferredResult(interval time.Duration) ctrl.Result {
+	if interval > 0 {
+		return ctrl.Result{RequeueAfter: interval}
+	}
+	return ctrl.Result{Requeue: true}
+}
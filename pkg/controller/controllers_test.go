@@ -3,12 +3,31 @@ package controller_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aquasecurity/starboard-operator/pkg/controller"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
 )
 
+func TestAddJitter(t *testing.T) {
+	t.Run("Should add jitter within range", func(t *testing.T) {
+		jittered := make(map[time.Duration]bool)
+		for i := 0; i < 1000; i++ {
+			actual := controller.AddJitter(30*time.Minute, 10*time.Minute)
+			assert.GreaterOrEqual(t, int64(actual), int64(30*time.Minute))
+			assert.Less(t, int64(actual), int64(40*time.Minute))
+			jittered[actual] = true
+		}
+		assert.Greater(t, len(jittered), 1, "Requeue intervals are spread out")
+	})
+
+	t.Run("Should return duration intact when jitter is not set", func(t *testing.T) {
+		assert.Equal(t, 30*time.Minute, controller.AddJitter(30*time.Minute, 0))
+	})
+}
+
 func TestComputeHash(t *testing.T) {
 
 	booleanValue1 := true
@@ -95,3 +114,8 @@ func TestComputeHash(t *testing.T) {
 	})
 
 }
+
+func TestNewDeferredResult(t *testing.T) {
+	assert.Equal(t, ctrl.Result{Requeue: true}, controller.NewDeferredResult(0))
+	assert.Equal(t, ctrl.Result{RequeueAfter: 30 * time.Second}, controller.NewDeferredResult(30*time.Second))
+}
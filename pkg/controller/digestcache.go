@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DigestCache remembers image digests which have been scanned recently, so that scan Jobs are not created again
+// for workloads running images with the same digests, e.g. while Pods are recreated during operator restarts.
+// It holds up to the configured number of digests, and evicts the least recently used ones. If a file path is
+// set, the cache is loaded from the file when it's constructed and saved whenever digests are added, so that it
+// survives restarts of the operator. The nil DigestCache never hits.
+type DigestCache struct {
+	size int
+	path string
+	mu   sync.Mutex
+	// entries holds *scannedDigest values, most recently used first.
+	entries *list.List
+	index   map[string]*list.Element
+}
+
+type scannedDigest struct {
+	Digest    string    `json:"digest"`
+	ScannedAt time.Time `json:"scannedAt"`
+}
+
+// NewDigestCache constructs the DigestCache of the specified size, which is persisted to the specified file
+// unless the path is blank. A missing file is treated as an empty cache.
+func NewDigestCache(size int, path string) (*DigestCache, error) {
+	c := &DigestCache{
+		size:    size,
+		path:    path,
+		entries: list.New(),
+		index:   make(map[string]*list.Element),
+	}
+	if path == "" {
+		return c, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading scanned digests: %w", err)
+	}
+	var digests []scannedDigest
+	err = json.Unmarshal(data, &digests)
+	if err != nil {
+		return nil, fmt.Errorf("decoding scanned digests %s: %w", path, err)
+	}
+	// Digests are saved most recently used first, so they're added in reverse order.
+	for i := len(digests) - 1; i >= 0; i-- {
+		c.add(digests[i].Digest, digests[i].ScannedAt)
+	}
+	return c, nil
+}
+
+// Add records that images with the specified digests were scanned at the given time. Returns an error if the
+// cache could not be saved, in which case digests are still cached in memory.
+func (c *DigestCache) Add(now time.Time, digests ...string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, digest := range digests {
+		if digest != "" {
+			c.add(digest, now)
+		}
+	}
+	return c.save()
+}
+
+// Get returns the time when the image with the specified digest was scanned, and whether it's cached.
+func (c *DigestCache) Get(digest string) (time.Time, bool) {
+	if c == nil {
+		return time.Time{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.index[digest]
+	if !ok {
+		return time.Time{}, false
+	}
+	c.entries.MoveToFront(element)
+	return element.Value.(*scannedDigest).ScannedAt, true
+}
+
+// Remove evicts the specified digests, e.g. when reports of the scanned images are gone. Returns an error if the
+// cache could not be saved, in which case digests are still evicted from memory.
+func (c *DigestCache) Remove(digests ...string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, digest := range digests {
+		if element, ok := c.index[digest]; ok {
+			c.entries.Remove(element)
+			delete(c.index, digest)
+		}
+	}
+	return c.save()
+}
+
+// Len returns the number of cached digests.
+func (c *DigestCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Len()
+}
+
+func (c *DigestCache) add(digest string, scannedAt time.Time) {
+	if element, ok := c.index[digest]; ok {
+		element.Value.(*scannedDigest).ScannedAt = scannedAt
+		c.entries.MoveToFront(element)
+		return
+	}
+	c.index[digest] = c.entries.PushFront(&scannedDigest{Digest: digest, ScannedAt: scannedAt})
+	for c.entries.Len() > c.size {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.index, oldest.Value.(*scannedDigest).Digest)
+	}
+}
+
+// save writes cached digests to a temporary file, which then replaces the configured one, so that the file is
+// never left partially written.
+func (c *DigestCache) save() error {
+	if c.path == "" {
+		return nil
+	}
+	digests := make([]scannedDigest, 0, c.entries.Len())
+	for element := c.entries.Front(); element != nil; element = element.Next() {
+		digests = append(digests, *element.Value.(*scannedDigest))
+	}
+	data, err := json.Marshal(digests)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("saving scanned digests: %w", err)
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), c.path)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return fmt.Errorf("saving scanned digests: %w", err)
+	}
+	return nil
+}
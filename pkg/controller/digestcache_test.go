@@ -0,0 +1,118 @@
+package controller_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestCache(t *testing.T) {
+	now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("Should never hit when cache is nil", func(t *testing.T) {
+		var cache *controller.DigestCache
+		require.NoError(t, cache.Add(now, "sha256:a"))
+		_, ok := cache.Get("sha256:a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("Should hit scanned digests and miss others", func(t *testing.T) {
+		cache, err := controller.NewDigestCache(2, "")
+		require.NoError(t, err)
+		require.NoError(t, cache.Add(now, "sha256:a", "", "sha256:b"))
+
+		scannedAt, ok := cache.Get("sha256:a")
+		assert.True(t, ok)
+		assert.Equal(t, now, scannedAt)
+		_, ok = cache.Get("sha256:c")
+		assert.False(t, ok)
+		assert.Equal(t, 2, cache.Len())
+	})
+
+	t.Run("Should update scan time of digest scanned again", func(t *testing.T) {
+		cache, err := controller.NewDigestCache(2, "")
+		require.NoError(t, err)
+		require.NoError(t, cache.Add(now, "sha256:a"))
+		require.NoError(t, cache.Add(now.Add(time.Hour), "sha256:a"))
+
+		scannedAt, ok := cache.Get("sha256:a")
+		assert.True(t, ok)
+		assert.Equal(t, now.Add(time.Hour), scannedAt)
+		assert.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("Should remove digests", func(t *testing.T) {
+		cache, err := controller.NewDigestCache(2, "")
+		require.NoError(t, err)
+		require.NoError(t, cache.Add(now, "sha256:a", "sha256:b"))
+		require.NoError(t, cache.Remove("sha256:a", "sha256:c"))
+
+		_, ok := cache.Get("sha256:a")
+		assert.False(t, ok)
+		_, ok = cache.Get("sha256:b")
+		assert.True(t, ok)
+		assert.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("Should evict least recently used digest", func(t *testing.T) {
+		cache, err := controller.NewDigestCache(2, "")
+		require.NoError(t, err)
+		require.NoError(t, cache.Add(now, "sha256:a", "sha256:b"))
+		// Getting sha256:a makes sha256:b the least recently used digest.
+		_, ok := cache.Get("sha256:a")
+		require.True(t, ok)
+		require.NoError(t, cache.Add(now, "sha256:c"))
+
+		_, ok = cache.Get("sha256:b")
+		assert.False(t, ok)
+		_, ok = cache.Get("sha256:a")
+		assert.True(t, ok)
+		_, ok = cache.Get("sha256:c")
+		assert.True(t, ok)
+	})
+
+	t.Run("Should persist digests to file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "digest-cache")
+		require.NoError(t, err)
+		defer func() {
+			_ = os.RemoveAll(dir)
+		}()
+		path := filepath.Join(dir, "digests.json")
+
+		cache, err := controller.NewDigestCache(2, path)
+		require.NoError(t, err)
+		assert.Equal(t, 0, cache.Len(), "Missing file is treated as empty cache")
+		require.NoError(t, cache.Add(now, "sha256:a"))
+		require.NoError(t, cache.Add(now.Add(time.Minute), "sha256:b"))
+
+		restored, err := controller.NewDigestCache(1, path)
+		require.NoError(t, err)
+		assert.Equal(t, 1, restored.Len())
+		scannedAt, ok := restored.Get("sha256:b")
+		assert.True(t, ok, "Most recently used digest is kept")
+		assert.Equal(t, now.Add(time.Minute), scannedAt)
+		_, ok = restored.Get("sha256:a")
+		assert.False(t, ok)
+	})
+
+	t.Run("Should return error when file is corrupted", func(t *testing.T) {
+		file, err := ioutil.TempFile("", "digests.json")
+		require.NoError(t, err)
+		defer func() {
+			_ = os.Remove(file.Name())
+		}()
+		_, err = file.WriteString("not json")
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		_, err = controller.NewDigestCache(2, file.Name())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// InFlight tracks reconcile requests in progress, so that they can be drained before the operator exits,
+// e.g. to let the JobController persist reports of completed scan Jobs during rolling updates. The nil
+// InFlight tracks nothing.
+type InFlight struct {
+	mu    sync.Mutex
+	count int
+	// idle is closed once the count of requests in progress drops to zero.
+	idle chan struct{}
+}
+
+// Track records the start of a reconcile request. The returned function must be called once the request
+// is done.
+func (f *InFlight) Track() func() {
+	if f == nil {
+		return func() {}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.count == 0 {
+		f.idle = make(chan struct{})
+	}
+	f.count++
+	var once sync.Once
+	return func() {
+		once.Do(f.done)
+	}
+}
+
+func (f *InFlight) done() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.count--
+	if f.count == 0 {
+		close(f.idle)
+	}
+}
+
+// Drain waits until no reconcile requests are in progress, or until the specified grace period elapses.
+// Returns the number of requests still in progress, which is 0 if all requests were drained.
+func (f *InFlight) Drain(clock clock.Clock, gracePeriod time.Duration) int {
+	if f == nil {
+		return 0
+	}
+	f.mu.Lock()
+	if f.count == 0 {
+		f.mu.Unlock()
+		return 0
+	}
+	idle := f.idle
+	f.mu.Unlock()
+
+	select {
+	case <-idle:
+	case <-clock.After(gracePeriod):
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.count
+}
@@ -0,0 +1,80 @@
+package controller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestInFlight_Drain(t *testing.T) {
+	now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	// drain drains the specified InFlight in the background and returns the channel of its result.
+	drain := func(inFlight *controller.InFlight, fakeClock *clock.FakeClock) <-chan int {
+		result := make(chan int)
+		go func() {
+			result <- inFlight.Drain(fakeClock, 30*time.Second)
+		}()
+		return result
+	}
+
+	t.Run("Should return immediately when no requests are in progress", func(t *testing.T) {
+		inFlight := &controller.InFlight{}
+		inFlight.Track()()
+
+		assert.Equal(t, 0, inFlight.Drain(clock.NewFakeClock(now), 30*time.Second))
+	})
+
+	t.Run("Should wait until requests in progress are done", func(t *testing.T) {
+		inFlight := &controller.InFlight{}
+		fakeClock := clock.NewFakeClock(now)
+		done1 := inFlight.Track()
+		done2 := inFlight.Track()
+
+		result := drain(inFlight, fakeClock)
+		require.Eventually(t, fakeClock.HasWaiters, 5*time.Second, 10*time.Millisecond)
+		done1()
+		done1()
+		select {
+		case <-result:
+			t.Fatal("Drain returned while a request is still in progress")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		done2()
+		select {
+		case remaining := <-result:
+			assert.Equal(t, 0, remaining)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Drain did not return once requests were done")
+		}
+	})
+
+	t.Run("Should give up when grace period elapses", func(t *testing.T) {
+		inFlight := &controller.InFlight{}
+		fakeClock := clock.NewFakeClock(now)
+		done := inFlight.Track()
+		inFlight.Track()()
+
+		result := drain(inFlight, fakeClock)
+		require.Eventually(t, fakeClock.HasWaiters, 5*time.Second, 10*time.Millisecond)
+		fakeClock.Step(30 * time.Second)
+		select {
+		case remaining := <-result:
+			assert.Equal(t, 1, remaining)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Drain did not time out")
+		}
+		done()
+	})
+
+	t.Run("Should track nothing when nil", func(t *testing.T) {
+		var inFlight *controller.InFlight
+		inFlight.Track()()
+		assert.Equal(t, 0, inFlight.Drain(clock.NewFakeClock(now), 30*time.Second))
+	})
+}
@@ -3,8 +3,18 @@ package job
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/aquasecurity/starboard-operator/pkg/metrics"
+	"github.com/aquasecurity/starboard-operator/pkg/notify"
+	"github.com/aquasecurity/starboard-operator/pkg/policy"
 
 	"github.com/aquasecurity/starboard-operator/pkg/resources"
+	"github.com/aquasecurity/starboard-operator/pkg/sbom"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/aquasecurity/starboard-operator/pkg/etc"
@@ -16,13 +26,19 @@ import (
 
 	"github.com/aquasecurity/starboard-operator/pkg/reports"
 	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/google/uuid"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	batchv1 "k8s.io/api/batch/v1"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 var (
@@ -35,20 +51,44 @@ type JobController struct {
 	LogsReader *logs.Reader
 	Scheme     *runtime.Scheme
 	Scanner    scanner.VulnerabilityScanner
-	Store      reports.StoreInterface
+	Writer     reports.Writer
+	SBOMWriter reports.SBOMWriter
+	Clock      clock.Clock
+	Recorder   record.EventRecorder
+	Notifier   notify.Notifier
+	// Policy loads the policy which drops ignored vulnerabilities before reports are written.
+	// Vulnerabilities are not filtered by a policy if it's nil.
+	Policy *policy.Loader
+	// InFlight tracks reconcile requests in progress, which are drained on shutdown. Requests are not
+	// tracked if it's nil.
+	InFlight *controller.InFlight
+	// CircuitBreaker records results of scans of images, so that images which failed to be scanned too many
+	// times in a row are not scanned until the cooldown period elapses. It never opens if it's nil.
+	CircuitBreaker *controller.CircuitBreaker
+	// Tracker records requeued and failed reconcile requests for the debug endpoint. Requests are not
+	// tracked if it's nil.
+	Tracker *controller.ReconcileTracker
+	// DigestCache records digests of scanned images once their reports are written. Digests are not recorded
+	// if it's nil.
+	DigestCache *controller.DigestCache
 }
 
-func (r *JobController) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+func (r *JobController) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
+	defer func() {
+		r.Tracker.Record(r.Clock.Now(), "job", req, result, err)
+	}()
+	defer r.InFlight.Track()()
+
 	ctx := context.Background()
 	log := log.WithValues("job", req.NamespacedName)
 
-	if req.Namespace != r.Config.Namespace {
+	if req.Namespace != r.Config.GetScanJobsNamespace() {
 		log.V(1).Info("Ignoring Job not managed by this operator")
 		return ctrl.Result{}, nil
 	}
 
 	job := &batchv1.Job{}
-	err := r.Client.Get(ctx, req.NamespacedName, job)
+	err = r.Client.Get(ctx, req.NamespacedName, job)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			log.V(1).Info("Ignoring Job that must have been deleted")
@@ -57,21 +97,46 @@ func (r *JobController) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return ctrl.Result{}, fmt.Errorf("getting job from cache: %w", err)
 	}
 
+	if !IsScanJob(r.Config, job) {
+		log.V(1).Info("Ignoring Job not managed by this operator")
+		return ctrl.Result{}, nil
+	}
+
+	if _, ok := job.Labels[etc.LabelConfigAudit]; ok {
+		log.V(1).Info("Ignoring config audit Job")
+		return ctrl.Result{}, nil
+	}
+
+	if job.DeletionTimestamp != nil {
+		log.V(1).Info("Ignoring Job that is being deleted")
+		return ctrl.Result{}, nil
+	}
+
+	// Logs of scan Jobs left in place are not parsed again, so that stale results are never written as new ones.
+	if _, ok := job.Annotations[etc.AnnotationScanJobProcessed]; ok {
+		log.V(1).Info("Ignoring scan Job which has been processed")
+		return ctrl.Result{}, nil
+	}
+
 	if len(job.Status.Conditions) == 0 {
 		log.V(1).Info("Ignoring Job without status conditions")
-		return ctrl.Result{}, nil
+		if job.Status.Active > 0 {
+			if workload, err := kube.ObjectFromLabelsSet(job.Labels); err == nil {
+				r.setScanStatus(ctx, workload, etc.ScanStatusInProgress)
+			}
+		}
+		// The scan is still running. Check back after the requeue interval, if configured.
+		return ctrl.Result{RequeueAfter: r.Config.ReconcileRequeueInterval}, nil
 	}
 
 	switch jobCondition := job.Status.Conditions[0].Type; jobCondition {
 	case batchv1.JobComplete:
-		err = r.processCompleteScanJob(ctx, job)
+		return ctrl.Result{}, r.processCompleteScanJob(ctx, job)
 	case batchv1.JobFailed:
-		err = r.processFailedScanJob(ctx, job)
+		return r.processFailedScanJob(ctx, job)
 	default:
-		err = fmt.Errorf("unrecognized scan job condition: %v", jobCondition)
+		return ctrl.Result{}, fmt.Errorf("unrecognized scan job condition: %v", jobCondition)
 	}
-
-	return ctrl.Result{}, err
 }
 
 func (r *JobController) processCompleteScanJob(ctx context.Context, scanJob *batchv1.Job) error {
@@ -91,15 +156,29 @@ func (r *JobController) processCompleteScanJob(ctx context.Context, scanJob *bat
 		return fmt.Errorf("expected label %s not set", etc.LabelPodSpecHash)
 	}
 
-	hasVulnerabilityReports, err := r.Store.HasVulnerabilityReports(ctx, workload, hash, containerImages)
+	hasVulnerabilityReports, err := r.Writer.HasReport(ctx, workload, hash, containerImages)
+	if err != nil {
+		return err
+	}
+
+	expired, err := r.hasExpiredVulnerabilityReports(ctx, workload, hash)
 	if err != nil {
 		return err
 	}
 
-	if hasVulnerabilityReports {
+	// Results of scan Jobs created on demand replace current reports.
+	_, rescan := scanJob.Annotations[etc.AnnotationRescan]
+
+	if hasVulnerabilityReports && !expired && !rescan {
 		log.V(1).Info("VulnerabilityReports already exist", "owner", workload)
-		log.V(1).Info("Deleting scan job")
-		return r.Client.Delete(ctx, scanJob, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		r.setScanStatus(ctx, workload, etc.ScanStatusCompleted)
+		r.recordScanJobMetrics(scanJob, metrics.ScanJobResultComplete)
+		return r.deleteScanJob(ctx, scanJob)
+	}
+
+	severities, err := reports.ParseSeverities(r.Config.ReportSeverities)
+	if err != nil {
+		return err
 	}
 
 	pod, err := r.GetPodControlledBy(ctx, scanJob)
@@ -107,8 +186,14 @@ func (r *JobController) processCompleteScanJob(ctx context.Context, scanJob *bat
 		return fmt.Errorf("getting pod controlled by %s/%s: %w", scanJob.Namespace, scanJob.Name, err)
 	}
 
-	vulnerabilityReports := make(map[string]v1alpha1.VulnerabilityScanResult)
+	// Each image is scanned only once by a scan Job container named after the first
+	// workload container referring to that image.
+	resultsByImage := make(map[string]v1alpha1.VulnerabilityScanResult)
 	for _, container := range pod.Spec.Containers {
+		imageRef, ok := containerImages[container.Name]
+		if !ok {
+			continue
+		}
 		logsReader, err := r.LogsReader.GetLogsForPod(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: pod.Name}, &corev1.PodLogOptions{
 			Container: container.Name,
 			Follow:    true,
@@ -116,20 +201,183 @@ func (r *JobController) processCompleteScanJob(ctx context.Context, scanJob *bat
 		if err != nil {
 			return fmt.Errorf("getting logs for pod %s/%s: %w", pod.Namespace, pod.Name, err)
 		}
-		vulnerabilityReports[container.Name], err = r.Scanner.ParseVulnerabilityScanResult(containerImages[container.Name], logsReader)
+		result, err := r.Scanner.ParseVulnerabilityScanResult(imageRef, logsReader)
+		_ = logsReader.Close()
+		if logs.IsReadTimeout(err) {
+			return fmt.Errorf("reading logs of container %s: %w", container.Name, err)
+		}
 		if err != nil {
-			return err
+			// Retrying won't help as the logs are not going to change. Leave the scan Job
+			// and its Pod in place so that the logs can be inspected.
+			log.Error(err, "Leaving scan job with logs that cannot be parsed", "container", container.Name)
+			r.recordEvent(ctx, workload, corev1.EventTypeWarning, controller.EventReasonScanFailed,
+				"Failed to parse scan result of image %s with %s: %v", imageRef, r.Scanner.GetName(), err)
+			r.setScanStatus(ctx, workload, etc.ScanStatusFailed)
+			r.recordScanJobMetrics(scanJob, metrics.ScanJobResultFailed)
+			return r.setScanJobProcessed(ctx, scanJob)
 		}
-		_ = logsReader.Close()
+		resultsByImage[imageRef] = reports.FilterVulnerabilities(result, severities)
+	}
+
+	vulnerabilityReports := make(map[string]v1alpha1.VulnerabilityScanResult)
+	for containerName, imageRef := range containerImages {
+		result, ok := resultsByImage[imageRef]
+		if !ok {
+			return fmt.Errorf("missing scan result for image %s of container %s", imageRef, containerName)
+		}
+		vulnerabilityReports[containerName] = result
+	}
+
+	err = r.applyPolicy(ctx, workload, containerImages, vulnerabilityReports)
+	if err != nil {
+		return err
 	}
 
 	log.Info("Writing VulnerabilityReports", "owner", workload)
-	err = r.Store.SaveVulnerabilityReports(ctx, workload, hash, vulnerabilityReports)
+	digests, err := resources.GetContainerImageDigestsFromJob(scanJob)
+	if err != nil {
+		return fmt.Errorf("getting container image digests: %w", err)
+	}
+
+	err = r.Writer.Write(ctx, workload, reports.WorkloadReport{
+		Hash:                hash,
+		Vulnerabilities:     vulnerabilityReports,
+		InitContainers:      resources.GetInitContainerNamesFromJob(scanJob),
+		EphemeralContainers: resources.GetEphemeralContainerNamesFromJob(scanJob),
+		Digests:             digests,
+	})
 	if err != nil {
 		return fmt.Errorf("writing vulnerability reports: %w", err)
 	}
-	log.V(1).Info("Deleting complete scan job")
-	return r.Client.Delete(ctx, scanJob, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	r.writeSBOMs(ctx, workload, hash, pod, containerImages)
+	scannedDigests := make([]string, 0, len(digests))
+	for _, digest := range digests {
+		scannedDigests = append(scannedDigests, digest)
+	}
+	if err = r.DigestCache.Add(r.Clock.Now(), scannedDigests...); err != nil {
+		log.Error(err, "Unable to save scanned digests")
+	}
+	for imageRef := range resultsByImage {
+		r.CircuitBreaker.RecordSuccess(imageRef)
+	}
+	r.setScanErrorAnnotation(ctx, workload, "")
+	r.setScanStatus(ctx, workload, etc.ScanStatusCompleted)
+	r.notify(ctx, workload, containerImages, vulnerabilityReports)
+	for imageRef, result := range resultsByImage {
+		r.recordEvent(ctx, workload, corev1.EventTypeNormal, controller.EventReasonScanCompleted,
+			"Scanned image %s with %s: %d critical, %d high, %d medium, %d low, %d unknown vulnerabilities",
+			imageRef, r.Scanner.GetName(),
+			result.Summary.CriticalCount, result.Summary.HighCount, result.Summary.MediumCount,
+			result.Summary.LowCount, result.Summary.UnknownCount)
+	}
+	r.recordScanJobMetrics(scanJob, metrics.ScanJobResultComplete)
+	return r.deleteScanJob(ctx, scanJob)
+}
+
+// applyPolicy drops vulnerabilities ignored by the policy from the specified reports of the workload
+// containers, and records the number of ignored vulnerabilities. Reports are left intact if the policy
+// is not configured.
+func (r *JobController) applyPolicy(ctx context.Context, workload kube.Object, containerImages kube.ContainerImages, reports map[string]v1alpha1.VulnerabilityScanResult) error {
+	if r.Policy == nil {
+		return nil
+	}
+	vulnerabilityPolicy, err := r.Policy.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("loading vulnerability policy: %w", err)
+	}
+	namespace := &corev1.Namespace{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: workload.Namespace}, namespace)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("getting namespace: %w", err)
+	}
+
+	for containerName, result := range reports {
+		filtered, ignored, err := vulnerabilityPolicy.Apply(ctx, policy.Input{
+			Namespace: policy.Namespace{Name: workload.Namespace, Labels: namespace.Labels},
+			Workload:  policy.Workload{Kind: string(workload.Kind), Name: workload.Name},
+			Container: containerName,
+			Image:     containerImages[containerName],
+		}, result)
+		if err != nil {
+			return fmt.Errorf("applying vulnerability policy to container %s: %w", containerName, err)
+		}
+		if ignored == 0 {
+			continue
+		}
+		log.V(1).Info("Ignoring vulnerabilities by policy", "owner", workload,
+			"container", containerName, "ignored", ignored)
+		metrics.RecordIgnoredVulnerabilities(workload.Namespace, ignored)
+		reports[containerName] = filtered
+	}
+	return nil
+}
+
+// writeSBOMs writes SBOMs generated by the scan Job Pod for the specified containers of the workload.
+// Scan Job Pods without SBOM containers are ignored. Errors are logged rather than returned, as
+// vulnerability reports are already written.
+func (r *JobController) writeSBOMs(ctx context.Context, workload kube.Object, hash string, pod *corev1.Pod, containerImages kube.ContainerImages) {
+	if r.SBOMWriter == nil {
+		return
+	}
+	log := log.WithValues("pod", fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+
+	containerNames := make(map[string]bool)
+	for _, container := range pod.Spec.Containers {
+		containerNames[container.Name] = true
+	}
+
+	// Similarly to vulnerabilities, each image has one SBOM generated by the scan Job container
+	// named after the first workload container referring to that image.
+	documentsByImage := make(map[string]sbom.Document)
+	for _, container := range pod.Spec.Containers {
+		imageRef, ok := containerImages[container.Name]
+		if !ok {
+			continue
+		}
+		sbomContainer := scanner.GetSBOMContainerName(container.Name)
+		if !containerNames[sbomContainer] {
+			continue
+		}
+		logsReader, err := r.LogsReader.GetLogsForPod(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: pod.Name}, &corev1.PodLogOptions{
+			Container: sbomContainer,
+			Follow:    true,
+		})
+		if err != nil {
+			log.Error(err, "Unable to get SBOM logs", "container", sbomContainer)
+			continue
+		}
+		document, err := sbom.ParseCycloneDX(logsReader)
+		_ = logsReader.Close()
+		if err != nil {
+			log.Error(err, "Unable to parse SBOM", "container", sbomContainer)
+			continue
+		}
+		documentsByImage[imageRef] = document
+	}
+
+	for containerName, imageRef := range containerImages {
+		document, ok := documentsByImage[imageRef]
+		if !ok {
+			continue
+		}
+		err := r.SBOMWriter.WriteSBOM(ctx, workload, hash, containerName, etc.SBOMFormatCycloneDX, document)
+		if err != nil {
+			log.Error(err, "Unable to write SBOM", "owner", workload, "container", containerName)
+		}
+	}
+}
+
+// hasExpiredVulnerabilityReports returns true if VulnerabilityReports of the specified
+// workload were written before the configured TTL, false otherwise or when the TTL is not set.
+func (r *JobController) hasExpiredVulnerabilityReports(ctx context.Context, workload kube.Object, hash string) (bool, error) {
+	if r.Config.ScanReportTTL <= 0 {
+		return false, nil
+	}
+	updateTime, err := r.Writer.GetUpdateTime(ctx, workload, hash)
+	if err != nil {
+		return false, fmt.Errorf("getting vulnerability reports update time: %w", err)
+	}
+	return r.Clock.Since(updateTime) >= r.Config.ScanReportTTL, nil
 }
 
 func (r *JobController) GetPodControlledBy(ctx context.Context, job *batchv1.Job) (*corev1.Pod, error) {
@@ -148,26 +396,495 @@ func (r *JobController) GetPodControlledBy(ctx context.Context, job *batchv1.Job
 	return podList.Items[0].DeepCopy(), nil
 }
 
-func (r *JobController) processFailedScanJob(ctx context.Context, scanJob *batchv1.Job) error {
+// processFailedScanJob recreates the specified scan Job if it failed due to transient errors and
+// the retry limit has not been reached yet. Subsequent retries are delayed exponentially.
+// Otherwise, the scan is considered permanently failed.
+func (r *JobController) processFailedScanJob(ctx context.Context, scanJob *batchv1.Job) (ctrl.Result, error) {
 	log := log.WithValues("job", fmt.Sprintf("%s/%s", scanJob.Namespace, scanJob.Name))
 
+	var statuses map[string]*corev1.ContainerStateTerminated
 	pod, err := r.GetPodControlledBy(ctx, scanJob)
+	switch {
+	case err == nil:
+		statuses = pods.GetTerminatedContainersStatusesByPod(pod)
+	case IsScanJobDeadlineExceeded(scanJob):
+		// The Pod of a scan Job which exceeded its deadline is killed and might be deleted already.
+		log.V(1).Info("Ignoring missing pod of scan job which exceeded its deadline", "error", err.Error())
+	default:
+		return ctrl.Result{}, err
+	}
+
+	retryCount := GetScanJobRetryCount(scanJob)
+	retry := IsScanJobFailureRetriable(scanJob, statuses) && retryCount < r.Config.ScanJobRetryLimit
+	if retry {
+		backoff := GetScanJobRetryBackoff(r.Config.ScanJobRetryBackoff, retryCount)
+		if elapsed := r.Clock.Since(GetScanJobFailureTime(scanJob)); elapsed < backoff {
+			log.V(1).Info("Waiting to retry failed scan job", "retryCount", retryCount, "requeueAfter", backoff-elapsed)
+			return ctrl.Result{RequeueAfter: backoff - elapsed}, nil
+		}
+	}
+
+	containerImages, err := resources.GetContainerImagesFromJob(scanJob)
 	if err != nil {
-		return err
+		return ctrl.Result{}, fmt.Errorf("getting container images: %w", err)
 	}
-	statuses := pods.GetTerminatedContainersStatusesByPod(pod)
+	workload, err := kube.ObjectFromLabelsSet(scanJob.Labels)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting workload from scan job labels set: %w", err)
+	}
+	reasons := make(map[string]bool)
+	failedImages := make(map[string]bool)
 	for container, status := range statuses {
 		if status.ExitCode == 0 {
 			continue
 		}
-		log.Error(nil, "Scan job container", "container", container, "status.reason", status.Reason, "status.message", status.Message)
+		if imageRef, ok := containerImages[container]; ok {
+			failedImages[imageRef] = true
+		}
+		scanErr := r.classifyScanError(ctx, scanJob, pod, container, status)
+		if scanErr == nil {
+			reasons[ScanErrorReasonUnknown] = true
+			log.Error(nil, "Scan job container", "container", container, "status.reason", status.Reason, "status.message", status.Message)
+			r.recordEvent(ctx, workload, corev1.EventTypeWarning, controller.EventReasonScanFailed,
+				"Failed to scan image %s with %s: %s: %s", containerImages[container], r.Scanner.GetName(),
+				status.Reason, status.Message)
+			continue
+		}
+		reasons[scanErr.Reason] = true
+		log.Error(scanErr, "Scan job container", "container", container, "error.reason", scanErr.Reason,
+			"status.reason", status.Reason, "status.message", status.Message)
+		r.recordEvent(ctx, workload, corev1.EventTypeWarning, controller.EventReasonScanFailed,
+			"Failed to scan image %s with %s: %s: %s: %s", containerImages[container], r.Scanner.GetName(),
+			scanErr.Error(), status.Reason, status.Message)
+	}
+	if len(reasons) == 0 && IsScanJobDeadlineExceeded(scanJob) {
+		reasons[logs.ErrTimeout.Reason] = true
+		r.recordEvent(ctx, workload, corev1.EventTypeWarning, controller.EventReasonScanFailed,
+			"Failed to scan images %s with %s: %s", strings.Join(GetUniqueImages(containerImages), ", "),
+			r.Scanner.GetName(), logs.ErrTimeout.Error())
+	}
+	r.setScanErrorAnnotation(ctx, workload, JoinScanErrorReasons(reasons))
+	r.recordScanJobMetrics(scanJob, metrics.ScanJobResultFailed)
+
+	if len(failedImages) == 0 {
+		for _, imageRef := range GetUniqueImages(containerImages) {
+			failedImages[imageRef] = true
+		}
+	}
+	var suspended []string
+	for imageRef := range failedImages {
+		if r.CircuitBreaker.RecordFailure(r.Clock.Now(), imageRef) {
+			suspended = append(suspended, imageRef)
+		}
+	}
+	if len(suspended) > 0 {
+		sort.Strings(suspended)
+		log.Info("Suspending scans of images which failed too many times in a row", "images", suspended,
+			"failureThreshold", r.Config.FailureThreshold, "failureCooldown", r.Config.FailureCooldown)
+		r.recordEvent(ctx, workload, corev1.EventTypeWarning, controller.EventReasonScanSuspended,
+			"Suspended scans of images %s for %s after %d consecutive failures", strings.Join(suspended, ", "),
+			r.Config.FailureCooldown, r.Config.FailureThreshold)
+		r.setScanStatus(ctx, workload, etc.ScanStatusSuspended)
+		return ctrl.Result{}, r.deleteScanJob(ctx, scanJob)
+	}
+
+	if !retry {
+		log.Info("Giving up failed scan job", "retryCount", retryCount, "retryLimit", r.Config.ScanJobRetryLimit)
+		r.setScanStatus(ctx, workload, etc.ScanStatusFailed)
+		return ctrl.Result{}, r.deleteScanJob(ctx, scanJob)
+	}
+
+	err = r.retryScanJob(ctx, scanJob, retryCount+1)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("retrying scan job: %w", err)
+	}
+	r.setScanStatus(ctx, workload, etc.ScanStatusPending)
+	// The failed scan Job is replaced, hence it's deleted regardless of the DeleteScanJobs flag.
+	log.V(1).Info("Deleting replaced scan job")
+	err = r.Client.Delete(ctx, scanJob, client.PropagationPolicy(metav1.DeletePropagationForeground))
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("deleting scan job: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// GetUniqueImages returns sorted references of the specified container images without duplicates.
+func GetUniqueImages(containerImages kube.ContainerImages) []string {
+	unique := make(map[string]bool)
+	var images []string
+	for _, imageRef := range containerImages {
+		if !unique[imageRef] {
+			unique[imageRef] = true
+			images = append(images, imageRef)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// ScanErrorReasonUnknown is the reason of scan failures which cannot be classified.
+const ScanErrorReasonUnknown = "Unknown"
+
+// classifyScanError returns the typed error of the specified failed scan Job container based on its logs.
+// The termination message of the container is classified instead when the logs cannot be read, and a
+// scan Job which exceeded its deadline for unrecognized reasons is considered timed out.
+func (r *JobController) classifyScanError(ctx context.Context, scanJob *batchv1.Job, pod *corev1.Pod, container string, status *corev1.ContainerStateTerminated) *logs.ScanError {
+	scanErr, err := r.LogsReader.GetScanErrorForPod(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: pod.Name}, container)
+	if err != nil {
+		log.V(1).Info("Classifying scan error by termination message", "container", container, "err", err.Error())
+		scanErr = logs.ClassifyScanError(strings.NewReader(status.Message))
+	}
+	if scanErr == nil && IsScanJobDeadlineExceeded(scanJob) {
+		return logs.ErrTimeout
+	}
+	return scanErr
+}
+
+// IsScanJobDeadlineExceeded returns true if the specified scan Job failed because it ran longer
+// than its active deadline, false otherwise.
+func IsScanJobDeadlineExceeded(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Reason == "DeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// JoinScanErrorReasons returns the specified scan error reasons sorted and separated by comma.
+func JoinScanErrorReasons(reasons map[string]bool) string {
+	var sorted []string
+	for reason := range reasons {
+		sorted = append(sorted, reason)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// setScanErrorAnnotation sets the etc.AnnotationScanError annotation of the specified workload to the given
+// reasons, or removes the annotation if reasons are blank. Errors are logged rather than returned as failing
+// to annotate the workload should not fail the reconciliation of a scan Job.
+func (r *JobController) setScanErrorAnnotation(ctx context.Context, workload kube.Object, reasons string) {
+	err := resources.SetWorkloadAnnotation(ctx, r.Client, workload, etc.AnnotationScanError, reasons)
+	switch {
+	case errors.IsNotFound(err):
+		log.V(1).Info("Ignoring scan error annotation for workload that cannot be retrieved", "workload", workload, "err", err.Error())
+	case err != nil:
+		log.Error(err, "Unable to annotate workload with scan error", "workload", workload)
+	}
+}
+
+// setScanStatus sets the etc.AnnotationScanStatus annotation of the specified workload to the given status.
+// Similarly to setScanErrorAnnotation, errors are logged rather than returned.
+func (r *JobController) setScanStatus(ctx context.Context, workload kube.Object, status etc.ScanStatus) {
+	err := resources.SetWorkloadAnnotation(ctx, r.Client, workload, etc.AnnotationScanStatus, string(status))
+	switch {
+	case errors.IsNotFound(err):
+		log.V(1).Info("Ignoring scan status for workload that cannot be retrieved", "workload", workload, "err", err.Error())
+	case err != nil:
+		log.Error(err, "Unable to annotate workload with scan status", "workload", workload, "status", status)
+	}
+}
+
+// retryScanJob creates a copy of the specified failed scan Job annotated with the given retry count.
+// Secrets owned by the failed scan Job, such as registry credentials, are also made owned by the copy
+// so that they're not garbage collected along with the failed scan Job.
+func (r *JobController) retryScanJob(ctx context.Context, failedJob *batchv1.Job, retryCount int) error {
+	log := log.WithValues("job", fmt.Sprintf("%s/%s", failedJob.Namespace, failedJob.Name))
+
+	// Make retrying idempotent in case the failed scan Job could not be deleted previously.
+	jobList := &batchv1.JobList{}
+	err := r.Client.List(ctx, jobList, client.MatchingLabels(GetScanJobSelectorLabels(failedJob)), client.InNamespace(failedJob.Namespace))
+	if err != nil {
+		return fmt.Errorf("listing scan jobs: %w", err)
+	}
+	for _, job := range jobList.Items {
+		if GetScanJobRetryCount(&job) >= retryCount {
+			log.V(1).Info("Scan job already retried", "retryJob", fmt.Sprintf("%s/%s", job.Namespace, job.Name))
+			return nil
+		}
+	}
+
+	retryJob := NewRetryScanJob(failedJob, retryCount)
+	log.Info("Retrying failed scan job", "retryJob", fmt.Sprintf("%s/%s", retryJob.Namespace, retryJob.Name),
+		"retryCount", retryCount)
+	err = r.Client.Create(ctx, retryJob)
+	if err != nil {
+		return err
+	}
+
+	for _, secretName := range GetSecretNamesFromJob(failedJob) {
+		secret := &corev1.Secret{}
+		err := r.Client.Get(ctx, types.NamespacedName{Namespace: failedJob.Namespace, Name: secretName}, secret)
+		if err != nil && errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("getting secret: %w", err)
+		}
+		if !IsOwnedBy(secret, failedJob) {
+			continue
+		}
+		err = controllerutil.SetOwnerReference(retryJob, secret, r.Scheme)
+		if err != nil {
+			return err
+		}
+		err = r.Client.Update(ctx, secret)
+		if err != nil {
+			return fmt.Errorf("updating secret: %w", err)
+		}
+	}
+	return nil
+}
+
+// NewRetryScanJob returns a copy of the specified failed scan Job annotated with the given retry count.
+// Labels and the selector generated by the Job controller are cleared so that new ones are generated.
+func NewRetryScanJob(failedJob *batchv1.Job, retryCount int) *batchv1.Job {
+	annotations := make(map[string]string)
+	for key, value := range failedJob.Annotations {
+		annotations[key] = value
+	}
+	annotations[etc.AnnotationScanJobRetryCount] = strconv.Itoa(retryCount)
+
+	spec := failedJob.Spec.DeepCopy()
+	spec.Selector = nil
+	spec.ManualSelector = nil
+	delete(spec.Template.Labels, "controller-uid")
+	delete(spec.Template.Labels, "job-name")
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        uuid.New().String(),
+			Namespace:   failedJob.Namespace,
+			Labels:      GetScanJobSelectorLabels(failedJob),
+			Annotations: annotations,
+		},
+		Spec: *spec,
+	}
+}
+
+// GetScanJobSelectorLabels returns labels of the specified scan Job without labels generated by the Job controller.
+func GetScanJobSelectorLabels(job *batchv1.Job) map[string]string {
+	labels := make(map[string]string)
+	for key, value := range job.Labels {
+		if key == "controller-uid" || key == "job-name" {
+			continue
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// GetSecretNamesFromJob returns names of Secrets referenced by environment variables of the specified Job containers.
+func GetSecretNamesFromJob(job *batchv1.Job) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, container := range append(append([]corev1.Container{}, job.Spec.Template.Spec.InitContainers...), job.Spec.Template.Spec.Containers...) {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil || seen[env.ValueFrom.SecretKeyRef.Name] {
+				continue
+			}
+			seen[env.ValueFrom.SecretKeyRef.Name] = true
+			names = append(names, env.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	return names
+}
+
+// IsOwnedBy returns true if the specified object has an owner reference to the given owner, false otherwise.
+func IsOwnedBy(object metav1.Object, owner metav1.Object) bool {
+	for _, ref := range object.GetOwnerReferences() {
+		if ref.Name == owner.GetName() && ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetScanJobRetryCount returns the number of times the specified scan Job has been retried.
+func GetScanJobRetryCount(job *batchv1.Job) int {
+	count, err := strconv.Atoi(job.Annotations[etc.AnnotationScanJobRetryCount])
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// GetScanJobRetryBackoff returns the length of time to wait before the next retry of a scan Job
+// that has already been retried the specified number of times. The backoff doubles with each retry.
+func GetScanJobRetryBackoff(initial time.Duration, retryCount int) time.Duration {
+	return initial * time.Duration(1<<uint(retryCount))
+}
+
+// GetScanJobFailureTime returns the transition time of the failed condition of the specified Job.
+func GetScanJobFailureTime(job *batchv1.Job) time.Time {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+var (
+	// imageNotFoundErrors are fragments of error messages reported by scanners when an image does not
+	// exist, which will not change by retrying the scan.
+	imageNotFoundErrors = []string{
+		"manifest unknown",
+		"name unknown",
+		"not found",
+		"does not exist",
+	}
+	// transientErrors are fragments of error messages reported by scanners when a registry is
+	// temporarily unavailable.
+	transientErrors = []string{
+		"timeout",
+		"timed out",
+		"connection refused",
+		"connection reset",
+		"no such host",
+		"temporary failure",
+		"tls handshake",
+		"too many requests",
+		"service unavailable",
+		"bad gateway",
+	}
+)
+
+// IsScanJobFailureRetriable returns true if the specified scan Job failed due to transient errors,
+// such as registry timeouts, false otherwise. A scan Job which exceeded its deadline is retriable,
+// whereas a scan Job which failed because an image was not found is not.
+func IsScanJobFailureRetriable(job *batchv1.Job, statuses map[string]*corev1.ContainerStateTerminated) bool {
+	retriable := IsScanJobDeadlineExceeded(job)
+	for _, status := range statuses {
+		if status.ExitCode == 0 {
+			continue
+		}
+		message := strings.ToLower(status.Message)
+		if containsAny(message, imageNotFoundErrors) {
+			return false
+		}
+		if containsAny(message, transientErrors) {
+			retriable = true
+		}
 	}
-	log.V(1).Info("Deleting failed scan job")
-	return r.Client.Delete(ctx, scanJob, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	return retriable
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteScanJob deletes the specified scan Job once it's processed, unless deletion of scan Jobs is disabled,
+// or the scan Job has the TTL after finished set, in which case it's left to the TTL controller of Kubernetes.
+// The foreground propagation policy makes sure that the Job is not removed before its Pods.
+func (r *JobController) deleteScanJob(ctx context.Context, scanJob *batchv1.Job) error {
+	log := log.WithValues("job", fmt.Sprintf("%s/%s", scanJob.Namespace, scanJob.Name))
+	if !r.Config.DeleteScanJobs {
+		log.V(1).Info("Leaving scan job as deletion of scan jobs is disabled")
+		return r.setScanJobProcessed(ctx, scanJob)
+	}
+	if scanJob.Spec.TTLSecondsAfterFinished != nil {
+		log.V(1).Info("Leaving scan job to be deleted by the TTL controller",
+			"ttlSecondsAfterFinished", *scanJob.Spec.TTLSecondsAfterFinished)
+		return r.setScanJobProcessed(ctx, scanJob)
+	}
+	log.V(1).Info("Deleting scan job")
+	err := r.Client.Delete(ctx, scanJob, client.PropagationPolicy(metav1.DeletePropagationForeground))
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("deleting scan job: %w", err)
+	}
+	return nil
+}
+
+// setScanJobProcessed patches the specified scan Job left in place with the etc.AnnotationScanJobProcessed
+// annotation, so that it's neither processed again nor considered pending by the PodController.
+func (r *JobController) setScanJobProcessed(ctx context.Context, scanJob *batchv1.Job) error {
+	// Do not modify the object that might be cached.
+	updated := scanJob.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string)
+	}
+	updated.Annotations[etc.AnnotationScanJobProcessed] = "true"
+	err := r.Client.Patch(ctx, updated, client.MergeFrom(scanJob))
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("annotating scan job as processed: %w", err)
+	}
+	return nil
+}
+
+// notify sends notifications about VulnerabilityReports with vulnerabilities at or above the configured
+// severity threshold. Errors are logged rather than returned as failing to notify should not fail the
+// reconciliation of a scan Job.
+func (r *JobController) notify(ctx context.Context, workload kube.Object, containerImages kube.ContainerImages, reports map[string]v1alpha1.VulnerabilityScanResult) {
+	if r.Notifier == nil {
+		return
+	}
+	threshold, err := notify.ParseSeverity(r.Config.NotifySeverityThreshold)
+	if err != nil {
+		log.Error(err, "Skipping notifications")
+		return
+	}
+	for container, report := range reports {
+		if !notify.HasVulnerabilitiesAtOrAbove(report.Summary, threshold) {
+			continue
+		}
+		err := r.Notifier.Notify(ctx, notify.NewPayload(workload, container, containerImages[container], report))
+		if err != nil {
+			log.Error(err, "Unable to send notification", "workload", workload, "container", container)
+		}
+	}
+}
+
+// recordEvent records an Event for the specified workload. Errors are logged rather than returned
+// as failing to record an Event should not fail the reconciliation of a scan Job.
+func (r *JobController) recordEvent(ctx context.Context, workload kube.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	obj, err := resources.GetRuntimeObjectFor(ctx, r.Client, workload)
+	if err != nil {
+		log.V(1).Info("Ignoring event for workload that cannot be retrieved", "workload", workload, "reason", reason, "err", err.Error())
+		return
+	}
+	r.Recorder.Eventf(obj.(runtime.Object), eventType, reason, messageFmt, args...)
+}
+
+func (r *JobController) recordScanJobMetrics(scanJob *batchv1.Job, result metrics.ScanJobResult) {
+	metrics.RecordScanJob(r.Scanner.GetName(), result, GetScanJobDuration(scanJob))
+}
+
+// GetScanJobDuration returns the length of time the specified scan Job has been running for.
+// The end time is the completion time of a complete Job or the transition time of the
+// last condition of a failed Job.
+func GetScanJobDuration(job *batchv1.Job) time.Duration {
+	if job.Status.StartTime == nil {
+		return 0
+	}
+	end := job.Status.CompletionTime
+	if end == nil && len(job.Status.Conditions) > 0 {
+		end = &job.Status.Conditions[len(job.Status.Conditions)-1].LastTransitionTime
+	}
+	if end == nil {
+		return 0
+	}
+	return end.Sub(job.Status.StartTime.Time)
+}
+
+// IsScanJob returns true if the specified Job is labeled with the configured scan Job label, and was created
+// by this instance of the operator, false otherwise.
+func IsScanJob(config etc.Operator, job metav1.Object) bool {
+	key, value := config.GetScanJobLabel()
+	actual, ok := job.GetLabels()[key]
+	return ok && actual == value && config.IsOwnedByInstance(job.GetLabels())
 }
 
 func (r *JobController) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&batchv1.Job{}).
+		WithEventFilter(predicate.NewPredicateFuncs(func(meta metav1.Object, _ runtime.Object) bool {
+			return IsScanJob(r.Config, meta)
+		})).
 		Complete(r)
 }
@@ -1 +1,1319 @@
-package job
+package job_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/aquasecurity/starboard-operator/pkg/controller/job"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/logs"
+	"github.com/aquasecurity/starboard-operator/pkg/notify"
+	"github.com/aquasecurity/starboard-operator/pkg/policy"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeScanner is a VulnerabilityScanner which returns the same scan result or error for any image.
+type fakeScanner struct {
+	result starboardv1alpha1.VulnerabilityScanResult
+	// results override the result for the specified image references.
+	results map[string]starboardv1alpha1.VulnerabilityScanResult
+	err     error
+}
+
+func (s *fakeScanner) GetName() string {
+	return "Fake"
+}
+
+func (s *fakeScanner) NewScanJob(_ scanner.JobMeta, _ scanner.Options, _ corev1.PodSpec) (*batchv1.Job, error) {
+	return nil, nil
+}
+
+func (s *fakeScanner) ParseVulnerabilityScanResult(imageRef string, _ io.ReadCloser) (starboardv1alpha1.VulnerabilityScanResult, error) {
+	if result, ok := s.results[imageRef]; ok {
+		return result, s.err
+	}
+	return s.result, s.err
+}
+
+// fakeNotifier is a Notifier which collects sent payloads.
+type fakeNotifier struct {
+	payloads []notify.Payload
+	err      error
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, payload notify.Payload) error {
+	n.payloads = append(n.payloads, payload)
+	return n.err
+}
+
+// fakeWriter is a report Writer which keeps written reports in memory.
+type fakeWriter struct {
+	reports map[kube.Object]reports.WorkloadReport
+}
+
+func (w *fakeWriter) Write(_ context.Context, workload kube.Object, report reports.WorkloadReport) error {
+	w.reports[workload] = report
+	return nil
+}
+
+func (w *fakeWriter) HasReport(_ context.Context, workload kube.Object, hash string, containerImages kube.ContainerImages) (bool, error) {
+	report, ok := w.reports[workload]
+	if !ok || report.Hash != hash {
+		return false, nil
+	}
+	for containerName := range containerImages {
+		if _, ok := report.Vulnerabilities[containerName]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (w *fakeWriter) GetUpdateTime(_ context.Context, _ kube.Object, _ string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+	return scheme
+}
+
+func newWorkload() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nginx",
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nginx", Image: "nginx:1.16"},
+			},
+		},
+	}
+}
+
+func newScanJob(condition batchv1.JobConditionType) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scan-job",
+			Namespace: "starboard-operator",
+			Labels: map[string]string{
+				kube.LabelResourceKind:         string(kube.KindPod),
+				kube.LabelResourceName:         "nginx",
+				kube.LabelResourceNamespace:    "default",
+				"app.kubernetes.io/managed-by": "starboard-operator",
+				etc.LabelPodSpecHash:           "7f8b9c6d5",
+			},
+			Annotations: map[string]string{
+				kube.AnnotationContainerImages: `{"nginx":"nginx:1.16"}`,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"controller-uid": "a4e3b5c1",
+				},
+			},
+		},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{
+				{Type: condition, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+// newScanResult returns the scan result with vulnerabilities of the specified severities.
+func newScanResult(severities ...starboardv1alpha1.Severity) starboardv1alpha1.VulnerabilityScanResult {
+	result := starboardv1alpha1.VulnerabilityScanResult{}
+	for i, severity := range severities {
+		result.Vulnerabilities = append(result.Vulnerabilities, starboardv1alpha1.Vulnerability{
+			VulnerabilityID: fmt.Sprintf("CVE-2020-%04d", i),
+			Severity:        severity,
+		})
+	}
+	result.Summary = reports.SummarizeVulnerabilities(result.Vulnerabilities)
+	return result
+}
+
+func newScanJobPod(exitCode int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scan-job-x8k2p",
+			Namespace: "starboard-operator",
+			Labels: map[string]string{
+				"controller-uid": "a4e3b5c1",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nginx", Image: "aquasec/trivy:0.11.0"},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "nginx",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: exitCode,
+							Reason:   "Error",
+							Message:  "unable to pull image",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newJobController constructs a JobController backed by the fake client populated with
+// the specified objects. Pod logs are served by the specified test server.
+func newJobController(t *testing.T, server *httptest.Server, config etc.Operator, objects ...runtime.Object) *job.JobController {
+	t.Helper()
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	scheme := newScheme()
+	fakeClient := fake.NewFakeClientWithScheme(scheme, objects...)
+	store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, config.InstanceID)
+	return &job.JobController{
+		Config:     config,
+		Client:     fakeClient,
+		LogsReader: logs.NewReader(clientset, 0, ""),
+		Scheme:     scheme,
+		Scanner: &fakeScanner{
+			result: starboardv1alpha1.VulnerabilityScanResult{
+				Summary: starboardv1alpha1.VulnerabilitySummary{
+					CriticalCount: 1,
+					HighCount:     2,
+				},
+			},
+		},
+		Writer:     store,
+		SBOMWriter: store,
+		Clock:      clock.RealClock{},
+		Recorder:   record.NewFakeRecorder(10),
+	}
+}
+
+func TestJobController_Reconcile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	t.Run("Should record event when scan job is complete", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Len(t, reportList.Items, 1)
+
+		events := jobController.Recorder.(*record.FakeRecorder).Events
+		require.Len(t, events, 1)
+		assert.Equal(t, "Normal ScanCompleted Scanned image nginx:1.16 with Fake: "+
+			"1 critical, 2 high, 0 medium, 0 low, 0 unknown vulnerabilities", <-events)
+	})
+
+	t.Run("Should record event when scan job is failed", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), newScanJob(batchv1.JobFailed), newScanJobPod(1))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		events := jobController.Recorder.(*record.FakeRecorder).Events
+		require.Len(t, events, 1)
+		assert.Equal(t, "Warning ScanFailed Failed to scan image nginx:1.16 with Fake: Error: unable to pull image", <-events)
+	})
+
+	t.Run("Should annotate workload with unknown scan error", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), newScanJob(batchv1.JobFailed), newScanJobPod(1))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		workload := &corev1.Pod{}
+		require.NoError(t, jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		assert.Equal(t, "Unknown", workload.Annotations[etc.AnnotationScanError])
+	})
+
+	t.Run("Should classify scan error by scan job logs", func(t *testing.T) {
+		logsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("FATAL\tGET https://index.docker.io/v2/library/nginx/manifests/1.999: MANIFEST_UNKNOWN: manifest unknown"))
+		}))
+		defer logsServer.Close()
+		jobController := newJobController(t, logsServer, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), newScanJob(batchv1.JobFailed), newScanJobPod(1))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		events := jobController.Recorder.(*record.FakeRecorder).Events
+		require.Len(t, events, 1)
+		assert.Equal(t, "Warning ScanFailed Failed to scan image nginx:1.16 with Fake: image not found: Error: unable to pull image", <-events)
+
+		workload := &corev1.Pod{}
+		require.NoError(t, jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		assert.Equal(t, "ImageNotFound", workload.Annotations[etc.AnnotationScanError])
+	})
+
+	t.Run("Should remove scan error annotation when scan job is complete", func(t *testing.T) {
+		workload := newWorkload()
+		workload.Annotations = map[string]string{etc.AnnotationScanError: "Timeout"}
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, workload, newScanJob(batchv1.JobComplete), newScanJobPod(0))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		updated := &corev1.Pod{}
+		require.NoError(t, jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nginx"}, updated))
+		assert.NotContains(t, updated.Annotations, etc.AnnotationScanError)
+	})
+
+	t.Run("Should requeue after configured interval when scan job is running", func(t *testing.T) {
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Status.Conditions = nil
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:                "starboard-operator",
+			ReconcileRequeueInterval: 45 * time.Second,
+		}, newWorkload(), scanJob)
+
+		result, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: 45 * time.Second}, result)
+	})
+
+	t.Run("Should annotate report with image digest", func(t *testing.T) {
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Annotations[etc.AnnotationContainerImageDigests] = `{"nginx":"sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c"}`
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), scanJob, newScanJobPod(0))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		report := &starboardv1alpha1.VulnerabilityReport{}
+		require.NoError(t, jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, report))
+		assert.Equal(t, "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c", report.Annotations[etc.AnnotationImageDigest])
+	})
+
+	t.Run("Should cache digests of scanned images", func(t *testing.T) {
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Annotations[etc.AnnotationContainerImageDigests] = `{"nginx":"sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c"}`
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), scanJob, newScanJobPod(0))
+		digestCache, err := controller.NewDigestCache(10, "")
+		require.NoError(t, err)
+		jobController.DigestCache = digestCache
+
+		_, err = jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		_, ok := digestCache.Get("sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c")
+		assert.True(t, ok)
+		assert.Equal(t, 1, digestCache.Len())
+	})
+
+	t.Run("Should write report with configured writer", func(t *testing.T) {
+		writer := &fakeWriter{reports: make(map[kube.Object]reports.WorkloadReport)}
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+		jobController.Writer = writer
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		report, ok := writer.reports[kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}]
+		require.True(t, ok)
+		assert.Equal(t, "7f8b9c6d5", report.Hash)
+		require.Contains(t, report.Vulnerabilities, "nginx")
+		assert.Equal(t, 1, report.Vulnerabilities["nginx"].Summary.CriticalCount)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Empty(t, reportList.Items)
+	})
+
+	t.Run("Should not write report when writer has report", func(t *testing.T) {
+		workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+		writer := &fakeWriter{reports: map[kube.Object]reports.WorkloadReport{
+			workload: {
+				Hash:            "7f8b9c6d5",
+				Vulnerabilities: map[string]starboardv1alpha1.VulnerabilityScanResult{"nginx": {}},
+			},
+		}}
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+		jobController.Writer = writer
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, writer.reports[workload].Vulnerabilities["nginx"].Summary.CriticalCount)
+		assert.Len(t, jobController.Recorder.(*record.FakeRecorder).Events, 0)
+	})
+
+	t.Run("Should write report when scan job was created on demand", func(t *testing.T) {
+		workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+		writer := &fakeWriter{reports: map[kube.Object]reports.WorkloadReport{
+			workload: {
+				Hash:            "7f8b9c6d5",
+				Vulnerabilities: map[string]starboardv1alpha1.VulnerabilityScanResult{"nginx": {}},
+			},
+		}}
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Annotations[etc.AnnotationRescan] = "1602756000"
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), scanJob, newScanJobPod(0))
+		jobController.Writer = writer
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, writer.reports[workload].Vulnerabilities["nginx"].Summary.CriticalCount)
+	})
+
+	t.Run("Should not record event when workload does not exist", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newScanJob(batchv1.JobFailed), newScanJobPod(1))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		assert.Len(t, jobController.Recorder.(*record.FakeRecorder).Events, 0)
+	})
+
+	t.Run("Should delete scan job when scan job is complete", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:      "starboard-operator",
+			DeleteScanJobs: true,
+		}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		err = jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}, &batchv1.Job{})
+		assert.True(t, errors.IsNotFound(err))
+	})
+
+	t.Run("Should not delete scan job when deletion of scan jobs is disabled", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:      "starboard-operator",
+			DeleteScanJobs: false,
+		}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		scanJob := &batchv1.Job{}
+		err = jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}, scanJob)
+		assert.NoError(t, err)
+		assert.Equal(t, "true", scanJob.Annotations[etc.AnnotationScanJobProcessed])
+	})
+
+	t.Run("Should not process scan job again once it has been processed", func(t *testing.T) {
+		writer := &fakeWriter{reports: make(map[kube.Object]reports.WorkloadReport)}
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Annotations[etc.AnnotationRescan] = "1602756000"
+		scanJob.Annotations[etc.AnnotationScanJobProcessed] = "true"
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:      "starboard-operator",
+			DeleteScanJobs: false,
+		}, newWorkload(), scanJob, newScanJobPod(0))
+		jobController.Writer = writer
+
+		result, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+		assert.Empty(t, writer.reports)
+		assert.Len(t, jobController.Recorder.(*record.FakeRecorder).Events, 0)
+	})
+
+	t.Run("Should leave scan job with TTL after finished to TTL controller", func(t *testing.T) {
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Spec.TTLSecondsAfterFinished = pointer.Int32Ptr(600)
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:      "starboard-operator",
+			DeleteScanJobs: true,
+		}, newWorkload(), scanJob, newScanJobPod(0))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		err = jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}, &batchv1.Job{})
+		assert.NoError(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Len(t, reportList.Items, 1)
+	})
+
+	t.Run("Should not delete scan job when scan result cannot be parsed", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:      "starboard-operator",
+			DeleteScanJobs: true,
+		}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+		jobController.Scanner = &fakeScanner{err: fmt.Errorf("invalid character 'x' looking for beginning of value")}
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		scanJob := &batchv1.Job{}
+		err = jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}, scanJob)
+		assert.NoError(t, err)
+		assert.Equal(t, "true", scanJob.Annotations[etc.AnnotationScanJobProcessed])
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Len(t, reportList.Items, 0)
+
+		events := jobController.Recorder.(*record.FakeRecorder).Events
+		require.Len(t, events, 1)
+		assert.Equal(t, "Warning ScanFailed Failed to parse scan result of image nginx:1.16 with Fake: "+
+			"invalid character 'x' looking for beginning of value", <-events)
+	})
+
+	t.Run("Should return error to retry when reading logs times out", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:      "starboard-operator",
+			DeleteScanJobs: true,
+		}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+		jobController.Scanner = &fakeScanner{err: fmt.Errorf("decoding report: %w", logs.ErrReadTimeout)}
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.Error(t, err)
+		assert.True(t, logs.IsReadTimeout(err))
+
+		err = jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}, &batchv1.Job{})
+		assert.NoError(t, err)
+		assert.Len(t, jobController.Recorder.(*record.FakeRecorder).Events, 0)
+	})
+
+	t.Run("Should filter vulnerabilities by configured severities", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:        "starboard-operator",
+			ReportSeverities: "CRITICAL",
+		}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+		jobController.Scanner = &fakeScanner{
+			result: starboardv1alpha1.VulnerabilityScanResult{
+				Summary: starboardv1alpha1.VulnerabilitySummary{CriticalCount: 1, LowCount: 1},
+				Vulnerabilities: []starboardv1alpha1.Vulnerability{
+					{VulnerabilityID: "CVE-2020-1967", Severity: starboardv1alpha1.SeverityCritical},
+					{VulnerabilityID: "CVE-2011-3374", Severity: starboardv1alpha1.SeverityLow},
+				},
+			},
+		}
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		require.Len(t, reportList.Items, 1)
+		assert.Equal(t, starboardv1alpha1.VulnerabilitySummary{CriticalCount: 1}, reportList.Items[0].Report.Summary)
+		assert.Equal(t, []starboardv1alpha1.Vulnerability{
+			{VulnerabilityID: "CVE-2020-1967", Severity: starboardv1alpha1.SeverityCritical},
+		}, reportList.Items[0].Report.Vulnerabilities)
+	})
+}
+
+func TestJobController_ScanJobsNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	config := etc.Operator{Namespace: "starboard-operator", ScanJobsNamespace: "starboard-scans"}
+	scanJob := newScanJob(batchv1.JobComplete)
+	scanJob.Namespace = "starboard-scans"
+	scanJobPod := newScanJobPod(0)
+	scanJobPod.Namespace = "starboard-scans"
+
+	t.Run("Should write report of workload scanned by job in scan jobs namespace", func(t *testing.T) {
+		jobController := newJobController(t, server, config, newWorkload(), scanJob.DeepCopy(), scanJobPod.DeepCopy())
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-scans", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		require.Len(t, reportList.Items, 1)
+		assert.Equal(t, "default", reportList.Items[0].Namespace)
+		require.Len(t, reportList.Items[0].OwnerReferences, 1)
+		assert.Equal(t, "Pod", reportList.Items[0].OwnerReferences[0].Kind)
+		assert.Equal(t, "nginx", reportList.Items[0].OwnerReferences[0].Name)
+	})
+
+	t.Run("Should ignore job in operator namespace", func(t *testing.T) {
+		jobController := newJobController(t, server, config, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Empty(t, reportList.Items)
+	})
+}
+
+func TestJobController_MultipleContainers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	t.Run("Should parse logs of each scan job container into reports of workload containers", func(t *testing.T) {
+		workload := newWorkload()
+		workload.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "busybox:1.32"}}
+		workload.Spec.Containers = append(workload.Spec.Containers,
+			corev1.Container{Name: "redis", Image: "redis:5"},
+			corev1.Container{Name: "sidecar", Image: "nginx:1.16"})
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Annotations[kube.AnnotationContainerImages] =
+			`{"init":"busybox:1.32","nginx":"nginx:1.16","redis":"redis:5","sidecar":"nginx:1.16"}`
+		scanJob.Annotations[etc.AnnotationInitContainerNames] = "init"
+		// The image shared by the nginx and sidecar containers is scanned once by the nginx container.
+		scanJobPod := newScanJobPod(0)
+		scanJobPod.Spec.Containers = []corev1.Container{
+			{Name: "init", Image: "aquasec/trivy:0.11.0"},
+			{Name: "nginx", Image: "aquasec/trivy:0.11.0"},
+			{Name: "redis", Image: "aquasec/trivy:0.11.0"},
+		}
+
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"},
+			workload, scanJob, scanJobPod)
+		jobController.Scanner = &fakeScanner{
+			results: map[string]starboardv1alpha1.VulnerabilityScanResult{
+				"busybox:1.32": newScanResult(starboardv1alpha1.SeverityLow),
+				"nginx:1.16":   newScanResult(starboardv1alpha1.SeverityCritical),
+				"redis:5":      newScanResult(starboardv1alpha1.SeverityHigh, starboardv1alpha1.SeverityHigh),
+			},
+		}
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		summaries := make(map[string]starboardv1alpha1.VulnerabilitySummary)
+		for _, report := range reportList.Items {
+			summaries[report.Labels[kube.LabelContainerName]] = report.Report.Summary
+		}
+		assert.Equal(t, map[string]starboardv1alpha1.VulnerabilitySummary{
+			"init":    {LowCount: 1},
+			"nginx":   {CriticalCount: 1},
+			"redis":   {HighCount: 2},
+			"sidecar": {CriticalCount: 1},
+		}, summaries)
+	})
+
+	t.Run("Should read logs of scanner containers of scan pod with init containers and sidecars", func(t *testing.T) {
+		var mu sync.Mutex
+		var containers []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			containers = append(containers, r.URL.Query().Get("container"))
+			mu.Unlock()
+			_, _ = w.Write([]byte("{}"))
+		}))
+		defer server.Close()
+
+		// The scan pod runs the init container which downloads the vulnerability database, and the sidecar
+		// injected by a service mesh, whose logs must not be parsed as scan results.
+		scanJobPod := newScanJobPod(0)
+		scanJobPod.Spec.InitContainers = []corev1.Container{{Name: "download-db", Image: "aquasec/trivy:0.11.0"}}
+		scanJobPod.Spec.Containers = []corev1.Container{
+			{Name: "istio-proxy", Image: "istio/proxyv2:1.8.0"},
+			{Name: "nginx", Image: "aquasec/trivy:0.11.0"},
+		}
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"},
+			newWorkload(), newScanJob(batchv1.JobComplete), scanJobPod)
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"nginx"}, containers)
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Len(t, reportList.Items, 1)
+	})
+}
+
+func TestJobController_WriteSBOM(t *testing.T) {
+	const bom = `{"bomFormat":"CycloneDX","specVersion":"1.3","version":1,"components":[{"type":"library","name":"openssl","version":"1.1.1d"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("container") {
+		case "sbom-nginx":
+			_, _ = w.Write([]byte(bom))
+		default:
+			_, _ = w.Write([]byte("{}"))
+		}
+	}))
+	defer server.Close()
+
+	t.Run("Should write SBOM generated by scan job", func(t *testing.T) {
+		scanJobPod := newScanJobPod(0)
+		scanJobPod.Spec.Containers = append(scanJobPod.Spec.Containers, corev1.Container{Name: "sbom-nginx", Image: "aquasec/trivy:0.11.0"})
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), newScanJob(batchv1.JobComplete), scanJobPod)
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		configMap := &corev1.ConfigMap{}
+		require.NoError(t, jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "sbom-pod-nginx-nginx"}, configMap))
+		assert.Equal(t, bom, configMap.Data[reports.SBOMDataKey])
+		assert.Equal(t, "cyclonedx", configMap.Labels[etc.LabelSBOMFormat])
+		assert.Equal(t, "7f8b9c6d5", configMap.Labels[etc.LabelPodSpecHash])
+		assert.Equal(t, "nginx", configMap.Labels[kube.LabelContainerName])
+		require.Len(t, configMap.OwnerReferences, 1)
+		assert.Equal(t, "Pod", configMap.OwnerReferences[0].Kind)
+	})
+
+	t.Run("Should not write SBOM when scan job does not generate it", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+
+		_, err := jobController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}})
+		require.NoError(t, err)
+
+		configMapList := &corev1.ConfigMapList{}
+		require.NoError(t, jobController.Client.List(context.Background(), configMapList))
+		assert.Empty(t, configMapList.Items)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Len(t, reportList.Items, 1)
+	})
+}
+
+func TestGetScanJobDuration(t *testing.T) {
+	startTime := metav1.NewTime(time.Date(2020, 9, 25, 10, 0, 0, 0, time.UTC))
+
+	testCases := []struct {
+		name             string
+		status           batchv1.JobStatus
+		expectedDuration time.Duration
+	}{
+		{
+			name:             "Should return zero when Job has not started",
+			status:           batchv1.JobStatus{},
+			expectedDuration: 0,
+		},
+		{
+			name: "Should return duration of complete Job",
+			status: batchv1.JobStatus{
+				StartTime:      &startTime,
+				CompletionTime: &metav1.Time{Time: startTime.Add(90 * time.Second)},
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobComplete, LastTransitionTime: metav1.NewTime(startTime.Add(91 * time.Second))},
+				},
+			},
+			expectedDuration: 90 * time.Second,
+		},
+		{
+			name: "Should return duration of failed Job",
+			status: batchv1.JobStatus{
+				StartTime: &startTime,
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobFailed, LastTransitionTime: metav1.NewTime(startTime.Add(5 * time.Minute))},
+				},
+			},
+			expectedDuration: 5 * time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedDuration, job.GetScanJobDuration(&batchv1.Job{Status: tc.status}))
+		})
+	}
+}
+
+func TestJobController_Notify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}}
+
+	t.Run("Should notify when report has vulnerabilities at or above threshold", func(t *testing.T) {
+		notifier := &fakeNotifier{}
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:               "starboard-operator",
+			NotifySeverityThreshold: "CRITICAL",
+		}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+		jobController.Notifier = notifier
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+
+		assert.Equal(t, []notify.Payload{
+			{
+				Image:     "nginx:1.16",
+				Namespace: "default",
+				Workload:  notify.Workload{Kind: "Pod", Name: "nginx"},
+				Container: "nginx",
+				Summary:   notify.Summary{Critical: 1, High: 2},
+			},
+		}, notifier.payloads)
+	})
+
+	t.Run("Should not notify when report has no vulnerabilities at or above threshold", func(t *testing.T) {
+		notifier := &fakeNotifier{}
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:               "starboard-operator",
+			NotifySeverityThreshold: "CRITICAL",
+		}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+		jobController.Scanner = &fakeScanner{
+			result: starboardv1alpha1.VulnerabilityScanResult{
+				Summary: starboardv1alpha1.VulnerabilitySummary{HighCount: 2},
+			},
+		}
+		jobController.Notifier = notifier
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+
+		assert.Empty(t, notifier.payloads)
+	})
+
+	t.Run("Should not fail when notification cannot be sent", func(t *testing.T) {
+		notifier := &fakeNotifier{err: fmt.Errorf("posting payload: unexpected status code: 503")}
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:               "starboard-operator",
+			DeleteScanJobs:          true,
+			NotifySeverityThreshold: "HIGH",
+		}, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+		jobController.Notifier = notifier
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+
+		assert.Len(t, notifier.payloads, 1)
+		err = jobController.Client.Get(context.Background(), request.NamespacedName, &batchv1.Job{})
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
+
+func TestJobController_VulnerabilityPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}}
+	policyName := types.NamespacedName{Namespace: "starboard-operator", Name: "vuln-policy"}
+	policyConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: policyName.Namespace, Name: policyName.Name},
+		Data: map[string]string{
+			policy.ConfigMapKey: `package starboard.vulnerabilities
+
+ignore {
+	input.namespace.labels.environment == "dev"
+	input.vulnerability.severity == "LOW"
+}
+`,
+		},
+	}
+	newNamespace := func(environment string) *corev1.Namespace {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"environment": environment}},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		namespace       *corev1.Namespace
+		expectedIDs     []string
+		expectedSummary starboardv1alpha1.VulnerabilitySummary
+	}{
+		{
+			name:            "Should drop vulnerabilities ignored by policy",
+			namespace:       newNamespace("dev"),
+			expectedIDs:     []string{"CVE-2020-0001"},
+			expectedSummary: starboardv1alpha1.VulnerabilitySummary{HighCount: 1},
+		},
+		{
+			name:            "Should keep vulnerabilities not ignored by policy",
+			namespace:       newNamespace("prod"),
+			expectedIDs:     []string{"CVE-2020-0001", "CVE-2020-0002"},
+			expectedSummary: starboardv1alpha1.VulnerabilitySummary{HighCount: 1, LowCount: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"},
+				newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0), tc.namespace, policyConfigMap)
+			jobController.Scanner = &fakeScanner{
+				result: starboardv1alpha1.VulnerabilityScanResult{
+					Summary: starboardv1alpha1.VulnerabilitySummary{HighCount: 1, LowCount: 1},
+					Vulnerabilities: []starboardv1alpha1.Vulnerability{
+						{VulnerabilityID: "CVE-2020-0001", Severity: starboardv1alpha1.SeverityHigh},
+						{VulnerabilityID: "CVE-2020-0002", Severity: starboardv1alpha1.SeverityLow},
+					},
+				},
+			}
+			jobController.Policy = policy.NewLoader(jobController.Client, policyName)
+
+			_, err := jobController.Reconcile(request)
+			require.NoError(t, err)
+
+			report := &starboardv1alpha1.VulnerabilityReport{}
+			require.NoError(t, jobController.Client.Get(context.Background(),
+				types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, report))
+			var ids []string
+			for _, vulnerability := range report.Report.Vulnerabilities {
+				ids = append(ids, vulnerability.VulnerabilityID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+			assert.Equal(t, tc.expectedSummary, report.Report.Summary)
+		})
+	}
+
+	t.Run("Should return error when policy configmap does not exist", func(t *testing.T) {
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"},
+			newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+		jobController.Policy = policy.NewLoader(jobController.Client, policyName)
+
+		_, err := jobController.Reconcile(request)
+		require.Error(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Empty(t, reportList.Items)
+	})
+}
+
+func TestJobController_RetryFailedScanJob(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	failureTime := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	newFailedScanJob := func(retryCount string) *batchv1.Job {
+		scanJob := newScanJob(batchv1.JobFailed)
+		scanJob.UID = "b4f6a8e2"
+		scanJob.Status.Conditions[0].LastTransitionTime = metav1.NewTime(failureTime)
+		if retryCount != "" {
+			scanJob.Annotations[etc.AnnotationScanJobRetryCount] = retryCount
+		}
+		return scanJob
+	}
+
+	newFailedScanJobPod := func(message string) *corev1.Pod {
+		pod := newScanJobPod(1)
+		pod.Status.ContainerStatuses[0].State.Terminated.Message = message
+		return pod
+	}
+
+	config := etc.Operator{
+		Namespace:           "starboard-operator",
+		DeleteScanJobs:      true,
+		ScanJobRetryLimit:   2,
+		ScanJobRetryBackoff: 30 * time.Second,
+	}
+
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}}
+
+	listScanJobs := func(t *testing.T, jobController *job.JobController) []batchv1.Job {
+		t.Helper()
+		jobList := &batchv1.JobList{}
+		require.NoError(t, jobController.Client.List(context.Background(), jobList))
+		return jobList.Items
+	}
+
+	t.Run("Should requeue failed scan job until backoff elapses", func(t *testing.T) {
+		jobController := newJobController(t, server, config, newWorkload(), newFailedScanJob(""),
+			newFailedScanJobPod("dial tcp 10.0.0.1:443: i/o timeout"))
+		jobController.Clock = clock.NewFakeClock(failureTime.Add(10 * time.Second))
+
+		result, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: 20 * time.Second}, result)
+
+		scanJobs := listScanJobs(t, jobController)
+		require.Len(t, scanJobs, 1)
+		assert.Equal(t, "scan-job", scanJobs[0].Name)
+		assert.Len(t, jobController.Recorder.(*record.FakeRecorder).Events, 0)
+	})
+
+	t.Run("Should recreate failed scan job when backoff elapses", func(t *testing.T) {
+		credentials := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "registry-credentials",
+				Namespace: "starboard-operator",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "batch/v1", Kind: "Job", Name: "scan-job", UID: "b4f6a8e2"},
+				},
+			},
+		}
+		failedJob := newFailedScanJob("1")
+		failedJob.Spec.Template.Spec.Containers = []corev1.Container{
+			{
+				Name: "nginx",
+				Env: []corev1.EnvVar{
+					{
+						Name: "TRIVY_USERNAME",
+						ValueFrom: &corev1.EnvVarSource{
+							SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "registry-credentials"},
+								Key:                  "nginx.username",
+							},
+						},
+					},
+				},
+			},
+		}
+
+		jobController := newJobController(t, server, config, newWorkload(), failedJob, credentials,
+			newFailedScanJobPod("dial tcp 10.0.0.1:443: i/o timeout"))
+		// The second retry is delayed by twice the initial backoff.
+		jobController.Clock = clock.NewFakeClock(failureTime.Add(60 * time.Second))
+
+		result, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		scanJobs := listScanJobs(t, jobController)
+		require.Len(t, scanJobs, 1)
+		retryJob := scanJobs[0]
+		assert.NotEqual(t, "scan-job", retryJob.Name)
+		assert.Equal(t, "2", retryJob.Annotations[etc.AnnotationScanJobRetryCount])
+		assert.Equal(t, failedJob.Labels, retryJob.Labels)
+		assert.Nil(t, retryJob.Spec.Selector)
+
+		secret := &corev1.Secret{}
+		require.NoError(t, jobController.Client.Get(context.Background(),
+			types.NamespacedName{Namespace: "starboard-operator", Name: "registry-credentials"}, secret))
+		require.Len(t, secret.OwnerReferences, 2)
+		assert.Equal(t, retryJob.Name, secret.OwnerReferences[1].Name)
+	})
+
+	t.Run("Should recreate scan job which exceeded its deadline when its pod is deleted", func(t *testing.T) {
+		failedJob := newFailedScanJob("")
+		failedJob.Status.Conditions[0].Reason = "DeadlineExceeded"
+		jobController := newJobController(t, server, config, newWorkload(), failedJob)
+		jobController.Clock = clock.NewFakeClock(failureTime.Add(30 * time.Second))
+
+		result, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		scanJobs := listScanJobs(t, jobController)
+		require.Len(t, scanJobs, 1)
+		assert.NotEqual(t, "scan-job", scanJobs[0].Name)
+		assert.Equal(t, "1", scanJobs[0].Annotations[etc.AnnotationScanJobRetryCount])
+
+		workload := &corev1.Pod{}
+		require.NoError(t, jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		assert.Equal(t, "Timeout", workload.Annotations[etc.AnnotationScanError])
+
+		events := jobController.Recorder.(*record.FakeRecorder).Events
+		require.Len(t, events, 1)
+		assert.Equal(t, "Warning ScanFailed Failed to scan images nginx:1.16 with Fake: scan timed out", <-events)
+	})
+
+	t.Run("Should not recreate failed scan job when retry limit is reached", func(t *testing.T) {
+		jobController := newJobController(t, server, config, newWorkload(), newFailedScanJob("2"),
+			newFailedScanJobPod("dial tcp 10.0.0.1:443: i/o timeout"))
+		jobController.Clock = clock.NewFakeClock(failureTime.Add(time.Hour))
+
+		result, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		assert.Len(t, listScanJobs(t, jobController), 0)
+		assert.Len(t, jobController.Recorder.(*record.FakeRecorder).Events, 1)
+	})
+
+	t.Run("Should not recreate failed scan job when circuit breaker opens", func(t *testing.T) {
+		workload := newWorkload()
+		jobController := newJobController(t, server, config, workload, newFailedScanJob(""),
+			newFailedScanJobPod("dial tcp 10.0.0.1:443: i/o timeout"))
+		jobController.Clock = clock.NewFakeClock(failureTime.Add(time.Hour))
+		jobController.Config.FailureThreshold = 1
+		jobController.Config.FailureCooldown = time.Hour
+		jobController.CircuitBreaker = controller.NewCircuitBreaker(1, time.Hour)
+
+		result, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		assert.Len(t, listScanJobs(t, jobController), 0)
+		require.NoError(t, jobController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		assert.Equal(t, string(etc.ScanStatusSuspended), workload.Annotations[etc.AnnotationScanStatus])
+		events := jobController.Recorder.(*record.FakeRecorder).Events
+		require.Len(t, events, 2)
+		<-events
+		assert.Equal(t, "Warning ScanSuspended Suspended scans of images nginx:1.16 for 1h0m0s after 1 consecutive failures", <-events)
+		assert.Equal(t, time.Hour, jobController.CircuitBreaker.Allow(failureTime.Add(time.Hour), []string{"nginx:1.16"}))
+	})
+
+	t.Run("Should not recreate failed scan job when image is not found", func(t *testing.T) {
+		jobController := newJobController(t, server, config, newWorkload(), newFailedScanJob(""),
+			newFailedScanJobPod("MANIFEST_UNKNOWN: manifest unknown; map[Tag:1.16]"))
+		jobController.Clock = clock.NewFakeClock(failureTime.Add(time.Hour))
+
+		result, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		assert.Len(t, listScanJobs(t, jobController), 0)
+	})
+}
+
+func TestJobController_ScanStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}}
+
+	getScanStatus := func(t *testing.T, jobController *job.JobController) string {
+		t.Helper()
+		workload := &corev1.Pod{}
+		require.NoError(t, jobController.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		return workload.Annotations[etc.AnnotationScanStatus]
+	}
+
+	t.Run("Should transition from in progress to completed", func(t *testing.T) {
+		workload := newWorkload()
+		workload.Annotations = map[string]string{etc.AnnotationScanStatus: string(etc.ScanStatusPending)}
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Status.Conditions = nil
+		scanJob.Status.Active = 1
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, workload, scanJob, newScanJobPod(0))
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Equal(t, string(etc.ScanStatusInProgress), getScanStatus(t, jobController))
+
+		require.NoError(t, jobController.Client.Get(ctx, request.NamespacedName, scanJob))
+		scanJob.Status.Active = 0
+		scanJob.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		require.NoError(t, jobController.Client.Update(ctx, scanJob))
+
+		_, err = jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Equal(t, string(etc.ScanStatusCompleted), getScanStatus(t, jobController))
+	})
+
+	t.Run("Should not set scan status when scan job is not active yet", func(t *testing.T) {
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Status.Conditions = nil
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, newWorkload(), scanJob)
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Empty(t, getScanStatus(t, jobController))
+	})
+
+	t.Run("Should set failed scan status when scan job is not retried", func(t *testing.T) {
+		workload := newWorkload()
+		workload.Annotations = map[string]string{etc.AnnotationScanStatus: string(etc.ScanStatusInProgress)}
+		jobController := newJobController(t, server, etc.Operator{Namespace: "starboard-operator"}, workload, newScanJob(batchv1.JobFailed), newScanJobPod(1))
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Equal(t, string(etc.ScanStatusFailed), getScanStatus(t, jobController))
+	})
+
+	t.Run("Should set pending scan status when scan job is retried", func(t *testing.T) {
+		workload := newWorkload()
+		workload.Annotations = map[string]string{etc.AnnotationScanStatus: string(etc.ScanStatusInProgress)}
+		scanJobPod := newScanJobPod(1)
+		scanJobPod.Status.ContainerStatuses[0].State.Terminated.Message = "dial tcp 10.0.0.1:443: i/o timeout"
+		jobController := newJobController(t, server, etc.Operator{
+			Namespace:         "starboard-operator",
+			ScanJobRetryLimit: 1,
+		}, workload, newScanJob(batchv1.JobFailed), scanJobPod)
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+		assert.Equal(t, string(etc.ScanStatusPending), getScanStatus(t, jobController))
+	})
+}
+
+func TestJobController_ScanJobLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	config := etc.Operator{
+		Namespace:         "starboard-operator",
+		ScanJobLabelKey:   "example.com/scanner",
+		ScanJobLabelValue: "tenant-a",
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}}
+
+	t.Run("Should ignore scan job without configured label", func(t *testing.T) {
+		jobController := newJobController(t, server, config, newWorkload(), newScanJob(batchv1.JobComplete), newScanJobPod(0))
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Empty(t, reportList.Items)
+		require.NoError(t, jobController.Client.Get(context.Background(), request.NamespacedName, &batchv1.Job{}))
+	})
+
+	t.Run("Should process scan job with configured label", func(t *testing.T) {
+		scanJob := newScanJob(batchv1.JobComplete)
+		delete(scanJob.Labels, "app.kubernetes.io/managed-by")
+		scanJob.Labels["example.com/scanner"] = "tenant-a"
+		jobController := newJobController(t, server, config, newWorkload(), scanJob, newScanJobPod(0))
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Len(t, reportList.Items, 1)
+	})
+
+	t.Run("Should select scan jobs by configured label", func(t *testing.T) {
+		scanJob := newScanJob(batchv1.JobComplete)
+		assert.False(t, job.IsScanJob(config, scanJob))
+		assert.True(t, job.IsScanJob(etc.Operator{}, scanJob))
+		scanJob.Labels["example.com/scanner"] = "tenant-a"
+		assert.True(t, job.IsScanJob(config, scanJob))
+	})
+}
+
+func TestJobController_InstanceID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	config := etc.Operator{
+		Namespace:  "starboard-operator",
+		InstanceID: "tenant-a",
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-job"}}
+
+	t.Run("Should ignore scan job created by another instance", func(t *testing.T) {
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Labels[etc.LabelInstanceID] = "tenant-b"
+		jobController := newJobController(t, server, config, newWorkload(), scanJob, newScanJobPod(0))
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		assert.Empty(t, reportList.Items)
+		require.NoError(t, jobController.Client.Get(context.Background(), request.NamespacedName, &batchv1.Job{}))
+	})
+
+	t.Run("Should process scan job created by this instance and label reports", func(t *testing.T) {
+		scanJob := newScanJob(batchv1.JobComplete)
+		scanJob.Labels[etc.LabelInstanceID] = "tenant-a"
+		jobController := newJobController(t, server, config, newWorkload(), scanJob, newScanJobPod(0))
+
+		_, err := jobController.Reconcile(request)
+		require.NoError(t, err)
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, jobController.Client.List(context.Background(), reportList))
+		require.Len(t, reportList.Items, 1)
+		assert.Equal(t, "tenant-a", reportList.Items[0].Labels[etc.LabelInstanceID])
+	})
+
+	t.Run("Should select scan jobs by instance label", func(t *testing.T) {
+		scanJob := newScanJob(batchv1.JobComplete)
+		assert.False(t, job.IsScanJob(config, scanJob), "Unlabeled jobs belong to the instance without ID")
+		assert.True(t, job.IsScanJob(etc.Operator{}, scanJob))
+		scanJob.Labels[etc.LabelInstanceID] = "tenant-a"
+		assert.True(t, job.IsScanJob(config, scanJob))
+		assert.False(t, job.IsScanJob(etc.Operator{}, scanJob))
+	})
+}
+
+func TestIsScanJobFailureRetriable(t *testing.T) {
+	testCases := []struct {
+		name              string
+		conditionReason   string
+		message           string
+		expectedRetriable bool
+	}{
+		{
+			name:              "Should retry registry timeout",
+			message:           "Get https://index.docker.io/v2/: net/http: TLS handshake timeout",
+			expectedRetriable: true,
+		},
+		{
+			name:              "Should retry unresolvable registry host",
+			message:           "dial tcp: lookup core.harbor.domain: no such host",
+			expectedRetriable: true,
+		},
+		{
+			name:              "Should retry scan job which exceeded its deadline",
+			conditionReason:   "DeadlineExceeded",
+			expectedRetriable: true,
+		},
+		{
+			name:              "Should not retry image that does not exist",
+			message:           "unable to inspect the image (nginx:9.99): Error: No such image: nginx:9.99; NAME_UNKNOWN: repository does not exist",
+			expectedRetriable: false,
+		},
+		{
+			name:              "Should not retry unknown error",
+			message:           "unable to initialize a scanner: unsupported OS",
+			expectedRetriable: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scanJob := &batchv1.Job{
+				Status: batchv1.JobStatus{
+					Conditions: []batchv1.JobCondition{
+						{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: tc.conditionReason},
+					},
+				},
+			}
+			statuses := map[string]*corev1.ContainerStateTerminated{
+				"nginx": {ExitCode: 1, Message: tc.message},
+			}
+			assert.Equal(t, tc.expectedRetriable, job.IsScanJobFailureRetriable(scanJob, statuses))
+		})
+	}
+}
+
+func TestGetScanJobRetryBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, job.GetScanJobRetryBackoff(30*time.Second, 0))
+	assert.Equal(t, 60*time.Second, job.GetScanJobRetryBackoff(30*time.Second, 1))
+	assert.Equal(t, 120*time.Second, job.GetScanJobRetryBackoff(30*time.Second, 2))
+}
@@ -0,0 +1,173 @@
+package once
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	log = ctrl.Log.WithName("once")
+)
+
+// DefaultPollInterval is the interval of checking scan Jobs when neither the Runner's PollInterval
+// nor the operator's ReconcileRequeueInterval is set.
+const DefaultPollInterval = 5 * time.Second
+
+// Runner scans Pods in target namespaces once without the controllers manager. It drives the PodController
+// and the JobController by calling their Reconcile functions directly instead of watching resources.
+type Runner struct {
+	Config        etc.Operator
+	Client        client.Client
+	PodReconciler reconcile.Reconciler
+	JobReconciler reconcile.Reconciler
+	PollInterval  time.Duration
+}
+
+// Run reconciles all Pods in target namespaces, which creates scan Jobs, and then reconciles scan Jobs
+// until they're finished, which writes reports. Pods deferred by the PodController, e.g. when the limit
+// of concurrent scan Jobs is exceeded, are reconciled again in subsequent iterations as long as scan Jobs
+// are active. Pods which are requeued for later, e.g. until their reports become stale, are done, and Pods
+// which stay deferred while no scan Jobs are active, e.g. because their images cannot be pulled, are skipped.
+// Returns an error if any Pod or scan Job could not be reconciled.
+func (r *Runner) Run(ctx context.Context) error {
+	pending, err := r.ListPods(ctx)
+	if err != nil {
+		return err
+	}
+	log.Info("Scanning Pods once", "count", len(pending))
+
+	processed := make(map[types.UID]bool)
+	failures := 0
+	active := 0
+	for {
+		var deferred []types.NamespacedName
+		for _, pod := range pending {
+			result, err := r.PodReconciler.Reconcile(ctrl.Request{NamespacedName: pod})
+			if err != nil {
+				log.Error(err, "Unable to reconcile pod", "pod", pod)
+				failures++
+				continue
+			}
+			if r.isDeferred(result) {
+				deferred = append(deferred, pod)
+			}
+		}
+		// Deferred Pods can make progress only when scan Jobs finish, hence the ones which stay deferred while
+		// no scan Jobs were active before and after they were reconciled are skipped.
+		stuck := active == 0 && len(deferred) == len(pending)
+		pending = deferred
+
+		var jobFailures int
+		active, jobFailures, err = r.reconcileScanJobs(ctx, processed)
+		if err != nil {
+			return err
+		}
+		failures += jobFailures
+
+		if len(pending) > 0 && stuck && active == 0 {
+			log.Info("Skipping Pods which cannot be scanned", "pods", pending)
+			pending = nil
+		}
+		if len(pending) == 0 && active == 0 {
+			break
+		}
+		log.V(1).Info("Waiting for scan jobs", "active", active, "deferredPods", len(pending))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.getPollInterval()):
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("failed to reconcile %d pods or scan jobs", failures)
+	}
+	log.Info("Scanned Pods once")
+	return nil
+}
+
+// isDeferred returns true if the specified result of reconciling a Pod is the one of a deferred request,
+// e.g. when the limit of concurrent scan Jobs is exceeded or the scan Job of the Pod is pending. Requests
+// requeued after other intervals, e.g. until reports become stale, are not deferred.
+func (r *Runner) isDeferred(result ctrl.Result) bool {
+	return result == controller.NewDeferredResult(r.Config.ReconcileRequeueInterval)
+}
+
+// ListPods returns names of Pods matching the scan label selector in target namespaces, or in all namespaces
+// if target namespaces are not set. Pods which should not be scanned, e.g. in excluded namespaces, are filtered
+// out by the PodController.
+func (r *Runner) ListPods(ctx context.Context) ([]types.NamespacedName, error) {
+	selector, err := r.Config.GetScanLabelSelector()
+	if err != nil {
+		return nil, err
+	}
+	namespaces := r.Config.GetTargetNamespaces()
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+	var names []types.NamespacedName
+	for _, namespace := range namespaces {
+		podList := &corev1.PodList{}
+		err := r.Client.List(ctx, podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector})
+		if err != nil {
+			return nil, fmt.Errorf("listing pods: %w", err)
+		}
+		for _, pod := range podList.Items {
+			names = append(names, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name})
+		}
+	}
+	return names, nil
+}
+
+// reconcileScanJobs reconciles scan Jobs in the scan Jobs namespace which have not been processed yet.
+// A scan Job is processed once it's finished and the JobController does not requeue it, e.g. to retry it
+// after a backoff. Returns the number of scan Jobs which are still active or requeued, and the number of
+// scan Jobs which could not be reconciled.
+func (r *Runner) reconcileScanJobs(ctx context.Context, processed map[types.UID]bool) (int, int, error) {
+	jobList := &batchv1.JobList{}
+	err := r.Client.List(ctx, jobList, client.InNamespace(r.Config.GetScanJobsNamespace()),
+		client.MatchingLabels(r.Config.GetScanJobLabels()))
+	if err != nil {
+		return 0, 0, fmt.Errorf("listing scan jobs: %w", err)
+	}
+	active, failures := 0, 0
+	for _, job := range jobList.Items {
+		if _, ok := job.Labels[etc.LabelConfigAudit]; ok || processed[job.UID] {
+			continue
+		}
+		name := types.NamespacedName{Namespace: job.Namespace, Name: job.Name}
+		result, err := r.JobReconciler.Reconcile(ctrl.Request{NamespacedName: name})
+		if err != nil {
+			log.Error(err, "Unable to reconcile scan job", "job", name)
+			processed[job.UID] = true
+			failures++
+			continue
+		}
+		if len(job.Status.Conditions) == 0 || result.Requeue || result.RequeueAfter > 0 {
+			active++
+			continue
+		}
+		processed[job.UID] = true
+	}
+	return active, failures, nil
+}
+
+func (r *Runner) getPollInterval() time.Duration {
+	if r.PollInterval > 0 {
+		return r.PollInterval
+	}
+	if r.Config.ReconcileRequeueInterval > 0 {
+		return r.Config.ReconcileRequeueInterval
+	}
+	return DefaultPollInterval
+}
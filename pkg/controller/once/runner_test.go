@@ -0,0 +1,216 @@
+package once_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller/once"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakePodReconciler creates a scan Job for each reconciled Pod. Pods are deferred the specified number of
+// times before their scan Jobs are created, and Pods with the specified requeue intervals are requeued
+// without creating scan Jobs.
+type fakePodReconciler struct {
+	client    client.Client
+	deferrals map[string]int
+	requeues  map[string]time.Duration
+	requests  []string
+}
+
+func (r *fakePodReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	r.requests = append(r.requests, req.String())
+	if r.deferrals[req.String()] > 0 {
+		r.deferrals[req.String()]--
+		return ctrl.Result{Requeue: true}, nil
+	}
+	if interval, ok := r.requeues[req.String()]; ok {
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+	return ctrl.Result{}, r.client.Create(context.Background(), &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scan-" + req.Namespace + "-" + req.Name,
+			Namespace: "starboard-operator",
+			UID:       types.UID(req.String()),
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "starboard-operator",
+			},
+		},
+	})
+}
+
+// fakeJobReconciler completes scan Jobs on the first reconciliation and deletes them on the second one.
+// Scan Jobs with the specified names keep running.
+type fakeJobReconciler struct {
+	client   client.Client
+	running  map[string]bool
+	requests []string
+}
+
+func (r *fakeJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	r.requests = append(r.requests, req.String())
+	if r.running[req.String()] {
+		return ctrl.Result{Requeue: true}, nil
+	}
+	ctx := context.Background()
+	job := &batchv1.Job{}
+	err := r.client.Get(ctx, req.NamespacedName, job)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(job.Status.Conditions) == 0 {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		return ctrl.Result{}, r.client.Update(ctx, job)
+	}
+	return ctrl.Result{}, r.client.Delete(ctx, job)
+}
+
+func newPod(namespace, name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func newRunner(config etc.Operator, objects ...runtime.Object) (*once.Runner, *fakePodReconciler, *fakeJobReconciler) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+	fakeClient := fake.NewFakeClientWithScheme(scheme, objects...)
+	podReconciler := &fakePodReconciler{
+		client:    fakeClient,
+		deferrals: make(map[string]int),
+		requeues:  make(map[string]time.Duration),
+	}
+	jobReconciler := &fakeJobReconciler{client: fakeClient, running: make(map[string]bool)}
+	return &once.Runner{
+		Config:        config,
+		Client:        fakeClient,
+		PodReconciler: podReconciler,
+		JobReconciler: jobReconciler,
+		PollInterval:  time.Millisecond,
+	}, podReconciler, jobReconciler
+}
+
+func TestRunner_ListPods(t *testing.T) {
+	labeledPod := newPod("foo", "redis")
+	labeledPod.Labels = map[string]string{"scan": "true"}
+	objects := []runtime.Object{newPod("default", "nginx"), labeledPod, newPod("bar", "mysql")}
+
+	testCases := []struct {
+		name         string
+		config       etc.Operator
+		expectedPods []types.NamespacedName
+	}{
+		{
+			name:   "Should list pods in all namespaces when target namespaces are not set",
+			config: etc.Operator{Namespace: "starboard-operator"},
+			expectedPods: []types.NamespacedName{
+				{Namespace: "bar", Name: "mysql"},
+				{Namespace: "default", Name: "nginx"},
+				{Namespace: "foo", Name: "redis"},
+			},
+		},
+		{
+			name:   "Should list pods in target namespaces",
+			config: etc.Operator{Namespace: "starboard-operator", TargetNamespaces: "default,foo"},
+			expectedPods: []types.NamespacedName{
+				{Namespace: "default", Name: "nginx"},
+				{Namespace: "foo", Name: "redis"},
+			},
+		},
+		{
+			name:   "Should list pods matching scan label selector",
+			config: etc.Operator{Namespace: "starboard-operator", ScanLabelSelector: "scan=true"},
+			expectedPods: []types.NamespacedName{
+				{Namespace: "foo", Name: "redis"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runner, _, _ := newRunner(tc.config, objects...)
+			pods, err := runner.ListPods(context.Background())
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectedPods, pods)
+		})
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	t.Run("Should reconcile pods and wait until scan jobs are finished", func(t *testing.T) {
+		runner, podReconciler, jobReconciler := newRunner(etc.Operator{Namespace: "starboard-operator"},
+			newPod("default", "nginx"), newPod("foo", "redis"))
+
+		require.NoError(t, runner.Run(context.Background()))
+
+		assert.ElementsMatch(t, []string{"default/nginx", "foo/redis"}, podReconciler.requests)
+		assert.ElementsMatch(t, []string{
+			"starboard-operator/scan-default-nginx", "starboard-operator/scan-default-nginx",
+			"starboard-operator/scan-foo-redis", "starboard-operator/scan-foo-redis",
+		}, jobReconciler.requests)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, runner.Client.List(context.Background(), jobList))
+		assert.Empty(t, jobList.Items)
+	})
+
+	t.Run("Should reconcile deferred pods until scan jobs are created", func(t *testing.T) {
+		runner, podReconciler, _ := newRunner(etc.Operator{Namespace: "starboard-operator"},
+			newPod("default", "nginx"), newPod("foo", "redis"))
+		podReconciler.deferrals["foo/redis"] = 2
+
+		require.NoError(t, runner.Run(context.Background()))
+
+		assert.ElementsMatch(t, []string{"default/nginx", "foo/redis", "foo/redis", "foo/redis"}, podReconciler.requests)
+	})
+
+	t.Run("Should not reconcile pods again when they are requeued for later", func(t *testing.T) {
+		runner, podReconciler, _ := newRunner(etc.Operator{Namespace: "starboard-operator"},
+			newPod("default", "nginx"), newPod("foo", "redis"))
+		podReconciler.requeues["foo/redis"] = 24 * time.Hour
+
+		require.NoError(t, runner.Run(context.Background()))
+
+		assert.ElementsMatch(t, []string{"default/nginx", "foo/redis"}, podReconciler.requests)
+	})
+
+	t.Run("Should skip deferred pods when no scan jobs are active", func(t *testing.T) {
+		runner, podReconciler, _ := newRunner(etc.Operator{Namespace: "starboard-operator"},
+			newPod("default", "nginx"), newPod("foo", "redis"))
+		podReconciler.deferrals["foo/redis"] = 1000
+
+		require.NoError(t, runner.Run(context.Background()))
+
+		assert.ElementsMatch(t, []string{"default/nginx", "foo/redis", "foo/redis", "foo/redis"}, podReconciler.requests)
+	})
+
+	t.Run("Should stop waiting when context is cancelled", func(t *testing.T) {
+		runner, podReconciler, jobReconciler := newRunner(etc.Operator{Namespace: "starboard-operator"},
+			newPod("default", "nginx"))
+		podReconciler.deferrals["default/nginx"] = 1000
+		jobReconciler.running["starboard-operator/scan-running"] = true
+		require.NoError(t, runner.Client.Create(context.Background(), &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "scan-running",
+				Namespace: "starboard-operator",
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "starboard-operator",
+				},
+			},
+		}))
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		assert.Equal(t, context.DeadlineExceeded, runner.Run(ctx))
+	})
+}
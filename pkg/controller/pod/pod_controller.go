@@ -3,10 +3,20 @@ package pod
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/aquasecurity/starboard-operator/pkg/docker"
+	"github.com/aquasecurity/starboard-operator/pkg/metrics"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/uuid"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/aquasecurity/starboard-operator/pkg/resources"
 
@@ -15,12 +25,23 @@ import (
 	"github.com/aquasecurity/starboard-operator/pkg/scanner"
 	batchv1 "k8s.io/api/batch/v1"
 
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
 	"github.com/aquasecurity/starboard/pkg/kube"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var (
@@ -28,11 +49,33 @@ var (
 )
 
 type PodController struct {
-	Config  etc.Operator
-	Client  client.Client
-	Store   reports.StoreInterface
-	Scanner scanner.VulnerabilityScanner
-	Scheme  *runtime.Scheme
+	Config              etc.Operator
+	Client              client.Client
+	Writer              reports.Writer
+	DigestReader        reports.DigestReader
+	Scanner             scanner.VulnerabilityScanner
+	Scheme              *runtime.Scheme
+	Clock               clock.Clock
+	Recorder            record.EventRecorder
+	RegistryRateLimiter *RegistryRateLimiter
+	DigestResolver      docker.DigestResolver
+	// CircuitBreaker defers scans of images which failed to be scanned too many times in a row until the
+	// cooldown period elapses. Scans are never deferred if it's nil.
+	CircuitBreaker *controller.CircuitBreaker
+	// IncrementalWriter carries VulnerabilityReports of unchanged containers over to new Pod spec hashes.
+	// All containers are scanned again whenever the Pod spec changes if it's nil.
+	IncrementalWriter reports.IncrementalWriter
+	// Tracker records requeued and failed reconcile requests for the debug endpoint. Requests are not
+	// tracked if it's nil.
+	Tracker *controller.ReconcileTracker
+	// DigestCache holds digests of recently scanned images. Reports of workloads running images whose digests
+	// were all scanned recently are shared instead of scanning the images again. Nothing is shared if it's nil,
+	// unless reports are shared by digest regardless of the cache.
+	DigestCache *controller.DigestCache
+	// StartTime is when the operator was started. Reports of images whose digests are held in the DigestCache
+	// are awaited within OPERATOR_SCANNED_DIGEST_GRACE_PERIOD since then, e.g. until the cache of the client is
+	// synced. Afterwards such digests are evicted and the images are scanned again.
+	StartTime time.Time
 }
 
 // Reconcile resolves the actual state of the system against the desired state of the system.
@@ -47,7 +90,10 @@ type PodController struct {
 //
 // The Reconcile function returns two object which indicate whether or not Kubernetes
 // should requeue the request.
-func (r *PodController) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+func (r *PodController) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
+	defer func() {
+		r.Tracker.Record(r.Clock.Now(), "pod", req, result, err)
+	}()
 	ctx := context.Background()
 
 	pod := &corev1.Pod{}
@@ -64,6 +110,20 @@ func (r *PodController) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return ctrl.Result{}, nil
 	}
 
+	if r.Config.IsNamespaceExcluded(req.Namespace) {
+		log.V(1).Info("Ignoring Pod in excluded namespace")
+		return ctrl.Result{}, nil
+	}
+
+	selected, err := r.IsNamespaceSelected(ctx, req.Namespace)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("checking namespace selector: %w", err)
+	}
+	if !selected {
+		log.V(1).Info("Ignoring Pod in namespace not matching selector", "selector", r.Config.TargetNamespaceSelector)
+		return ctrl.Result{}, nil
+	}
+
 	// Retrieve the Pod from cache.
 	err = r.Client.Get(ctx, req.NamespacedName, pod)
 	if err != nil && errors.IsNotFound(err) {
@@ -74,86 +134,954 @@ func (r *PodController) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	}
 
 	// Check if the Pod is managed by the operator, i.e. is controlled by a scan Job created by the PodController.
-	if IsPodManagedByStarboardOperator(pod) {
+	if IsPodManagedByStarboardOperator(r.Config, pod) {
 		log.V(1).Info("Ignoring Pod managed by this operator")
 		return ctrl.Result{}, nil
 	}
 
+	// The scan label selector is checked here as well, because Pods are enqueued by watches of namespaces
+	// and image pull Secrets regardless of their labels.
+	scanLabelSelector, err := r.Config.GetScanLabelSelector()
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting scan label selector: %w", err)
+	}
+	if !scanLabelSelector.Matches(labels.Set(pod.Labels)) {
+		log.V(1).Info("Ignoring Pod not matching scan label selector", "selector", r.Config.ScanLabelSelector)
+		return ctrl.Result{}, nil
+	}
+
 	// Check if the Pod is being terminated.
 	if pod.DeletionTimestamp != nil {
 		log.V(1).Info("Ignoring Pod that is being terminated")
 		return ctrl.Result{}, nil
 	}
 
-	// Check if the Pod containers are ready.
-	if !resources.HasContainersReadyCondition(pod) {
-		log.V(1).Info("Ignoring Pod that is being scheduled")
+	owner, err := resources.GetOwnerWorkload(ctx, r.Client, pod)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("resolving pod owner: %w", err)
+	}
+	log.V(1).Info("Resolving Pod owner", "owner", owner)
+
+	skipScan, err := r.IsScanSkipped(ctx, pod, owner)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("checking skip scan annotation: %w", err)
+	}
+	if skipScan {
+		log.V(1).Info("Ignoring Pod annotated to skip scanning", "annotation", etc.AnnotationSkipScan)
 		return ctrl.Result{}, nil
 	}
 
-	owner := resources.GetImmediateOwnerReference(pod)
-	log.V(1).Info("Resolving immediate Pod owner", "owner", owner)
+	// Containers running images from registries which are not allowed to be scanned, or excluded images, are
+	// left out of the Pod spec, so that they're neither scanned nor expected to have VulnerabilityReports.
+	spec, excludedImages := FilterContainersByRegistry(r.Config, pod.Spec)
+	if len(excludedImages) > 0 {
+		if len(scanner.GetContainersToScan(spec)) == 0 {
+			log.V(1).Info("Ignoring Pod with images not allowed to be scanned", "images", excludedImages)
+			return ctrl.Result{}, nil
+		}
+		log.V(1).Info("Skipping images not allowed to be scanned", "images", excludedImages)
+		pod.Spec = spec
+	}
+
+	// Reports are attached to the Pod itself in the pod report scope, hence each Pod is scanned.
+	if r.Config.ReportScope == etc.ReportScopePod {
+		owner = kube.Object{Kind: kube.KindPod, Name: pod.Name, Namespace: pod.Namespace}
+	}
+
+	// Images which the kubelet fails to pull would most likely fail to be pulled by the scanner as well,
+	// hence scan Jobs are not created until the workload itself is fixed.
+	if images := resources.GetImagesFailingToPull(pod); len(images) > 0 {
+		log.V(1).Info("Requeueing Pod whose images cannot be pulled", "images", images)
+		if !r.Config.DryRun {
+			r.setScanStatus(ctx, owner, etc.ScanStatusImagePullBackOff)
+			r.Recorder.Eventf(pod, corev1.EventTypeWarning, controller.EventReasonImagePullBackOff,
+				"Skipped scanning images %s which cannot be pulled", strings.Join(images, ", "))
+		}
+		return controller.NewDeferredResult(r.Config.ReconcileRequeueInterval), nil
+	}
+
+	if r.Config.ScanOnlyRunning && !resources.HasRunningContainers(pod) {
+		log.V(1).Info("Requeueing Pod whose containers are not running")
+		return controller.NewDeferredResult(r.Config.ReconcileRequeueInterval), nil
+	}
+
+	// Check if the Pod containers are ready. Pods whose images are pinned by digest, or whose image digests
+	// can be resolved from registries, do not have to wait for the kubelet to pull images.
+	if !resources.HasContainersReadyCondition(pod) && r.DigestResolver == nil && !resources.HasOnlyPinnedImages(pod.Spec) {
+		log.V(1).Info("Ignoring Pod that is being scheduled")
+		return ctrl.Result{}, nil
+	}
 
-	hash := controller.ComputeHash(pod.Spec)
+	// Ephemeral containers, e.g. added by `kubectl debug`, are left out of the hash, so that adding them does
+	// not invalidate VulnerabilityReports of other containers.
+	hash := controller.ComputeHash(resources.GetPodSpecWithoutEphemeralContainers(pod.Spec))
 
 	// Check if containers of the Pod have corresponding VulnerabilityReports.
-	hasVulnerabilityReports, err := r.Store.HasVulnerabilityReports(ctx, owner, hash, resources.GetContainerImagesFromPodSpec(pod.Spec))
+	hasVulnerabilityReports, err := r.Writer.HasReport(ctx, owner, hash, resources.GetContainerImagesFromPodSpec(pod.Spec))
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("getting vulnerability reports: %w", err)
 	}
 
-	if hasVulnerabilityReports {
-		log.V(1).Info("Ignoring Pod that already has VulnerabilityReports")
-		return ctrl.Result{}, nil
+	rescanNonce := GetRescanNonce(pod)
+
+	// Containers added or changed since the workload was last scanned are scanned incrementally, i.e. images of
+	// unchanged containers are not scanned again, and reports of removed containers are deleted.
+	incremental := false
+	if !hasVulnerabilityReports && rescanNonce == "" && r.IncrementalWriter != nil {
+		spec, err := r.GetContainersToScanIncrementally(ctx, owner, hash, pod)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating vulnerability reports incrementally: %w", err)
+		}
+		if spec != nil {
+			containers := scanner.GetContainersToScan(*spec)
+			if len(containers) == 0 {
+				log.V(1).Info("Ignoring Pod whose containers did not change since the last scan")
+				if !r.Config.DryRun {
+					r.setScanStatus(ctx, owner, etc.ScanStatusCompleted)
+				}
+				return ctrl.Result{}, nil
+			}
+			var names []string
+			for _, container := range containers {
+				names = append(names, container.Name)
+			}
+			log.V(1).Info("Scanning containers added or changed since the last scan", "containers", names)
+			pod.Spec = *spec
+			incremental = true
+		}
+	}
+
+	// Ephemeral containers added after other containers were scanned are scanned incrementally, i.e. only images
+	// of ephemeral containers without VulnerabilityReports are scanned.
+	if !hasVulnerabilityReports && rescanNonce == "" && !incremental && len(pod.Spec.EphemeralContainers) > 0 {
+		spec, err := r.GetEphemeralContainersToScan(ctx, owner, hash, pod.Spec)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("getting ephemeral containers to scan: %w", err)
+		}
+		if spec != nil {
+			log.V(1).Info("Scanning ephemeral containers added to Pod",
+				"containers", resources.GetEphemeralContainerNamesFromPodSpec(*spec))
+			pod.Spec = *spec
+		}
+	}
+
+	if hasVulnerabilityReports && rescanNonce != "" {
+		log.V(1).Info("Rescanning Pod on demand", "annotation", etc.AnnotationRescan, "nonce", rescanNonce)
+	} else if hasVulnerabilityReports {
+		if r.Config.ScanReportTTL <= 0 {
+			log.V(1).Info("Ignoring Pod that already has VulnerabilityReports")
+			return ctrl.Result{}, nil
+		}
+		updateTime, err := r.Writer.GetUpdateTime(ctx, owner, hash)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("getting vulnerability reports update time: %w", err)
+		}
+		if age := r.Clock.Since(updateTime); age < r.Config.ScanReportTTL {
+			requeueAfter := controller.AddJitter(r.Config.ScanReportTTL-age, r.Config.RescanJitter)
+			log.V(1).Info("Ignoring Pod that already has VulnerabilityReports", "requeueAfter", requeueAfter)
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		log.V(1).Info("Rescanning Pod with expired VulnerabilityReports", "ttl", r.Config.ScanReportTTL)
+	}
+
+	if (r.Config.ShareReportsByDigest || r.DigestCache != nil) && rescanNonce == "" {
+		imageIDs, err := r.GetContainerImageIDs(ctx, pod)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("getting container image ids: %w", err)
+		}
+		scannedRecently := r.IsScannedRecently(pod, imageIDs)
+		if r.Config.ShareReportsByDigest || scannedRecently {
+			result, shared, err := r.shareReportsByDigest(ctx, owner, hash, pod, imageIDs)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("sharing vulnerability reports by digest: %w", err)
+			}
+			if shared {
+				return result, nil
+			}
+		}
+		// Reports of images scanned recently might not be found yet, e.g. until the cache of the client is
+		// synced after a restart, hence the Pod is requeued instead of scanning the same images again. Once
+		// the grace period elapses the reports are considered gone, e.g. deleted, and the images are scanned.
+		if scannedRecently {
+			if r.Clock.Since(r.StartTime) < r.Config.ScannedDigestGracePeriod {
+				log.V(1).Info("Requeueing Pod whose images were scanned recently until their VulnerabilityReports are found")
+				return controller.NewDeferredResult(r.Config.ReconcileRequeueInterval), nil
+			}
+			log.V(1).Info("Evicting scanned digests of images whose VulnerabilityReports are not found")
+			if err := r.DigestCache.Remove(GetImageDigests(pod, imageIDs)...); err != nil {
+				log.Error(err, "Unable to save scanned digests")
+			}
+		}
 	}
 
 	// Create a scan Job to create VulnerabilityReports for the Pod containers images.
-	err = r.ensureScanJob(ctx, owner, hash, pod)
+	result, err = r.ensureScanJob(ctx, owner, hash, pod, rescanNonce)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("ensuring scan job: %w", err)
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
-func (r *PodController) ensureScanJob(ctx context.Context, owner kube.Object, hash string, pod *corev1.Pod) error {
+// ensureScanJob creates a scan Job for the specified Pod unless there's a pending one, i.e. one which has not
+// been processed by the JobController yet. The non-blank rescanNonce is recorded on the scan Job and, once the
+// Job is created, on the Pod as processed.
+func (r *PodController) ensureScanJob(ctx context.Context, owner kube.Object, hash string, pod *corev1.Pod, rescanNonce string) (ctrl.Result, error) {
 	log := log.WithValues("pod", fmt.Sprintf("%s/%s", pod.Namespace, pod.Name), "hash", hash)
 
 	log.V(1).Info("Ensuring scan Job")
 
-	jobList := &batchv1.JobList{}
-	err := r.Client.List(ctx, jobList, client.MatchingLabels{
+	// Config audit Jobs share the workload labels, so they're excluded explicitly.
+	notConfigAudit, err := labels.NewRequirement(etc.LabelConfigAudit, selection.DoesNotExist, nil)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	jobLabels := labels.Set{
 		kube.LabelResourceNamespace: pod.Namespace,
 		kube.LabelResourceKind:      string(owner.Kind),
 		kube.LabelResourceName:      owner.Name,
 		etc.LabelPodSpecHash:        hash,
-	}, client.InNamespace(r.Config.Namespace))
+	}
+	if r.Config.InstanceID != "" {
+		jobLabels[etc.LabelInstanceID] = r.Config.InstanceID
+	}
+	selector := labels.SelectorFromSet(jobLabels).Add(*notConfigAudit)
+
+	jobList := &batchv1.JobList{}
+	err = r.Client.List(ctx, jobList, client.MatchingLabelsSelector{Selector: selector},
+		client.InNamespace(r.Config.GetScanJobsNamespace()))
 	if err != nil {
-		return fmt.Errorf("listing jos: %w", err)
+		return ctrl.Result{}, fmt.Errorf("listing jos: %w", err)
 	}
 
-	if len(jobList.Items) > 0 {
-		log.V(1).Info("Scan job already exists",
-			"job", fmt.Sprintf("%s/%s", jobList.Items[0].Namespace, jobList.Items[0].Name))
-		return nil
+	for _, job := range jobList.Items {
+		// Scan Jobs left in place after they have been processed do not block new scans.
+		if _, ok := job.Annotations[etc.AnnotationScanJobProcessed]; ok {
+			continue
+		}
+		log.V(1).Info("Scan job already exists", "job", fmt.Sprintf("%s/%s", job.Namespace, job.Name))
+		// The scan is still running. Check back after the requeue interval, if configured.
+		return ctrl.Result{RequeueAfter: r.Config.ReconcileRequeueInterval}, nil
+	}
+
+	// Wait for the kubelet to report image IDs, so that reports can be attributed to immutable digests.
+	imageIDs, err := r.GetContainerImageIDs(ctx, pod)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting container image ids: %w", err)
+	}
+	for _, container := range append(append([]corev1.Container{}, pod.Spec.Containers...), resources.GetEphemeralContainersFromPodSpec(pod.Spec)...) {
+		if _, ok := imageIDs[container.Name]; !ok {
+			log.V(1).Info("Requeueing Pod as image ID is not reported yet", "container", container.Name)
+			return controller.NewDeferredResult(r.Config.ReconcileRequeueInterval), nil
+		}
+	}
+
+	limitExceeded, err := r.IsConcurrentScanJobsLimitExceeded(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if limitExceeded {
+		log.V(1).Info("Requeueing Pod as concurrent scan jobs limit is exceeded",
+			"limit", r.Config.ConcurrentScanJobsLimit)
+		return controller.NewDeferredResult(r.Config.ReconcileRequeueInterval), nil
 	}
 
 	jobMeta, err := r.GetJobMetaFrom(owner, hash, pod.Spec)
 	if err != nil {
-		return err
+		return ctrl.Result{}, err
+	}
+
+	digests := kube.ContainerImages{}
+	for container, imageID := range imageIDs {
+		// Images without repository digests, e.g. built locally, are reported by their spec image refs only.
+		if digest := resources.GetDigestFromImageID(imageID); digest != "" {
+			digests[container] = digest
+		}
+	}
+	if len(digests) > 0 {
+		digestsAsJSON, err := digests.AsJSON()
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		jobMeta.Annotations[etc.AnnotationContainerImageDigests] = digestsAsJSON
+	}
+
+	if rescanNonce != "" {
+		jobMeta.Annotations[etc.AnnotationRescan] = rescanNonce
+	}
+
+	for key, value := range r.Config.GetScanJobPodAnnotations() {
+		jobMeta.Annotations[key] = value
+	}
+
+	propagatedLabels, err := r.GetPropagatedLabels(ctx, pod, owner)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting propagated labels: %w", err)
+	}
+	for key, value := range propagatedLabels {
+		// Never override labels used by the operator to track scan Jobs.
+		if _, exists := jobMeta.Labels[key]; !exists {
+			jobMeta.Labels[key] = value
+		}
+	}
+
+	scanJobResources, err := r.Config.GetScanJobResourceRequirements()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	imagePullPolicy, err := r.Config.GetScanJobImagePullPolicy()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	nodeSelector, err := r.Config.GetScanJobNodeSelector()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	tolerations, err := r.Config.GetScanJobTolerations()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	affinity, err := r.Config.GetScanJobAffinity()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	hostAliases, err := r.Config.GetScanJobHostAliases()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	dnsConfig, err := r.Config.GetScanJobDNSConfig()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	envFrom, err := r.Config.GetScanJobEnvFrom()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	volumes, err := r.Config.GetScanJobVolumes()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	volumeMounts, err := r.Config.GetScanJobVolumeMounts()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	podSecurityContext, securityContext, err := r.Config.GetScanJobSecurityContext()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	severities, err := reports.ParseSeverities(r.Config.ReportSeverities)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	registryCredentials, err := r.GetRegistryCredentials(ctx, pod)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting registry credentials: %w", err)
+	}
+
+	ignoreFileConfigMap, err := r.GetIgnoreFileConfigMap(ctx, pod.Namespace)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("getting ignore file configmap: %w", err)
+	}
+
+	options := scanner.Options{
+		Namespace:                      r.Config.GetScanJobsNamespace(),
+		ServiceAccountName:             r.Config.GetScanJobServiceAccount(),
+		PriorityClassName:              r.Config.ScanJobPriorityClassName,
+		ScanJobTimeout:                 r.Config.ScanJobTimeout,
+		ScanJobBackoffLimit:            r.Config.ScanJobBackoffLimit,
+		ScanJobTTLSecondsAfterFinished: r.Config.GetScanJobTTLSecondsAfterFinished(),
+		ScanJobResources:               scanJobResources,
+		RegistryCredentials:            registryCredentials,
+		ScanJobNodeSelector:            nodeSelector,
+		ScanJobTolerations:             tolerations,
+		ScanJobAffinity:                affinity,
+		ScanJobHostAliases:             hostAliases,
+		ScanJobDNSConfig:               dnsConfig,
+		Severities:                     severities,
+		ScanJobHTTPProxy:               r.Config.ScanJobHTTPProxy,
+		ScanJobHTTPSProxy:              r.Config.ScanJobHTTPSProxy,
+		ScanJobNoProxy:                 r.Config.ScanJobNoProxy,
+		ScanJobEnvFrom:                 envFrom,
+		ScanJobVolumes:                 volumes,
+		ScanJobVolumeMounts:            volumeMounts,
+		ScanJobImagePullPolicy:         imagePullPolicy,
+		ScanJobCACertConfigMap:         r.Config.ScanJobCACertConfigMap,
+		IgnoreFileConfigMap:            ignoreFileConfigMap,
+		ScanJobPodSecurityContext:      podSecurityContext,
+		ScanJobSecurityContext:         securityContext,
 	}
 
-	scanJob, err := r.Scanner.NewScanJob(jobMeta, scanner.Options{
-		Namespace:          r.Config.Namespace,
-		ServiceAccountName: r.Config.ServiceAccount,
-		ScanJobTimeout:     r.Config.ScanJobTimeout,
-	}, pod.Spec)
+	var credentialsSecret *corev1.Secret
+	if len(registryCredentials) > 0 {
+		credentialsSecret = NewRegistryCredentialsSecret(r.Config.GetScanJobsNamespace(), jobMeta, registryCredentials)
+		options.RegistryCredentialsSecret = credentialsSecret.Name
+	}
+
+	scanJob, err := r.Scanner.NewScanJob(jobMeta, options, pod.Spec)
 	if err != nil {
-		return fmt.Errorf("constructing scan job: %w", err)
+		return ctrl.Result{}, fmt.Errorf("constructing scan job: %w", err)
+	}
+
+	if r.Config.DryRun {
+		log.Info("Dry run: skipping creation of scan job",
+			"owner", owner, "images", GetUniqueImages(pod.Spec), "scanner", r.Scanner.GetName())
+		metrics.RecordDryRunScan(r.Scanner.GetName())
+		return ctrl.Result{}, nil
+	}
+
+	if delay := r.CircuitBreaker.Allow(r.Clock.Now(), GetUniqueImages(pod.Spec)); delay > 0 {
+		log.V(1).Info("Requeueing Pod as scans of its images are suspended", "requeueAfter", delay)
+		r.setScanStatus(ctx, owner, etc.ScanStatusSuspended)
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	var hosts []string
+	if r.RegistryRateLimiter != nil {
+		hosts = GetRegistryHosts(pod.Spec)
+		if delay := r.RegistryRateLimiter.Reserve(r.Clock.Now(), hosts); delay > 0 {
+			log.V(1).Info("Requeueing Pod as registry rate limit is exceeded", "registries", hosts, "requeueAfter", delay)
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
 	}
+
+	if credentialsSecret != nil {
+		log.V(1).Info("Creating registry credentials secret",
+			"secret", fmt.Sprintf("%s/%s", credentialsSecret.Namespace, credentialsSecret.Name))
+		err = r.Client.Create(ctx, credentialsSecret)
+		if err != nil {
+			r.releaseRegistryRateLimit(hosts)
+			return ctrl.Result{}, fmt.Errorf("creating registry credentials secret: %w", err)
+		}
+	}
+
 	log.V(1).Info("Creating scan job",
 		"job", fmt.Sprintf("%s/%s", scanJob.Namespace, scanJob.Name))
-	return r.Client.Create(ctx, scanJob)
+	err = r.Client.Create(ctx, scanJob)
+	if err != nil {
+		if credentialsSecret != nil {
+			_ = r.Client.Delete(ctx, credentialsSecret)
+		}
+		r.releaseRegistryRateLimit(hosts)
+		return ctrl.Result{}, err
+	}
+
+	if credentialsSecret != nil {
+		// Make the scan Job own the Secret so that the Secret is garbage collected along with the Job.
+		err = controllerutil.SetOwnerReference(scanJob, credentialsSecret, r.Scheme)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		err = r.Client.Update(ctx, credentialsSecret)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating registry credentials secret: %w", err)
+		}
+	}
+
+	if rescanNonce != "" {
+		err = r.setRescanProcessed(ctx, pod, rescanNonce)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("setting rescan processed annotation: %w", err)
+		}
+	}
+
+	r.setScanStatus(ctx, owner, etc.ScanStatusPending)
+	r.Recorder.Eventf(pod, corev1.EventTypeNormal, controller.EventReasonScanJobCreated,
+		"Created scan job %s/%s to scan images %s with %s", scanJob.Namespace, scanJob.Name,
+		strings.Join(GetUniqueImages(pod.Spec), ", "), r.Scanner.GetName())
+	return ctrl.Result{}, nil
+}
+
+// releaseRegistryRateLimit puts back tokens reserved for the specified registry hosts when the scan Job
+// has not been created, so that failed attempts do not count against the rate limit.
+func (r *PodController) releaseRegistryRateLimit(hosts []string) {
+	if r.RegistryRateLimiter != nil {
+		r.RegistryRateLimiter.Release(hosts)
+	}
+}
+
+// shareReportsByDigest writes VulnerabilityReports of the specified workload copied from reports of other
+// workloads, possibly in other namespaces, which run images with the same digests as the given Pod. If some
+// reports are missing, but pending scan Jobs already scan images with the missing digests, the request is
+// deferred until the reports are written. Returns false if there's nothing to share, in which case a scan
+// Job should be created. Reports are not written in the dry-run mode.
+func (r *PodController) shareReportsByDigest(ctx context.Context, owner kube.Object, hash string, pod *corev1.Pod, imageIDs map[string]string) (ctrl.Result, bool, error) {
+	log := log.WithValues("pod", fmt.Sprintf("%s/%s", pod.Namespace, pod.Name), "hash", hash)
+
+	digests := kube.ContainerImages{}
+	for container := range resources.GetContainerImagesFromPodSpec(pod.Spec) {
+		digest := resources.GetDigestFromImageID(imageIDs[container])
+		if digest == "" {
+			// Images without digests, e.g. built locally, cannot be matched with reports of other workloads.
+			return ctrl.Result{}, false, nil
+		}
+		digests[container] = digest
+	}
+
+	vulnerabilities := make(map[string]starboardv1alpha1.VulnerabilityScanResult)
+	var missingDigests []string
+	for container, digest := range digests {
+		report, err := r.DigestReader.FindByDigest(ctx, digest)
+		if err != nil {
+			return ctrl.Result{}, false, err
+		}
+		if report == nil || (r.Config.ScanReportTTL > 0 && r.Clock.Since(report.UpdatedAt) >= r.Config.ScanReportTTL) {
+			missingDigests = append(missingDigests, digest)
+			continue
+		}
+		vulnerabilities[container] = report.Report
+	}
+
+	if len(missingDigests) > 0 {
+		pending, err := r.HasPendingScanJobsForDigests(ctx, missingDigests)
+		if err != nil {
+			return ctrl.Result{}, false, err
+		}
+		if !pending {
+			return ctrl.Result{}, false, nil
+		}
+		log.V(1).Info("Requeueing Pod as images with the same digests are being scanned", "digests", missingDigests)
+		return controller.NewDeferredResult(r.Config.ReconcileRequeueInterval), true, nil
+	}
+
+	if r.Config.DryRun {
+		log.Info("Dry run: skipping sharing of VulnerabilityReports of images with the same digests", "owner", owner)
+		return ctrl.Result{}, true, nil
+	}
+
+	log.V(1).Info("Sharing VulnerabilityReports of images with the same digests", "owner", owner)
+	err := r.Writer.Write(ctx, owner, reports.WorkloadReport{
+		Hash:                hash,
+		Vulnerabilities:     vulnerabilities,
+		InitContainers:      resources.GetInitContainerNamesFromPodSpec(pod.Spec),
+		EphemeralContainers: resources.GetEphemeralContainerNamesFromPodSpec(pod.Spec),
+		Digests:             digests,
+	})
+	if err != nil {
+		return ctrl.Result{}, false, err
+	}
+	r.setScanStatus(ctx, owner, etc.ScanStatusCompleted)
+	return ctrl.Result{}, true, nil
+}
+
+// IsScannedRecently checks whether images of all containers of the specified Pod, whose IDs are given, have digests
+// which are held in the DigestCache, and which were scanned within OPERATOR_SCAN_REPORT_TTL if it's set. Returns
+// false if the cache is nil or any image digest is unknown.
+func (r *PodController) IsScannedRecently(pod *corev1.Pod, imageIDs map[string]string) bool {
+	if r.DigestCache == nil {
+		return false
+	}
+	for container := range resources.GetContainerImagesFromPodSpec(pod.Spec) {
+		digest := resources.GetDigestFromImageID(imageIDs[container])
+		if digest == "" {
+			return false
+		}
+		scannedAt, ok := r.DigestCache.Get(digest)
+		if !ok || (r.Config.ScanReportTTL > 0 && r.Clock.Since(scannedAt) >= r.Config.ScanReportTTL) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetImageDigests returns digests of images of all containers of the specified Pod, whose IDs are given.
+// Images without digests are ignored.
+func GetImageDigests(pod *corev1.Pod, imageIDs map[string]string) []string {
+	var digests []string
+	for container := range resources.GetContainerImagesFromPodSpec(pod.Spec) {
+		if digest := resources.GetDigestFromImageID(imageIDs[container]); digest != "" {
+			digests = append(digests, digest)
+		}
+	}
+	return digests
+}
+
+// GetEphemeralContainersToScan returns a copy of the specified PodSpec with ephemeral containers whose
+// VulnerabilityReports are missing only, provided that all other containers have VulnerabilityReports
+// for the given hash. Returns nil if other containers must be scanned as well.
+func (r *PodController) GetEphemeralContainersToScan(ctx context.Context, owner kube.Object, hash string, spec corev1.PodSpec) (*corev1.PodSpec, error) {
+	hasVulnerabilityReports, err := r.Writer.HasReport(ctx, owner, hash,
+		resources.GetContainerImagesFromPodSpec(resources.GetPodSpecWithoutEphemeralContainers(spec)))
+	if err != nil || !hasVulnerabilityReports {
+		return nil, err
+	}
+	var missing []corev1.EphemeralContainer
+	for _, container := range spec.EphemeralContainers {
+		hasVulnerabilityReport, err := r.Writer.HasReport(ctx, owner, hash, kube.ContainerImages{container.Name: container.Image})
+		if err != nil {
+			return nil, err
+		}
+		if !hasVulnerabilityReport {
+			missing = append(missing, container)
+		}
+	}
+	scanned := spec.DeepCopy()
+	scanned.InitContainers = nil
+	scanned.Containers = nil
+	scanned.EphemeralContainers = missing
+	return scanned, nil
+}
+
+// GetContainersToScanIncrementally compares containers of the specified Pod with current VulnerabilityReports
+// of the owner, and returns a copy of the Pod spec with containers which must be scanned for the given hash only.
+// Reports of containers whose images have the same digests as the scanned ones are carried over to the hash,
+// and reports of containers removed from the Pod spec are deleted, unless the operator runs in the dry-run mode.
+// Returns nil if there are no reports to carry over or delete, and none of the reports was written for the hash,
+// in which case all containers must be scanned.
+func (r *PodController) GetContainersToScanIncrementally(ctx context.Context, owner kube.Object, hash string, pod *corev1.Pod) (*corev1.PodSpec, error) {
+	containerReports, err := r.IncrementalWriter.GetContainerReports(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if len(containerReports) == 0 {
+		return nil, nil
+	}
+
+	images := resources.GetContainerImagesFromPodSpec(pod.Spec)
+	var removed []string
+	for container, report := range containerReports {
+		if _, ok := images[container]; !ok && report.Hash != hash {
+			removed = append(removed, container)
+		}
+	}
+
+	var imageIDs map[string]string
+	scanned := make(map[string]bool)
+	var unchanged []string
+	for container := range images {
+		report, ok := containerReports[container]
+		if !ok {
+			continue
+		}
+		if report.Hash == hash {
+			scanned[container] = true
+			continue
+		}
+		if report.Digest == "" {
+			continue
+		}
+		if imageIDs == nil {
+			imageIDs, err = r.GetContainerImageIDs(ctx, pod)
+			if err != nil {
+				return nil, fmt.Errorf("getting container image ids: %w", err)
+			}
+		}
+		if resources.GetDigestFromImageID(imageIDs[container]) == report.Digest {
+			scanned[container] = true
+			unchanged = append(unchanged, container)
+		}
+	}
+	if len(scanned) == 0 && len(removed) == 0 {
+		return nil, nil
+	}
+	sort.Strings(unchanged)
+	sort.Strings(removed)
+
+	log := log.WithValues("pod", fmt.Sprintf("%s/%s", pod.Namespace, pod.Name), "hash", hash)
+	if len(unchanged) > 0 {
+		log.V(1).Info("Carrying over VulnerabilityReports of unchanged containers", "containers", unchanged)
+		if !r.Config.DryRun {
+			err = r.IncrementalWriter.UpdateContainerReportsHash(ctx, owner, hash, unchanged)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(removed) > 0 {
+		log.V(1).Info("Deleting VulnerabilityReports of removed containers", "containers", removed)
+		if !r.Config.DryRun {
+			err = r.IncrementalWriter.DeleteContainerReports(ctx, owner, removed)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	spec := pod.Spec.DeepCopy()
+	var initContainers, containers []corev1.Container
+	for _, container := range spec.InitContainers {
+		if !scanned[container.Name] {
+			initContainers = append(initContainers, container)
+		}
+	}
+	for _, container := range spec.Containers {
+		if !scanned[container.Name] {
+			containers = append(containers, container)
+		}
+	}
+	var ephemeralContainers []corev1.EphemeralContainer
+	for _, container := range spec.EphemeralContainers {
+		if !scanned[container.Name] {
+			ephemeralContainers = append(ephemeralContainers, container)
+		}
+	}
+	spec.InitContainers = initContainers
+	spec.Containers = containers
+	spec.EphemeralContainers = ephemeralContainers
+	return spec, nil
+}
+
+// HasPendingScanJobsForDigests checks whether unfinished scan Jobs scan images with all the specified digests.
+func (r *PodController) HasPendingScanJobsForDigests(ctx context.Context, digests []string) (bool, error) {
+	jobList := &batchv1.JobList{}
+	err := r.Client.List(ctx, jobList, client.MatchingLabels(r.Config.GetScanJobLabels()),
+		client.InNamespace(r.Config.GetScanJobsNamespace()))
+	if err != nil {
+		return false, fmt.Errorf("listing scan jobs: %w", err)
+	}
+
+	pending := make(map[string]bool)
+	for _, job := range jobList.Items {
+		if IsJobFinished(job) {
+			continue
+		}
+		jobDigests, err := resources.GetContainerImageDigestsFromJob(&job)
+		if err != nil {
+			return false, err
+		}
+		for _, digest := range jobDigests {
+			pending[digest] = true
+		}
+	}
+	for _, digest := range digests {
+		if !pending[digest] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// GetUniqueImages returns images of init containers, containers, and ephemeral containers of the specified PodSpec
+// in order of appearance without duplicates.
+func GetUniqueImages(spec corev1.PodSpec) []string {
+	var images []string
+	for _, c := range scanner.GetContainersToScan(spec) {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+// FilterContainersByRegistry returns a copy of the specified PodSpec without init containers, containers, and
+// ephemeral containers whose images are not allowed to be scanned by OPERATOR_SCAN_ALLOW_REGISTRIES and OPERATOR_SCAN_DENY_REGISTRIES,
+// or are excluded by OPERATOR_EXCLUDE_IMAGES, along with the excluded images in order of appearance without duplicates.
+func FilterContainersByRegistry(config etc.Operator, spec corev1.PodSpec) (corev1.PodSpec, []string) {
+	var excluded []string
+	isScanned := func(image string) bool {
+		return config.IsImageAllowed(image) && !config.IsImageExcluded(image)
+	}
+	filter := func(containers []corev1.Container) []corev1.Container {
+		var allowed []corev1.Container
+		for _, c := range containers {
+			if isScanned(c.Image) {
+				allowed = append(allowed, c)
+			} else if !SliceContainsString(excluded, c.Image) {
+				excluded = append(excluded, c.Image)
+			}
+		}
+		return allowed
+	}
+	filtered := *spec.DeepCopy()
+	filtered.InitContainers = filter(filtered.InitContainers)
+	filtered.Containers = filter(filtered.Containers)
+	var ephemeralContainers []corev1.EphemeralContainer
+	for _, c := range filtered.EphemeralContainers {
+		if isScanned(c.Image) {
+			ephemeralContainers = append(ephemeralContainers, c)
+		} else if !SliceContainsString(excluded, c.Image) {
+			excluded = append(excluded, c.Image)
+		}
+	}
+	filtered.EphemeralContainers = ephemeralContainers
+	return filtered, excluded
+}
+
+// GetContainerImageIDs returns the mapping from a container name to the ID of the image it runs, as reported
+// by the kubelet, for init containers, containers, and ephemeral containers of the specified Pod. Images pinned by digest, which are
+// not reported yet, are identified by their references. If the DigestResolver is set, IDs of other images which
+// are not reported yet are resolved from registries, e.g. `nginx@sha256:4cd8...` for `nginx:1.16`.
+// Images whose digests cannot be resolved are omitted, so that the Pod waits for the kubelet.
+func (r *PodController) GetContainerImageIDs(ctx context.Context, pod *corev1.Pod) (map[string]string, error) {
+	imageIDs := resources.GetContainerImageIDsFromPodStatus(pod.Status)
+	for container, image := range resources.GetPinnedContainerImagesFromPodSpec(pod.Spec) {
+		if _, ok := imageIDs[container]; !ok {
+			imageIDs[container] = image
+		}
+	}
+	if r.DigestResolver == nil {
+		return imageIDs, nil
+	}
+
+	var unresolved []corev1.Container
+	for _, container := range append(append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...),
+		resources.GetEphemeralContainersFromPodSpec(pod.Spec)...) {
+		if _, ok := imageIDs[container.Name]; !ok {
+			unresolved = append(unresolved, container)
+		}
+	}
+	if len(unresolved) == 0 {
+		return imageIDs, nil
+	}
+
+	credentials, err := r.GetRegistryCredentials(ctx, pod)
+	if err != nil {
+		return nil, fmt.Errorf("getting registry credentials: %w", err)
+	}
+	for _, container := range unresolved {
+		digest, err := r.DigestResolver.ResolveDigest(ctx, container.Image, credentials[container.Name])
+		if err != nil {
+			log.Error(err, "Unable to resolve image digest", "image", container.Image)
+			continue
+		}
+		ref, err := name.ParseReference(container.Image)
+		if err != nil {
+			return nil, err
+		}
+		imageIDs[container.Name] = ref.Context().Name() + "@" + digest
+	}
+	return imageIDs, nil
+}
+
+// GetIgnoreFileConfigMap returns the name of the ConfigMap in the scan Jobs namespace holding the `.trivyignore`
+// file for workloads in the specified namespace. The `<configmap>-<namespace>` ConfigMap, where configmap is the
+// configured one, overrides the configured ConfigMap if it exists. Returns a blank string if the ConfigMap is not configured.
+func (r *PodController) GetIgnoreFileConfigMap(ctx context.Context, namespace string) (string, error) {
+	configMap := r.Config.TrivyIgnoreFileConfigMap
+	if configMap == "" {
+		return "", nil
+	}
+	override := configMap + "-" + namespace
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: r.Config.GetScanJobsNamespace(), Name: override}, &corev1.ConfigMap{})
+	if err != nil && errors.IsNotFound(err) {
+		return configMap, nil
+	} else if err != nil {
+		return "", err
+	}
+	return override, nil
+}
+
+// GetRegistryCredentials returns registry credentials for images of the specified Pod containers
+// keyed by container name. Credentials are read from image pull Secrets referenced by the Pod
+// and its service account. Missing Secrets or service account are ignored.
+func (r *PodController) GetRegistryCredentials(ctx context.Context, pod *corev1.Pod) (map[string]docker.Auth, error) {
+	secretRefs := append([]corev1.LocalObjectReference{}, pod.Spec.ImagePullSecrets...)
+
+	serviceAccountName := pod.Spec.ServiceAccountName
+	if serviceAccountName == "" {
+		serviceAccountName = "default"
+	}
+	serviceAccount := &corev1.ServiceAccount{}
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: serviceAccountName}, serviceAccount)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("getting service account: %w", err)
+	}
+	if err == nil {
+		secretRefs = append(secretRefs, serviceAccount.ImagePullSecrets...)
+	}
+
+	var secrets []corev1.Secret
+	for _, secretRef := range secretRefs {
+		secret := corev1.Secret{}
+		err := r.Client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: secretRef.Name}, &secret)
+		if err != nil && errors.IsNotFound(err) {
+			log.V(1).Info("Ignoring image pull secret that does not exist",
+				"secret", fmt.Sprintf("%s/%s", pod.Namespace, secretRef.Name))
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("getting image pull secret: %w", err)
+		}
+		secrets = append(secrets, secret)
+	}
+
+	if len(secrets) == 0 {
+		return nil, nil
+	}
+
+	serverCredentials, err := docker.ReadCredentialsFromSecrets(secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make(map[string]docker.Auth)
+	for _, container := range append(append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...),
+		resources.GetEphemeralContainersFromPodSpec(pod.Spec)...) {
+		auth, ok, err := docker.GetCredentialsForImage(serverCredentials, container.Image)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			credentials[container.Name] = auth
+		}
+	}
+	return credentials, nil
+}
+
+// NewRegistryCredentialsSecret constructs a new Secret holding the specified registry
+// credentials, which can be referenced by containers of a scan Job.
+func NewRegistryCredentialsSecret(namespace string, meta scanner.JobMeta, credentials map[string]docker.Auth) *corev1.Secret {
+	data := make(map[string][]byte)
+	for container, auth := range credentials {
+		usernameKey, passwordKey := scanner.GetRegistryCredentialsSecretKeys(container)
+		data[usernameKey] = []byte(auth.Username)
+		data[passwordKey] = []byte(auth.Password)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      uuid.New().String(),
+			Namespace: namespace,
+			Labels:    meta.Labels,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// IsConcurrentScanJobsLimitExceeded returns true if the number of active scan Jobs
+// has reached the configured limit, false otherwise. The limit of 0 means that the
+// number of concurrent scan Jobs is unlimited.
+//
+// Active scan Jobs are the ones in the scan Jobs namespace, which are labeled with
+// the configured scan Job label and are neither complete nor failed.
+func (r *PodController) IsConcurrentScanJobsLimitExceeded(ctx context.Context) (bool, error) {
+	if r.Config.ConcurrentScanJobsLimit <= 0 {
+		return false, nil
+	}
+
+	jobList := &batchv1.JobList{}
+	err := r.Client.List(ctx, jobList, client.MatchingLabels(r.Config.GetScanJobLabels()),
+		client.InNamespace(r.Config.GetScanJobsNamespace()))
+	if err != nil {
+		return false, fmt.Errorf("listing scan jobs: %w", err)
+	}
+
+	active := 0
+	for _, job := range jobList.Items {
+		if !IsJobFinished(job) {
+			active++
+		}
+	}
+	return active >= r.Config.ConcurrentScanJobsLimit, nil
+}
+
+// IsJobFinished returns true if the specified Job has the complete or failed condition, false otherwise.
+func IsJobFinished(job batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) &&
+			condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *PodController) GetJobMetaFrom(owner kube.Object, hash string, spec corev1.PodSpec) (scanner.JobMeta, error) {
@@ -163,46 +1091,146 @@ func (r *PodController) GetJobMetaFrom(owner kube.Object, hash string, spec core
 		return scanner.JobMeta{}, err
 	}
 
+	annotations := map[string]string{
+		kube.AnnotationContainerImages: containerImagesAsJSON,
+	}
+	if initContainerNames := resources.GetInitContainerNamesFromPodSpec(spec); len(initContainerNames) > 0 {
+		annotations[etc.AnnotationInitContainerNames] = strings.Join(initContainerNames, ",")
+	}
+	if ephemeralContainerNames := resources.GetEphemeralContainerNamesFromPodSpec(spec); len(ephemeralContainerNames) > 0 {
+		annotations[etc.AnnotationEphemeralContainerNames] = strings.Join(ephemeralContainerNames, ",")
+	}
+
+	jobLabels := map[string]string{
+		kube.LabelResourceKind:      string(owner.Kind),
+		kube.LabelResourceName:      owner.Name,
+		kube.LabelResourceNamespace: owner.Namespace,
+		etc.LabelPodSpecHash:        hash,
+	}
+	for key, value := range r.Config.GetScanJobLabels() {
+		jobLabels[key] = value
+	}
 	return scanner.JobMeta{
-		Labels: map[string]string{
-			kube.LabelResourceKind:         string(owner.Kind),
-			kube.LabelResourceName:         owner.Name,
-			kube.LabelResourceNamespace:    owner.Namespace,
-			"app.kubernetes.io/managed-by": "starboard-operator",
-			etc.LabelPodSpecHash:           hash,
-		},
-		Annotations: map[string]string{
-			kube.AnnotationContainerImages: containerImagesAsJSON,
-		},
+		Labels:      jobLabels,
+		Annotations: annotations,
 	}, nil
 }
 
+// GetPropagatedLabels returns labels of the specified Pod, or its owner if the Pod does not have them,
+// whose keys are configured to be copied to scan Jobs. Keys present on neither are omitted.
+func (r *PodController) GetPropagatedLabels(ctx context.Context, pod *corev1.Pod, owner kube.Object) (map[string]string, error) {
+	keys := r.Config.GetScanJobPropagateLabels()
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	var ownerLabels map[string]string
+	if owner.Kind != kube.KindPod {
+		obj, err := resources.GetRuntimeObjectFor(ctx, r.Client, owner)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			ownerLabels = obj.GetLabels()
+		}
+	}
+	propagated := make(map[string]string)
+	for _, key := range keys {
+		if value, ok := pod.Labels[key]; ok {
+			propagated[key] = value
+		} else if value, ok := ownerLabels[key]; ok {
+			propagated[key] = value
+		}
+	}
+	return propagated, nil
+}
+
+// IsScanSkipped returns true if the specified Pod or its owner is annotated with
+// the etc.AnnotationSkipScan annotation set to "true", false otherwise.
+// Existing reports of skipped workloads are left in place.
+func (r *PodController) IsScanSkipped(ctx context.Context, pod *corev1.Pod, owner kube.Object) (bool, error) {
+	if pod.Annotations[etc.AnnotationSkipScan] == "true" {
+		return true, nil
+	}
+	if owner.Kind == kube.KindPod {
+		return false, nil
+	}
+	obj, err := resources.GetRuntimeObjectFor(ctx, r.Client, owner)
+	if err != nil && errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return obj.GetAnnotations()[etc.AnnotationSkipScan] == "true", nil
+}
+
+// GetRescanNonce returns the value of the etc.AnnotationRescan annotation of the specified Pod
+// if it differs from the value of the etc.AnnotationRescanProcessed annotation, i.e. a rescan
+// was requested but not processed yet. Returns a blank string otherwise.
+func GetRescanNonce(pod *corev1.Pod) string {
+	nonce := pod.Annotations[etc.AnnotationRescan]
+	if nonce == pod.Annotations[etc.AnnotationRescanProcessed] {
+		return ""
+	}
+	return nonce
+}
+
+// setRescanProcessed patches the specified Pod with the etc.AnnotationRescanProcessed annotation
+// so that the same rescan request is not processed again.
+func (r *PodController) setRescanProcessed(ctx context.Context, pod *corev1.Pod, nonce string) error {
+	// Do not modify the object that might be cached.
+	updated := pod.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string)
+	}
+	updated.Annotations[etc.AnnotationRescanProcessed] = nonce
+	return r.Client.Patch(ctx, updated, client.MergeFrom(pod))
+}
+
+// setScanStatus sets the etc.AnnotationScanStatus annotation of the specified workload to the given status.
+// Errors are logged rather than returned as the scan Job is already created or reports are already written.
+func (r *PodController) setScanStatus(ctx context.Context, workload kube.Object, status etc.ScanStatus) {
+	err := resources.SetWorkloadAnnotation(ctx, r.Client, workload, etc.AnnotationScanStatus, string(status))
+	if err != nil {
+		log.Error(err, "Unable to annotate workload with scan status", "workload", workload, "status", status)
+	}
+}
+
+// IsNamespaceSelected returns true if the specified namespace matches the target namespace
+// selector or the selector is not set, false otherwise. A namespace that does not exist
+// is not selected.
+func (r *PodController) IsNamespaceSelected(ctx context.Context, name string) (bool, error) {
+	selector, err := r.Config.GetTargetNamespaceSelector()
+	if err != nil {
+		return false, err
+	}
+	if selector == nil {
+		return true, nil
+	}
+	namespace := &corev1.Namespace{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: name}, namespace)
+	if err != nil && errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("getting namespace: %w", err)
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
 // IgnorePodInOperatorNamespace determines whether to reconcile the specified Pod
 // based on the give InstallMode or not. Returns true if the Pod should be ignored,
 // false otherwise.
 //
-// In the SingleNamespace install mode we're configuring Client cache
-// to watch the operator namespace, in which the operator runs scan Jobs.
-// However, we do not want to scan the workloads that might run in the
-// operator namespace.
-//
-// In the MultiNamespace install mode we're configuring Client cache
-// to watch the operator namespace, in which the operator runs scan Jobs.
-// However, we do not want to scan the workloads that might run in the
-// operator namespace unless the operator namespace is added to the list
-// of target namespaces.
+// In the SingleNamespace and MultiNamespace install modes we're configuring Client
+// cache to watch the operator namespace, in which the operator runs config audit Jobs,
+// and the scan Jobs namespace, which defaults to the operator namespace. So is the
+// case in the OwnNamespace install mode if scan Jobs run in a different namespace.
+// However, we do not want to scan the workloads that might run in these namespaces
+// unless they're added to the list of target namespaces.
 func (r *PodController) IgnorePodInOperatorNamespace(installMode etc.InstallMode, pod types.NamespacedName) bool {
-	if installMode == etc.InstallModeSingleNamespace &&
-		pod.Namespace == r.Config.Namespace {
-		return true
-	}
-
-	if installMode == etc.InstallModeMultiNamespace &&
-		pod.Namespace == r.Config.Namespace &&
-		!SliceContainsString(r.Config.GetTargetNamespaces(), r.Config.Namespace) {
-		return true
+	switch installMode {
+	case etc.InstallModeOwnNamespace, etc.InstallModeSingleNamespace, etc.InstallModeMultiNamespace:
+		return !SliceContainsString(r.Config.GetTargetNamespaces(), pod.Namespace)
 	}
-
 	return false
 }
 
@@ -210,16 +1238,161 @@ func (r *PodController) IgnorePodInOperatorNamespace(installMode etc.InstallMode
 // is managed by the Starboard Operator, false otherwise.
 //
 // We define managed Pods as ones controlled by Jobs created by the Starboard Operator.
-// They're labeled with `app.kubernetes.io/managed-by=starboard-operator`.
-func IsPodManagedByStarboardOperator(pod *corev1.Pod) bool {
-	managedBy, exists := pod.Labels["app.kubernetes.io/managed-by"]
-	return exists && managedBy == "starboard-operator"
+// They're labeled with the configured scan Job label, which defaults to
+// `app.kubernetes.io/managed-by=starboard-operator`.
+func IsPodManagedByStarboardOperator(config etc.Operator, pod *corev1.Pod) bool {
+	key, value := config.GetScanJobLabel()
+	managedBy, exists := pod.Labels[key]
+	return exists && managedBy == value
 }
 
 func (r *PodController) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Pod{}).
-		Complete(r)
+	selector, err := r.Config.GetScanLabelSelector()
+	if err != nil {
+		return err
+	}
+	// Pods which do not match the scan label selector are never scanned, so that teams opt in to scanning.
+	// The predicate only filters out Pod events, hence Pods enqueued by other watches are checked on reconcile.
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Pod{}, builder.WithPredicates(NewScanLabelPredicate(selector)))
+	if r.Config.TargetNamespaceSelector != "" {
+		// Reconcile Pods of a namespace whenever its labels change, so that workloads
+		// of namespaces that started matching the selector are scanned.
+		controllerBuilder = controllerBuilder.Watches(&source.Kind{Type: &corev1.Namespace{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.GetPodRequestsForNamespace),
+		})
+	}
+	// Reconcile Pods using an image pull Secret whenever it's created or its credentials change, so that
+	// scans which failed with missing or stale credentials are retried without waiting for the report TTL.
+	controllerBuilder = controllerBuilder.Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(r.GetPodRequestsForSecret),
+	}, builder.WithPredicates(NewImagePullSecretPredicate()))
+	return controllerBuilder.Complete(r)
+}
+
+// NewScanLabelPredicate returns the predicate which filters out events of Pods whose labels do not match
+// the specified selector.
+func NewScanLabelPredicate(selector labels.Selector) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(meta metav1.Object, _ runtime.Object) bool {
+		return selector.Matches(labels.Set(meta.GetLabels()))
+	})
+}
+
+// GetPodRequestsForNamespace maps the specified namespace to reconcile requests
+// for all Pods in that namespace.
+func (r *PodController) GetPodRequestsForNamespace(object handler.MapObject) []reconcile.Request {
+	podList := &corev1.PodList{}
+	err := r.Client.List(context.Background(), podList, client.InNamespace(object.Meta.GetName()))
+	if err != nil {
+		log.Error(err, "Unable to list pods", "namespace", object.Meta.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, len(podList.Items))
+	for i, pod := range podList.Items {
+		requests[i] = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}}
+	}
+	return requests
+}
+
+// NewImagePullSecretPredicate returns the predicate which filters out events of Secrets other than image
+// pull Secrets, as well as updates which do not change credentials and deletions.
+func NewImagePullSecretPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return IsImagePullSecret(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !IsImagePullSecret(e.ObjectNew) {
+				return false
+			}
+			oldSecret, ok := e.ObjectOld.(*corev1.Secret)
+			if !ok {
+				return true
+			}
+			newSecret := e.ObjectNew.(*corev1.Secret)
+			return oldSecret.Type != newSecret.Type || !reflect.DeepEqual(oldSecret.Data, newSecret.Data)
+		},
+		DeleteFunc: func(_ event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(_ event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// IsImagePullSecret returns true if the specified object is a Secret holding registry credentials.
+func IsImagePullSecret(object runtime.Object) bool {
+	secret, ok := object.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+	return secret.Type == corev1.SecretTypeDockerConfigJson || secret.Type == corev1.SecretTypeDockercfg
+}
+
+// GetPodRequestsForSecret maps the specified image pull Secret to reconcile requests for Pods which
+// refer to it directly or through their service accounts. Circuits of images of these Pods are reset,
+// as their scans might have failed because of the credentials held by the Secret. Pods which do not match
+// the scan label selector are left out.
+func (r *PodController) GetPodRequestsForSecret(object handler.MapObject) []reconcile.Request {
+	ctx := context.Background()
+	namespace, name := object.Meta.GetNamespace(), object.Meta.GetName()
+
+	selector, err := r.Config.GetScanLabelSelector()
+	if err != nil {
+		log.Error(err, "Unable to get scan label selector")
+		return nil
+	}
+
+	serviceAccountList := &corev1.ServiceAccountList{}
+	err = r.Client.List(ctx, serviceAccountList, client.InNamespace(namespace))
+	if err != nil {
+		log.Error(err, "Unable to list service accounts", "namespace", namespace)
+		return nil
+	}
+	serviceAccounts := make(map[string]bool)
+	for _, serviceAccount := range serviceAccountList.Items {
+		if HasImagePullSecret(serviceAccount.ImagePullSecrets, name) {
+			serviceAccounts[serviceAccount.Name] = true
+		}
+	}
+
+	podList := &corev1.PodList{}
+	err = r.Client.List(ctx, podList, client.InNamespace(namespace))
+	if err != nil {
+		log.Error(err, "Unable to list pods", "namespace", namespace)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, pod := range podList.Items {
+		serviceAccountName := pod.Spec.ServiceAccountName
+		if serviceAccountName == "" {
+			serviceAccountName = "default"
+		}
+		if !HasImagePullSecret(pod.Spec.ImagePullSecrets, name) && !serviceAccounts[serviceAccountName] {
+			continue
+		}
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		r.CircuitBreaker.Reset(GetUniqueImages(pod.Spec))
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}})
+	}
+	if len(requests) > 0 {
+		log.V(1).Info("Reconciling Pods using changed image pull secret",
+			"secret", fmt.Sprintf("%s/%s", namespace, name), "pods", len(requests))
+	}
+	return requests
+}
+
+// HasImagePullSecret returns true if the specified references contain the image pull Secret with the given name.
+func HasImagePullSecret(refs []corev1.LocalObjectReference, name string) bool {
+	for _, ref := range refs {
+		if ref.Name == name {
+			return true
+		}
+	}
+	return false
 }
 
 // SliceContainsString returns true if the specified slice of strings
@@ -1 +1,2273 @@
-package pod
+package pod_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/aquasecurity/starboard-operator/pkg/controller/pod"
+	"github.com/aquasecurity/starboard-operator/pkg/docker"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/metrics"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	"github.com/aquasecurity/starboard-operator/pkg/trivy"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+	return scheme
+}
+
+const nginxDigest = "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c"
+
+func newPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nginx",
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nginx", Image: "nginx:1.16"},
+			},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.ContainersReady, Status: corev1.ConditionTrue},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "nginx", Image: "nginx:1.16", ImageID: "docker-pullable://nginx@" + nginxDigest},
+			},
+		},
+	}
+}
+
+func newScanJob(name string, conditions ...batchv1.JobCondition) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "starboard-operator",
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "starboard-operator",
+			},
+		},
+		Status: batchv1.JobStatus{
+			Conditions: conditions,
+		},
+	}
+}
+
+func newVulnerabilityReport(hash string, updatedAt time.Time) *starboardv1alpha1.VulnerabilityReport {
+	return &starboardv1alpha1.VulnerabilityReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-nginx-nginx",
+			Namespace: "default",
+			Labels: map[string]string{
+				kube.LabelResourceKind:      string(kube.KindPod),
+				kube.LabelResourceName:      "nginx",
+				kube.LabelResourceNamespace: "default",
+				kube.LabelContainerName:     "nginx",
+				etc.LabelPodSpecHash:        hash,
+			},
+			Annotations: map[string]string{
+				etc.AnnotationReportUpdatedAt: updatedAt.Format(time.RFC3339),
+			},
+		},
+	}
+}
+
+func newPodController(config etc.Operator, clock clock.Clock, objects ...runtime.Object) *pod.PodController {
+	scheme := newScheme()
+	fakeClient := fake.NewFakeClientWithScheme(scheme, objects...)
+	store := reports.NewStore(fakeClient, scheme, clock, reports.Compression{}, 0, 0, false, nil, "")
+	return &pod.PodController{
+		Config:            config,
+		Client:            fakeClient,
+		Writer:            store,
+		DigestReader:      store,
+		IncrementalWriter: store,
+		Scanner:           trivy.NewScanner(etc.ScannerTrivy{ImageRef: "aquasec/trivy:0.11.0"}),
+		Scheme:            scheme,
+		Clock:             clock,
+		Recorder:          record.NewFakeRecorder(10),
+	}
+}
+
+func TestPodController_Reconcile(t *testing.T) {
+	t.Run("Should create scan job when concurrent scan jobs limit is not exceeded", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:               "starboard-operator",
+			ConcurrentScanJobsLimit: 2,
+		}, clock.RealClock{}, newPod(),
+			newScanJob("active"),
+			newScanJob("complete", batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}),
+			newScanJob("failed", batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}),
+		)
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 4)
+	})
+
+	t.Run("Should requeue when concurrent scan jobs limit is exceeded", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:               "starboard-operator",
+			ConcurrentScanJobsLimit: 2,
+		}, clock.RealClock{}, newPod(),
+			newScanJob("active-1"),
+			newScanJob("active-2"),
+		)
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{Requeue: true}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 2)
+	})
+
+	t.Run("Should requeue after configured interval when concurrent scan jobs limit is exceeded", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:                "starboard-operator",
+			ConcurrentScanJobsLimit:  1,
+			ReconcileRequeueInterval: 45 * time.Second,
+		}, clock.RealClock{}, newPod(),
+			newScanJob("active"),
+		)
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: 45 * time.Second}, result)
+	})
+
+	t.Run("Should create scan job when concurrent scan jobs are unlimited", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:               "starboard-operator",
+			ConcurrentScanJobsLimit: 0,
+		}, clock.RealClock{}, newPod(),
+			newScanJob("active-1"),
+			newScanJob("active-2"),
+		)
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 3)
+	})
+
+	t.Run("Should rescan Pod when VulnerabilityReports are older than TTL", func(t *testing.T) {
+		now := time.Date(2020, 9, 25, 10, 0, 0, 0, time.UTC)
+		fakeClock := clock.NewFakeClock(now.Add(30 * time.Minute))
+		hash := controller.ComputeHash(newPod().Spec)
+
+		podController := newPodController(etc.Operator{
+			Namespace:     "starboard-operator",
+			ScanReportTTL: time.Hour,
+		}, fakeClock, newPod(), newVulnerabilityReport(hash, now))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: 30 * time.Minute}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+
+		fakeClock.Step(30 * time.Minute)
+
+		result, err = podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 1)
+	})
+
+	t.Run("Should requeue Pod with jitter when VulnerabilityReports are not older than TTL", func(t *testing.T) {
+		now := time.Date(2020, 9, 25, 10, 0, 0, 0, time.UTC)
+		hash := controller.ComputeHash(newPod().Spec)
+
+		podController := newPodController(etc.Operator{
+			Namespace:     "starboard-operator",
+			ScanReportTTL: time.Hour,
+			RescanJitter:  10 * time.Minute,
+		}, clock.NewFakeClock(now.Add(30*time.Minute)), newPod(), newVulnerabilityReport(hash, now))
+
+		for i := 0; i < 10; i++ {
+			result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, int64(result.RequeueAfter), int64(30*time.Minute))
+			assert.Less(t, int64(result.RequeueAfter), int64(40*time.Minute))
+		}
+	})
+
+	t.Run("Should not rescan Pod when TTL is not set", func(t *testing.T) {
+		now := time.Date(2020, 9, 25, 10, 0, 0, 0, time.UTC)
+		fakeClock := clock.NewFakeClock(now.Add(24 * time.Hour))
+		hash := controller.ComputeHash(newPod().Spec)
+
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, fakeClock, newPod(), newVulnerabilityReport(hash, now))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+	})
+
+	t.Run("Should pass registry credentials from image pull secrets to scan job", func(t *testing.T) {
+		workload := newPod()
+		workload.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "dockerhub"}, {Name: "missing"}}
+		workload.Spec.Containers = append(workload.Spec.Containers, corev1.Container{
+			Name:  "sidecar",
+			Image: "core.harbor.domain/library/sidecar:1.0",
+		}, corev1.Container{
+			Name:  "public",
+			Image: "quay.io/prometheus/prometheus:v2.20.0",
+		})
+		workload.Status.ContainerStatuses = append(workload.Status.ContainerStatuses, corev1.ContainerStatus{
+			Name:    "sidecar",
+			ImageID: "docker-pullable://core.harbor.domain/library/sidecar@sha256:5b8e7d1f2a3c4b6d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d",
+		}, corev1.ContainerStatus{
+			Name:    "public",
+			ImageID: "docker-pullable://quay.io/prometheus/prometheus@sha256:7c1a9e2b3d4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b",
+		})
+
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload,
+			&corev1.ServiceAccount{
+				ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: "default"},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "harbor"}},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "dockerhub", Namespace: "default"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"username":"root","password":"s3cret"}}}`),
+				},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "harbor", Namespace: "default"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(`{"auths":{"core.harbor.domain":{"username":"admin","password":"Harbor12345"}}}`),
+				},
+			},
+		)
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		secretList := &corev1.SecretList{}
+		require.NoError(t, podController.Client.List(context.Background(), secretList, client.InNamespace("starboard-operator")))
+		require.Len(t, secretList.Items, 1)
+		assert.Equal(t, map[string][]byte{
+			"nginx.username":   []byte("root"),
+			"nginx.password":   []byte("s3cret"),
+			"sidecar.username": []byte("admin"),
+			"sidecar.password": []byte("Harbor12345"),
+		}, secretList.Items[0].Data)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		containers := jobList.Items[0].Spec.Template.Spec.Containers
+		require.Len(t, containers, 3)
+		require.Len(t, containers[0].Env, 2)
+		assert.Equal(t, "TRIVY_USERNAME", containers[0].Env[0].Name)
+		assert.Equal(t, secretList.Items[0].Name, containers[0].Env[0].ValueFrom.SecretKeyRef.Name)
+		assert.Equal(t, "nginx.username", containers[0].Env[0].ValueFrom.SecretKeyRef.Key)
+		assert.Equal(t, "sidecar.password", containers[1].Env[1].ValueFrom.SecretKeyRef.Key)
+		assert.Empty(t, containers[2].Env)
+	})
+
+	t.Run("Should scan images of init containers", func(t *testing.T) {
+		workload := newPod()
+		workload.Spec.InitContainers = []corev1.Container{
+			{Name: "migrate", Image: "flyway/flyway:7.0"},
+			{Name: "init", Image: "nginx:1.16"},
+		}
+
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload)
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		scanJob := jobList.Items[0]
+		assert.Equal(t, "migrate,init", scanJob.Annotations[etc.AnnotationInitContainerNames])
+		assert.JSONEq(t, `{"migrate":"flyway/flyway:7.0","init":"nginx:1.16","nginx":"nginx:1.16"}`,
+			scanJob.Annotations[kube.AnnotationContainerImages])
+
+		containers := scanJob.Spec.Template.Spec.Containers
+		require.Len(t, containers, 2)
+		assert.Equal(t, "migrate", containers[0].Name)
+		assert.Equal(t, "flyway/flyway:7.0", containers[0].Args[len(containers[0].Args)-1])
+		assert.Equal(t, "init", containers[1].Name)
+		assert.Equal(t, "nginx:1.16", containers[1].Args[len(containers[1].Args)-1])
+	})
+
+	t.Run("Should not create scan job for Pod annotated to skip scanning", func(t *testing.T) {
+		workload := newPod()
+		workload.Annotations = map[string]string{
+			etc.AnnotationSkipScan: "true",
+		}
+
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload)
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+	})
+
+	t.Run("Should not create scan job for Pod whose owner is annotated to skip scanning", func(t *testing.T) {
+		workload := newPod()
+		workload.OwnerReferences = []metav1.OwnerReference{
+			{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       "nginx-6d4cf56db6",
+				Controller: pointer.BoolPtr(true),
+			},
+		}
+
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload, &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "nginx-6d4cf56db6",
+				Namespace: "default",
+				Annotations: map[string]string{
+					etc.AnnotationSkipScan: "true",
+				},
+			},
+		})
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+	})
+
+	t.Run("Should record event when scan job is created", func(t *testing.T) {
+		workload := newPod()
+		workload.Spec.InitContainers = []corev1.Container{
+			{Name: "init", Image: "busybox:1.32"},
+		}
+
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload)
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+
+		events := podController.Recorder.(*record.FakeRecorder).Events
+		require.Len(t, events, 1)
+		assert.Equal(t, "Normal ScanJobCreated Created scan job starboard-operator/"+jobList.Items[0].Name+
+			" to scan images busybox:1.32, nginx:1.16 with Trivy", <-events)
+	})
+
+	t.Run("Should not record event when Pod is annotated to skip scanning", func(t *testing.T) {
+		workload := newPod()
+		workload.Annotations = map[string]string{
+			etc.AnnotationSkipScan: "true",
+		}
+
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload)
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		assert.Len(t, podController.Recorder.(*record.FakeRecorder).Events, 0)
+	})
+
+	t.Run("Should not create scan job in dry-run mode", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+			DryRun:    true,
+		}, clock.RealClock{}, newPod())
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+		assert.Len(t, podController.Recorder.(*record.FakeRecorder).Events, 0)
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.DryRunScansTotal.WithLabelValues("Trivy")))
+	})
+
+	t.Run("Should run scan job with configured service account", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:             "starboard-operator",
+			ServiceAccount:        "starboard-operator",
+			ScanJobServiceAccount: "starboard-scanner",
+		}, clock.RealClock{}, newPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		assert.Equal(t, "starboard-scanner", jobList.Items[0].Spec.Template.Spec.ServiceAccountName)
+	})
+
+	t.Run("Should run scan job with configured priority class", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:                "starboard-operator",
+			ScanJobPriorityClassName: "low-priority",
+		}, clock.RealClock{}, newPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		assert.Equal(t, "low-priority", jobList.Items[0].Spec.Template.Spec.PriorityClassName)
+	})
+
+	t.Run("Should run scan job with configured TTL after finished", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:         "starboard-operator",
+			ScanJobTTLSeconds: 600,
+		}, clock.RealClock{}, newPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		assert.Equal(t, pointer.Int32Ptr(600), jobList.Items[0].Spec.TTLSecondsAfterFinished)
+	})
+
+	t.Run("Should run scan job without TTL after finished by default", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		assert.Nil(t, jobList.Items[0].Spec.TTLSecondsAfterFinished)
+	})
+
+	t.Run("Should run scan job with configured volumes and volume mounts", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:           "starboard-operator",
+			ScanJobVolumes:      `[{"name":"trivy-cache","persistentVolumeClaim":{"claimName":"trivy-cache"}}]`,
+			ScanJobVolumeMounts: `[{"name":"trivy-cache","mountPath":"/var/lib/trivy"}]`,
+		}, clock.RealClock{}, newPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		podSpec := jobList.Items[0].Spec.Template.Spec
+		assert.Contains(t, podSpec.Volumes, corev1.Volume{
+			Name: "trivy-cache",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "trivy-cache"},
+			},
+		})
+		require.Len(t, podSpec.Containers, 1)
+		assert.Contains(t, podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: "trivy-cache", MountPath: "/var/lib/trivy"})
+	})
+
+	t.Run("Should not create scan job for Pod in namespace not matching selector", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:               "starboard-operator",
+			TargetNamespaceSelector: "starboard.aquasecurity.github.io/scan=true",
+		}, clock.RealClock{}, newPod(), &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		})
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+	})
+
+	t.Run("Should not create scan job for Pod in excluded namespace", func(t *testing.T) {
+		systemPod := newPod()
+		systemPod.Namespace = "kube-system"
+		podController := newPodController(etc.Operator{
+			Namespace:         "starboard-operator",
+			ExcludeNamespaces: "kube-system,kube-public,kube-node-lease",
+		}, clock.RealClock{}, systemPod, newPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "kube-system", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+
+		_, err = podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 1)
+	})
+
+	t.Run("Should create scan job when config audit job exists", func(t *testing.T) {
+		configAuditJob := newScanJob("config-audit")
+		configAuditJob.Labels[kube.LabelResourceKind] = string(kube.KindPod)
+		configAuditJob.Labels[kube.LabelResourceName] = "nginx"
+		configAuditJob.Labels[kube.LabelResourceNamespace] = "default"
+		configAuditJob.Labels[etc.LabelPodSpecHash] = controller.ComputeHash(newPod().Spec)
+		configAuditJob.Labels[etc.LabelConfigAudit] = "true"
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newPod(), configAuditJob)
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 2)
+	})
+
+	newFinishedScanJob := func(annotations map[string]string) *batchv1.Job {
+		scanJob := newScanJob("finished", batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue})
+		scanJob.Labels[kube.LabelResourceKind] = string(kube.KindPod)
+		scanJob.Labels[kube.LabelResourceName] = "nginx"
+		scanJob.Labels[kube.LabelResourceNamespace] = "default"
+		scanJob.Labels[etc.LabelPodSpecHash] = controller.ComputeHash(newPod().Spec)
+		scanJob.Annotations = annotations
+		return scanJob
+	}
+
+	t.Run("Should create scan job when finished scan job has been processed", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newPod(), newFinishedScanJob(map[string]string{etc.AnnotationScanJobProcessed: "true"}))
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 2)
+	})
+
+	t.Run("Should not create scan job when finished scan job has not been processed yet", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:                "starboard-operator",
+			ReconcileRequeueInterval: 30 * time.Second,
+		}, clock.RealClock{}, newPod(), newFinishedScanJob(nil))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: 30 * time.Second}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 1)
+	})
+}
+
+func TestPodController_ImageDigests(t *testing.T) {
+	t.Run("Should annotate scan job with image digests", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newPod())
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		assert.JSONEq(t, `{"nginx":"`+nginxDigest+`"}`, jobList.Items[0].Annotations[etc.AnnotationContainerImageDigests])
+	})
+
+	t.Run("Should requeue when image ID is not reported yet", func(t *testing.T) {
+		workload := newPod()
+		workload.Status.ContainerStatuses = nil
+		podController := newPodController(etc.Operator{
+			Namespace:                "starboard-operator",
+			ReconcileRequeueInterval: 10 * time.Second,
+		}, clock.RealClock{}, workload)
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: 10 * time.Second}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+	})
+
+	t.Run("Should not annotate scan job with image IDs without digests", func(t *testing.T) {
+		workload := newPod()
+		workload.Status.ContainerStatuses[0].ImageID = "sha256:0e5dfb4b4b2c0f8e0c2b5d5b3c6a0d1c9f1b5a0e2d3c4b5a6f7e8d9c0b1a2f3e"
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload)
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		assert.NotContains(t, jobList.Items[0].Annotations, etc.AnnotationContainerImageDigests)
+	})
+}
+
+// fakeDigestResolver resolves images to the specified digests and records credentials it was called with.
+type fakeDigestResolver struct {
+	digests map[string]string
+	auths   map[string]docker.Auth
+}
+
+func (r *fakeDigestResolver) ResolveDigest(_ context.Context, imageRef string, auth docker.Auth) (string, error) {
+	r.auths[imageRef] = auth
+	digest, ok := r.digests[imageRef]
+	if !ok {
+		return "", fmt.Errorf("manifest unknown: %s", imageRef)
+	}
+	return digest, nil
+}
+
+func TestPodController_ResolveImageDigests(t *testing.T) {
+	newPendingPod := func() *corev1.Pod {
+		workload := newPod()
+		workload.Status = corev1.PodStatus{Phase: corev1.PodPending}
+		return workload
+	}
+
+	t.Run("Should scan pending pod with resolved image digests", func(t *testing.T) {
+		resolver := &fakeDigestResolver{digests: map[string]string{"nginx:1.16": nginxDigest}, auths: map[string]docker.Auth{}}
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newPendingPod())
+		podController.DigestResolver = resolver
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		assert.JSONEq(t, `{"nginx":"`+nginxDigest+`"}`, jobList.Items[0].Annotations[etc.AnnotationContainerImageDigests])
+	})
+
+	t.Run("Should resolve image digests with image pull secret credentials", func(t *testing.T) {
+		workload := newPendingPod()
+		workload.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "regcred"}}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "default"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"username":"root","password":"s3cret"}}}`),
+			},
+		}
+		resolver := &fakeDigestResolver{digests: map[string]string{"nginx:1.16": nginxDigest}, auths: map[string]docker.Auth{}}
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload, secret)
+		podController.DigestResolver = resolver
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]docker.Auth{"nginx:1.16": {Username: "root", Password: "s3cret"}}, resolver.auths)
+	})
+
+	t.Run("Should requeue when image digest cannot be resolved", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:                "starboard-operator",
+			ReconcileRequeueInterval: 10 * time.Second,
+		}, clock.RealClock{}, newPendingPod())
+		podController.DigestResolver = &fakeDigestResolver{digests: map[string]string{}, auths: map[string]docker.Auth{}}
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: 10 * time.Second}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+	})
+
+	t.Run("Should not resolve image digests reported by kubelet", func(t *testing.T) {
+		resolver := &fakeDigestResolver{digests: map[string]string{}, auths: map[string]docker.Auth{}}
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newPod())
+		podController.DigestResolver = resolver
+
+		imageIDs, err := podController.GetContainerImageIDs(context.Background(), newPod())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"nginx": "docker-pullable://nginx@" + nginxDigest}, imageIDs)
+		assert.Empty(t, resolver.auths)
+	})
+}
+
+func TestPodController_PinnedImages(t *testing.T) {
+	newPendingPod := func(image string) *corev1.Pod {
+		workload := newPod()
+		workload.Spec.Containers[0].Image = image
+		workload.Status = corev1.PodStatus{Phase: corev1.PodPending}
+		return workload
+	}
+
+	t.Run("Should scan pending pod with image referenced by digest", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newPendingPod("nginx@"+nginxDigest))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		assert.JSONEq(t, `{"nginx":"`+nginxDigest+`"}`, jobList.Items[0].Annotations[etc.AnnotationContainerImageDigests])
+	})
+
+	t.Run("Should ignore pending pod with image referenced by tag", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newPendingPod("nginx:1.16"))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Empty(t, jobList.Items)
+	})
+
+	t.Run("Should identify image referenced by digest not reported by kubelet", func(t *testing.T) {
+		workload := newPendingPod("nginx@" + nginxDigest)
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload)
+
+		imageIDs, err := podController.GetContainerImageIDs(context.Background(), workload)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"nginx": "nginx@" + nginxDigest}, imageIDs)
+	})
+}
+
+func TestPodController_IncrementalReports(t *testing.T) {
+	const (
+		envoyDigest    = "sha256:7d2cb6e2b1b1f6f3e3a8c5c2a2f0f2d2f8e2b5e0e2d1c9f5b3c7e1d8a6f4b2c0"
+		nginx117Digest = "sha256:9a0e1ddf0b1fa3bbd1a3e3a2d4f8b7c5d3b1e6f4a2c0e8d6b4a2f0e8c6d4b2a0"
+	)
+
+	newSidecarPod := func() *corev1.Pod {
+		workload := newPod()
+		workload.Spec.Containers = append(workload.Spec.Containers,
+			corev1.Container{Name: "sidecar", Image: "envoyproxy/envoy:v1.16.0"})
+		workload.Status.ContainerStatuses = append(workload.Status.ContainerStatuses,
+			corev1.ContainerStatus{Name: "sidecar", Image: "envoyproxy/envoy:v1.16.0", ImageID: "docker-pullable://envoyproxy/envoy@" + envoyDigest})
+		return workload
+	}
+
+	newContainerVulnerabilityReport := func(container, hash, digest string) *starboardv1alpha1.VulnerabilityReport {
+		report := newVulnerabilityReport(hash, time.Now())
+		report.Name = "pod-nginx-" + container
+		report.Labels[kube.LabelContainerName] = container
+		report.Annotations[etc.AnnotationImageDigest] = digest
+		return report
+	}
+
+	getReport := func(t *testing.T, podController *pod.PodController, name string) *starboardv1alpha1.VulnerabilityReport {
+		t.Helper()
+		report := &starboardv1alpha1.VulnerabilityReport{}
+		err := podController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: name}, report)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		require.NoError(t, err)
+		return report
+	}
+
+	listScanJobs := func(t *testing.T, podController *pod.PodController) []batchv1.Job {
+		t.Helper()
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		return jobList.Items
+	}
+
+	t.Run("Should scan only container added to Pod", func(t *testing.T) {
+		workload := newSidecarPod()
+		hash := controller.ComputeHash(workload.Spec)
+		previousHash := controller.ComputeHash(newPod().Spec)
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload, newContainerVulnerabilityReport("nginx", previousHash, nginxDigest))
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		scanJobs := listScanJobs(t, podController)
+		require.Len(t, scanJobs, 1)
+		assert.Equal(t, hash, scanJobs[0].Labels[etc.LabelPodSpecHash])
+		assert.JSONEq(t, `{"sidecar":"envoyproxy/envoy:v1.16.0"}`, scanJobs[0].Annotations[kube.AnnotationContainerImages])
+		containers := scanJobs[0].Spec.Template.Spec.Containers
+		require.Len(t, containers, 1)
+		assert.Equal(t, "sidecar", containers[0].Name)
+
+		report := getReport(t, podController, "pod-nginx-nginx")
+		require.NotNil(t, report)
+		assert.Equal(t, hash, report.Labels[etc.LabelPodSpecHash])
+	})
+
+	t.Run("Should delete report of container removed from Pod without scanning", func(t *testing.T) {
+		hash := controller.ComputeHash(newPod().Spec)
+		previousHash := controller.ComputeHash(newSidecarPod().Spec)
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newPod(),
+			newContainerVulnerabilityReport("nginx", previousHash, nginxDigest),
+			newContainerVulnerabilityReport("sidecar", previousHash, envoyDigest))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+		assert.Empty(t, listScanJobs(t, podController))
+
+		assert.Nil(t, getReport(t, podController, "pod-nginx-sidecar"))
+		report := getReport(t, podController, "pod-nginx-nginx")
+		require.NotNil(t, report)
+		assert.Equal(t, hash, report.Labels[etc.LabelPodSpecHash])
+
+		hasReports, err := podController.Writer.HasReport(context.Background(),
+			kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}, hash, kube.ContainerImages{"nginx": "nginx:1.16"})
+		require.NoError(t, err)
+		assert.True(t, hasReports)
+	})
+
+	t.Run("Should scan only container whose image changed", func(t *testing.T) {
+		workload := newSidecarPod()
+		workload.Spec.Containers[0].Image = "nginx:1.17"
+		workload.Status.ContainerStatuses[0] = corev1.ContainerStatus{
+			Name: "nginx", Image: "nginx:1.17", ImageID: "docker-pullable://nginx@" + nginx117Digest,
+		}
+		hash := controller.ComputeHash(workload.Spec)
+		previousHash := controller.ComputeHash(newSidecarPod().Spec)
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload,
+			newContainerVulnerabilityReport("nginx", previousHash, nginxDigest),
+			newContainerVulnerabilityReport("sidecar", previousHash, envoyDigest))
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		scanJobs := listScanJobs(t, podController)
+		require.Len(t, scanJobs, 1)
+		assert.JSONEq(t, `{"nginx":"nginx:1.17"}`, scanJobs[0].Annotations[kube.AnnotationContainerImages])
+
+		assert.Equal(t, previousHash, getReport(t, podController, "pod-nginx-nginx").Labels[etc.LabelPodSpecHash])
+		assert.Equal(t, hash, getReport(t, podController, "pod-nginx-sidecar").Labels[etc.LabelPodSpecHash])
+	})
+
+	t.Run("Should scan all containers when reports have no digests", func(t *testing.T) {
+		workload := newSidecarPod()
+		previousHash := controller.ComputeHash(newPod().Spec)
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload, newVulnerabilityReport(previousHash, time.Now()))
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		scanJobs := listScanJobs(t, podController)
+		require.Len(t, scanJobs, 1)
+		assert.Len(t, scanJobs[0].Spec.Template.Spec.Containers, 2)
+		assert.Equal(t, previousHash, getReport(t, podController, "pod-nginx-nginx").Labels[etc.LabelPodSpecHash])
+	})
+
+	t.Run("Should not update reports in dry-run mode", func(t *testing.T) {
+		previousHash := controller.ComputeHash(newSidecarPod().Spec)
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+			DryRun:    true,
+		}, clock.RealClock{}, newPod(),
+			newContainerVulnerabilityReport("nginx", previousHash, nginxDigest),
+			newContainerVulnerabilityReport("sidecar", previousHash, envoyDigest))
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		assert.NotNil(t, getReport(t, podController, "pod-nginx-sidecar"))
+		assert.Equal(t, previousHash, getReport(t, podController, "pod-nginx-nginx").Labels[etc.LabelPodSpecHash])
+	})
+}
+
+func TestPodController_EphemeralContainers(t *testing.T) {
+	const busyboxDigest = "sha256:4cd88c4bb4bba3d7e52f5fd2e3fd0bbf6f5a6f2ec3c6e79b0d3a8b3ec9e3b86f"
+
+	newDebuggedPod := func() *corev1.Pod {
+		workload := newPod()
+		workload.Spec.EphemeralContainers = []corev1.EphemeralContainer{
+			{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox:1.32"},
+				TargetContainerName:      "nginx",
+			},
+		}
+		workload.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{
+			{Name: "debugger", Image: "busybox:1.32", ImageID: "docker-pullable://busybox@" + busyboxDigest},
+		}
+		return workload
+	}
+
+	newDebuggerVulnerabilityReport := func(hash string) *starboardv1alpha1.VulnerabilityReport {
+		report := newVulnerabilityReport(hash, time.Now())
+		report.Name = "pod-nginx-debugger"
+		report.Labels[kube.LabelContainerName] = "debugger"
+		return report
+	}
+
+	listScanJobs := func(t *testing.T, podController *pod.PodController) []batchv1.Job {
+		t.Helper()
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		return jobList.Items
+	}
+
+	t.Run("Should scan images of ephemeral containers", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newDebuggedPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		scanJobs := listScanJobs(t, podController)
+		require.Len(t, scanJobs, 1)
+		scanJob := scanJobs[0]
+		assert.Equal(t, "debugger", scanJob.Annotations[etc.AnnotationEphemeralContainerNames])
+		assert.Equal(t, controller.ComputeHash(newPod().Spec), scanJob.Labels[etc.LabelPodSpecHash])
+		assert.JSONEq(t, `{"nginx":"nginx:1.16","debugger":"busybox:1.32"}`,
+			scanJob.Annotations[kube.AnnotationContainerImages])
+		assert.JSONEq(t, fmt.Sprintf(`{"nginx":"%s","debugger":"%s"}`, nginxDigest, busyboxDigest),
+			scanJob.Annotations[etc.AnnotationContainerImageDigests])
+
+		containers := scanJob.Spec.Template.Spec.Containers
+		require.Len(t, containers, 2)
+		assert.Equal(t, "nginx", containers[0].Name)
+		assert.Equal(t, "debugger", containers[1].Name)
+		assert.Equal(t, "busybox:1.32", containers[1].Args[len(containers[1].Args)-1])
+	})
+
+	t.Run("Should scan only ephemeral containers added after Pod was scanned", func(t *testing.T) {
+		hash := controller.ComputeHash(newPod().Spec)
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newDebuggedPod(), newVulnerabilityReport(hash, time.Now()))
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		scanJobs := listScanJobs(t, podController)
+		require.Len(t, scanJobs, 1)
+		scanJob := scanJobs[0]
+		assert.Equal(t, hash, scanJob.Labels[etc.LabelPodSpecHash])
+		assert.Equal(t, "debugger", scanJob.Annotations[etc.AnnotationEphemeralContainerNames])
+		assert.JSONEq(t, `{"debugger":"busybox:1.32"}`, scanJob.Annotations[kube.AnnotationContainerImages])
+
+		containers := scanJob.Spec.Template.Spec.Containers
+		require.Len(t, containers, 1)
+		assert.Equal(t, "debugger", containers[0].Name)
+	})
+
+	t.Run("Should not create scan job when ephemeral containers have VulnerabilityReports", func(t *testing.T) {
+		hash := controller.ComputeHash(newPod().Spec)
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newDebuggedPod(), newVulnerabilityReport(hash, time.Now()), newDebuggerVulnerabilityReport(hash))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+		assert.Len(t, listScanJobs(t, podController), 0)
+	})
+
+	t.Run("Should requeue when image ID of ephemeral container is not reported yet", func(t *testing.T) {
+		workload := newDebuggedPod()
+		workload.Status.EphemeralContainerStatuses = nil
+		podController := newPodController(etc.Operator{
+			Namespace:                "starboard-operator",
+			ReconcileRequeueInterval: time.Minute,
+		}, clock.RealClock{}, workload, newVulnerabilityReport(controller.ComputeHash(newPod().Spec), time.Now()))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: time.Minute}, result)
+		assert.Len(t, listScanJobs(t, podController), 0)
+	})
+}
+
+func TestPodController_PropagateLabels(t *testing.T) {
+	workload := newPod()
+	workload.Labels = map[string]string{
+		"team":                         "payments",
+		"app.kubernetes.io/managed-by": "helm",
+	}
+	workload.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: "apps/v1",
+			Kind:       "ReplicaSet",
+			Name:       "nginx-6d4cf56db6",
+			Controller: pointer.BoolPtr(true),
+		},
+	}
+	owner := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nginx-6d4cf56db6",
+			Namespace: "default",
+			Labels: map[string]string{
+				"team":        "platform",
+				"cost-center": "cc-42",
+			},
+		},
+	}
+
+	podController := newPodController(etc.Operator{
+		Namespace:              "starboard-operator",
+		ScanJobPropagateLabels: "team,cost-center,environment,app.kubernetes.io/managed-by",
+	}, clock.RealClock{}, workload, owner)
+
+	_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+	require.NoError(t, err)
+
+	jobList := &batchv1.JobList{}
+	require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+	require.Len(t, jobList.Items, 1)
+	for _, labels := range []map[string]string{jobList.Items[0].Labels, jobList.Items[0].Spec.Template.Labels} {
+		assert.Equal(t, "payments", labels["team"], "Pod labels take precedence over owner labels")
+		assert.Equal(t, "cc-42", labels["cost-center"], "Missing Pod labels are copied from the owner")
+		assert.NotContains(t, labels, "environment", "Absent labels are not copied")
+		assert.Equal(t, "starboard-operator", labels["app.kubernetes.io/managed-by"], "Operator labels are not overridden")
+	}
+}
+
+func TestPodController_ScanJobLabel(t *testing.T) {
+	config := etc.Operator{
+		Namespace:         "starboard-operator",
+		ScanJobLabelKey:   "example.com/scanner",
+		ScanJobLabelValue: "tenant-a",
+	}
+
+	t.Run("Should label scan job with configured label", func(t *testing.T) {
+		podController := newPodController(config, clock.RealClock{}, newPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList,
+			client.InNamespace("starboard-operator"), client.MatchingLabels{"example.com/scanner": "tenant-a"}))
+		require.Len(t, jobList.Items, 1)
+		assert.NotContains(t, jobList.Items[0].Labels, "app.kubernetes.io/managed-by")
+	})
+
+	t.Run("Should ignore pod of scan job with configured label", func(t *testing.T) {
+		pod := newPod()
+		pod.Labels = map[string]string{"example.com/scanner": "tenant-a"}
+		podController := newPodController(config, clock.RealClock{}, pod)
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList))
+		assert.Empty(t, jobList.Items)
+	})
+
+	t.Run("Should count active scan jobs with configured label", func(t *testing.T) {
+		config := config
+		config.ConcurrentScanJobsLimit = 1
+		labeledJob := newScanJob("scan-labeled")
+		labeledJob.Labels = map[string]string{"example.com/scanner": "tenant-a"}
+		podController := newPodController(config, clock.RealClock{}, newScanJob("scan-default"))
+
+		exceeded, err := podController.IsConcurrentScanJobsLimitExceeded(context.Background())
+		require.NoError(t, err)
+		assert.False(t, exceeded)
+
+		require.NoError(t, podController.Client.Create(context.Background(), labeledJob))
+		exceeded, err = podController.IsConcurrentScanJobsLimitExceeded(context.Background())
+		require.NoError(t, err)
+		assert.True(t, exceeded)
+	})
+}
+
+func TestPodController_InstanceID(t *testing.T) {
+	config := etc.Operator{
+		Namespace:  "starboard-operator",
+		InstanceID: "tenant-a",
+	}
+
+	t.Run("Should label scan job with instance ID", func(t *testing.T) {
+		podController := newPodController(config, clock.RealClock{}, newPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		assert.Equal(t, "tenant-a", jobList.Items[0].Labels[etc.LabelInstanceID])
+		assert.Equal(t, "starboard-operator", jobList.Items[0].Labels["app.kubernetes.io/managed-by"])
+	})
+
+	t.Run("Should not count scan jobs of another instance", func(t *testing.T) {
+		config := config
+		config.ConcurrentScanJobsLimit = 1
+		otherJob := newScanJob("scan-other")
+		otherJob.Labels[etc.LabelInstanceID] = "tenant-b"
+		podController := newPodController(config, clock.RealClock{}, otherJob)
+
+		exceeded, err := podController.IsConcurrentScanJobsLimitExceeded(context.Background())
+		require.NoError(t, err)
+		assert.False(t, exceeded)
+
+		ownJob := newScanJob("scan-own")
+		ownJob.Labels[etc.LabelInstanceID] = "tenant-a"
+		require.NoError(t, podController.Client.Create(context.Background(), ownJob))
+		exceeded, err = podController.IsConcurrentScanJobsLimitExceeded(context.Background())
+		require.NoError(t, err)
+		assert.True(t, exceeded)
+	})
+
+	t.Run("Should ignore pod of scan job created by another instance", func(t *testing.T) {
+		pod := newPod()
+		pod.Labels = map[string]string{
+			"app.kubernetes.io/managed-by": "starboard-operator",
+			etc.LabelInstanceID:            "tenant-b",
+		}
+		podController := newPodController(config, clock.RealClock{}, pod)
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList))
+		assert.Empty(t, jobList.Items)
+	})
+}
+
+func TestPodController_ScanJobsNamespace(t *testing.T) {
+	config := etc.Operator{
+		Namespace:         "starboard-operator",
+		ServiceAccount:    "starboard-operator",
+		ScanJobsNamespace: "starboard-scans",
+	}
+
+	t.Run("Should create scan job and registry credentials in scan jobs namespace", func(t *testing.T) {
+		workload := newPod()
+		workload.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "dockerhub"}}
+		podController := newPodController(config, clock.RealClock{}, workload,
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "dockerhub", Namespace: "default"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"username":"root","password":"s3cret"}}}`),
+				},
+			},
+		)
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList))
+		require.Len(t, jobList.Items, 1)
+		scanJob := jobList.Items[0]
+		assert.Equal(t, "starboard-scans", scanJob.Namespace)
+		assert.Equal(t, "default", scanJob.Labels[kube.LabelResourceNamespace])
+		assert.Empty(t, scanJob.Spec.Template.Spec.ServiceAccountName, "Scan job runs with default service account of its namespace")
+
+		secretList := &corev1.SecretList{}
+		require.NoError(t, podController.Client.List(context.Background(), secretList, client.InNamespace("starboard-scans")))
+		require.Len(t, secretList.Items, 1)
+		assert.Equal(t, secretList.Items[0].Name, scanJob.Spec.Template.Spec.Containers[0].Env[0].ValueFrom.SecretKeyRef.Name)
+	})
+
+	t.Run("Should not create scan job when one exists in scan jobs namespace", func(t *testing.T) {
+		podController := newPodController(config, clock.RealClock{}, newPod())
+		request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}}
+
+		_, err := podController.Reconcile(request)
+		require.NoError(t, err)
+		_, err = podController.Reconcile(request)
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList))
+		assert.Len(t, jobList.Items, 1)
+	})
+
+	t.Run("Should count active scan jobs in scan jobs namespace", func(t *testing.T) {
+		config := config
+		config.ConcurrentScanJobsLimit = 1
+		podController := newPodController(config, clock.RealClock{}, newScanJob("scan-operator-namespace"))
+
+		exceeded, err := podController.IsConcurrentScanJobsLimitExceeded(context.Background())
+		require.NoError(t, err)
+		assert.False(t, exceeded)
+
+		scanJob := newScanJob("scan-scans-namespace")
+		scanJob.Namespace = "starboard-scans"
+		require.NoError(t, podController.Client.Create(context.Background(), scanJob))
+		exceeded, err = podController.IsConcurrentScanJobsLimitExceeded(context.Background())
+		require.NoError(t, err)
+		assert.True(t, exceeded)
+	})
+
+	t.Run("Should ignore Pod in scan jobs namespace which is not a target namespace", func(t *testing.T) {
+		config := config
+		config.TargetNamespaces = "default"
+		podController := newPodController(config, clock.RealClock{})
+
+		assert.True(t, podController.IgnorePodInOperatorNamespace(etc.InstallModeSingleNamespace,
+			types.NamespacedName{Namespace: "starboard-scans", Name: "nginx"}))
+		assert.True(t, podController.IgnorePodInOperatorNamespace(etc.InstallModeSingleNamespace,
+			types.NamespacedName{Namespace: "starboard-operator", Name: "nginx"}))
+		assert.False(t, podController.IgnorePodInOperatorNamespace(etc.InstallModeSingleNamespace,
+			types.NamespacedName{Namespace: "default", Name: "nginx"}))
+	})
+}
+
+func TestPodController_ReportScope(t *testing.T) {
+	newReplicaSetPod := func() (*corev1.Pod, *appsv1.ReplicaSet) {
+		workload := newPod()
+		workload.UID = "7b3a9c1e"
+		workload.OwnerReferences = []metav1.OwnerReference{
+			{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       "nginx-6d4cf56db6",
+				UID:        "e2c8f4a6",
+				Controller: pointer.BoolPtr(true),
+			},
+		}
+		return workload, &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-6d4cf56db6", Namespace: "default", UID: "e2c8f4a6"},
+		}
+	}
+
+	testCases := []struct {
+		name              string
+		scope             etc.ReportScope
+		expectedOwnerKind string
+		expectedOwnerName string
+		expectedReport    string
+	}{
+		{
+			name:              "Should attach report to owner of Pod by default",
+			expectedOwnerKind: "ReplicaSet",
+			expectedOwnerName: "nginx-6d4cf56db6",
+			expectedReport:    "replicaset-nginx-6d4cf56db6-nginx",
+		},
+		{
+			name:              "Should attach report to owner of Pod in owner scope",
+			scope:             etc.ReportScopeOwner,
+			expectedOwnerKind: "ReplicaSet",
+			expectedOwnerName: "nginx-6d4cf56db6",
+			expectedReport:    "replicaset-nginx-6d4cf56db6-nginx",
+		},
+		{
+			name:              "Should attach report to Pod in pod scope",
+			scope:             etc.ReportScopePod,
+			expectedOwnerKind: "Pod",
+			expectedOwnerName: "nginx",
+			expectedReport:    "pod-nginx-nginx",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			workload, replicaSet := newReplicaSetPod()
+			podController := newPodController(etc.Operator{
+				Namespace:   "starboard-operator",
+				ReportScope: tc.scope,
+			}, clock.RealClock{}, workload, replicaSet)
+
+			_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+			require.NoError(t, err)
+
+			jobList := &batchv1.JobList{}
+			require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+			require.Len(t, jobList.Items, 1)
+			labels := jobList.Items[0].Labels
+			assert.Equal(t, tc.expectedOwnerKind, labels[kube.LabelResourceKind])
+			assert.Equal(t, tc.expectedOwnerName, labels[kube.LabelResourceName])
+
+			// Complete the scan Job by writing the report of the workload recorded by the scan Job.
+			err = podController.Writer.Write(context.Background(), kube.Object{
+				Kind:      kube.Kind(labels[kube.LabelResourceKind]),
+				Name:      labels[kube.LabelResourceName],
+				Namespace: labels[kube.LabelResourceNamespace],
+			}, reports.WorkloadReport{
+				Hash:            labels[etc.LabelPodSpecHash],
+				Vulnerabilities: map[string]starboardv1alpha1.VulnerabilityScanResult{"nginx": {}},
+			})
+			require.NoError(t, err)
+
+			report := &starboardv1alpha1.VulnerabilityReport{}
+			require.NoError(t, podController.Client.Get(context.Background(),
+				types.NamespacedName{Namespace: "default", Name: tc.expectedReport}, report))
+			owner := metav1.GetControllerOf(report)
+			require.NotNil(t, owner)
+			assert.Equal(t, tc.expectedOwnerKind, owner.Kind)
+			assert.Equal(t, tc.expectedOwnerName, owner.Name)
+
+			// The Pod is not scanned again once its workload has the report.
+			_, err = podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+			require.NoError(t, err)
+			require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+			assert.Len(t, jobList.Items, 1)
+		})
+	}
+}
+
+func TestPodController_ScanRegistries(t *testing.T) {
+	newSidecarPod := func() *corev1.Pod {
+		workload := newPod()
+		workload.Spec.InitContainers = []corev1.Container{
+			{Name: "init", Image: "untrusted.io/busybox:1.32"},
+		}
+		workload.Spec.Containers = append(workload.Spec.Containers,
+			corev1.Container{Name: "sidecar", Image: "untrusted.io/envoy:1.16"})
+		return workload
+	}
+
+	t.Run("Should scan only images from allowed registries", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:          "starboard-operator",
+			ScanDenyRegistries: "untrusted.io",
+		}, clock.RealClock{}, newSidecarPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		containers := jobList.Items[0].Spec.Template.Spec.Containers
+		require.Len(t, containers, 1)
+		assert.Equal(t, "nginx", containers[0].Name)
+	})
+
+	t.Run("Should ignore Pod without images from allowed registries", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:           "starboard-operator",
+			ScanAllowRegistries: "*.corp.example.com",
+		}, clock.RealClock{}, newSidecarPod())
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Empty(t, jobList.Items)
+	})
+}
+
+func TestPodController_ExcludeImages(t *testing.T) {
+	newSidecarPod := func() *corev1.Pod {
+		workload := newPod()
+		workload.Spec.Containers = append(workload.Spec.Containers,
+			corev1.Container{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:1.8.0"})
+		return workload
+	}
+
+	t.Run("Should not scan excluded images", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:     "starboard-operator",
+			ExcludeImages: "*/istio/proxyv2",
+		}, clock.RealClock{}, newSidecarPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		require.Len(t, jobList.Items, 1)
+		containers := jobList.Items[0].Spec.Template.Spec.Containers
+		require.Len(t, containers, 1)
+		assert.Equal(t, "nginx", containers[0].Name)
+	})
+
+	t.Run("Should ignore Pod with only excluded images", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:     "starboard-operator",
+			ExcludeImages: "*/istio/proxyv2,nginx",
+		}, clock.RealClock{}, newSidecarPod())
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Empty(t, jobList.Items)
+	})
+}
+
+func TestPodController_IgnoreFile(t *testing.T) {
+	newConfigMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "starboard-operator"},
+			Data:       map[string]string{".trivyignore": "CVE-2019-1543\n"},
+		}
+	}
+
+	testCases := []struct {
+		name              string
+		config            etc.Operator
+		objects           []runtime.Object
+		expectedConfigMap string
+	}{
+		{
+			name:   "Should not mount ignore file when not configured",
+			config: etc.Operator{Namespace: "starboard-operator"},
+		},
+		{
+			name:              "Should mount configured ignore file",
+			config:            etc.Operator{Namespace: "starboard-operator", TrivyIgnoreFileConfigMap: "trivyignore"},
+			objects:           []runtime.Object{newConfigMap("trivyignore"), newConfigMap("trivyignore-kube-system")},
+			expectedConfigMap: "trivyignore",
+		},
+		{
+			name:              "Should mount ignore file overridden for namespace",
+			config:            etc.Operator{Namespace: "starboard-operator", TrivyIgnoreFileConfigMap: "trivyignore"},
+			objects:           []runtime.Object{newConfigMap("trivyignore"), newConfigMap("trivyignore-default")},
+			expectedConfigMap: "trivyignore-default",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			podController := newPodController(tc.config, clock.RealClock{}, append(tc.objects, newPod())...)
+
+			_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+			require.NoError(t, err)
+
+			jobList := &batchv1.JobList{}
+			require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+			require.Len(t, jobList.Items, 1)
+			var configMaps []string
+			for _, volume := range jobList.Items[0].Spec.Template.Spec.Volumes {
+				if volume.ConfigMap != nil {
+					configMaps = append(configMaps, volume.ConfigMap.Name)
+				}
+			}
+			container := jobList.Items[0].Spec.Template.Spec.Containers[0]
+			if tc.expectedConfigMap == "" {
+				assert.Empty(t, configMaps)
+				assert.NotContains(t, container.Args, "--ignorefile")
+				return
+			}
+			assert.Equal(t, []string{tc.expectedConfigMap}, configMaps)
+			assert.Contains(t, container.Args, "--ignorefile")
+		})
+	}
+}
+
+func TestPodController_SecurityContext(t *testing.T) {
+	podController := newPodController(etc.Operator{
+		Namespace:              "starboard-operator",
+		ScanJobSecurityContext: etc.SecurityContextRestricted,
+	}, clock.RealClock{}, newPod())
+
+	_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+	require.NoError(t, err)
+
+	jobList := &batchv1.JobList{}
+	require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+	require.Len(t, jobList.Items, 1)
+	template := jobList.Items[0].Spec.Template
+	assert.Equal(t, corev1.SeccompProfileRuntimeDefault, template.Annotations[corev1.SeccompPodAnnotationKey])
+	require.NotNil(t, template.Spec.SecurityContext)
+	assert.Equal(t, pointer.BoolPtr(true), template.Spec.SecurityContext.RunAsNonRoot)
+	for _, container := range append(template.Spec.InitContainers, template.Spec.Containers...) {
+		require.NotNil(t, container.SecurityContext, container.Name)
+		assert.Equal(t, pointer.BoolPtr(false), container.SecurityContext.AllowPrivilegeEscalation, container.Name)
+		assert.Equal(t, []corev1.Capability{"ALL"}, container.SecurityContext.Capabilities.Drop, container.Name)
+	}
+}
+
+func TestPodController_ScanOnlyRunning(t *testing.T) {
+	newRunningPod := func() *corev1.Pod {
+		workload := newPod()
+		workload.Status.Phase = corev1.PodRunning
+		workload.Status.ContainerStatuses[0].Ready = true
+		workload.Status.ContainerStatuses[0].State = corev1.ContainerState{
+			Running: &corev1.ContainerStateRunning{},
+		}
+		return workload
+	}
+
+	pendingPod := newPod()
+	pendingPod.Status.Phase = corev1.PodPending
+	pendingPod.Status.ContainerStatuses[0].State = corev1.ContainerState{
+		Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
+	}
+
+	crashingPod := newRunningPod()
+	crashingPod.Status.Conditions[0].Status = corev1.ConditionFalse
+	crashingPod.Status.ContainerStatuses[0].Ready = false
+	crashingPod.Status.ContainerStatuses[0].State = corev1.ContainerState{
+		Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+	}
+
+	testCases := []struct {
+		name            string
+		scanOnlyRunning bool
+		pod             *corev1.Pod
+		expectedResult  ctrl.Result
+		expectedJobs    int
+	}{
+		{
+			name:            "Should create scan job for running pod",
+			scanOnlyRunning: true,
+			pod:             newRunningPod(),
+			expectedResult:  ctrl.Result{},
+			expectedJobs:    1,
+		},
+		{
+			name:            "Should requeue pending pod",
+			scanOnlyRunning: true,
+			pod:             pendingPod,
+			expectedResult:  ctrl.Result{Requeue: true},
+			expectedJobs:    0,
+		},
+		{
+			name:            "Should requeue pod in crash loop",
+			scanOnlyRunning: true,
+			pod:             crashingPod,
+			expectedResult:  ctrl.Result{Requeue: true},
+			expectedJobs:    0,
+		},
+		{
+			name:            "Should create scan job for pod in crash loop when disabled",
+			scanOnlyRunning: false,
+			pod:             crashingPod,
+			expectedResult:  ctrl.Result{},
+			expectedJobs:    1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			podController := newPodController(etc.Operator{
+				Namespace:       "starboard-operator",
+				ScanOnlyRunning: tc.scanOnlyRunning,
+			}, clock.RealClock{}, tc.pod.DeepCopy())
+
+			result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+
+			jobList := &batchv1.JobList{}
+			require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+			assert.Len(t, jobList.Items, tc.expectedJobs)
+		})
+	}
+}
+
+func TestPodController_ScanStatus(t *testing.T) {
+	t.Run("Should set pending scan status when scan job is created", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, newPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		workload := &corev1.Pod{}
+		require.NoError(t, podController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		assert.Equal(t, string(etc.ScanStatusPending), workload.Annotations[etc.AnnotationScanStatus])
+	})
+
+	t.Run("Should set suspended scan status when circuit breaker is open", func(t *testing.T) {
+		now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.NewFakeClock(now.Add(10*time.Minute)), newPod())
+		podController.CircuitBreaker = controller.NewCircuitBreaker(1, time.Hour)
+		podController.CircuitBreaker.RecordFailure(now, "nginx:1.16")
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: 50 * time.Minute}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+		workload := &corev1.Pod{}
+		require.NoError(t, podController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		assert.Equal(t, string(etc.ScanStatusSuspended), workload.Annotations[etc.AnnotationScanStatus])
+	})
+
+	t.Run("Should set image pull back-off scan status when image cannot be pulled", func(t *testing.T) {
+		workload := newPod()
+		workload.Status.Conditions = nil
+		workload.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{
+				Name:  "nginx",
+				Image: "nginx:1.16",
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image \"nginx:1.16\""},
+				},
+			},
+		}
+		podController := newPodController(etc.Operator{
+			Namespace:                "starboard-operator",
+			ReconcileRequeueInterval: time.Minute,
+		}, clock.RealClock{}, workload)
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: time.Minute}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Len(t, jobList.Items, 0)
+		require.NoError(t, podController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		assert.Equal(t, string(etc.ScanStatusImagePullBackOff), workload.Annotations[etc.AnnotationScanStatus])
+		events := podController.Recorder.(*record.FakeRecorder).Events
+		require.Len(t, events, 1)
+		assert.Equal(t, "Warning ImagePullBackOff Skipped scanning images nginx:1.16 which cannot be pulled", <-events)
+	})
+
+	t.Run("Should not set scan status in dry run", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+			DryRun:    true,
+		}, clock.RealClock{}, newPod())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		workload := &corev1.Pod{}
+		require.NoError(t, podController.Client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		assert.NotContains(t, workload.Annotations, etc.AnnotationScanStatus)
+	})
+}
+
+func TestPodController_ShareReportsByDigest(t *testing.T) {
+	workload := newPod()
+	stagingWorkload := newPod()
+	stagingWorkload.Namespace = "staging"
+
+	listScanJobs := func(t *testing.T, podController *pod.PodController) []batchv1.Job {
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		return jobList.Items
+	}
+
+	t.Run("Should create one scan job for the same digest across namespaces", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:            "starboard-operator",
+			ShareReportsByDigest: true,
+		}, clock.RealClock{}, workload.DeepCopy(), stagingWorkload.DeepCopy())
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+		jobs := listScanJobs(t, podController)
+		require.Len(t, jobs, 1)
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "staging", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.True(t, result.Requeue, "Pod is requeued until the pending scan job writes the report")
+		require.Len(t, listScanJobs(t, podController), 1)
+
+		// Complete the scan Job of the default namespace.
+		report := starboardv1alpha1.VulnerabilityScanResult{
+			Scanner: starboardv1alpha1.Scanner{Name: "Trivy"},
+			Summary: starboardv1alpha1.VulnerabilitySummary{CriticalCount: 1},
+			Vulnerabilities: []starboardv1alpha1.Vulnerability{
+				{VulnerabilityID: "CVE-2020-3810", Severity: starboardv1alpha1.SeverityCritical},
+			},
+		}
+		err = podController.Writer.Write(context.Background(), kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"},
+			reports.WorkloadReport{
+				Hash:            controller.ComputeHash(workload.Spec),
+				Vulnerabilities: map[string]starboardv1alpha1.VulnerabilityScanResult{"nginx": report},
+				Digests:         kube.ContainerImages{"nginx": nginxDigest},
+			})
+		require.NoError(t, err)
+		require.NoError(t, podController.Client.Delete(context.Background(), &jobs[0]))
+
+		result, err = podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "staging", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+		assert.Empty(t, listScanJobs(t, podController))
+
+		sharedReport := &starboardv1alpha1.VulnerabilityReport{}
+		require.NoError(t, podController.Client.Get(context.Background(),
+			types.NamespacedName{Namespace: "staging", Name: "pod-nginx-nginx"}, sharedReport))
+		assert.Equal(t, report, sharedReport.Report)
+		assert.Equal(t, nginxDigest, sharedReport.Annotations[etc.AnnotationImageDigest])
+
+		sharedWorkload := &corev1.Pod{}
+		require.NoError(t, podController.Client.Get(context.Background(),
+			types.NamespacedName{Namespace: "staging", Name: "nginx"}, sharedWorkload))
+		assert.Equal(t, string(etc.ScanStatusCompleted), sharedWorkload.Annotations[etc.AnnotationScanStatus])
+	})
+
+	t.Run("Should not share reports in dry-run mode", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:            "starboard-operator",
+			ShareReportsByDigest: true,
+			DryRun:               true,
+		}, clock.RealClock{}, workload.DeepCopy(), stagingWorkload.DeepCopy())
+		err := podController.Writer.Write(context.Background(), kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"},
+			reports.WorkloadReport{
+				Hash: controller.ComputeHash(workload.Spec),
+				Vulnerabilities: map[string]starboardv1alpha1.VulnerabilityScanResult{
+					"nginx": {Scanner: starboardv1alpha1.Scanner{Name: "Trivy"}},
+				},
+				Digests: kube.ContainerImages{"nginx": nginxDigest},
+			})
+		require.NoError(t, err)
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "staging", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+		assert.Empty(t, listScanJobs(t, podController))
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, podController.Client.List(context.Background(), reportList, client.InNamespace("staging")))
+		assert.Empty(t, reportList.Items)
+
+		sharedWorkload := &corev1.Pod{}
+		require.NoError(t, podController.Client.Get(context.Background(),
+			types.NamespacedName{Namespace: "staging", Name: "nginx"}, sharedWorkload))
+		assert.NotContains(t, sharedWorkload.Annotations, etc.AnnotationScanStatus)
+	})
+
+	t.Run("Should create scan job for each namespace when disabled", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace: "starboard-operator",
+		}, clock.RealClock{}, workload.DeepCopy(), stagingWorkload.DeepCopy())
+
+		for _, namespace := range []string{"default", "staging"} {
+			_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: "nginx"}})
+			require.NoError(t, err)
+		}
+		assert.Len(t, listScanJobs(t, podController), 2)
+	})
+}
+
+func TestPodController_DigestCache(t *testing.T) {
+	now := time.Now()
+	workload := newPod()
+	stagingWorkload := newPod()
+	stagingWorkload.Namespace = "staging"
+	report := starboardv1alpha1.VulnerabilityScanResult{
+		Scanner: starboardv1alpha1.Scanner{Name: "Trivy"},
+		Summary: starboardv1alpha1.VulnerabilitySummary{CriticalCount: 1},
+		Vulnerabilities: []starboardv1alpha1.Vulnerability{
+			{VulnerabilityID: "CVE-2020-3810", Severity: starboardv1alpha1.SeverityCritical},
+		},
+	}
+
+	// newPodControllerWithReport returns the controller with the report of the nginx image of the default
+	// namespace, whose digest is cached as scanned at the given time unless it's zero.
+	newPodControllerWithReport := func(t *testing.T, config etc.Operator, scannedAt time.Time) *pod.PodController {
+		podController := newPodController(config, clock.NewFakeClock(now), workload.DeepCopy(), stagingWorkload.DeepCopy())
+		err := podController.Writer.Write(context.Background(), kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"},
+			reports.WorkloadReport{
+				Hash:            controller.ComputeHash(workload.Spec),
+				Vulnerabilities: map[string]starboardv1alpha1.VulnerabilityScanResult{"nginx": report},
+				Digests:         kube.ContainerImages{"nginx": nginxDigest},
+			})
+		require.NoError(t, err)
+		podController.DigestCache, err = controller.NewDigestCache(10, "")
+		require.NoError(t, err)
+		if !scannedAt.IsZero() {
+			require.NoError(t, podController.DigestCache.Add(scannedAt, nginxDigest))
+		}
+		return podController
+	}
+
+	listScanJobs := func(t *testing.T, podController *pod.PodController) []batchv1.Job {
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		return jobList.Items
+	}
+
+	t.Run("Should share reports instead of creating scan job when digest is cached", func(t *testing.T) {
+		podController := newPodControllerWithReport(t, etc.Operator{
+			Namespace: "starboard-operator",
+		}, now.Add(-time.Minute))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "staging", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+		assert.Empty(t, listScanJobs(t, podController))
+
+		sharedReport := &starboardv1alpha1.VulnerabilityReport{}
+		require.NoError(t, podController.Client.Get(context.Background(),
+			types.NamespacedName{Namespace: "staging", Name: "pod-nginx-nginx"}, sharedReport))
+		assert.Equal(t, report, sharedReport.Report)
+	})
+
+	t.Run("Should requeue instead of creating scan job when digest is cached but report is not found", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:                "starboard-operator",
+			ReconcileRequeueInterval: 30 * time.Second,
+			ScannedDigestGracePeriod: 5 * time.Minute,
+		}, clock.NewFakeClock(now), stagingWorkload.DeepCopy())
+		podController.StartTime = now.Add(-time.Minute)
+		var err error
+		podController.DigestCache, err = controller.NewDigestCache(10, "")
+		require.NoError(t, err)
+		require.NoError(t, podController.DigestCache.Add(now.Add(-time.Minute), nginxDigest))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "staging", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: 30 * time.Second}, result)
+		assert.Empty(t, listScanJobs(t, podController))
+	})
+
+	t.Run("Should evict digest and create scan job when digest is cached but report is gone after grace period", func(t *testing.T) {
+		podController := newPodController(etc.Operator{
+			Namespace:                "starboard-operator",
+			ReconcileRequeueInterval: 30 * time.Second,
+			ScannedDigestGracePeriod: 5 * time.Minute,
+		}, clock.NewFakeClock(now), stagingWorkload.DeepCopy())
+		podController.StartTime = now.Add(-time.Hour)
+		var err error
+		podController.DigestCache, err = controller.NewDigestCache(10, "")
+		require.NoError(t, err)
+		require.NoError(t, podController.DigestCache.Add(now.Add(-time.Minute), nginxDigest))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "staging", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+		assert.Len(t, listScanJobs(t, podController), 1)
+		_, ok := podController.DigestCache.Get(nginxDigest)
+		assert.False(t, ok, "Digest is evicted")
+	})
+
+	t.Run("Should not share reports when digest is cached in dry-run mode", func(t *testing.T) {
+		podController := newPodControllerWithReport(t, etc.Operator{
+			Namespace: "starboard-operator",
+			DryRun:    true,
+		}, now.Add(-time.Minute))
+
+		result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "staging", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+		assert.Empty(t, listScanJobs(t, podController))
+
+		reportList := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, podController.Client.List(context.Background(), reportList, client.InNamespace("staging")))
+		assert.Empty(t, reportList.Items)
+	})
+
+	t.Run("Should create scan job when digest is not cached", func(t *testing.T) {
+		podController := newPodControllerWithReport(t, etc.Operator{
+			Namespace: "starboard-operator",
+		}, time.Time{})
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "staging", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Len(t, listScanJobs(t, podController), 1)
+	})
+
+	t.Run("Should create scan job when digest was scanned before report TTL", func(t *testing.T) {
+		podController := newPodControllerWithReport(t, etc.Operator{
+			Namespace:     "starboard-operator",
+			ScanReportTTL: time.Hour,
+		}, now.Add(-2*time.Hour))
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "staging", Name: "nginx"}})
+		require.NoError(t, err)
+		assert.Len(t, listScanJobs(t, podController), 1)
+	})
+}
+
+func TestPodController_Rescan(t *testing.T) {
+	ctx := context.Background()
+	hash := controller.ComputeHash(newPod().Spec)
+	podController := newPodController(etc.Operator{
+		Namespace: "starboard-operator",
+	}, clock.RealClock{}, newPod(), newVulnerabilityReport(hash, time.Now()))
+
+	// reconcile annotates the Pod with the specified rescan nonce, reconciles the Pod and
+	// returns scan Jobs which exist afterwards. Scan Jobs are deleted as if they completed.
+	reconcile := func(t *testing.T, nonce string) []batchv1.Job {
+		t.Helper()
+		workload := &corev1.Pod{}
+		require.NoError(t, podController.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		if nonce != "" {
+			if workload.Annotations == nil {
+				workload.Annotations = make(map[string]string)
+			}
+			workload.Annotations[etc.AnnotationRescan] = nonce
+			require.NoError(t, podController.Client.Update(ctx, workload))
+		}
+
+		_, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}})
+		require.NoError(t, err)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(ctx, jobList, client.InNamespace("starboard-operator")))
+		for i := range jobList.Items {
+			require.NoError(t, podController.Client.Delete(ctx, &jobList.Items[i]))
+		}
+		return jobList.Items
+	}
+
+	t.Run("Should not create scan job when Pod has VulnerabilityReports", func(t *testing.T) {
+		assert.Empty(t, reconcile(t, ""))
+	})
+
+	t.Run("Should create scan job when rescan is requested", func(t *testing.T) {
+		jobs := reconcile(t, "1")
+		require.Len(t, jobs, 1)
+		assert.Equal(t, "1", jobs[0].Annotations[etc.AnnotationRescan])
+
+		workload := &corev1.Pod{}
+		require.NoError(t, podController.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "nginx"}, workload))
+		assert.Equal(t, "1", workload.Annotations[etc.AnnotationRescanProcessed])
+	})
+
+	t.Run("Should not create scan job when rescan is processed", func(t *testing.T) {
+		assert.Empty(t, reconcile(t, "1"))
+	})
+
+	t.Run("Should create scan job when rescan nonce changes", func(t *testing.T) {
+		jobs := reconcile(t, "2")
+		require.Len(t, jobs, 1)
+		assert.Equal(t, "2", jobs[0].Annotations[etc.AnnotationRescan])
+	})
+}
+
+func TestNewScanLabelPredicate(t *testing.T) {
+	newLabeledPod := func(labels map[string]string) *corev1.Pod {
+		workload := newPod()
+		workload.Labels = labels
+		return workload
+	}
+
+	testCases := []struct {
+		name     string
+		selector string
+		labels   map[string]string
+		expected bool
+	}{
+		{
+			name:     "Should match any Pod when selector is not set",
+			expected: true,
+		},
+		{
+			name:     "Should match Pod with selected labels",
+			selector: "scan=true",
+			labels:   map[string]string{"scan": "true", "app": "nginx"},
+			expected: true,
+		},
+		{
+			name:     "Should not match Pod with other label value",
+			selector: "scan=true",
+			labels:   map[string]string{"scan": "false"},
+			expected: false,
+		},
+		{
+			name:     "Should not match Pod without labels",
+			selector: "scan=true",
+			expected: false,
+		},
+		{
+			name:     "Should match Pod with set-based selector",
+			selector: "team in (payments,search),!legacy",
+			labels:   map[string]string{"team": "search"},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			selector, err := etc.Operator{ScanLabelSelector: tc.selector}.GetScanLabelSelector()
+			require.NoError(t, err)
+			p := pod.NewScanLabelPredicate(selector)
+			workload := newLabeledPod(tc.labels)
+
+			assert.Equal(t, tc.expected, p.Create(event.CreateEvent{Meta: workload, Object: workload}))
+			assert.Equal(t, tc.expected, p.Update(event.UpdateEvent{MetaOld: newPod(), ObjectOld: newPod(), MetaNew: workload, ObjectNew: workload}))
+			assert.Equal(t, tc.expected, p.Delete(event.DeleteEvent{Meta: workload, Object: workload}))
+			assert.Equal(t, tc.expected, p.Generic(event.GenericEvent{Meta: workload, Object: workload}))
+		})
+	}
+}
+
+func TestPodController_ScanLabelSelector(t *testing.T) {
+	t.Run("Should not scan Pod not matching selector enqueued through namespace watch", func(t *testing.T) {
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "default",
+				Labels: map[string]string{"starboard.aquasecurity.github.io/scan": "true"},
+			},
+		}
+		podController := newPodController(etc.Operator{
+			Namespace:               "starboard-operator",
+			ScanLabelSelector:       "scan=true",
+			TargetNamespaceSelector: "starboard.aquasecurity.github.io/scan=true",
+		}, clock.RealClock{}, namespace, newPod())
+
+		requests := podController.GetPodRequestsForNamespace(handler.MapObject{Meta: namespace, Object: namespace})
+		require.Len(t, requests, 1)
+		result, err := podController.Reconcile(requests[0])
+		require.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, result)
+
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(context.Background(), jobList, client.InNamespace("starboard-operator")))
+		assert.Empty(t, jobList.Items)
+	})
+}
+
+func TestPodController_IsNamespaceSelected(t *testing.T) {
+	newNamespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		selector         string
+		namespace        string
+		expectedSelected bool
+	}{
+		{
+			name:             "Should select any namespace when selector is not set",
+			namespace:        "default",
+			expectedSelected: true,
+		},
+		{
+			name:             "Should select namespace matching selector",
+			selector:         "starboard.aquasecurity.github.io/scan=true",
+			namespace:        "dev",
+			expectedSelected: true,
+		},
+		{
+			name:             "Should not select namespace not matching selector",
+			selector:         "starboard.aquasecurity.github.io/scan=true",
+			namespace:        "default",
+			expectedSelected: false,
+		},
+		{
+			name:             "Should not select namespace that does not exist",
+			selector:         "starboard.aquasecurity.github.io/scan=true",
+			namespace:        "ghost",
+			expectedSelected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			podController := newPodController(etc.Operator{
+				Namespace:               "starboard-operator",
+				TargetNamespaceSelector: tc.selector,
+			}, clock.RealClock{},
+				newNamespace("default", nil),
+				newNamespace("dev", map[string]string{"starboard.aquasecurity.github.io/scan": "true"}),
+			)
+
+			selected, err := podController.IsNamespaceSelected(context.Background(), tc.namespace)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedSelected, selected)
+		})
+	}
+}
+
+func TestPodController_GetPodRequestsForSecret(t *testing.T) {
+	newPodWithServiceAccount := func(name, serviceAccountName string, imagePullSecrets ...string) *corev1.Pod {
+		workload := newPod()
+		workload.Name = name
+		workload.Spec.ServiceAccountName = serviceAccountName
+		for _, secretName := range imagePullSecrets {
+			workload.Spec.ImagePullSecrets = append(workload.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: secretName})
+		}
+		return workload
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-credentials", Namespace: "default"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+	}
+
+	t.Run("Should enqueue Pods using image pull secret directly or through service account", func(t *testing.T) {
+		otherNamespacePod := newPodWithServiceAccount("nginx-other", "", "registry-credentials")
+		otherNamespacePod.Namespace = "dev"
+		podController := newPodController(etc.Operator{Namespace: "starboard-operator"}, clock.RealClock{},
+			&corev1.ServiceAccount{
+				ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: "default"},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-credentials"}},
+			},
+			&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: "builder", Namespace: "default"},
+			},
+			newPodWithServiceAccount("nginx-direct", "builder", "other-credentials", "registry-credentials"),
+			newPodWithServiceAccount("nginx-default-sa", ""),
+			newPodWithServiceAccount("nginx-unrelated", "builder", "other-credentials"),
+			otherNamespacePod,
+		)
+
+		requests := podController.GetPodRequestsForSecret(handler.MapObject{Meta: secret, Object: secret})
+		assert.ElementsMatch(t, []reconcile.Request{
+			{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx-direct"}},
+			{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx-default-sa"}},
+		}, requests)
+	})
+
+	t.Run("Should close circuits of images of enqueued Pods", func(t *testing.T) {
+		now := time.Now()
+		podController := newPodController(etc.Operator{Namespace: "starboard-operator"}, clock.RealClock{},
+			newPodWithServiceAccount("nginx", "", "registry-credentials"))
+		podController.CircuitBreaker = controller.NewCircuitBreaker(1, time.Hour)
+		podController.CircuitBreaker.RecordFailure(now, "nginx:1.16")
+
+		requests := podController.GetPodRequestsForSecret(handler.MapObject{Meta: secret, Object: secret})
+		assert.Len(t, requests, 1)
+		assert.Equal(t, time.Duration(0), podController.CircuitBreaker.Allow(now, []string{"nginx:1.16"}))
+	})
+
+	t.Run("Should not enqueue Pods not matching scan label selector", func(t *testing.T) {
+		now := time.Now()
+		labeledPod := newPodWithServiceAccount("nginx-labeled", "", "registry-credentials")
+		labeledPod.Labels = map[string]string{"scan": "true"}
+		labeledPod.Spec.Containers[0].Image = "nginx:1.17"
+		podController := newPodController(etc.Operator{Namespace: "starboard-operator", ScanLabelSelector: "scan=true"},
+			clock.RealClock{}, newPodWithServiceAccount("nginx", "", "registry-credentials"), labeledPod)
+		podController.CircuitBreaker = controller.NewCircuitBreaker(1, time.Hour)
+		podController.CircuitBreaker.RecordFailure(now, "nginx:1.16")
+
+		requests := podController.GetPodRequestsForSecret(handler.MapObject{Meta: secret, Object: secret})
+		assert.Equal(t, []reconcile.Request{
+			{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx-labeled"}},
+		}, requests)
+		assert.NotEqual(t, time.Duration(0), podController.CircuitBreaker.Allow(now, []string{"nginx:1.16"}),
+			"Circuit of image of Pod not matching selector is not reset")
+	})
+}
+
+func TestNewImagePullSecretPredicate(t *testing.T) {
+	newSecret := func(secretType corev1.SecretType, data string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry-credentials", Namespace: "default"},
+			Type:       secretType,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(data)},
+		}
+	}
+	p := pod.NewImagePullSecretPredicate()
+
+	t.Run("Should match created image pull secret", func(t *testing.T) {
+		secret := newSecret(corev1.SecretTypeDockerConfigJson, `{"auths":{}}`)
+		assert.True(t, p.Create(event.CreateEvent{Meta: secret, Object: secret}))
+		secret = newSecret(corev1.SecretTypeDockercfg, `{}`)
+		assert.True(t, p.Create(event.CreateEvent{Meta: secret, Object: secret}))
+	})
+
+	t.Run("Should not match other secret", func(t *testing.T) {
+		secret := newSecret(corev1.SecretTypeOpaque, `{}`)
+		assert.False(t, p.Create(event.CreateEvent{Meta: secret, Object: secret}))
+	})
+
+	t.Run("Should match image pull secret whose credentials changed", func(t *testing.T) {
+		oldSecret := newSecret(corev1.SecretTypeDockerConfigJson, `{"auths":{"index.docker.io":{"auth":"b2xkOnBhc3M="}}}`)
+		newSecret := newSecret(corev1.SecretTypeDockerConfigJson, `{"auths":{"index.docker.io":{"auth":"bmV3OnBhc3M="}}}`)
+		assert.True(t, p.Update(event.UpdateEvent{MetaOld: oldSecret, ObjectOld: oldSecret, MetaNew: newSecret, ObjectNew: newSecret}))
+	})
+
+	t.Run("Should not match image pull secret whose credentials did not change", func(t *testing.T) {
+		oldSecret := newSecret(corev1.SecretTypeDockerConfigJson, `{"auths":{}}`)
+		newSecret := newSecret(corev1.SecretTypeDockerConfigJson, `{"auths":{}}`)
+		newSecret.Labels = map[string]string{"team": "payments"}
+		assert.False(t, p.Update(event.UpdateEvent{MetaOld: oldSecret, ObjectOld: oldSecret, MetaNew: newSecret, ObjectNew: newSecret}))
+	})
+
+	t.Run("Should not match deleted image pull secret", func(t *testing.T) {
+		secret := newSecret(corev1.SecretTypeDockerConfigJson, `{"auths":{}}`)
+		assert.False(t, p.Delete(event.DeleteEvent{Meta: secret, Object: secret}))
+	})
+}
@@ -0,0 +1,116 @@
+package pod
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/resources"
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RegistryRateLimiter limits the rate of creating scan Jobs for images of the same registry host, so that
+// scanners do not trip rate limits of registries, e.g. Docker Hub. Each registry host has its own token
+// bucket, which holds up to the configured count of tokens and is refilled at the configured rate.
+type RegistryRateLimiter struct {
+	limit   etc.RateLimit
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewRegistryRateLimiter(limit etc.RateLimit) *RegistryRateLimiter {
+	return &RegistryRateLimiter{
+		limit:   limit,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Reserve takes one token from buckets of all the specified registry hosts at the given time if each
+// of them has a token. Otherwise, no tokens are taken and the delay after which all of them will have
+// a token is returned.
+func (l *RegistryRateLimiter) Reserve(now time.Time, hosts []string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tokenInterval := l.limit.Interval / time.Duration(l.limit.Count)
+	var delay time.Duration
+	for _, host := range hosts {
+		bucket := l.refill(host, now)
+		if bucket.tokens >= 1 {
+			continue
+		}
+		if hostDelay := time.Duration((1 - bucket.tokens) * float64(tokenInterval)); hostDelay > delay {
+			delay = hostDelay
+		}
+	}
+	if delay > 0 {
+		return delay
+	}
+	for _, host := range hosts {
+		l.buckets[host].tokens--
+	}
+	return 0
+}
+
+// Release puts back tokens reserved for the specified registry hosts, e.g. when the scan Job could not be
+// created. Buckets are not filled beyond the configured count of tokens.
+func (l *RegistryRateLimiter) Release(hosts []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, host := range hosts {
+		bucket, ok := l.buckets[host]
+		if !ok {
+			continue
+		}
+		bucket.tokens++
+		if bucket.tokens > float64(l.limit.Count) {
+			bucket.tokens = float64(l.limit.Count)
+		}
+	}
+}
+
+// refill adds tokens accumulated since the last refill to the bucket of the specified host.
+// The bucket of a host seen for the first time is full.
+func (l *RegistryRateLimiter) refill(host string, now time.Time) *tokenBucket {
+	bucket, ok := l.buckets[host]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(l.limit.Count), last: now}
+		l.buckets[host] = bucket
+		return bucket
+	}
+	if elapsed := now.Sub(bucket.last); elapsed > 0 {
+		bucket.tokens += float64(l.limit.Count) * float64(elapsed) / float64(l.limit.Interval)
+		if bucket.tokens > float64(l.limit.Count) {
+			bucket.tokens = float64(l.limit.Count)
+		}
+		bucket.last = now
+	}
+	return bucket
+}
+
+// GetRegistryHosts returns sorted registry hosts of images of the specified PodSpec without duplicates,
+// e.g. index.docker.io for images hosted on Docker Hub. Images with invalid references are ignored.
+func GetRegistryHosts(spec corev1.PodSpec) []string {
+	unique := make(map[string]bool)
+	for _, image := range resources.GetContainerImagesFromPodSpec(spec) {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			continue
+		}
+		unique[ref.Context().RegistryStr()] = true
+	}
+	var hosts []string
+	for host := range unique {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
@@ -0,0 +1,175 @@
+package pod_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller/pod"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRegistryRateLimiter_Reserve(t *testing.T) {
+	now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+	dockerHub := []string{"index.docker.io"}
+	quay := []string{"quay.io"}
+
+	t.Run("Should allow burst up to count and then spread reservations", func(t *testing.T) {
+		limiter := pod.NewRegistryRateLimiter(etc.RateLimit{Count: 2, Interval: time.Minute})
+
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now, dockerHub))
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now, dockerHub))
+		assert.Equal(t, 30*time.Second, limiter.Reserve(now, dockerHub))
+		assert.Equal(t, 20*time.Second, limiter.Reserve(now.Add(10*time.Second), dockerHub))
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now.Add(30*time.Second), dockerHub))
+		assert.Equal(t, 30*time.Second, limiter.Reserve(now.Add(30*time.Second), dockerHub))
+	})
+
+	t.Run("Should limit registry hosts independently", func(t *testing.T) {
+		limiter := pod.NewRegistryRateLimiter(etc.RateLimit{Count: 1, Interval: time.Minute})
+
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now, dockerHub))
+		assert.Equal(t, time.Minute, limiter.Reserve(now, dockerHub))
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now, quay))
+	})
+
+	t.Run("Should not take tokens unless all registry hosts have them", func(t *testing.T) {
+		limiter := pod.NewRegistryRateLimiter(etc.RateLimit{Count: 1, Interval: time.Minute})
+
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now, dockerHub))
+		assert.Equal(t, time.Minute, limiter.Reserve(now, append(dockerHub, quay...)))
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now, quay), "Token of quay.io is not taken")
+	})
+}
+
+func TestRegistryRateLimiter_Release(t *testing.T) {
+	now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+	dockerHub := []string{"index.docker.io"}
+
+	t.Run("Should put back released tokens", func(t *testing.T) {
+		limiter := pod.NewRegistryRateLimiter(etc.RateLimit{Count: 1, Interval: time.Minute})
+
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now, dockerHub))
+		limiter.Release(dockerHub)
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now, dockerHub))
+		assert.Equal(t, time.Minute, limiter.Reserve(now, dockerHub))
+	})
+
+	t.Run("Should not fill buckets beyond count", func(t *testing.T) {
+		limiter := pod.NewRegistryRateLimiter(etc.RateLimit{Count: 1, Interval: time.Minute})
+
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now, dockerHub))
+		limiter.Release(dockerHub)
+		limiter.Release(dockerHub)
+		assert.Equal(t, time.Duration(0), limiter.Reserve(now, dockerHub))
+		assert.Equal(t, time.Minute, limiter.Reserve(now, dockerHub))
+	})
+}
+
+func TestGetRegistryHosts(t *testing.T) {
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			{Name: "init", Image: "busybox:1.32"},
+		},
+		Containers: []corev1.Container{
+			{Name: "nginx", Image: "nginx:1.16"},
+			{Name: "redis", Image: "quay.io/bitnami/redis:6.0"},
+			{Name: "app", Image: "registry.example.com:5000/app@sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c"},
+			{Name: "invalid", Image: "INVALID"},
+		},
+	}
+	assert.Equal(t, []string{"index.docker.io", "quay.io", "registry.example.com:5000"}, pod.GetRegistryHosts(spec))
+}
+
+func TestPodController_RegistryRateLimit(t *testing.T) {
+	ctx := context.Background()
+	fakeClock := clock.NewFakeClock(time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC))
+
+	// A burst of Pods running images hosted on Docker Hub.
+	var objects []runtime.Object
+	for i := 0; i < 5; i++ {
+		workload := newPod()
+		workload.Name = fmt.Sprintf("nginx-%d", i)
+		objects = append(objects, workload)
+	}
+	podController := newPodController(etc.Operator{Namespace: "starboard-operator"}, fakeClock, objects...)
+	podController.RegistryRateLimiter = pod.NewRegistryRateLimiter(etc.RateLimit{Count: 2, Interval: time.Minute})
+
+	reconcileAll := func(t *testing.T) []ctrl.Result {
+		t.Helper()
+		var results []ctrl.Result
+		for i := 0; i < 5; i++ {
+			result, err := podController.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{
+				Namespace: "default",
+				Name:      fmt.Sprintf("nginx-%d", i),
+			}})
+			require.NoError(t, err)
+			results = append(results, result)
+		}
+		return results
+	}
+
+	countScanJobs := func(t *testing.T) int {
+		t.Helper()
+		jobList := &batchv1.JobList{}
+		require.NoError(t, podController.Client.List(ctx, jobList, client.InNamespace("starboard-operator")))
+		return len(jobList.Items)
+	}
+
+	results := reconcileAll(t)
+	assert.Equal(t, 2, countScanJobs(t), "Scan jobs are created up to the burst")
+	assert.Equal(t, ctrl.Result{RequeueAfter: 30 * time.Second}, results[2])
+
+	fakeClock.Step(30 * time.Second)
+	reconcileAll(t)
+	assert.Equal(t, 3, countScanJobs(t), "One more scan job is created after the refill interval")
+
+	fakeClock.Step(time.Minute)
+	reconcileAll(t)
+	assert.Equal(t, 5, countScanJobs(t))
+}
+
+// failingJobClient fails to create the first scan Job.
+type failingJobClient struct {
+	client.Client
+	failed bool
+}
+
+func (c *failingJobClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*batchv1.Job); ok && !c.failed {
+		c.failed = true
+		return errors.New("internal error")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestPodController_RegistryRateLimit_CreateFailure(t *testing.T) {
+	ctx := context.Background()
+	fakeClock := clock.NewFakeClock(time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC))
+	podController := newPodController(etc.Operator{Namespace: "starboard-operator"}, fakeClock, newPod())
+	podController.Client = &failingJobClient{Client: podController.Client}
+	podController.RegistryRateLimiter = pod.NewRegistryRateLimiter(etc.RateLimit{Count: 1, Interval: time.Minute})
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: newPod().Name}}
+
+	_, err := podController.Reconcile(request)
+	require.Error(t, err)
+
+	result, err := podController.Reconcile(request)
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result, "Token is not consumed by the failed attempt")
+
+	jobList := &batchv1.JobList{}
+	require.NoError(t, podController.Client.List(ctx, jobList, client.InNamespace("starboard-operator")))
+	assert.Len(t, jobList.Items, 1)
+}
@@ -0,0 +1,122 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	log = ctrl.Log.WithName("scheduler")
+)
+
+// Scheduler rescans all Pods in target namespaces on the configured schedule, independently of Pod events.
+// Rescans are requested by annotating Pods with etc.AnnotationRescan, so that scan Jobs are created by the
+// PodController within the limit of concurrent scan Jobs. It complements rescans of expired reports.
+type Scheduler struct {
+	Config   etc.Operator
+	Client   client.Client
+	Clock    clock.Clock
+	Schedule cron.Schedule
+}
+
+// Start triggers rescans at activation times of the schedule until the stop channel is closed. Failed
+// rescans are logged and retried at the next activation time. The Scheduler implements manager.Runnable,
+// which is run only by the leader when leader election is enabled.
+func (s *Scheduler) Start(stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	log.Info("Starting scheduler", "schedule", s.Config.ScanCron)
+	for {
+		now := s.Clock.Now()
+		next := s.Schedule.Next(now)
+		select {
+		case <-stop:
+			return nil
+		case <-s.Clock.After(next.Sub(now)):
+		}
+		count, err := s.Trigger(ctx, next)
+		if err != nil {
+			log.Error(err, "Unable to trigger rescan")
+			continue
+		}
+		log.Info("Triggered rescan", "pods", count)
+	}
+}
+
+// Trigger annotates Pods matching the scan label selector in target namespaces with the etc.AnnotationRescan
+// annotation set to the Unix time of the specified activation. Pods in the operator namespace, in excluded
+// namespaces, and Pods which are being deleted are skipped. Returns the number of annotated Pods.
+func (s *Scheduler) Trigger(ctx context.Context, activation time.Time) (int, error) {
+	nonce := strconv.FormatInt(activation.Unix(), 10)
+	selector, err := s.Config.GetScanLabelSelector()
+	if err != nil {
+		return 0, err
+	}
+	namespaces, err := s.GetNamespaces(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, namespace := range namespaces {
+		podList := &corev1.PodList{}
+		err := s.Client.List(ctx, podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector})
+		if err != nil {
+			return count, fmt.Errorf("listing pods: %w", err)
+		}
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			if pod.Namespace == s.Config.Namespace || s.Config.IsNamespaceExcluded(pod.Namespace) ||
+				pod.DeletionTimestamp != nil || pod.Annotations[etc.AnnotationRescan] == nonce {
+				continue
+			}
+			// Do not modify the object that might be cached.
+			updated := pod.DeepCopy()
+			if updated.Annotations == nil {
+				updated.Annotations = make(map[string]string)
+			}
+			updated.Annotations[etc.AnnotationRescan] = nonce
+			err = s.Client.Patch(ctx, updated, client.MergeFrom(pod))
+			if err != nil {
+				return count, fmt.Errorf("annotating pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetNamespaces returns names of namespaces matching the target namespace selector, if set, or target
+// namespaces. A blank name, which stands for all namespaces, is returned if neither is set.
+func (s *Scheduler) GetNamespaces(ctx context.Context) ([]string, error) {
+	selector, err := s.Config.GetTargetNamespaceSelector()
+	if err != nil {
+		return nil, err
+	}
+	if selector == nil {
+		namespaces := s.Config.GetTargetNamespaces()
+		if len(namespaces) == 0 {
+			namespaces = []string{""}
+		}
+		return namespaces, nil
+	}
+	namespaceList := &corev1.NamespaceList{}
+	err = s.Client.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("listing namespaces: %w", err)
+	}
+	var namespaces []string
+	for _, namespace := range namespaceList.Items {
+		namespaces = append(namespaces, namespace.Name)
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,161 @@
+package schedule_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller/schedule"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newPod(namespace, name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func newNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+// getRescanned returns names of Pods annotated with the specified rescan nonce.
+func getRescanned(t *testing.T, c client.Client, nonce string) []string {
+	t.Helper()
+	podList := &corev1.PodList{}
+	require.NoError(t, c.List(context.Background(), podList))
+	var names []string
+	for _, pod := range podList.Items {
+		if pod.Annotations[etc.AnnotationRescan] == nonce {
+			names = append(names, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}.String())
+		}
+	}
+	return names
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	activation := time.Date(2020, 10, 1, 2, 0, 0, 0, time.UTC)
+	nonce := strconv.FormatInt(activation.Unix(), 10)
+
+	testCases := []struct {
+		name              string
+		config            etc.Operator
+		objects           []runtime.Object
+		expectedRescanned []string
+	}{
+		{
+			name:   "Should rescan pods in all namespaces except operator and excluded ones",
+			config: etc.Operator{Namespace: "starboard-operator", ExcludeNamespaces: "kube-system"},
+			objects: []runtime.Object{
+				newPod("default", "nginx"),
+				newPod("dev", "redis"),
+				newPod("kube-system", "coredns"),
+				newPod("starboard-operator", "scan-job"),
+			},
+			expectedRescanned: []string{"default/nginx", "dev/redis"},
+		},
+		{
+			name:   "Should rescan pods in target namespaces",
+			config: etc.Operator{Namespace: "starboard-operator", TargetNamespaces: "dev"},
+			objects: []runtime.Object{
+				newPod("default", "nginx"),
+				newPod("dev", "redis"),
+			},
+			expectedRescanned: []string{"dev/redis"},
+		},
+		{
+			name:   "Should rescan pods in namespaces matching selector",
+			config: etc.Operator{Namespace: "starboard-operator", TargetNamespaceSelector: "scan=true"},
+			objects: []runtime.Object{
+				newNamespace("default", nil),
+				newNamespace("dev", map[string]string{"scan": "true"}),
+				newPod("default", "nginx"),
+				newPod("dev", "redis"),
+			},
+			expectedRescanned: []string{"dev/redis"},
+		},
+		{
+			name:   "Should rescan pods matching scan label selector",
+			config: etc.Operator{Namespace: "starboard-operator", ScanLabelSelector: "scan=true"},
+			objects: []runtime.Object{
+				newPod("default", "nginx"),
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: "redis", Labels: map[string]string{"scan": "true"}}},
+			},
+			expectedRescanned: []string{"dev/redis"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewFakeClient(tc.objects...)
+			scheduler := &schedule.Scheduler{
+				Config: tc.config,
+				Client: fakeClient,
+				Clock:  clock.NewFakeClock(activation),
+			}
+
+			count, err := scheduler.Trigger(context.Background(), activation)
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.expectedRescanned), count)
+			assert.ElementsMatch(t, tc.expectedRescanned, getRescanned(t, fakeClient, nonce))
+
+			count, err = scheduler.Trigger(context.Background(), activation)
+			require.NoError(t, err)
+			assert.Equal(t, 0, count, "Pods already annotated for the activation are skipped")
+		})
+	}
+}
+
+func TestScheduler_Start(t *testing.T) {
+	scanSchedule := mustParse(t, "0 2 * * *")
+	now := time.Date(2020, 10, 1, 1, 30, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(now)
+	fakeClient := fake.NewFakeClient(newPod("default", "nginx"))
+	scheduler := &schedule.Scheduler{
+		Config:   etc.Operator{Namespace: "starboard-operator"},
+		Client:   fakeClient,
+		Clock:    fakeClock,
+		Schedule: scanSchedule,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- scheduler.Start(stop)
+	}()
+
+	// Step to the next activation time.
+	waitForTimer(t, fakeClock)
+	fakeClock.Step(30 * time.Minute)
+	nonce := strconv.FormatInt(time.Date(2020, 10, 1, 2, 0, 0, 0, time.UTC).Unix(), 10)
+	assert.Eventually(t, func() bool {
+		return len(getRescanned(t, fakeClient, nonce)) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The scheduler waits for the activation time on the next day.
+	waitForTimer(t, fakeClock)
+	close(stop)
+	require.NoError(t, <-done)
+}
+
+func mustParse(t *testing.T, spec string) cron.Schedule {
+	t.Helper()
+	scanSchedule, err := cron.ParseStandard(spec)
+	require.NoError(t, err)
+	return scanSchedule
+}
+
+// waitForTimer waits until the scheduler is waiting for the next activation time.
+func waitForTimer(t *testing.T, fakeClock *clock.FakeClock) {
+	t.Helper()
+	require.Eventually(t, fakeClock.HasWaiters, 5*time.Second, 10*time.Millisecond)
+}
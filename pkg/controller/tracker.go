@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ReconcileTracker records results of reconcile requests, so that requests which are pending, i.e. requeued or
+// failed, and their last errors can be reported by the debug endpoint. A request is no longer pending once it's
+// reconciled without being requeued. The nil ReconcileTracker tracks nothing.
+type ReconcileTracker struct {
+	mu      sync.Mutex
+	pending map[trackerKey]*PendingReconcile
+}
+
+type trackerKey struct {
+	controller string
+	request    ctrl.Request
+}
+
+// PendingReconcile describes a reconcile request which has been requeued or failed.
+type PendingReconcile struct {
+	Controller string
+	Namespace  string
+	Name       string
+	// Since is the time of the first result of the request which requeued it.
+	Since time.Time
+	// LastError is the error returned by the last reconcile of the request, if any.
+	LastError string
+	// LastErrorTime is the time of the last error.
+	LastErrorTime time.Time
+	// Failures is the number of errors returned in a row.
+	Failures int
+}
+
+// Record records the result of the specified reconcile request of the named controller at the given time.
+func (t *ReconcileTracker) Record(now time.Time, controller string, req ctrl.Request, result ctrl.Result, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := trackerKey{controller: controller, request: req}
+	if err == nil && !result.Requeue && result.RequeueAfter <= 0 {
+		delete(t.pending, key)
+		return
+	}
+	if t.pending == nil {
+		t.pending = make(map[trackerKey]*PendingReconcile)
+	}
+	p, ok := t.pending[key]
+	if !ok {
+		p = &PendingReconcile{
+			Controller: controller,
+			Namespace:  req.Namespace,
+			Name:       req.Name,
+			Since:      now,
+		}
+		t.pending[key] = p
+	}
+	if err != nil {
+		p.LastError = err.Error()
+		p.LastErrorTime = now
+		p.Failures++
+	} else {
+		p.Failures = 0
+	}
+}
+
+// GetPending returns copies of pending reconcile requests sorted by controller, namespace, and name.
+func (t *ReconcileTracker) GetPending() []PendingReconcile {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pending := make([]PendingReconcile, 0, len(t.pending))
+	for _, p := range t.pending {
+		pending = append(pending, *p)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].Controller != pending[j].Controller {
+			return pending[i].Controller < pending[j].Controller
+		}
+		if pending[i].Namespace != pending[j].Namespace {
+			return pending[i].Namespace < pending[j].Namespace
+		}
+		return pending[i].Name < pending[j].Name
+	})
+	return pending
+}
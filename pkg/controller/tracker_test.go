@@ -0,0 +1,77 @@
+package controller_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestReconcileTracker(t *testing.T) {
+	now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+	nginx := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}}
+	redis := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "cache", Name: "redis"}}
+
+	t.Run("Should track nothing when tracker is nil", func(t *testing.T) {
+		var tracker *controller.ReconcileTracker
+		tracker.Record(now, "pod", nginx, ctrl.Result{}, errors.New("boom"))
+		assert.Empty(t, tracker.GetPending())
+	})
+
+	t.Run("Should track requeued and failed requests", func(t *testing.T) {
+		tracker := &controller.ReconcileTracker{}
+		tracker.Record(now, "pod", nginx, ctrl.Result{RequeueAfter: time.Minute}, nil)
+		tracker.Record(now.Add(time.Minute), "pod", nginx, ctrl.Result{}, errors.New("getting pod from cache: timeout"))
+		tracker.Record(now.Add(2*time.Minute), "pod", nginx, ctrl.Result{}, errors.New("creating scan job: forbidden"))
+		tracker.Record(now, "job", redis, ctrl.Result{Requeue: true}, nil)
+		tracker.Record(now, "pod", redis, ctrl.Result{}, nil)
+
+		assert.Equal(t, []controller.PendingReconcile{
+			{
+				Controller: "job",
+				Namespace:  "cache",
+				Name:       "redis",
+				Since:      now,
+			},
+			{
+				Controller:    "pod",
+				Namespace:     "default",
+				Name:          "nginx",
+				Since:         now,
+				LastError:     "creating scan job: forbidden",
+				LastErrorTime: now.Add(2 * time.Minute),
+				Failures:      2,
+			},
+		}, tracker.GetPending())
+	})
+
+	t.Run("Should reset failures when request is requeued without error", func(t *testing.T) {
+		tracker := &controller.ReconcileTracker{}
+		tracker.Record(now, "pod", nginx, ctrl.Result{}, errors.New("boom"))
+		tracker.Record(now.Add(time.Minute), "pod", nginx, ctrl.Result{Requeue: true}, nil)
+
+		assert.Equal(t, []controller.PendingReconcile{
+			{
+				Controller:    "pod",
+				Namespace:     "default",
+				Name:          "nginx",
+				Since:         now,
+				LastError:     "boom",
+				LastErrorTime: now,
+				Failures:      0,
+			},
+		}, tracker.GetPending())
+	})
+
+	t.Run("Should forget request once it's reconciled", func(t *testing.T) {
+		tracker := &controller.ReconcileTracker{}
+		tracker.Record(now, "pod", nginx, ctrl.Result{}, errors.New("boom"))
+		tracker.Record(now.Add(time.Minute), "pod", nginx, ctrl.Result{}, nil)
+
+		assert.Empty(t, tracker.GetPending())
+	})
+}
@@ -0,0 +1,187 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Path is the path which the debug state is served at.
+	Path = "/debug/state"
+)
+
+var (
+	log = ctrl.Log.WithName("debug")
+)
+
+// State is the snapshot of the work in progress of the operator, which helps to triage scans that seem stuck.
+type State struct {
+	// ActiveScanJobs are scan Jobs which have neither completed nor failed yet.
+	ActiveScanJobs []ScanJob `json:"activeScanJobs"`
+	// PendingReconciles is the number of requeued or failed reconcile requests keyed by namespace.
+	PendingReconciles map[string]int `json:"pendingReconciles"`
+	// LastErrors are the last errors of failed reconcile requests, most recent first.
+	LastErrors []ErrorSummary `json:"lastErrors"`
+}
+
+// ScanJob describes a scan Job which is still running.
+type ScanJob struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Workload is the kind, namespace, and name of the scanned workload, e.g. ReplicaSet/default/nginx-6799fc88d8.
+	Workload  string    `json:"workload,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ErrorSummary describes the last error of a reconcile request which keeps failing.
+type ErrorSummary struct {
+	Controller string    `json:"controller"`
+	Namespace  string    `json:"namespace"`
+	Name       string    `json:"name"`
+	Error      string    `json:"error"`
+	Time       time.Time `json:"time"`
+	// Failures is the number of errors returned by the reconcile request in a row.
+	Failures int `json:"failures"`
+}
+
+// Handler serves the debug State as JSON. Scan Jobs are listed with the specified client, and pending
+// reconcile requests are read from the specified tracker.
+type Handler struct {
+	Config  etc.Operator
+	Client  client.Reader
+	Tracker *controller.ReconcileTracker
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	state, err := h.GetState(r.Context())
+	if err != nil {
+		log.Error(err, "Unable to get debug state")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// GetState returns the current debug State.
+func (h *Handler) GetState(ctx context.Context) (State, error) {
+	jobList := &batchv1.JobList{}
+	err := h.Client.List(ctx, jobList, client.InNamespace(h.Config.GetScanJobsNamespace()),
+		client.MatchingLabels(h.Config.GetScanJobLabels()))
+	if err != nil {
+		return State{}, fmt.Errorf("listing scan jobs: %w", err)
+	}
+	state := State{
+		ActiveScanJobs:    []ScanJob{},
+		PendingReconciles: map[string]int{},
+		LastErrors:        []ErrorSummary{},
+	}
+	for _, job := range jobList.Items {
+		if isFinished(job) {
+			continue
+		}
+		scanJob := ScanJob{
+			Name:      job.Name,
+			Namespace: job.Namespace,
+			CreatedAt: job.CreationTimestamp.Time,
+		}
+		if kind, ok := job.Labels[kube.LabelResourceKind]; ok {
+			scanJob.Workload = fmt.Sprintf("%s/%s/%s", kind, job.Labels[kube.LabelResourceNamespace],
+				job.Labels[kube.LabelResourceName])
+		}
+		state.ActiveScanJobs = append(state.ActiveScanJobs, scanJob)
+	}
+	sort.Slice(state.ActiveScanJobs, func(i, j int) bool {
+		return state.ActiveScanJobs[i].CreatedAt.Before(state.ActiveScanJobs[j].CreatedAt)
+	})
+
+	for _, pending := range h.Tracker.GetPending() {
+		state.PendingReconciles[pending.Namespace]++
+		if pending.LastError == "" {
+			continue
+		}
+		state.LastErrors = append(state.LastErrors, ErrorSummary{
+			Controller: pending.Controller,
+			Namespace:  pending.Namespace,
+			Name:       pending.Name,
+			Error:      pending.LastError,
+			Time:       pending.LastErrorTime,
+			Failures:   pending.Failures,
+		})
+	}
+	sort.SliceStable(state.LastErrors, func(i, j int) bool {
+		return state.LastErrors[i].Time.After(state.LastErrors[j].Time)
+	})
+	return state, nil
+}
+
+// isFinished returns true if the specified Job has completed or failed.
+func isFinished(job batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) &&
+			condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// Server serves the debug endpoint over plain HTTP. It should be bound to the loopback interface, so that the
+// endpoint is only reachable with `kubectl port-forward`.
+type Server struct {
+	BindAddress string
+	Handler     http.Handler
+}
+
+// Start serves the debug endpoint until the stop channel is closed. The Server implements manager.Runnable.
+func (s *Server) Start(stop <-chan struct{}) error {
+	listener, err := net.Listen("tcp", s.BindAddress)
+	if err != nil {
+		return fmt.Errorf("listening on debug bind address %s: %w", s.BindAddress, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, s.Handler)
+	server := &http.Server{Handler: mux}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Info("Starting debug server", "address", listener.Addr().String(), "path", Path)
+		errs <- server.Serve(listener)
+	}()
+
+	select {
+	case err = <-errs:
+		return err
+	case <-stop:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
+
+// NeedLeaderElection returns false, so that every replica of the operator serves its own debug state.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,139 @@
+package debug_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/controller"
+	"github.com/aquasecurity/starboard-operator/pkg/debug"
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHandler(t *testing.T) {
+	now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+	config := etc.Operator{
+		Namespace:         "starboard-operator",
+		ScanJobLabelKey:   "app.kubernetes.io/managed-by",
+		ScanJobLabelValue: "starboard-operator",
+	}
+
+	newJob := func(name string, created time.Time, conditions ...batchv1.JobCondition) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "starboard-operator",
+				CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "starboard-operator",
+					kube.LabelResourceKind:         "ReplicaSet",
+					kube.LabelResourceNamespace:    "default",
+					kube.LabelResourceName:         name,
+				},
+			},
+			Status: batchv1.JobStatus{
+				Conditions: conditions,
+			},
+		}
+	}
+
+	t.Run("Should serve empty state", func(t *testing.T) {
+		handler := &debug.Handler{
+			Config: config,
+			Client: fake.NewFakeClient(),
+		}
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, debug.Path, nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"activeScanJobs":[],"pendingReconciles":{},"lastErrors":[]}`, recorder.Body.String())
+	})
+
+	t.Run("Should serve active scan jobs and pending reconciles", func(t *testing.T) {
+		tracker := &controller.ReconcileTracker{}
+		tracker.Record(now, "pod", ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}},
+			ctrl.Result{}, errors.New("ensuring scan job: forbidden"))
+		tracker.Record(now.Add(time.Minute), "pod", ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "redis"}},
+			ctrl.Result{}, errors.New("getting pod from cache: timeout"))
+		tracker.Record(now, "job", ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "starboard-operator", Name: "scan-1"}},
+			ctrl.Result{RequeueAfter: time.Minute}, nil)
+
+		handler := &debug.Handler{
+			Config: config,
+			Client: fake.NewFakeClient(
+				newJob("nginx-6799fc88d8", now.Add(time.Minute)),
+				newJob("redis-5b8d9b9c4d", now),
+				newJob("mongo-7d8f9c6b5", now, batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}),
+			),
+			Tracker: tracker,
+		}
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, debug.Path, nil))
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{
+  "activeScanJobs": [
+    {
+      "name": "redis-5b8d9b9c4d",
+      "namespace": "starboard-operator",
+      "workload": "ReplicaSet/default/redis-5b8d9b9c4d",
+      "createdAt": "2020-10-01T10:00:00Z"
+    },
+    {
+      "name": "nginx-6799fc88d8",
+      "namespace": "starboard-operator",
+      "workload": "ReplicaSet/default/nginx-6799fc88d8",
+      "createdAt": "2020-10-01T10:01:00Z"
+    }
+  ],
+  "pendingReconciles": {
+    "default": 2,
+    "starboard-operator": 1
+  },
+  "lastErrors": [
+    {
+      "controller": "pod",
+      "namespace": "default",
+      "name": "redis",
+      "error": "getting pod from cache: timeout",
+      "time": "2020-10-01T10:01:00Z",
+      "failures": 1
+    },
+    {
+      "controller": "pod",
+      "namespace": "default",
+      "name": "nginx",
+      "error": "ensuring scan job: forbidden",
+      "time": "2020-10-01T10:00:00Z",
+      "failures": 1
+    }
+  ]
+}`, recorder.Body.String())
+	})
+
+	t.Run("Should not allow methods other than GET", func(t *testing.T) {
+		handler := &debug.Handler{
+			Config: config,
+			Client: fake.NewFakeClient(),
+		}
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, debug.Path, nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+}
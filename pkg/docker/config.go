@@ -0,0 +1,146 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// dockerHubServer is the canonical registry server of Docker Hub images.
+	dockerHubServer = "index.docker.io"
+)
+
+// Auth represents credentials used to login to a Docker registry.
+type Auth struct {
+	Username string
+	Password string
+}
+
+func (a Auth) String() string {
+	return "[REDACTED]"
+}
+
+// authEntry represents a single entry of the auths map in a Docker config file.
+type authEntry struct {
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// config represents Docker configuration which is typically saved as `~/.docker/config.json`
+// and stored as the value of the `.dockerconfigjson` key of image pull Secrets.
+type config struct {
+	Auths map[string]authEntry `json:"auths"`
+}
+
+// ReadCredentialsFromSecret returns the mapping from a registry host to Auth for the specified
+// image pull Secret. Both kubernetes.io/dockerconfigjson and the legacy kubernetes.io/dockercfg
+// Secret types are supported. Secrets of other types yield an empty mapping.
+func ReadCredentialsFromSecret(secret corev1.Secret) (map[string]Auth, error) {
+	var entries map[string]authEntry
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		var dockerConfig config
+		err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &dockerConfig)
+		if err != nil {
+			return nil, fmt.Errorf("parsing secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		entries = dockerConfig.Auths
+	case corev1.SecretTypeDockercfg:
+		err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &entries)
+		if err != nil {
+			return nil, fmt.Errorf("parsing secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+	default:
+		return map[string]Auth{}, nil
+	}
+
+	credentials := make(map[string]Auth)
+	for server, entry := range entries {
+		auth, err := entry.decode()
+		if err != nil {
+			return nil, fmt.Errorf("decoding credentials for %s in secret %s/%s: %w", server, secret.Namespace, secret.Name, err)
+		}
+		if auth == (Auth{}) {
+			continue
+		}
+		host, err := GetHostFromServer(server)
+		if err != nil {
+			return nil, err
+		}
+		credentials[host] = auth
+	}
+	return credentials, nil
+}
+
+// ReadCredentialsFromSecrets merges credentials read from the specified image pull Secrets.
+// Similarly to the kubelet, the first Secret with credentials for a given registry host wins.
+func ReadCredentialsFromSecrets(secrets []corev1.Secret) (map[string]Auth, error) {
+	credentials := make(map[string]Auth)
+	for _, secret := range secrets {
+		secretCredentials, err := ReadCredentialsFromSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+		for host, auth := range secretCredentials {
+			if _, exists := credentials[host]; !exists {
+				credentials[host] = auth
+			}
+		}
+	}
+	return credentials, nil
+}
+
+// GetCredentialsForImage returns Auth for the registry of the specified image reference.
+// Returns false if there are no matching credentials.
+func GetCredentialsForImage(credentials map[string]Auth, imageRef string) (Auth, bool, error) {
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return Auth{}, false, err
+	}
+	auth, ok := credentials[ref.Context().RegistryStr()]
+	return auth, ok, nil
+}
+
+// GetHostFromServer returns the host for the specified registry server.
+//
+// In Docker config files auth keys can be specified as URLs or host names.
+// For the sake of comparison we need to normalize the registry identifier.
+// The Docker Hub aliases are normalized to index.docker.io.
+func GetHostFromServer(server string) (string, error) {
+	host := server
+	if strings.HasPrefix(server, "http://") ||
+		strings.HasPrefix(server, "https://") {
+		parsed, err := url.Parse(server)
+		if err != nil {
+			return "", err
+		}
+		host = parsed.Host
+	}
+	switch host {
+	case "docker.io", "registry-1.docker.io":
+		return dockerHubServer, nil
+	}
+	return host, nil
+}
+
+func (e authEntry) decode() (Auth, error) {
+	if e.Auth == "" {
+		return Auth{Username: e.Username, Password: e.Password}, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(e.Auth)
+	if err != nil {
+		return Auth{}, err
+	}
+	split := strings.SplitN(string(decoded), ":", 2)
+	if len(split) != 2 {
+		return Auth{}, fmt.Errorf("expected username and password separated by colon")
+	}
+	return Auth{Username: split[0], Password: split[1]}, nil
+}
@@ -0,0 +1,153 @@
+package docker_test
+
+import (
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/docker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestReadCredentialsFromSecret(t *testing.T) {
+	testCases := []struct {
+		name                string
+		secret              corev1.Secret
+		expectedCredentials map[string]docker.Auth
+		expectedError       string
+	}{
+		{
+			name: "Should read base64 encoded auth from dockerconfigjson",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(`{"auths":{"core.harbor.domain":{"auth":"YWRtaW46SGFyYm9yMTIzNDU="}}}`),
+				},
+			},
+			expectedCredentials: map[string]docker.Auth{
+				"core.harbor.domain": {Username: "admin", Password: "Harbor12345"},
+			},
+		},
+		{
+			name: "Should read username and password from dockerconfigjson",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"username":"root","password":"s3cret"}}}`),
+				},
+			},
+			expectedCredentials: map[string]docker.Auth{
+				"index.docker.io": {Username: "root", Password: "s3cret"},
+			},
+		},
+		{
+			name: "Should read password containing colon",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(`{"auths":{"quay.io":{"auth":"cm9ib3Q6cGFzczp3b3Jk"}}}`),
+				},
+			},
+			expectedCredentials: map[string]docker.Auth{
+				"quay.io": {Username: "robot", Password: "pass:word"},
+			},
+		},
+		{
+			name: "Should read legacy dockercfg",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeDockercfg,
+				Data: map[string][]byte{
+					corev1.DockerConfigKey: []byte(`{"docker.io":{"auth":"cm9vdDpzM2NyZXQ="},"gcr.io":{}}`),
+				},
+			},
+			expectedCredentials: map[string]docker.Auth{
+				"index.docker.io": {Username: "root", Password: "s3cret"},
+			},
+		},
+		{
+			name: "Should ignore opaque secret",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					"password": []byte("s3cret"),
+				},
+			},
+			expectedCredentials: map[string]docker.Auth{},
+		},
+		{
+			name: "Should return error when auth is malformed",
+			secret: corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(`{"auths":{"quay.io":{"auth":"cm9ib3Q="}}}`),
+				},
+			},
+			expectedError: "decoding credentials for quay.io in secret /: expected username and password separated by colon",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			credentials, err := docker.ReadCredentialsFromSecret(tc.secret)
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedCredentials, credentials)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestGetCredentialsForImage(t *testing.T) {
+	credentials, err := docker.ReadCredentialsFromSecrets([]corev1.Secret{
+		{
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"auths":{"core.harbor.domain":{"username":"admin","password":"Harbor12345"}}}`),
+			},
+		},
+		{
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"auths":{"core.harbor.domain":{"username":"guest","password":"guest"},"docker.io":{"username":"root","password":"s3cret"}}}`),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		imageRef      string
+		expectedAuth  docker.Auth
+		expectedFound bool
+	}{
+		{
+			name:          "Should pick credentials from the first matching secret",
+			imageRef:      "core.harbor.domain/library/nginx:1.16",
+			expectedAuth:  docker.Auth{Username: "admin", Password: "Harbor12345"},
+			expectedFound: true,
+		},
+		{
+			name:          "Should match Docker Hub image without registry",
+			imageRef:      "nginx:1.16",
+			expectedAuth:  docker.Auth{Username: "root", Password: "s3cret"},
+			expectedFound: true,
+		},
+		{
+			name:          "Should not match unknown registry",
+			imageRef:      "quay.io/prometheus/prometheus:v2.20.0",
+			expectedFound: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auth, found, err := docker.GetCredentialsForImage(credentials, tc.imageRef)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.expectedAuth, auth)
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+)
+
+// manifestMediaTypes are media types of manifests accepted when resolving digests. Manifest lists and
+// OCI image indexes come first, so that the digest of a multi-platform image is the same as the one
+// reported by the kubelet.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// DigestResolver resolves image references to digests of their manifests without pulling images.
+type DigestResolver interface {
+	// ResolveDigest returns the digest of the specified image reference, e.g. `sha256:4cd8...` for
+	// `nginx:1.16`. Blank Auth means that the registry is accessed anonymously.
+	ResolveDigest(ctx context.Context, imageRef string, auth Auth) (string, error)
+}
+
+// RegistryDigestResolver resolves digests with the Docker Registry HTTP API V2. Registries which require
+// bearer tokens are supported by the token authentication flow, where the specified credentials are
+// exchanged for a token. Registries which require basic authentication receive the credentials directly.
+type RegistryDigestResolver struct {
+	client *http.Client
+}
+
+// NewRegistryDigestResolver constructs a new RegistryDigestResolver with the specified HTTP client.
+// The default client with a timeout is used if the client is nil.
+func NewRegistryDigestResolver(client *http.Client) *RegistryDigestResolver {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	return &RegistryDigestResolver{client: client}
+}
+
+func (r *RegistryDigestResolver) ResolveDigest(ctx context.Context, imageRef string, auth Auth) (string, error) {
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return "", fmt.Errorf("parsing image reference %s: %w", imageRef, err)
+	}
+	if digest, ok := ref.(name.Digest); ok {
+		return digest.DigestStr(), nil
+	}
+
+	repository := ref.Context()
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", repository.Registry.Scheme(),
+		repository.RegistryStr(), repository.RepositoryStr(), ref.Identifier())
+
+	resp, err := r.getManifest(ctx, http.MethodHead, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	authorization := ""
+	if resp.StatusCode == http.StatusUnauthorized {
+		authorization, err = r.authorize(ctx, resp.Header.Get("WWW-Authenticate"), repository, auth)
+		if err != nil {
+			return "", fmt.Errorf("authorizing to registry %s: %w", repository.RegistryStr(), err)
+		}
+		resp, err = r.getManifest(ctx, http.MethodHead, manifestURL, authorization)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("getting manifest of %s: unexpected status %s", imageRef, resp.Status)
+	}
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	// The digest header is optional, in which case the digest is computed from the manifest content.
+	resp, err = r.getManifest(ctx, http.MethodGet, manifestURL, authorization)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("getting manifest of %s: unexpected status %s", imageRef, resp.Status)
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", fmt.Errorf("reading manifest of %s: %w", imageRef, err)
+	}
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
+}
+
+func (r *RegistryDigestResolver) getManifest(ctx context.Context, method, manifestURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(method, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getting manifest: %w", err)
+	}
+	return resp, nil
+}
+
+// authorize returns the value of the Authorization header which satisfies the specified challenge,
+// i.e. the value of the WWW-Authenticate header returned by the registry.
+func (r *RegistryDigestResolver) authorize(ctx context.Context, challenge string, repository name.Repository, auth Auth) (string, error) {
+	scheme, params := ParseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if auth == (Auth{}) {
+			return "", fmt.Errorf("registry requires basic authentication but credentials are not set")
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(auth.Username, auth.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		token, err := r.getToken(ctx, params, repository, auth)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge: %q", challenge)
+	}
+}
+
+// tokenResponse represents the response of a token server. Token servers return the token as
+// the token or the access_token property, or both.
+type tokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// getToken requests a bearer token with the pull scope for the specified repository from the token server
+// given by the realm parameter of the challenge.
+func (r *RegistryDigestResolver) getToken(ctx context.Context, params map[string]string, repository name.Repository, auth Auth) (string, error) {
+	realm, ok := params["realm"]
+	if !ok {
+		return "", fmt.Errorf("bearer challenge without realm")
+	}
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("parsing realm: %w", err)
+	}
+	query := tokenURL.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	scope, ok := params["scope"]
+	if !ok {
+		scope = repository.Scope("pull")
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	if auth != (Auth{}) {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("getting token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("getting token: unexpected status %s", resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading token: %w", err)
+	}
+	var token tokenResponse
+	err = json.Unmarshal(body, &token)
+	if err != nil {
+		return "", fmt.Errorf("parsing token: %w", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", fmt.Errorf("token server returned empty token")
+}
+
+// ParseChallenge parses the value of the WWW-Authenticate header, e.g.
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`, into the authentication
+// scheme and parameters. Quoted parameter values may contain commas, e.g. `scope="repository:foo:pull,push"`.
+func ParseChallenge(challenge string) (string, map[string]string) {
+	params := make(map[string]string)
+	challenge = strings.TrimSpace(challenge)
+	index := strings.IndexByte(challenge, ' ')
+	if index == -1 {
+		return challenge, params
+	}
+	scheme, rest := challenge[:index], challenge[index+1:]
+	for {
+		rest = strings.TrimLeft(rest, " ,")
+		eq := strings.IndexByte(rest, '=')
+		if eq == -1 {
+			return scheme, params
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = rest[eq+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end == -1 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end == -1 {
+				value, rest = rest, ""
+			} else {
+				value, rest = rest[:end], rest[end:]
+			}
+		}
+		params[key] = strings.TrimSpace(value)
+	}
+}
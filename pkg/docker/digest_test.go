@@ -0,0 +1,221 @@
+package docker_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/docker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	manifestDigest = "sha256:4cd8a1d2c66a2ef1b2ac2b6a8f1e1bbb0c0f5d0a6d7c8f1b2c3d4e5f6a7b8c9d"
+	manifest       = `{"schemaVersion":2}`
+)
+
+// newRegistry returns a mock registry which serves the manifest of the nginx:1.16 image. The authorize
+// function returns true if the request is authorized to get the manifest.
+func newRegistry(t *testing.T, withDigestHeader bool, authorize func(w http.ResponseWriter, r *http.Request) bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			username, password, ok := r.BasicAuth()
+			if !ok || username != "root" || password != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "registry.example.com", r.URL.Query().Get("service"))
+			assert.Equal(t, "repository:library/nginx:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token":"t0k3n"}`))
+			return
+		}
+		if r.URL.Path != "/v2/library/nginx/manifests/1.16" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Contains(t, r.Header.Get("Accept"), "application/vnd.docker.distribution.manifest.list.v2+json")
+		if !authorize(w, r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if withDigestHeader {
+			w.Header().Set("Docker-Content-Digest", manifestDigest)
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(manifest))
+		}
+	}))
+}
+
+func TestRegistryDigestResolver_ResolveDigest(t *testing.T) {
+	anonymous := func(_ http.ResponseWriter, _ *http.Request) bool {
+		return true
+	}
+
+	testCases := []struct {
+		name             string
+		withDigestHeader bool
+		authorize        func(server *httptest.Server) func(w http.ResponseWriter, r *http.Request) bool
+		imageRef         string
+		auth             docker.Auth
+		expectedDigest   string
+		expectedError    string
+	}{
+		{
+			name:             "Should resolve digest from anonymous registry",
+			withDigestHeader: true,
+			authorize: func(_ *httptest.Server) func(w http.ResponseWriter, r *http.Request) bool {
+				return anonymous
+			},
+			imageRef:       "library/nginx:1.16",
+			expectedDigest: manifestDigest,
+		},
+		{
+			name:             "Should resolve digest from registry which requires bearer token",
+			withDigestHeader: true,
+			authorize: func(server *httptest.Server) func(w http.ResponseWriter, r *http.Request) bool {
+				return func(w http.ResponseWriter, r *http.Request) bool {
+					if r.Header.Get("Authorization") == "Bearer t0k3n" {
+						return true
+					}
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.example.com",scope="repository:library/nginx:pull"`, server.URL))
+					return false
+				}
+			},
+			imageRef:       "library/nginx:1.16",
+			auth:           docker.Auth{Username: "root", Password: "s3cret"},
+			expectedDigest: manifestDigest,
+		},
+		{
+			name:             "Should return error when token server rejects credentials",
+			withDigestHeader: true,
+			authorize: func(server *httptest.Server) func(w http.ResponseWriter, r *http.Request) bool {
+				return func(w http.ResponseWriter, r *http.Request) bool {
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.example.com"`, server.URL))
+					return false
+				}
+			},
+			imageRef:      "library/nginx:1.16",
+			auth:          docker.Auth{Username: "root", Password: "invalid"},
+			expectedError: "authorizing to registry %s: getting token: unexpected status 401 Unauthorized",
+		},
+		{
+			name:             "Should resolve digest from registry which requires basic authentication",
+			withDigestHeader: true,
+			authorize: func(_ *httptest.Server) func(w http.ResponseWriter, r *http.Request) bool {
+				return func(w http.ResponseWriter, r *http.Request) bool {
+					if username, password, ok := r.BasicAuth(); ok && username == "root" && password == "s3cret" {
+						return true
+					}
+					w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+					return false
+				}
+			},
+			imageRef:       "library/nginx:1.16",
+			auth:           docker.Auth{Username: "root", Password: "s3cret"},
+			expectedDigest: manifestDigest,
+		},
+		{
+			name: "Should compute digest from manifest when digest header is not returned",
+			authorize: func(_ *httptest.Server) func(w http.ResponseWriter, r *http.Request) bool {
+				return anonymous
+			},
+			imageRef:       "library/nginx:1.16",
+			expectedDigest: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest))),
+		},
+		{
+			name: "Should return digest of image reference by digest",
+			authorize: func(_ *httptest.Server) func(w http.ResponseWriter, r *http.Request) bool {
+				return anonymous
+			},
+			imageRef:       "library/nginx@" + manifestDigest,
+			expectedDigest: manifestDigest,
+		},
+		{
+			name: "Should return error when manifest does not exist",
+			authorize: func(_ *httptest.Server) func(w http.ResponseWriter, r *http.Request) bool {
+				return anonymous
+			},
+			imageRef:      "library/nginx:9.99",
+			expectedError: "getting manifest of %s/library/nginx:9.99: unexpected status 404 Not Found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = newRegistry(t, tc.withDigestHeader, func(w http.ResponseWriter, r *http.Request) bool {
+				return tc.authorize(server)(w, r)
+			})
+			defer server.Close()
+			host := strings.TrimPrefix(server.URL, "http://")
+
+			resolver := docker.NewRegistryDigestResolver(server.Client())
+			digest, err := resolver.ResolveDigest(context.Background(), host+"/"+tc.imageRef, tc.auth)
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedDigest, digest)
+			default:
+				require.EqualError(t, err, fmt.Sprintf(tc.expectedError, host))
+			}
+		})
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	testCases := []struct {
+		name           string
+		challenge      string
+		expectedScheme string
+		expectedParams map[string]string
+	}{
+		{
+			name:           "Should parse bearer challenge",
+			challenge:      `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`,
+			expectedScheme: "Bearer",
+			expectedParams: map[string]string{
+				"realm":   "https://auth.docker.io/token",
+				"service": "registry.docker.io",
+				"scope":   "repository:library/nginx:pull",
+			},
+		},
+		{
+			name:           "Should parse quoted value with comma",
+			challenge:      `Bearer realm="https://quay.io/v2/auth", scope="repository:foo/bar:pull,push"`,
+			expectedScheme: "Bearer",
+			expectedParams: map[string]string{
+				"realm": "https://quay.io/v2/auth",
+				"scope": "repository:foo/bar:pull,push",
+			},
+		},
+		{
+			name:           "Should parse unquoted values",
+			challenge:      `Basic realm=registry,charset=UTF-8`,
+			expectedScheme: "Basic",
+			expectedParams: map[string]string{
+				"realm":   "registry",
+				"charset": "UTF-8",
+			},
+		},
+		{
+			name:           "Should parse scheme without parameters",
+			challenge:      `Basic`,
+			expectedScheme: "Basic",
+			expectedParams: map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme, params := docker.ParseChallenge(tc.challenge)
+			assert.Equal(t, tc.expectedScheme, scheme)
+			assert.Equal(t, tc.expectedParams, params)
+		})
+	}
+}
@@ -1,15 +1,109 @@
 package etc
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aquasecurity/starboard-operator/pkg/docker"
 	"github.com/caarlos0/env/v6"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/pointer"
 )
 
 const (
 	LabelPodSpecHash = "pod-spec-hash"
+
+	// AnnotationReportUpdatedAt holds the RFC3339 timestamp of the last update of a report.
+	AnnotationReportUpdatedAt = "starboard.aquasecurity.github.io/report-updated-at"
+
+	// AnnotationInitContainerNames holds comma separated names of init containers scanned by a scan Job.
+	AnnotationInitContainerNames = "starboard.aquasecurity.github.io/init-container-names"
+
+	// AnnotationEphemeralContainerNames holds comma separated names of ephemeral containers scanned by a scan Job.
+	AnnotationEphemeralContainerNames = "starboard.aquasecurity.github.io/ephemeral-container-names"
+
+	// AnnotationSkipScan when set to "true" on a Pod or its owner excludes the workload from scanning.
+	AnnotationSkipScan = "starboard.aquasecurity.github.io/skip-scan"
+
+	// AnnotationScanJobRetryCount holds the number of times a failed scan Job has been recreated.
+	AnnotationScanJobRetryCount = "starboard.aquasecurity.github.io/scan-job-retry-count"
+
+	// AnnotationScanJobProcessed is set on finished scan Jobs which are left in place after their results have
+	// been processed, e.g. because their logs cannot be parsed or deletion of scan Jobs is disabled.
+	AnnotationScanJobProcessed = "starboard.aquasecurity.github.io/scan-job-processed"
+
+	// AnnotationCompressedVulnerabilities holds gzipped and base64 encoded JSON of vulnerabilities
+	// of a report whose size exceeded the compression threshold.
+	AnnotationCompressedVulnerabilities = "starboard.aquasecurity.github.io/compressed-vulnerabilities"
+
+	// AnnotationTruncatedVulnerabilities holds the number of vulnerabilities of a report which were dropped,
+	// because the report had more vulnerabilities than the configured maximum.
+	AnnotationTruncatedVulnerabilities = "starboard.aquasecurity.github.io/truncated-vulnerabilities"
+
+	// AnnotationVulnerabilityCount holds the total number of vulnerabilities of a report, i.e. the sum of
+	// counts of all severities in its summary, which is computed when the report is written.
+	AnnotationVulnerabilityCount = "starboard.aquasecurity.github.io/vulnerability-count"
+
+	// AnnotationScannerName holds the name of the scanner which produced a VulnerabilityReport, e.g. Trivy.
+	AnnotationScannerName = "starboard.aquasecurity.github.io/scanner-name"
+
+	// AnnotationScannerVersion holds the version of the scanner which produced a VulnerabilityReport.
+	AnnotationScannerVersion = "starboard.aquasecurity.github.io/scanner-version"
+
+	// LabelInitContainer is set to "true" on reports of init containers.
+	LabelInitContainer = "starboard.aquasecurity.github.io/init-container"
+
+	// LabelEphemeralContainer is set to "true" on reports of ephemeral containers, e.g. added by `kubectl debug`.
+	LabelEphemeralContainer = "starboard.aquasecurity.github.io/ephemeral-container"
+
+	// AnnotationContainerImageDigests holds JSON mapping from container names to digests of images
+	// resolved by the kubelet, which is set on scan Jobs.
+	AnnotationContainerImageDigests = "starboard.aquasecurity.github.io/container-image-digests"
+
+	// AnnotationImageDigest holds the digest of the scanned image, which is set on VulnerabilityReports.
+	AnnotationImageDigest = "starboard.aquasecurity.github.io/image-digest"
+
+	// AnnotationScanError holds comma separated reasons of the last failed scan of a workload,
+	// e.g. ImageNotFound or AuthRequired. It's removed once the workload is scanned successfully.
+	AnnotationScanError = "starboard.aquasecurity.github.io/scan-error"
+
+	// AnnotationScanStatus holds the ScanStatus of the last scan of a workload.
+	AnnotationScanStatus = "starboard.aquasecurity.github.io/scan-status"
+
+	// AnnotationRescan when set on a Pod to a value that differs from the AnnotationRescanProcessed
+	// annotation triggers a scan of the workload even if it has current reports. The value is an
+	// arbitrary nonce, e.g. a timestamp. It's also set on scan Jobs created on demand.
+	AnnotationRescan = "starboard.aquasecurity.github.io/rescan"
+
+	// AnnotationRescanProcessed holds the value of the AnnotationRescan annotation of a Pod for which
+	// a scan Job was last created.
+	AnnotationRescanProcessed = "starboard.aquasecurity.github.io/rescan-processed"
+
+	// LabelSBOMFormat holds the format of the SBOM stored in a ConfigMap, e.g. cyclonedx.
+	LabelSBOMFormat = "starboard.aquasecurity.github.io/sbom-format"
+
+	// LabelConfigAudit is set to "true" on config audit Jobs to tell them apart from vulnerability scan Jobs.
+	LabelConfigAudit = "starboard.aquasecurity.github.io/config-audit"
+
+	// LabelReportHistory is set to "true" on copies of previous VulnerabilityReports kept as history. They're
+	// not labeled with LabelPodSpecHash, so that they're never mistaken for current reports.
+	LabelReportHistory = "starboard.aquasecurity.github.io/report-history"
+
+	// LabelInstanceID holds the ID of the operator instance which created a scan Job or a report, so that
+	// multiple instances of the operator do not process each other's scan Jobs.
+	LabelInstanceID = "starboard.aquasecurity.github.io/instance-id"
 )
 
 type VersionInfo struct {
@@ -19,40 +113,597 @@ type VersionInfo struct {
 }
 
 type Config struct {
-	Operator       Operator
-	ScannerAquaCSP ScannerAquaCSP
-	ScannerTrivy   ScannerTrivy
+	Operator           Operator
+	ScannerAquaCSP     ScannerAquaCSP
+	ScannerTrivy       ScannerTrivy
+	ScannerGrype       ScannerGrype
+	ScannerScout       ScannerScout
+	ConfigAuditPolaris ConfigAuditPolaris
 }
 
 type Operator struct {
-	Namespace              string        `env:"OPERATOR_NAMESPACE"`
-	TargetNamespaces       string        `env:"OPERATOR_TARGET_NAMESPACES"`
-	ServiceAccount         string        `env:"OPERATOR_SERVICE_ACCOUNT" envDefault:"starboard-operator"`
-	ScanJobTimeout         time.Duration `env:"OPERATOR_SCAN_JOB_TIMEOUT" envDefault:"5m"`
-	MetricsBindAddress     string        `env:"OPERATOR_METRICS_BIND_ADDRESS" envDefault:":8080"`
-	HealthProbeBindAddress string        `env:"OPERATOR_HEALTH_PROBE_BIND_ADDRESS" envDefault:":9090"`
-	LogDevMode             bool          `env:"OPERATOR_LOG_DEV_MODE" envDefault:"false"`
+	Namespace                string        `env:"OPERATOR_NAMESPACE"`
+	Scanner                  string        `env:"OPERATOR_SCANNER"`
+	TargetNamespaces         string        `env:"OPERATOR_TARGET_NAMESPACES"`
+	TargetNamespaceSelector  string        `env:"OPERATOR_TARGET_NAMESPACE_SELECTOR"`
+	DetectInstallMode        bool          `env:"OPERATOR_DETECT_INSTALL_MODE" envDefault:"false"`
+	ScanLabelSelector        string        `env:"OPERATOR_SCAN_LABEL_SELECTOR"`
+	ExcludeNamespaces        string        `env:"OPERATOR_EXCLUDE_NAMESPACES" envDefault:"kube-system,kube-public,kube-node-lease"`
+	ScanAllowRegistries      string        `env:"OPERATOR_SCAN_ALLOW_REGISTRIES"`
+	ScanDenyRegistries       string        `env:"OPERATOR_SCAN_DENY_REGISTRIES"`
+	ExcludeImages            string        `env:"OPERATOR_EXCLUDE_IMAGES"`
+	ServiceAccount           string        `env:"OPERATOR_SERVICE_ACCOUNT" envDefault:"starboard-operator"`
+	ScanJobTimeout           time.Duration `env:"OPERATOR_SCAN_JOB_TIMEOUT" envDefault:"5m"`
+	LogReadTimeout           time.Duration `env:"OPERATOR_LOG_READ_TIMEOUT" envDefault:"1m"`
+	ShutdownGracePeriod      time.Duration `env:"OPERATOR_SHUTDOWN_GRACE_PERIOD" envDefault:"0"`
+	MetricsBindAddress       string        `env:"OPERATOR_METRICS_BIND_ADDRESS" envDefault:":8080"`
+	MetricsCertFile          string        `env:"OPERATOR_METRICS_TLS_CERT_FILE"`
+	MetricsKeyFile           string        `env:"OPERATOR_METRICS_TLS_KEY_FILE"`
+	MetricsAuthEnabled       bool          `env:"OPERATOR_METRICS_AUTH_ENABLED" envDefault:"false"`
+	HealthProbeBindAddress   string        `env:"OPERATOR_HEALTH_PROBE_BIND_ADDRESS" envDefault:":9090"`
+	DebugEndpointEnabled     bool          `env:"OPERATOR_DEBUG_ENDPOINT_ENABLED" envDefault:"false"`
+	DebugBindAddress         string        `env:"OPERATOR_DEBUG_BIND_ADDRESS" envDefault:"127.0.0.1:8082"`
+	LogDevMode               bool          `env:"OPERATOR_LOG_DEV_MODE" envDefault:"false"`
+	LogFormat                string        `env:"OPERATOR_LOG_FORMAT"`
+	LogLevel                 string        `env:"OPERATOR_LOG_LEVEL"`
+	ScanJobCPURequest        string        `env:"OPERATOR_SCAN_JOB_CPU_REQUEST" envDefault:"100m"`
+	ScanJobMemoryRequest     string        `env:"OPERATOR_SCAN_JOB_MEMORY_REQUEST" envDefault:"100M"`
+	ScanJobCPULimit          string        `env:"OPERATOR_SCAN_JOB_CPU_LIMIT" envDefault:"500m"`
+	ScanJobMemoryLimit       string        `env:"OPERATOR_SCAN_JOB_MEMORY_LIMIT" envDefault:"500M"`
+	ConcurrentScanJobsLimit  int           `env:"OPERATOR_CONCURRENT_SCAN_JOBS_LIMIT" envDefault:"10"`
+	ScanReportTTL            time.Duration `env:"OPERATOR_SCAN_REPORT_TTL" envDefault:"0"`
+	RescanJitter             time.Duration `env:"OPERATOR_RESCAN_JITTER" envDefault:"0"`
+	ScanCron                 string        `env:"OPERATOR_SCAN_CRON"`
+	DeleteScanJobs           bool          `env:"OPERATOR_DELETE_SCAN_JOBS" envDefault:"true"`
+	ScanJobRetryLimit        int           `env:"OPERATOR_SCAN_JOB_RETRY_LIMIT" envDefault:"3"`
+	ScanJobRetryBackoff      time.Duration `env:"OPERATOR_SCAN_JOB_RETRY_BACKOFF" envDefault:"30s"`
+	ScanJobBackoffLimit      int32         `env:"OPERATOR_SCAN_JOB_BACKOFF_LIMIT" envDefault:"0"`
+	ScanJobTTLSeconds        int32         `env:"OPERATOR_SCAN_JOB_TTL_SECONDS" envDefault:"0"`
+	FailureThreshold         int           `env:"OPERATOR_FAILURE_THRESHOLD" envDefault:"0"`
+	FailureCooldown          time.Duration `env:"OPERATOR_FAILURE_COOLDOWN" envDefault:"1h"`
+	ScanJobNodeSelector      string        `env:"OPERATOR_SCAN_JOB_NODE_SELECTOR"`
+	ScanJobTolerations       string        `env:"OPERATOR_SCAN_JOB_TOLERATIONS"`
+	ScanJobAffinity          string        `env:"OPERATOR_SCAN_JOB_AFFINITY"`
+	ScanJobHostAliases       string        `env:"OPERATOR_SCAN_JOB_HOST_ALIASES"`
+	ScanJobDNSConfig         string        `env:"OPERATOR_SCAN_JOB_DNS_CONFIG"`
+	ScanJobServiceAccount    string        `env:"OPERATOR_SCAN_JOB_SERVICE_ACCOUNT"`
+	ScanJobPriorityClassName string        `env:"OPERATOR_SCAN_JOB_PRIORITY_CLASS_NAME"`
+	ScanJobsNamespace        string        `env:"OPERATOR_SCAN_JOBS_NAMESPACE"`
+	ScanJobHTTPProxy         string        `env:"OPERATOR_SCAN_JOB_HTTP_PROXY"`
+	ScanJobHTTPSProxy        string        `env:"OPERATOR_SCAN_JOB_HTTPS_PROXY"`
+	ScanJobNoProxy           string        `env:"OPERATOR_SCAN_JOB_NO_PROXY"`
+	ScanJobEnvFrom           string        `env:"OPERATOR_SCAN_JOB_ENV_FROM"`
+	ScanJobVolumes           string        `env:"OPERATOR_SCAN_JOB_VOLUMES"`
+	ScanJobVolumeMounts      string        `env:"OPERATOR_SCAN_JOB_VOLUME_MOUNTS"`
+	ScanJobImagePullPolicy   string        `env:"OPERATOR_SCAN_JOB_IMAGE_PULL_POLICY" envDefault:"IfNotPresent"`
+	ScanJobCACertConfigMap   string        `env:"OPERATOR_SCAN_JOB_CA_CERT_CONFIGMAP"`
+	TrivyIgnoreFileConfigMap string        `env:"OPERATOR_SCANNER_TRIVY_IGNORE_FILE_CONFIGMAP"`
+	VulnPolicyConfigMap      string        `env:"OPERATOR_VULN_POLICY_CONFIGMAP"`
+	ScanJobPropagateLabels   string        `env:"OPERATOR_SCAN_JOB_PROPAGATE_LABELS"`
+	ScanJobLabelKey          string        `env:"OPERATOR_SCAN_JOB_LABEL_KEY" envDefault:"app.kubernetes.io/managed-by"`
+	ScanJobLabelValue        string        `env:"OPERATOR_SCAN_JOB_LABEL_VALUE" envDefault:"starboard-operator"`
+	InstanceID               string        `env:"OPERATOR_INSTANCE_ID"`
+	ScanJobSecurityContext   string        `env:"OPERATOR_SCAN_JOB_SECURITY_CONTEXT"`
+	RegistryRateLimit        string        `env:"OPERATOR_REGISTRY_RATE_LIMIT"`
+	ShareReportsByDigest     bool          `env:"OPERATOR_SHARE_REPORTS_BY_DIGEST" envDefault:"false"`
+	ScannedDigestCacheSize   int           `env:"OPERATOR_SCANNED_DIGEST_CACHE_SIZE" envDefault:"0"`
+	ScannedDigestCacheFile   string        `env:"OPERATOR_SCANNED_DIGEST_CACHE_FILE"`
+	ScannedDigestGracePeriod time.Duration `env:"OPERATOR_SCANNED_DIGEST_GRACE_PERIOD" envDefault:"5m"`
+	ScanOnlyRunning          bool          `env:"OPERATOR_SCAN_ONLY_RUNNING" envDefault:"false"`
+	ResolveImageDigests      bool          `env:"OPERATOR_RESOLVE_IMAGE_DIGESTS" envDefault:"false"`
+	CompressReports          bool          `env:"OPERATOR_COMPRESS_REPORTS" envDefault:"false"`
+	CompressReportsThreshold int           `env:"OPERATOR_COMPRESS_REPORTS_THRESHOLD" envDefault:"65536"`
+	MaxReportItems           int           `env:"OPERATOR_MAX_REPORT_ITEMS" envDefault:"0"`
+	ReportHistoryLimit       int           `env:"OPERATOR_REPORT_HISTORY_LIMIT" envDefault:"1"`
+	ServerSideApply          bool          `env:"OPERATOR_SERVER_SIDE_APPLY" envDefault:"true"`
+	ReportAnnotations        string        `env:"OPERATOR_REPORT_ANNOTATIONS"`
+	NotifyWebhookURL         string        `env:"OPERATOR_NOTIFY_WEBHOOK_URL"`
+	NotifyWebhookSecret      string        `env:"OPERATOR_NOTIFY_WEBHOOK_SECRET"`
+	NotifySlackWebhookURL    string        `env:"OPERATOR_NOTIFY_SLACK_WEBHOOK_URL"`
+	NotifySeverityThreshold  string        `env:"OPERATOR_NOTIFY_SEVERITY_THRESHOLD" envDefault:"CRITICAL"`
+	LeaderElectionEnabled    bool          `env:"OPERATOR_LEADER_ELECTION_ENABLED" envDefault:"false"`
+	LeaderElectionID         string        `env:"OPERATOR_LEADER_ELECTION_ID" envDefault:"starboard-operator"`
+	LeaderElectionNamespace  string        `env:"OPERATOR_LEADER_ELECTION_NAMESPACE"`
+	DryRun                   bool          `env:"OPERATOR_DRY_RUN" envDefault:"false"`
+	ReportSeverities         string        `env:"OPERATOR_REPORT_SEVERITIES"`
+	ReconcileRequeueInterval time.Duration `env:"OPERATOR_RECONCILE_REQUEUE_INTERVAL" envDefault:"0"`
+	ReportBackend            ReportBackend `env:"OPERATOR_REPORT_BACKEND" envDefault:"CRD"`
+	ReportS3Endpoint         string        `env:"OPERATOR_REPORT_S3_ENDPOINT"`
+	ReportS3Bucket           string        `env:"OPERATOR_REPORT_S3_BUCKET"`
+	ReportS3Region           string        `env:"OPERATOR_REPORT_S3_REGION" envDefault:"us-east-1"`
+	ReportS3AccessKey        string        `env:"OPERATOR_REPORT_S3_ACCESS_KEY"`
+	ReportS3SecretKey        string        `env:"OPERATOR_REPORT_S3_SECRET_KEY"`
+	ReportS3Insecure         bool          `env:"OPERATOR_REPORT_S3_INSECURE" envDefault:"false"`
+	ReportScope              ReportScope   `env:"OPERATOR_REPORT_SCOPE" envDefault:"owner"`
+	AdmissionEnabled         bool          `env:"OPERATOR_ADMISSION_WEBHOOK_ENABLED" envDefault:"false"`
+	AdmissionPort            int           `env:"OPERATOR_ADMISSION_WEBHOOK_PORT" envDefault:"9443"`
+	AdmissionCertDir         string        `env:"OPERATOR_ADMISSION_WEBHOOK_CERT_DIR" envDefault:"/tmp/k8s-webhook-server/serving-certs"`
+	AdmissionMaxCritical     int           `env:"OPERATOR_ADMISSION_WEBHOOK_CRITICAL_THRESHOLD" envDefault:"0"`
+	AdmissionAllowUnscanned  bool          `env:"OPERATOR_ADMISSION_WEBHOOK_ALLOW_UNSCANNED" envDefault:"true"`
 }
 
+// ReportBackend describes where scan reports are written.
+type ReportBackend string
+
+const (
+	// ReportBackendCRD writes reports as custom resources.
+	ReportBackendCRD ReportBackend = "CRD"
+)
+
+// ReportScope describes which workload VulnerabilityReports are attached to.
+type ReportScope string
+
+const (
+	// ReportScopeOwner attaches reports to the top-level workload controlling a Pod, e.g. a ReplicaSet.
+	ReportScopeOwner ReportScope = "owner"
+	// ReportScopePod attaches reports to each Pod, e.g. for forensic purposes.
+	ReportScopePod ReportScope = "pod"
+)
+
+// RateLimit describes how many events are allowed per interval.
+type RateLimit struct {
+	Count    int
+	Interval time.Duration
+}
+
+// ScanStatus describes the progress of scanning a workload, which is stored in the
+// AnnotationScanStatus annotation of the workload.
+type ScanStatus string
+
+const (
+	// ScanStatusPending indicates that a scan Job was created, or recreated after a transient
+	// failure, but is not running yet.
+	ScanStatusPending ScanStatus = "Pending"
+	// ScanStatusInProgress indicates that a scan Job is running.
+	ScanStatusInProgress ScanStatus = "InProgress"
+	// ScanStatusCompleted indicates that reports of the workload are up to date.
+	ScanStatusCompleted ScanStatus = "Completed"
+	// ScanStatusFailed indicates that the last scan failed and won't be retried.
+	ScanStatusFailed ScanStatus = "Failed"
+	// ScanStatusSuspended indicates that scans of the workload are suspended for the cooldown period,
+	// because its images failed to be scanned too many times in a row.
+	ScanStatusSuspended ScanStatus = "Suspended"
+	// ScanStatusImagePullBackOff indicates that the workload is not scanned, because the kubelet fails to pull
+	// its images. The scan is retried once the images are pulled.
+	ScanStatusImagePullBackOff ScanStatus = "ImagePullBackOff"
+)
+
+// SecurityContextRestricted is the value of OPERATOR_SCAN_JOB_SECURITY_CONTEXT which runs scan Jobs
+// with the restricted security context, e.g. required by the OpenShift restricted SCC.
+const SecurityContextRestricted = "Restricted"
+
+// TrivyMode describes how Trivy is run by scan Jobs.
+type TrivyMode string
+
+const (
+	// TrivyModeStandalone downloads the vulnerability database in each scan Job.
+	TrivyModeStandalone TrivyMode = "Standalone"
+	// TrivyModeClientServer delegates scanning to a remote Trivy server.
+	TrivyModeClientServer TrivyMode = "ClientServer"
+)
+
+// TrivyScanType describes what Trivy scans in scan Jobs.
+type TrivyScanType string
+
+const (
+	// TrivyScanTypeImage scans images pulled by Trivy from registries.
+	TrivyScanTypeImage TrivyScanType = "image"
+	// TrivyScanTypeRootfs scans the root filesystem of scan containers which run the scanned images.
+	TrivyScanTypeRootfs TrivyScanType = "rootfs"
+)
+
+// ScanOutputMode describes how scan containers pass their output to the operator.
+type ScanOutputMode string
+
+const (
+	// ScanOutputModeLogs prints the JSON output of scanners to logs of scan containers.
+	ScanOutputModeLogs ScanOutputMode = "logs"
+	// ScanOutputModeFile writes the JSON output of scanners to a file in a volume of the scan Job, which is
+	// printed gzipped and base64 encoded after scanners exit, so that large outputs are not truncated.
+	ScanOutputModeFile ScanOutputMode = "file"
+)
+
+// SBOMFormat describes the format of software bills of materials generated by scan Jobs.
+type SBOMFormat string
+
+const (
+	SBOMFormatCycloneDX SBOMFormat = "cyclonedx"
+)
+
 type ScannerTrivy struct {
-	Enabled  bool   `env:"OPERATOR_SCANNER_TRIVY_ENABLED" envDefault:"true"`
-	Version  string `env:"OPERATOR_SCANNER_TRIVY_VERSION" envDefault:"0.11.0"`
-	ImageRef string `env:"OPERATOR_SCANNER_TRIVY_IMAGE" envDefault:"aquasec/trivy:0.11.0"`
+	Enabled       bool           `env:"OPERATOR_SCANNER_TRIVY_ENABLED" envDefault:"true"`
+	Version       string         `env:"OPERATOR_SCANNER_TRIVY_VERSION" envDefault:"0.11.0"`
+	ImageRef      string         `env:"OPERATOR_SCANNER_TRIVY_IMAGE" envDefault:"aquasec/trivy:0.11.0"`
+	Mode          TrivyMode      `env:"OPERATOR_SCANNER_TRIVY_MODE" envDefault:"Standalone"`
+	ScanType      TrivyScanType  `env:"OPERATOR_SCANNER_TRIVY_SCAN_TYPE" envDefault:"image"`
+	ServerURL     string         `env:"OPERATOR_SCANNER_TRIVY_SERVER_URL"`
+	IgnoreUnfixed bool           `env:"OPERATOR_SCANNER_TRIVY_IGNORE_UNFIXED" envDefault:"false"`
+	Timeout       time.Duration  `env:"OPERATOR_SCANNER_TRIVY_TIMEOUT" envDefault:"0"`
+	Insecure      bool           `env:"OPERATOR_SCANNER_TRIVY_INSECURE" envDefault:"false"`
+	CachePVC      string         `env:"OPERATOR_SCANNER_TRIVY_CACHE_PVC"`
+	CacheDir      string         `env:"OPERATOR_SCANNER_TRIVY_CACHE_DIR"`
+	GenerateSBOM  bool           `env:"OPERATOR_SCANNER_TRIVY_GENERATE_SBOM" envDefault:"false"`
+	SBOMFormat    SBOMFormat     `env:"OPERATOR_SCANNER_TRIVY_SBOM_FORMAT" envDefault:"cyclonedx"`
+	Command       string         `env:"OPERATOR_SCANNER_TRIVY_COMMAND"`
+	Args          string         `env:"OPERATOR_SCANNER_TRIVY_ARGS"`
+	OutputMode    ScanOutputMode `env:"OPERATOR_SCANNER_TRIVY_SCAN_OUTPUT_MODE" envDefault:"logs"`
+	Platform      string         `env:"OPERATOR_SCANNER_TRIVY_PLATFORM"`
+}
+
+type ScannerGrype struct {
+	Enabled  bool   `env:"OPERATOR_SCANNER_GRYPE_ENABLED" envDefault:"false"`
+	Version  string `env:"OPERATOR_SCANNER_GRYPE_VERSION" envDefault:"0.1.0"`
+	ImageRef string `env:"OPERATOR_SCANNER_GRYPE_IMAGE" envDefault:"anchore/grype:v0.1.0"`
+}
+
+// Validate checks that the image reference of Grype is valid.
+func (c ScannerGrype) Validate() error {
+	return ValidateImageRef("OPERATOR_SCANNER_GRYPE_IMAGE", c.ImageRef)
+}
+
+type ScannerScout struct {
+	Enabled     bool   `env:"OPERATOR_SCANNER_SCOUT_ENABLED" envDefault:"false"`
+	Version     string `env:"OPERATOR_SCANNER_SCOUT_VERSION" envDefault:"1.0.9"`
+	ImageRef    string `env:"OPERATOR_SCANNER_SCOUT_IMAGE" envDefault:"docker/scout-cli:1.0.9"`
+	TokenSecret string `env:"OPERATOR_SCANNER_SCOUT_TOKEN_SECRET"`
+}
+
+// Validate checks that the image reference of Docker Scout is valid, and that the Secret holding the Docker Hub
+// credentials, which Docker Scout requires, is set.
+func (c ScannerScout) Validate() error {
+	err := ValidateImageRef("OPERATOR_SCANNER_SCOUT_IMAGE", c.ImageRef)
+	if err != nil {
+		return err
+	}
+	if c.TokenSecret == "" {
+		return fmt.Errorf("%s must be set", "OPERATOR_SCANNER_SCOUT_TOKEN_SECRET")
+	}
+	return nil
+}
+
+type ConfigAuditPolaris struct {
+	Enabled  bool   `env:"OPERATOR_CONFIG_AUDIT_ENABLED" envDefault:"false"`
+	Version  string `env:"OPERATOR_CONFIG_AUDIT_POLARIS_VERSION" envDefault:"1.2"`
+	ImageRef string `env:"OPERATOR_CONFIG_AUDIT_POLARIS_IMAGE" envDefault:"quay.io/fairwinds/polaris:1.2"`
 }
 
 type ScannerAquaCSP struct {
-	Enabled  bool   `env:"OPERATOR_SCANNER_AQUA_CSP_ENABLED" envDefault:"false"`
-	Version  string `env:"OPERATOR_SCANNER_AQUA_CSP_VERSION" envDefault:"5.0"`
-	ImageRef string `env:"OPERATOR_SCANNER_AQUA_CSP_IMAGE" envDefault:"aquasec/scanner:5.0"`
-	Host     string `env:"OPERATOR_SCANNER_AQUA_CSP_HOST"`
-	Username string `env:"OPERATOR_SCANNER_AQUA_CSP_USERNAME"`
-	Password string `env:"OPERATOR_SCANNER_AQUA_CSP_PASSWORD"`
+	Enabled           bool   `env:"OPERATOR_SCANNER_AQUA_CSP_ENABLED" envDefault:"false"`
+	Version           string `env:"OPERATOR_SCANNER_AQUA_CSP_VERSION" envDefault:"5.0"`
+	ImageRef          string `env:"OPERATOR_SCANNER_AQUA_CSP_IMAGE" envDefault:"aquasec/scanner:5.0"`
+	WrapperImageRef   string `env:"OPERATOR_SCANNER_AQUA_CSP_WRAPPER_IMAGE"`
+	Host              string `env:"OPERATOR_SCANNER_AQUA_CSP_HOST"`
+	Username          string `env:"OPERATOR_SCANNER_AQUA_CSP_USERNAME"`
+	Password          string `env:"OPERATOR_SCANNER_AQUA_CSP_PASSWORD"`
+	CredentialsSecret string `env:"OPERATOR_SCANNER_AQUA_CSP_CREDENTIALS_SECRET" envDefault:"starboard-operator"`
+	Registry          string `env:"OPERATOR_SCANNER_AQUA_CSP_REGISTRY"`
+	Register          bool   `env:"OPERATOR_SCANNER_AQUA_CSP_REGISTER" envDefault:"false"`
+	Insecure          bool   `env:"OPERATOR_SCANNER_AQUA_CSP_INSECURE" envDefault:"false"`
+}
+
+// DefaultAquaCSPCredentialsSecret is the name of the Secret holding Aqua CSP credentials unless configured otherwise.
+const DefaultAquaCSPCredentialsSecret = "starboard-operator"
+
+// GetCredentialsSecret returns the name of the Secret holding the host and credentials of the Aqua CSP
+// management console, which defaults to DefaultAquaCSPCredentialsSecret if it's not set.
+func (c ScannerAquaCSP) GetCredentialsSecret() string {
+	if c.CredentialsSecret == "" {
+		return DefaultAquaCSPCredentialsSecret
+	}
+	return c.CredentialsSecret
+}
+
+// GetWrapperImageRef returns the reference of the image which runs scannercli and converts its output,
+// i.e. aquasec/starboard-scanner-aqua tagged with the operator version, unless overridden, e.g. to pull
+// the image from a mirror registry.
+func (c ScannerAquaCSP) GetWrapperImageRef(version VersionInfo) string {
+	if c.WrapperImageRef != "" {
+		return c.WrapperImageRef
+	}
+	return fmt.Sprintf("aquasec/starboard-scanner-aqua:%s", version.Version)
+}
+
+// Validate checks that image references of the Aqua CSP scanner are valid, and that settings required to run
+// scan Jobs are set, i.e. the address and the version of the Aqua CSP management console, and the name of the
+// registry which scanned images are registered in if registration is enabled. Credentials are not validated,
+// because scan Jobs read them from the credentials Secret.
+func (c ScannerAquaCSP) Validate() error {
+	err := ValidateImageRef("OPERATOR_SCANNER_AQUA_CSP_IMAGE", c.ImageRef)
+	if err != nil {
+		return err
+	}
+	err = ValidateImageRef("OPERATOR_SCANNER_AQUA_CSP_WRAPPER_IMAGE", c.WrapperImageRef)
+	if err != nil {
+		return err
+	}
+	if c.Host == "" {
+		return fmt.Errorf("%s must be set", "OPERATOR_SCANNER_AQUA_CSP_HOST")
+	}
+	host, err := url.Parse(c.Host)
+	if err != nil || (host.Scheme != "http" && host.Scheme != "https") || host.Host == "" {
+		return fmt.Errorf("%s must be an http or https URL but got %q", "OPERATOR_SCANNER_AQUA_CSP_HOST", c.Host)
+	}
+	if c.Version == "" {
+		return fmt.Errorf("%s must be set", "OPERATOR_SCANNER_AQUA_CSP_VERSION")
+	}
+	if c.Register && c.Registry == "" {
+		return fmt.Errorf("%s must be set when %s is true", "OPERATOR_SCANNER_AQUA_CSP_REGISTRY",
+			"OPERATOR_SCANNER_AQUA_CSP_REGISTER")
+	}
+	return nil
+}
+
+// ValidateImageRef checks that the value of the specified setting is a valid image reference,
+// e.g. `registry.local:5000/aquasec/trivy:0.11.0`. Blank values are skipped.
+func ValidateImageRef(key, imageRef string) error {
+	if imageRef == "" {
+		return nil
+	}
+	_, err := name.ParseReference(imageRef)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", key, err)
+	}
+	return nil
+}
+
+// RedactedValue replaces values of sensitive configuration settings.
+const RedactedValue = "[REDACTED]"
+
+// GetEnv returns the mapping from environment variable names to values of the
+// configuration settings.
+func (c Config) GetEnv() map[string]string {
+	envs := make(map[string]string)
+	collectEnv(reflect.ValueOf(c), envs)
+	return envs
+}
+
+func collectEnv(value reflect.Value, envs map[string]string) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct {
+			collectEnv(field, envs)
+			continue
+		}
+		name, ok := value.Type().Field(i).Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		envs[name] = fmt.Sprint(field.Interface())
+	}
+}
+
+// RedactEnv returns a copy of the specified mapping from environment variable names to values
+// in which non-blank values of passwords, secrets, and tokens are replaced with RedactedValue.
+func RedactEnv(envs map[string]string) map[string]string {
+	redacted := make(map[string]string, len(envs))
+	for name, value := range envs {
+		if value != "" && isSensitiveEnv(name) {
+			value = RedactedValue
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+func isSensitiveEnv(name string) bool {
+	// Slack incoming webhook URLs embed the secret which authorizes posting messages.
+	for _, keyword := range []string{"PASSWORD", "SECRET", "TOKEN", "SLACK_WEBHOOK_URL"} {
+		if strings.Contains(name, keyword) {
+			return true
+		}
+	}
+	return false
 }
 
 func GetOperatorConfig() (Config, error) {
 	var config Config
 	err := env.Parse(&config)
-	return config, err
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetScanLabelSelector()
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetTargetNamespaceSelector()
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetScanJobResourceRequirements()
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetScanJobImagePullPolicy()
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetScanJobNodeSelector()
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetScanJobTolerations()
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetScanJobAffinity()
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetScanJobHostAliases()
+	if err != nil {
+		return config, err
+	}
+	err = config.Operator.ValidateScanRegistries()
+	if err != nil {
+		return config, err
+	}
+	for _, pattern := range config.Operator.GetExcludeImages() {
+		if _, err = path.Match(pattern, ""); err != nil {
+			return config, fmt.Errorf("%s contains invalid pattern %q: %w", "OPERATOR_EXCLUDE_IMAGES", pattern, err)
+		}
+	}
+	_, err = config.Operator.GetScanJobEnvFrom()
+	if err != nil {
+		return config, err
+	}
+	err = config.Operator.ValidateScanJobVolumes()
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetScanJobDNSConfig()
+	if err != nil {
+		return config, err
+	}
+	_, _, err = config.Operator.GetScanJobSecurityContext()
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetRegistryRateLimit()
+	if err != nil {
+		return config, err
+	}
+	_, err = config.Operator.GetReportAnnotations()
+	if err != nil {
+		return config, err
+	}
+	err = config.Operator.ValidateScanJobLabel()
+	if err != nil {
+		return config, err
+	}
+	if errs := validation.IsValidLabelValue(config.Operator.InstanceID); len(errs) > 0 {
+		return config, fmt.Errorf("%s must be a valid label value: %s", "OPERATOR_INSTANCE_ID", strings.Join(errs, "; "))
+	}
+	err = config.Operator.ValidateTargetNamespaces()
+	if err != nil {
+		return config, err
+	}
+	if namespace := config.Operator.ScanJobsNamespace; namespace != "" {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return config, fmt.Errorf("%s must be a valid namespace name: %s", "OPERATOR_SCAN_JOBS_NAMESPACE", strings.Join(errs, "; "))
+		}
+	}
+	if name := config.Operator.ScanJobPriorityClassName; name != "" {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return config, fmt.Errorf("%s must be a valid priority class name: %s", "OPERATOR_SCAN_JOB_PRIORITY_CLASS_NAME", strings.Join(errs, "; "))
+		}
+	}
+	_, err = config.Operator.GetScanSchedule()
+	if err != nil {
+		return config, err
+	}
+	switch config.Operator.ReportScope {
+	case ReportScopeOwner, ReportScopePod:
+	default:
+		return config, fmt.Errorf("unrecognized %s: %q", "OPERATOR_REPORT_SCOPE", config.Operator.ReportScope)
+	}
+	if config.Operator.LogReadTimeout < 0 {
+		return config, fmt.Errorf("%s must not be negative", "OPERATOR_LOG_READ_TIMEOUT")
+	}
+	if config.Operator.RescanJitter < 0 {
+		return config, fmt.Errorf("%s must not be negative", "OPERATOR_RESCAN_JITTER")
+	}
+	if config.Operator.ShutdownGracePeriod < 0 {
+		return config, fmt.Errorf("%s must not be negative", "OPERATOR_SHUTDOWN_GRACE_PERIOD")
+	}
+	if config.Operator.ScanJobBackoffLimit < 0 {
+		return config, fmt.Errorf("%s must not be negative", "OPERATOR_SCAN_JOB_BACKOFF_LIMIT")
+	}
+	if config.Operator.ScanJobTTLSeconds < 0 {
+		return config, fmt.Errorf("%s must not be negative", "OPERATOR_SCAN_JOB_TTL_SECONDS")
+	}
+	if (config.Operator.MetricsCertFile == "") != (config.Operator.MetricsKeyFile == "") {
+		return config, fmt.Errorf("%s and %s must be set together", "OPERATOR_METRICS_TLS_CERT_FILE", "OPERATOR_METRICS_TLS_KEY_FILE")
+	}
+	if config.Operator.MetricsAuthEnabled && !config.Operator.IsMetricsTLSEnabled() {
+		return config, fmt.Errorf("%s must be set when %s is true", "OPERATOR_METRICS_TLS_CERT_FILE", "OPERATOR_METRICS_AUTH_ENABLED")
+	}
+	if config.Operator.ReportS3Bucket != "" && config.Operator.ReportS3Endpoint == "" {
+		return config, fmt.Errorf("%s must be set when %s is set", "OPERATOR_REPORT_S3_ENDPOINT", "OPERATOR_REPORT_S3_BUCKET")
+	}
+	if config.Operator.FailureThreshold < 0 {
+		return config, fmt.Errorf("%s must not be negative", "OPERATOR_FAILURE_THRESHOLD")
+	}
+	if config.Operator.FailureThreshold > 0 && config.Operator.FailureCooldown <= 0 {
+		return config, fmt.Errorf("%s must be positive", "OPERATOR_FAILURE_COOLDOWN")
+	}
+	if config.Operator.ScannedDigestCacheSize < 0 {
+		return config, fmt.Errorf("%s must not be negative", "OPERATOR_SCANNED_DIGEST_CACHE_SIZE")
+	}
+	if config.Operator.ScannedDigestCacheFile != "" && config.Operator.ScannedDigestCacheSize == 0 {
+		return config, fmt.Errorf("%s must be positive when %s is set", "OPERATOR_SCANNED_DIGEST_CACHE_SIZE",
+			"OPERATOR_SCANNED_DIGEST_CACHE_FILE")
+	}
+	if config.Operator.ScannedDigestGracePeriod < 0 {
+		return config, fmt.Errorf("%s must not be negative", "OPERATOR_SCANNED_DIGEST_GRACE_PERIOD")
+	}
+	if config.Operator.ReportHistoryLimit < 1 {
+		return config, fmt.Errorf("%s must be positive", "OPERATOR_REPORT_HISTORY_LIMIT")
+	}
+	if config.Operator.AdmissionMaxCritical < 0 {
+		return config, fmt.Errorf("%s must not be negative", "OPERATOR_ADMISSION_WEBHOOK_CRITICAL_THRESHOLD")
+	}
+	return config, nil
+}
+
+// Names of vulnerability scanners selected with the OPERATOR_SCANNER variable.
+const (
+	ScannerNameTrivy   = "trivy"
+	ScannerNameAquaCSP = "aqua-csp"
+	ScannerNameGrype   = "grype"
+	ScannerNameScout   = "scout"
+)
+
+// GetScannerName returns the name of the enabled vulnerability scanner. The scanner is selected by the name set
+// as OPERATOR_SCANNER, which takes precedence over the flags enabling each scanner. Otherwise, exactly one
+// of the flags must be set.
+func (c Config) GetScannerName() (string, error) {
+	if name := c.Operator.Scanner; name != "" {
+		if strings.Contains(name, ",") {
+			return "", fmt.Errorf("multiple vulnerability scanners enabled: %s", name)
+		}
+		return strings.TrimSpace(name), nil
+	}
+	var enabled []string
+	for _, scanner := range []struct {
+		name    string
+		enabled bool
+	}{
+		{name: ScannerNameTrivy, enabled: c.ScannerTrivy.Enabled},
+		{name: ScannerNameAquaCSP, enabled: c.ScannerAquaCSP.Enabled},
+		{name: ScannerNameGrype, enabled: c.ScannerGrype.Enabled},
+		{name: ScannerNameScout, enabled: c.ScannerScout.Enabled},
+	} {
+		if scanner.enabled {
+			enabled = append(enabled, scanner.name)
+		}
+	}
+	if len(enabled) > 1 {
+		return "", fmt.Errorf("multiple vulnerability scanners enabled: %s", strings.Join(enabled, ","))
+	}
+	if len(enabled) == 0 {
+		return "", errors.New("none vulnerability scanner enabled")
+	}
+	return enabled[0], nil
+}
+
+// ValidateScanners checks that exactly one vulnerability scanner is enabled and
+// that its configuration is valid.
+func (c Config) ValidateScanners() error {
+	name, err := c.GetScannerName()
+	if err != nil {
+		return err
+	}
+	switch name {
+	case ScannerNameTrivy:
+		return c.ScannerTrivy.Validate()
+	case ScannerNameAquaCSP:
+		return c.ScannerAquaCSP.Validate()
+	case ScannerNameGrype:
+		return c.ScannerGrype.Validate()
+	case ScannerNameScout:
+		return c.ScannerScout.Validate()
+	}
+	return nil
 }
 
 // GetOperatorNamespace returns the namespace the operator should be running in.
@@ -64,13 +715,742 @@ func (c Operator) GetOperatorNamespace() (string, error) {
 	return "", fmt.Errorf("%s must be set", "OPERATOR_NAMESPACE")
 }
 
-// GetTargetNamespaces returns namespaces the operator should be watching for changes.
+// AllNamespaces is the value of OPERATOR_TARGET_NAMESPACES which stands for all namespaces, same as
+// the empty value.
+const AllNamespaces = "*"
+
+// GetTargetNamespaces returns namespaces the operator should be watching for changes. Names are trimmed,
+// and blank or duplicate names are ignored. Returns an empty slice for all namespaces.
 func (c Operator) GetTargetNamespaces() []string {
-	namespaces := c.TargetNamespaces
-	if namespaces != "" {
-		return strings.Split(namespaces, ",")
+	namespaces := []string{}
+	seen := make(map[string]bool)
+	for _, namespace := range strings.Split(c.TargetNamespaces, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == AllNamespaces {
+			return []string{}
+		}
+		if namespace == "" || seen[namespace] {
+			continue
+		}
+		seen[namespace] = true
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces
+}
+
+// ValidateTargetNamespaces checks that the configured target namespaces are valid namespace names, i.e.
+// DNS-1123 labels, and that AllNamespaces is not combined with particular namespaces.
+func (c Operator) ValidateTargetNamespaces() error {
+	var names, invalid []string
+	all := false
+	for _, namespace := range strings.Split(c.TargetNamespaces, ",") {
+		switch namespace = strings.TrimSpace(namespace); {
+		case namespace == "":
+		case namespace == AllNamespaces:
+			all = true
+		case len(validation.IsDNS1123Label(namespace)) > 0:
+			invalid = append(invalid, strconv.Quote(namespace))
+		default:
+			names = append(names, namespace)
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%s must contain valid namespace names, invalid: %s", "OPERATOR_TARGET_NAMESPACES",
+			strings.Join(invalid, ", "))
+	}
+	if all && len(names) > 0 {
+		return fmt.Errorf("%s must not combine %q with namespace names", "OPERATOR_TARGET_NAMESPACES", AllNamespaces)
+	}
+	return nil
+}
+
+// GetExcludeNamespaces returns namespaces whose workloads are never scanned, even if they're
+// watched by the operator. Blank names are ignored.
+func (c Operator) GetExcludeNamespaces() []string {
+	var namespaces []string
+	for _, namespace := range strings.Split(c.ExcludeNamespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// IsNamespaceExcluded returns true if the specified namespace is listed in OPERATOR_EXCLUDE_NAMESPACES,
+// false otherwise.
+func (c Operator) IsNamespaceExcluded(name string) bool {
+	for _, namespace := range c.GetExcludeNamespaces() {
+		if namespace == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetScanAllowRegistries returns patterns of registry hosts whose images are scanned, e.g.
+// `registry.corp:5000` or `*.corp.example.com`. Returns nil if images from all registries are scanned.
+func (c Operator) GetScanAllowRegistries() []string {
+	return parseRegistryPatterns(c.ScanAllowRegistries)
+}
+
+// GetScanDenyRegistries returns patterns of registry hosts whose images are never scanned, even if
+// they match OPERATOR_SCAN_ALLOW_REGISTRIES.
+func (c Operator) GetScanDenyRegistries() []string {
+	return parseRegistryPatterns(c.ScanDenyRegistries)
+}
+
+// ValidateScanRegistries returns an error if registry patterns of OPERATOR_SCAN_ALLOW_REGISTRIES
+// or OPERATOR_SCAN_DENY_REGISTRIES are malformed.
+func (c Operator) ValidateScanRegistries() error {
+	for _, list := range []struct {
+		envName  string
+		patterns []string
+	}{
+		{envName: "OPERATOR_SCAN_ALLOW_REGISTRIES", patterns: c.GetScanAllowRegistries()},
+		{envName: "OPERATOR_SCAN_DENY_REGISTRIES", patterns: c.GetScanDenyRegistries()},
+	} {
+		for _, pattern := range list.patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%s contains invalid pattern %q: %w", list.envName, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// IsImageAllowed returns true if the registry of the specified image reference is allowed to be scanned,
+// false otherwise. The denylist takes precedence over the allowlist, and the empty allowlist allows all
+// registries. Images whose registry cannot be determined are allowed only if the allowlist is empty.
+func (c Operator) IsImageAllowed(imageRef string) bool {
+	allowed := c.GetScanAllowRegistries()
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return len(allowed) == 0
+	}
+	host := ref.Context().RegistryStr()
+	if matchRegistry(c.GetScanDenyRegistries(), host) {
+		return false
+	}
+	return len(allowed) == 0 || matchRegistry(allowed, host)
+}
+
+// GetExcludeImages returns glob patterns of images which are never scanned, e.g. `*/istio/proxyv2` or
+// `docker.io/istio/proxyv2:1.8.*`. Blank patterns are ignored.
+func (c Operator) GetExcludeImages() []string {
+	var patterns []string
+	for _, pattern := range strings.Split(c.ExcludeImages, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// IsImageExcluded returns true if the specified image reference matches any of the OPERATOR_EXCLUDE_IMAGES
+// patterns, false otherwise. Patterns are matched against the reference as written and against its repository
+// without the tag and the digest, both as written and fully qualified, e.g. the `istio/proxyv2:1.8.0` image is
+// matched as `istio/proxyv2:1.8.0`, `istio/proxyv2`, `index.docker.io/istio/proxyv2:1.8.0`, and
+// `index.docker.io/istio/proxyv2`. Docker Hub images are also matched with the `docker.io` registry.
+func (c Operator) IsImageExcluded(imageRef string) bool {
+	patterns := c.GetExcludeImages()
+	if len(patterns) == 0 {
+		return false
+	}
+	repository := imageRef
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository = repository[:i]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	candidates := []string{imageRef, repository}
+	if ref, err := name.ParseReference(imageRef); err == nil {
+		for _, candidate := range []string{ref.Name(), ref.Context().Name()} {
+			candidates = append(candidates, candidate)
+			if strings.HasPrefix(candidate, name.DefaultRegistry+"/") {
+				candidates = append(candidates, "docker.io"+strings.TrimPrefix(candidate, name.DefaultRegistry))
+			}
+		}
+	}
+	for _, pattern := range patterns {
+		for _, candidate := range candidates {
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseRegistryPatterns splits the specified comma separated patterns of registry hosts. Blank patterns
+// are ignored and Docker Hub aliases, e.g. `docker.io`, are normalized to the host of image references.
+func parseRegistryPatterns(value string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if host, err := docker.GetHostFromServer(pattern); err == nil {
+			pattern = host
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// matchRegistry returns true if the specified registry host matches any of the given patterns, where `*`
+// matches any sequence of characters, including dots, e.g. `*.corp.example.com` matches subdomains.
+func matchRegistry(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, host); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetScanJobPropagateLabels returns keys of labels copied from scanned workloads to scan Jobs,
+// e.g. for cost allocation. Blank keys are ignored.
+func (c Operator) GetScanJobPropagateLabels() []string {
+	var keys []string
+	for _, key := range strings.Split(c.ScanJobPropagateLabels, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// GetTargetNamespaceSelector returns the label selector of namespaces the operator should be
+// watching for changes, e.g. `starboard.aquasecurity.github.io/scan=true`. Returns nil if the
+// selector is not set. The selector is an alternative to the explicit list of target namespaces,
+// therefore it's an error to set both.
+func (c Operator) GetTargetNamespaceSelector() (labels.Selector, error) {
+	if c.TargetNamespaceSelector == "" {
+		return nil, nil
+	}
+	if len(c.GetTargetNamespaces()) > 0 {
+		return nil, fmt.Errorf("%s and %s are mutually exclusive", "OPERATOR_TARGET_NAMESPACES", "OPERATOR_TARGET_NAMESPACE_SELECTOR")
+	}
+	selector, err := labels.Parse(c.TargetNamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_TARGET_NAMESPACE_SELECTOR", err)
+	}
+	return selector, nil
+}
+
+// GetScanLabelSelector returns the label selector of Pods the operator should scan, e.g. `scan=true`.
+// Returns the selector matching all Pods if the selector is not set.
+func (c Operator) GetScanLabelSelector() (labels.Selector, error) {
+	if c.ScanLabelSelector == "" {
+		return labels.Everything(), nil
+	}
+	selector, err := labels.Parse(c.ScanLabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_SCAN_LABEL_SELECTOR", err)
+	}
+	return selector, nil
+}
+
+// GetScanSchedule returns the schedule of rescanning all Pods in target namespaces, which is parsed from
+// the standard cron expression, e.g. `0 2 * * *`, or a descriptor, e.g. `@daily`. Returns nil if the
+// schedule is not set.
+func (c Operator) GetScanSchedule() (cron.Schedule, error) {
+	if c.ScanCron == "" {
+		return nil, nil
+	}
+	schedule, err := cron.ParseStandard(c.ScanCron)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_SCAN_CRON", err)
+	}
+	return schedule, nil
+}
+
+// GetScanJobLabel returns the key and the value of the label which marks scan Jobs created by the operator,
+// and which selects them. Defaults to app.kubernetes.io/managed-by=starboard-operator.
+func (c Operator) GetScanJobLabel() (string, string) {
+	key, value := c.ScanJobLabelKey, c.ScanJobLabelValue
+	if key == "" {
+		key = "app.kubernetes.io/managed-by"
+	}
+	if value == "" {
+		value = "starboard-operator"
+	}
+	return key, value
+}
+
+// GetScanJobLabels returns the label which marks scan Jobs as a labels set, e.g. to select scan Jobs.
+// The set includes the LabelInstanceID label if the instance ID is configured.
+func (c Operator) GetScanJobLabels() map[string]string {
+	key, value := c.GetScanJobLabel()
+	scanJobLabels := map[string]string{key: value}
+	if c.InstanceID != "" {
+		scanJobLabels[LabelInstanceID] = c.InstanceID
+	}
+	return scanJobLabels
+}
+
+// IsOwnedByInstance returns true if the object with the specified labels was created by this instance of the
+// operator, i.e. its LabelInstanceID label equals the configured instance ID. Objects without the label are
+// owned by the instance whose ID is not configured.
+func (c Operator) IsOwnedByInstance(objectLabels map[string]string) bool {
+	return objectLabels[LabelInstanceID] == c.InstanceID
+}
+
+// ValidateScanJobLabel checks that the configured key and value of the label which marks scan Jobs are valid.
+func (c Operator) ValidateScanJobLabel() error {
+	key, value := c.GetScanJobLabel()
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return fmt.Errorf("%s must be a valid label key: %s", "OPERATOR_SCAN_JOB_LABEL_KEY", strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return fmt.Errorf("%s must be a valid label value: %s", "OPERATOR_SCAN_JOB_LABEL_VALUE", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// GetScanJobServiceAccount returns the name of the service account to run scan Jobs.
+// Defaults to the service account of the operator, unless scan Jobs run in a namespace other
+// than the operator namespace, in which case a blank name stands for the default service account
+// of that namespace.
+func (c Operator) GetScanJobServiceAccount() string {
+	if c.ScanJobServiceAccount != "" {
+		return c.ScanJobServiceAccount
+	}
+	if c.GetScanJobsNamespace() != c.Namespace {
+		return ""
+	}
+	return c.ServiceAccount
+}
+
+// GetScanJobsNamespace returns the namespace where vulnerability scan Jobs are created, along with
+// Secrets holding registry credentials of scanned images. Defaults to the operator namespace rather than
+// the namespace of the scanned workload, because the Job controller watches scan Jobs in a single namespace,
+// and the operator is not granted permissions to manage Jobs and Secrets in target namespaces.
+func (c Operator) GetScanJobsNamespace() string {
+	if c.ScanJobsNamespace != "" {
+		return c.ScanJobsNamespace
+	}
+	return c.Namespace
+}
+
+// GetScanJobTTLSecondsAfterFinished returns the number of seconds after which finished scan Jobs are deleted by
+// the TTL controller of Kubernetes, or nil if OPERATOR_SCAN_JOB_TTL_SECONDS is not set.
+func (c Operator) GetScanJobTTLSecondsAfterFinished() *int32 {
+	if c.ScanJobTTLSeconds <= 0 {
+		return nil
+	}
+	ttl := c.ScanJobTTLSeconds
+	return &ttl
+}
+
+// IsMetricsTLSEnabled returns true if metrics are served over HTTPS with the configured certificate and key,
+// rather than over plain HTTP by the controllers manager.
+func (c Operator) IsMetricsTLSEnabled() bool {
+	return c.MetricsCertFile != "" && c.MetricsKeyFile != ""
+}
+
+// GetScanJobReadWindow returns the length of time the operator may take to read logs of a finished scan Job,
+// i.e. the timeout of reading logs plus the interval after which running scan Jobs are checked again.
+func (c Operator) GetScanJobReadWindow() time.Duration {
+	return c.LogReadTimeout + c.ReconcileRequeueInterval
+}
+
+// GetScanJobTTLWarning returns the warning about OPERATOR_SCAN_JOB_TTL_SECONDS shorter than the read window
+// of scan Jobs, in which case finished scan Jobs may be deleted before their logs are read. Returns a blank
+// string if the TTL is not set or is long enough.
+func (c Operator) GetScanJobTTLWarning() string {
+	ttl := c.GetScanJobTTLSecondsAfterFinished()
+	if ttl == nil {
+		return ""
+	}
+	if window := c.GetScanJobReadWindow(); time.Duration(*ttl)*time.Second < window {
+		return fmt.Sprintf("%s of %ds is shorter than the read window of scan jobs %s, finished scan jobs may be deleted before their logs are read",
+			"OPERATOR_SCAN_JOB_TTL_SECONDS", *ttl, window)
+	}
+	return ""
+}
+
+// GetScanJobResourceRequirements returns compute resources required by containers of a scan Job.
+// A blank quantity is omitted from the returned requests or limits.
+func (c Operator) GetScanJobResourceRequirements() (corev1.ResourceRequirements, error) {
+	requirements := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{},
+		Limits:   corev1.ResourceList{},
+	}
+	for _, q := range []struct {
+		list  corev1.ResourceList
+		name  corev1.ResourceName
+		key   string
+		value string
+	}{
+		{requirements.Requests, corev1.ResourceCPU, "OPERATOR_SCAN_JOB_CPU_REQUEST", c.ScanJobCPURequest},
+		{requirements.Requests, corev1.ResourceMemory, "OPERATOR_SCAN_JOB_MEMORY_REQUEST", c.ScanJobMemoryRequest},
+		{requirements.Limits, corev1.ResourceCPU, "OPERATOR_SCAN_JOB_CPU_LIMIT", c.ScanJobCPULimit},
+		{requirements.Limits, corev1.ResourceMemory, "OPERATOR_SCAN_JOB_MEMORY_LIMIT", c.ScanJobMemoryLimit},
+	} {
+		if q.value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			return corev1.ResourceRequirements{}, fmt.Errorf("parsing %s: %w", q.key, err)
+		}
+		q.list[q.name] = quantity
+	}
+	return requirements, nil
+}
+
+// GetScanJobImagePullPolicy returns the pull policy of scanner images run by scan Jobs.
+// Defaults to IfNotPresent if the pull policy is not set.
+func (c Operator) GetScanJobImagePullPolicy() (corev1.PullPolicy, error) {
+	switch policy := corev1.PullPolicy(c.ScanJobImagePullPolicy); policy {
+	case "":
+		return corev1.PullIfNotPresent, nil
+	case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%s must be one of %s, %s, or %s but got %q", "OPERATOR_SCAN_JOB_IMAGE_PULL_POLICY",
+			corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever, policy)
+	}
+}
+
+// GetScanJobNodeSelector returns the node selector of scan Jobs parsed from the JSON object,
+// e.g. `{"kubernetes.io/os":"linux"}`. Returns nil if the node selector is not set.
+func (c Operator) GetScanJobNodeSelector() (map[string]string, error) {
+	if c.ScanJobNodeSelector == "" {
+		return nil, nil
+	}
+	var nodeSelector map[string]string
+	err := json.Unmarshal([]byte(c.ScanJobNodeSelector), &nodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_SCAN_JOB_NODE_SELECTOR", err)
+	}
+	return nodeSelector, nil
+}
+
+// GetScanJobTolerations returns tolerations of scan Jobs parsed from the JSON array,
+// e.g. `[{"key":"dedicated","operator":"Equal","value":"scanners","effect":"NoSchedule"}]`.
+// Returns nil if tolerations are not set.
+func (c Operator) GetScanJobTolerations() ([]corev1.Toleration, error) {
+	if c.ScanJobTolerations == "" {
+		return nil, nil
+	}
+	var tolerations []corev1.Toleration
+	err := json.Unmarshal([]byte(c.ScanJobTolerations), &tolerations)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_SCAN_JOB_TOLERATIONS", err)
+	}
+	return tolerations, nil
+}
+
+// GetScanJobAffinity returns the affinity of scan Jobs parsed from the JSON object with the
+// same structure as the affinity of a PodSpec. Returns nil if the affinity is not set.
+func (c Operator) GetScanJobAffinity() (*corev1.Affinity, error) {
+	if c.ScanJobAffinity == "" {
+		return nil, nil
+	}
+	affinity := &corev1.Affinity{}
+	err := json.Unmarshal([]byte(c.ScanJobAffinity), affinity)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_SCAN_JOB_AFFINITY", err)
+	}
+	return affinity, nil
+}
+
+// GetScanJobHostAliases returns host aliases of scan Jobs parsed from the JSON array,
+// e.g. `[{"ip":"10.0.0.10","hostnames":["registry.corp"]}]`, which are added to the hosts
+// file of scan Job Pods. Returns nil if host aliases are not set.
+func (c Operator) GetScanJobHostAliases() ([]corev1.HostAlias, error) {
+	if c.ScanJobHostAliases == "" {
+		return nil, nil
+	}
+	var hostAliases []corev1.HostAlias
+	err := json.Unmarshal([]byte(c.ScanJobHostAliases), &hostAliases)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_SCAN_JOB_HOST_ALIASES", err)
+	}
+	return hostAliases, nil
+}
+
+// GetScanJobEnvFrom returns sources of environment variables of scan Job containers parsed from the JSON
+// array with the same structure as the envFrom of a container, e.g. `[{"secretRef":{"name":"scanner-env"}}]`.
+// Returns nil if sources are not set.
+func (c Operator) GetScanJobEnvFrom() ([]corev1.EnvFromSource, error) {
+	if c.ScanJobEnvFrom == "" {
+		return nil, nil
+	}
+	var envFrom []corev1.EnvFromSource
+	err := json.Unmarshal([]byte(c.ScanJobEnvFrom), &envFrom)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_SCAN_JOB_ENV_FROM", err)
+	}
+	return envFrom, nil
+}
+
+// GetScanJobDNSConfig returns the DNS config of scan Jobs parsed from the JSON object with the
+// same structure as the dnsConfig of a PodSpec, e.g. `{"searches":["corp.local"]}`. Returns nil
+// if the DNS config is not set.
+func (c Operator) GetScanJobDNSConfig() (*corev1.PodDNSConfig, error) {
+	if c.ScanJobDNSConfig == "" {
+		return nil, nil
+	}
+	dnsConfig := &corev1.PodDNSConfig{}
+	err := json.Unmarshal([]byte(c.ScanJobDNSConfig), dnsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_SCAN_JOB_DNS_CONFIG", err)
+	}
+	return dnsConfig, nil
+}
+
+// GetScanJobVolumes returns additional volumes of scan Job Pods parsed from the JSON array with the same
+// structure as the volumes of a Pod spec, e.g. `[{"name":"trivy-cache","persistentVolumeClaim":{"claimName":"trivy-cache"}}]`.
+// Returns nil if volumes are not set.
+func (c Operator) GetScanJobVolumes() ([]corev1.Volume, error) {
+	if c.ScanJobVolumes == "" {
+		return nil, nil
+	}
+	var volumes []corev1.Volume
+	err := json.Unmarshal([]byte(c.ScanJobVolumes), &volumes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_SCAN_JOB_VOLUMES", err)
+	}
+	return volumes, nil
+}
+
+// GetScanJobVolumeMounts returns additional volume mounts of scan Job containers which run the scanner parsed
+// from the JSON array with the same structure as the volumeMounts of a container, e.g.
+// `[{"name":"trivy-cache","mountPath":"/var/lib/trivy"}]`. Returns nil if volume mounts are not set.
+func (c Operator) GetScanJobVolumeMounts() ([]corev1.VolumeMount, error) {
+	if c.ScanJobVolumeMounts == "" {
+		return nil, nil
+	}
+	var volumeMounts []corev1.VolumeMount
+	err := json.Unmarshal([]byte(c.ScanJobVolumeMounts), &volumeMounts)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_SCAN_JOB_VOLUME_MOUNTS", err)
+	}
+	return volumeMounts, nil
+}
+
+// ValidateScanJobVolumes checks that additional volumes of scan Jobs have unique and valid names, and that
+// additional volume mounts refer to these volumes.
+func (c Operator) ValidateScanJobVolumes() error {
+	volumes, err := c.GetScanJobVolumes()
+	if err != nil {
+		return err
+	}
+	volumeMounts, err := c.GetScanJobVolumeMounts()
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool)
+	for _, volume := range volumes {
+		if errs := validation.IsDNS1123Label(volume.Name); len(errs) > 0 {
+			return fmt.Errorf("%s must contain valid volume names: %q: %s", "OPERATOR_SCAN_JOB_VOLUMES", volume.Name, strings.Join(errs, "; "))
+		}
+		if names[volume.Name] {
+			return fmt.Errorf("%s must not contain duplicate volume names: %q", "OPERATOR_SCAN_JOB_VOLUMES", volume.Name)
+		}
+		names[volume.Name] = true
+	}
+	for _, volumeMount := range volumeMounts {
+		if !names[volumeMount.Name] {
+			return fmt.Errorf("%s must refer to volumes set in %s: %q", "OPERATOR_SCAN_JOB_VOLUME_MOUNTS", "OPERATOR_SCAN_JOB_VOLUMES", volumeMount.Name)
+		}
+		if volumeMount.MountPath == "" {
+			return fmt.Errorf("%s must set mount paths: %q", "OPERATOR_SCAN_JOB_VOLUME_MOUNTS", volumeMount.Name)
+		}
+	}
+	return nil
+}
+
+// GetScanJobSecurityContext returns security contexts of scan Job Pods and their containers.
+// Returns nil contexts if the security context is not set. The restricted security context runs
+// containers as non-root users without privilege escalation and with all capabilities dropped.
+// The user ID is not set, so that it can be assigned by the platform, e.g. by OpenShift SCCs.
+func (c Operator) GetScanJobSecurityContext() (*corev1.PodSecurityContext, *corev1.SecurityContext, error) {
+	switch c.ScanJobSecurityContext {
+	case "":
+		return nil, nil, nil
+	case SecurityContextRestricted:
+		return &corev1.PodSecurityContext{
+			RunAsNonRoot: pointer.BoolPtr(true),
+		}, &corev1.SecurityContext{
+			RunAsNonRoot:             pointer.BoolPtr(true),
+			AllowPrivilegeEscalation: pointer.BoolPtr(false),
+			Privileged:               pointer.BoolPtr(false),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("%s must be %s but got %q", "OPERATOR_SCAN_JOB_SECURITY_CONTEXT",
+			SecurityContextRestricted, c.ScanJobSecurityContext)
+	}
+}
+
+// GetRegistryRateLimit returns the number of scan Jobs which may be created per interval for images of the same
+// registry host, parsed from the `<count>/<interval>` format, e.g. `10/m` or `100/6h`. The interval is a duration
+// with the count of 1 implied if omitted. Returns nil if the rate limit is not set.
+func (c Operator) GetRegistryRateLimit() (*RateLimit, error) {
+	if c.RegistryRateLimit == "" {
+		return nil, nil
+	}
+	parts := strings.SplitN(c.RegistryRateLimit, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("%s must be in the <count>/<interval> format but got %q", "OPERATOR_REGISTRY_RATE_LIMIT",
+			c.RegistryRateLimit)
+	}
+	count, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_REGISTRY_RATE_LIMIT", err)
+	}
+	interval := parts[1]
+	if interval != "" && (interval[0] < '0' || interval[0] > '9') {
+		interval = "1" + interval
+	}
+	duration, err := time.ParseDuration(interval)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", "OPERATOR_REGISTRY_RATE_LIMIT", err)
+	}
+	if count <= 0 || duration <= 0 {
+		return nil, fmt.Errorf("%s must have positive count and interval but got %q", "OPERATOR_REGISTRY_RATE_LIMIT",
+			c.RegistryRateLimit)
+	}
+	return &RateLimit{Count: count, Interval: duration}, nil
+}
+
+// GetReportAnnotations returns annotations of VulnerabilityReports parsed from comma separated `key=value`
+// pairs, e.g. `team=payments,owner=jane@example.com`. Keys prefixed with `starboard.aquasecurity.github.io/` are
+// reserved for annotations set by the operator. Returns nil if annotations are not set.
+func (c Operator) GetReportAnnotations() (map[string]string, error) {
+	if c.ReportAnnotations == "" {
+		return nil, nil
+	}
+	annotations := make(map[string]string)
+	for _, pair := range strings.Split(c.ReportAnnotations, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s must be comma separated key=value pairs but got %q", "OPERATOR_REPORT_ANNOTATIONS",
+				pair)
+		}
+		key := strings.TrimSpace(parts[0])
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("%s must have valid annotation keys but got %q: %s", "OPERATOR_REPORT_ANNOTATIONS",
+				key, strings.Join(errs, "; "))
+		}
+		if strings.HasPrefix(key, "starboard.aquasecurity.github.io/") {
+			return nil, fmt.Errorf("%s must not have keys prefixed with %s but got %q", "OPERATOR_REPORT_ANNOTATIONS",
+				"starboard.aquasecurity.github.io/", key)
+		}
+		annotations[key] = strings.TrimSpace(parts[1])
+	}
+	return annotations, nil
+}
+
+// GetScanJobPodAnnotations returns annotations of scan Job Pods required by the configured security context.
+// The seccomp profile is set with the annotation, because the field of the security context is not
+// supported by all Kubernetes versions the operator runs on.
+func (c Operator) GetScanJobPodAnnotations() map[string]string {
+	if c.ScanJobSecurityContext != SecurityContextRestricted {
+		return nil
+	}
+	return map[string]string{
+		corev1.SeccompPodAnnotationKey: corev1.SeccompProfileRuntimeDefault,
+	}
+}
+
+// GetCommand returns the command of Trivy scan containers parsed from the JSON array,
+// e.g. `["/usr/local/bin/scan.sh"]`. Returns nil if the command is not set, in which case
+// the built-in trivy command is used.
+func (c ScannerTrivy) GetCommand() ([]string, error) {
+	return parseStringArray("OPERATOR_SCANNER_TRIVY_COMMAND", c.Command)
+}
+
+// GetArgs returns arguments of Trivy scan containers parsed from the JSON array,
+// e.g. `["--format","json"]`. Returns nil if arguments are not set, in which case
+// the built-in arguments are used.
+func (c ScannerTrivy) GetArgs() ([]string, error) {
+	return parseStringArray("OPERATOR_SCANNER_TRIVY_ARGS", c.Args)
+}
+
+func parseStringArray(name, value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var values []string
+	err := json.Unmarshal([]byte(value), &values)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return values, nil
+}
+
+// Validate checks that Trivy mode is supported and that the server URL is set in ClientServer mode.
+// It also checks that the scan type is supported, where the rootfs scan type requires Standalone mode.
+// If SBOM generation is enabled, it also checks that the SBOM format is supported.
+func (c ScannerTrivy) Validate() error {
+	err := ValidateImageRef("OPERATOR_SCANNER_TRIVY_IMAGE", c.ImageRef)
+	if err != nil {
+		return err
+	}
+	switch c.Mode {
+	case TrivyModeStandalone:
+	case TrivyModeClientServer:
+		if c.ServerURL == "" {
+			return fmt.Errorf("%s must be set in %s mode", "OPERATOR_SCANNER_TRIVY_SERVER_URL", TrivyModeClientServer)
+		}
+	default:
+		return fmt.Errorf("unrecognized %s: %q", "OPERATOR_SCANNER_TRIVY_MODE", c.Mode)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("%s must not be negative", "OPERATOR_SCANNER_TRIVY_TIMEOUT")
+	}
+	switch c.ScanType {
+	case "", TrivyScanTypeImage:
+	case TrivyScanTypeRootfs:
+		if c.Mode != TrivyModeStandalone {
+			return fmt.Errorf("%s %s is supported only in %s mode", "OPERATOR_SCANNER_TRIVY_SCAN_TYPE", TrivyScanTypeRootfs, TrivyModeStandalone)
+		}
+	default:
+		return fmt.Errorf("unrecognized %s: %q", "OPERATOR_SCANNER_TRIVY_SCAN_TYPE", c.ScanType)
+	}
+	switch c.OutputMode {
+	case "", ScanOutputModeLogs:
+	case ScanOutputModeFile:
+		// Scanned images run in the rootfs scan type might not have the shell which prints the output file.
+		if c.ScanType == TrivyScanTypeRootfs {
+			return fmt.Errorf("%s %s is not supported with %s %s", "OPERATOR_SCANNER_TRIVY_SCAN_OUTPUT_MODE", ScanOutputModeFile,
+				"OPERATOR_SCANNER_TRIVY_SCAN_TYPE", TrivyScanTypeRootfs)
+		}
+	default:
+		return fmt.Errorf("unrecognized %s: %q", "OPERATOR_SCANNER_TRIVY_SCAN_OUTPUT_MODE", c.OutputMode)
+	}
+	if c.Platform != "" {
+		parts := strings.Split(c.Platform, "/")
+		if (len(parts) != 2 && len(parts) != 3) || parts[0] == "" || parts[1] == "" || parts[len(parts)-1] == "" {
+			return fmt.Errorf("%s must be in the <os>/<arch>[/<variant>] format but got %q",
+				"OPERATOR_SCANNER_TRIVY_PLATFORM", c.Platform)
+		}
+		// Images run in the rootfs scan type are pulled by the kubelet in the platform of the node.
+		if c.ScanType == TrivyScanTypeRootfs {
+			return fmt.Errorf("%s is not supported with %s %s", "OPERATOR_SCANNER_TRIVY_PLATFORM",
+				"OPERATOR_SCANNER_TRIVY_SCAN_TYPE", TrivyScanTypeRootfs)
+		}
+	}
+	if c.GenerateSBOM && c.SBOMFormat != SBOMFormatCycloneDX {
+		return fmt.Errorf("unsupported %s: %q", "OPERATOR_SCANNER_TRIVY_SBOM_FORMAT", c.SBOMFormat)
+	}
+	_, err = c.GetCommand()
+	if err != nil {
+		return err
+	}
+	_, err = c.GetArgs()
+	if err != nil {
+		return err
 	}
-	return []string{}
+	return nil
 }
 
 // InstallMode represents multitenancy support defined by the Operator Lifecycle Manager spec.
@@ -1,11 +1,17 @@
 package etc_test
 
 import (
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aquasecurity/starboard-operator/pkg/etc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
 )
 
 func TestOperator_GetTargetNamespaces(t *testing.T) {
@@ -35,6 +41,41 @@ func TestOperator_GetTargetNamespaces(t *testing.T) {
 			},
 			expectedTargetNamespaces: []string{"foo", "bar", "baz"},
 		},
+		{
+			name: "Should trim whitespace",
+			operator: etc.Operator{
+				TargetNamespaces: " foo , bar\t",
+			},
+			expectedTargetNamespaces: []string{"foo", "bar"},
+		},
+		{
+			name: "Should drop empty entries",
+			operator: etc.Operator{
+				TargetNamespaces: ",foo,, ,bar,",
+			},
+			expectedTargetNamespaces: []string{"foo", "bar"},
+		},
+		{
+			name: "Should drop duplicate entries",
+			operator: etc.Operator{
+				TargetNamespaces: "foo,bar,foo, bar",
+			},
+			expectedTargetNamespaces: []string{"foo", "bar"},
+		},
+		{
+			name: "Should return all namespaces when blank",
+			operator: etc.Operator{
+				TargetNamespaces: " , ",
+			},
+			expectedTargetNamespaces: []string{},
+		},
+		{
+			name: "Should return all namespaces for wildcard",
+			operator: etc.Operator{
+				TargetNamespaces: " * ",
+			},
+			expectedTargetNamespaces: []string{},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -44,62 +85,1765 @@ func TestOperator_GetTargetNamespaces(t *testing.T) {
 	}
 }
 
-func TestOperator_GetInstallMode(t *testing.T) {
+func TestOperator_ValidateTargetNamespaces(t *testing.T) {
 	testCases := []struct {
-		name string
+		name             string
+		targetNamespaces string
+		expectedError    string
+	}{
+		{
+			name:             "Should accept all namespaces",
+			targetNamespaces: "",
+		},
+		{
+			name:             "Should accept wildcard",
+			targetNamespaces: "*",
+		},
+		{
+			name:             "Should accept namespace names with whitespace and empty entries",
+			targetNamespaces: " foo,,bar-1 ,",
+		},
+		{
+			name:             "Should return error listing invalid namespace names",
+			targetNamespaces: "foo,Bar,baz_qux,-dev",
+			expectedError:    `OPERATOR_TARGET_NAMESPACES must contain valid namespace names, invalid: "Bar", "baz_qux", "-dev"`,
+		},
+		{
+			name:             "Should return error when namespace name is too long",
+			targetNamespaces: strings.Repeat("a", 64),
+			expectedError:    `OPERATOR_TARGET_NAMESPACES must contain valid namespace names, invalid: "` + strings.Repeat("a", 64) + `"`,
+		},
+		{
+			name:             "Should return error when wildcard is combined with namespace names",
+			targetNamespaces: "*,foo",
+			expectedError:    `OPERATOR_TARGET_NAMESPACES must not combine "*" with namespace names`,
+		},
+	}
 
-		operator            etc.Operator
-		expectedInstallMode etc.InstallMode
-		expectedError       string
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := etc.Operator{TargetNamespaces: tc.targetNamespaces}.ValidateTargetNamespaces()
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestOperator_GetScanJobPropagateLabels(t *testing.T) {
+	testCases := []struct {
+		name         string
+		operator     etc.Operator
+		expectedKeys []string
 	}{
 		{
-			name: "Should resolve OwnNamespace",
+			name:         "Should return nil when not set",
+			operator:     etc.Operator{},
+			expectedKeys: nil,
+		},
+		{
+			name:         "Should return trimmed keys without blanks",
+			operator:     etc.Operator{ScanJobPropagateLabels: "team, cost-center,,app.kubernetes.io/part-of"},
+			expectedKeys: []string{"team", "cost-center", "app.kubernetes.io/part-of"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedKeys, tc.operator.GetScanJobPropagateLabels())
+		})
+	}
+}
+
+func TestOperator_IsNamespaceExcluded(t *testing.T) {
+	testCases := []struct {
+		name             string
+		env              map[string]string
+		namespace        string
+		expectedExcluded bool
+	}{
+		{
+			name:             "Should exclude kube-system by default",
+			namespace:        "kube-system",
+			expectedExcluded: true,
+		},
+		{
+			name:             "Should exclude kube-node-lease by default",
+			namespace:        "kube-node-lease",
+			expectedExcluded: true,
+		},
+		{
+			name:             "Should not exclude default namespace by default",
+			namespace:        "default",
+			expectedExcluded: false,
+		},
+		{
+			name:             "Should override default excluded namespaces",
+			env:              map[string]string{"OPERATOR_EXCLUDE_NAMESPACES": "istio-system, monitoring"},
+			namespace:        "monitoring",
+			expectedExcluded: true,
+		},
+		{
+			name:             "Should not exclude kube-system when default is overridden",
+			env:              map[string]string{"OPERATOR_EXCLUDE_NAMESPACES": "istio-system"},
+			namespace:        "kube-system",
+			expectedExcluded: false,
+		},
+		{
+			name:             "Should not exclude any namespace when cleared",
+			env:              map[string]string{"OPERATOR_EXCLUDE_NAMESPACES": ""},
+			namespace:        "kube-system",
+			expectedExcluded: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, value := range tc.env {
+				require.NoError(t, os.Setenv(name, value))
+			}
+			defer func() {
+				for name := range tc.env {
+					_ = os.Unsetenv(name)
+				}
+			}()
+			config, err := etc.GetOperatorConfig()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedExcluded, config.Operator.IsNamespaceExcluded(tc.namespace))
+		})
+	}
+}
+
+func TestOperator_IsImageAllowed(t *testing.T) {
+	testCases := []struct {
+		name     string
+		operator etc.Operator
+		image    string
+		expected bool
+	}{
+		{
+			name:     "Should allow any registry by default",
+			operator: etc.Operator{},
+			image:    "registry.corp:5000/nginx:1.16",
+			expected: true,
+		},
+		{
+			name:     "Should allow registry in allowlist",
+			operator: etc.Operator{ScanAllowRegistries: "quay.io, registry.corp:5000"},
+			image:    "registry.corp:5000/nginx:1.16",
+			expected: true,
+		},
+		{
+			name:     "Should not allow registry missing in allowlist",
+			operator: etc.Operator{ScanAllowRegistries: "quay.io,registry.corp:5000"},
+			image:    "gcr.io/distroless/base",
+			expected: false,
+		},
+		{
+			name:     "Should allow registry matching wildcard in allowlist",
+			operator: etc.Operator{ScanAllowRegistries: "*.corp.example.com"},
+			image:    "eu.registry.corp.example.com/nginx:1.16",
+			expected: true,
+		},
+		{
+			name:     "Should not match domain itself with wildcard of subdomains",
+			operator: etc.Operator{ScanAllowRegistries: "*.corp.example.com"},
+			image:    "corp.example.com/nginx:1.16",
+			expected: false,
+		},
+		{
+			name:     "Should allow Docker Hub images by alias",
+			operator: etc.Operator{ScanAllowRegistries: "docker.io"},
+			image:    "nginx:1.16",
+			expected: true,
+		},
+		{
+			name:     "Should not allow registry in denylist",
+			operator: etc.Operator{ScanDenyRegistries: "docker.io"},
+			image:    "library/nginx:1.16",
+			expected: false,
+		},
+		{
+			name:     "Should allow registry missing in denylist",
+			operator: etc.Operator{ScanDenyRegistries: "docker.io"},
+			image:    "quay.io/prometheus/prometheus:v2.20.0",
+			expected: true,
+		},
+		{
+			name:     "Should prefer denylist over allowlist",
+			operator: etc.Operator{ScanAllowRegistries: "*.corp.example.com", ScanDenyRegistries: "untrusted.corp.example.com"},
+			image:    "untrusted.corp.example.com/nginx:1.16",
+			expected: false,
+		},
+		{
+			name:     "Should prefer wildcard in denylist over allowlist",
+			operator: etc.Operator{ScanAllowRegistries: "registry.corp.example.com", ScanDenyRegistries: "*.example.com"},
+			image:    "registry.corp.example.com/nginx:1.16",
+			expected: false,
+		},
+		{
+			name:     "Should allow malformed image by default",
+			operator: etc.Operator{ScanDenyRegistries: "docker.io"},
+			image:    "NGINX",
+			expected: true,
+		},
+		{
+			name:     "Should not allow malformed image with allowlist",
+			operator: etc.Operator{ScanAllowRegistries: "docker.io"},
+			image:    "NGINX",
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.operator.IsImageAllowed(tc.image))
+		})
+	}
+}
+
+func TestOperator_IsImageExcluded(t *testing.T) {
+	testCases := []struct {
+		name     string
+		operator etc.Operator
+		image    string
+		expected bool
+	}{
+		{
+			name:     "Should not exclude any image by default",
+			operator: etc.Operator{},
+			image:    "docker.io/istio/proxyv2:1.8.0",
+			expected: false,
+		},
+		{
+			name:     "Should exclude image matching repository without tag",
+			operator: etc.Operator{ExcludeImages: "istio/proxyv2"},
+			image:    "istio/proxyv2:1.8.0",
+			expected: true,
+		},
+		{
+			name:     "Should exclude image pinned by digest matching repository",
+			operator: etc.Operator{ExcludeImages: "gcr.io/istio-release/proxyv2"},
+			image:    "gcr.io/istio-release/proxyv2@sha256:4cd8d16e2b4d3e2f8d0a1c3e8a1f3a6f26e6c2a6f0ac6f6f0e45e5b5d5b5c5d5",
+			expected: true,
+		},
+		{
+			name:     "Should exclude image matching wildcard of registry",
+			operator: etc.Operator{ExcludeImages: "*/istio/proxyv2"},
+			image:    "istio/proxyv2:1.8.0",
+			expected: true,
+		},
+		{
+			name:     "Should exclude Docker Hub image by alias",
+			operator: etc.Operator{ExcludeImages: "docker.io/istio/*"},
+			image:    "istio/proxyv2:1.8.0",
+			expected: true,
+		},
+		{
+			name:     "Should exclude image matching wildcard of tag",
+			operator: etc.Operator{ExcludeImages: "quay.io/prometheus/node-exporter, istio/proxyv2:1.8.*"},
+			image:    "istio/proxyv2:1.8.0",
+			expected: true,
+		},
+		{
+			name:     "Should not exclude image with other tag",
+			operator: etc.Operator{ExcludeImages: "istio/proxyv2:1.8.*"},
+			image:    "istio/proxyv2:1.9.0",
+			expected: false,
+		},
+		{
+			name:     "Should not exclude image from other registry",
+			operator: etc.Operator{ExcludeImages: "docker.io/istio/proxyv2"},
+			image:    "gcr.io/istio-release/proxyv2:1.8.0",
+			expected: false,
+		},
+		{
+			name:     "Should not match path segments with wildcard",
+			operator: etc.Operator{ExcludeImages: "*/proxyv2"},
+			image:    "gcr.io/istio-release/proxyv2:1.8.0",
+			expected: false,
+		},
+		{
+			name:     "Should exclude repository with registry port",
+			operator: etc.Operator{ExcludeImages: "registry.corp:5000/envoy"},
+			image:    "registry.corp:5000/envoy:1.16",
+			expected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.operator.IsImageExcluded(tc.image))
+		})
+	}
+
+	t.Run("Should return error when pattern is invalid", func(t *testing.T) {
+		require.NoError(t, os.Setenv("OPERATOR_EXCLUDE_IMAGES", "istio/[proxyv2"))
+		defer func() {
+			_ = os.Unsetenv("OPERATOR_EXCLUDE_IMAGES")
+		}()
+		_, err := etc.GetOperatorConfig()
+		require.EqualError(t, err, `OPERATOR_EXCLUDE_IMAGES contains invalid pattern "istio/[proxyv2": syntax error in pattern`)
+	})
+}
+
+func TestOperator_ValidateScanRegistries(t *testing.T) {
+	t.Run("Should accept valid patterns", func(t *testing.T) {
+		assert.NoError(t, etc.Operator{ScanAllowRegistries: "*.corp.example.com,quay.io", ScanDenyRegistries: "docker.io"}.ValidateScanRegistries())
+	})
+
+	t.Run("Should return error when pattern is malformed", func(t *testing.T) {
+		err := etc.Operator{ScanDenyRegistries: "quay.io,[corp"}.ValidateScanRegistries()
+		assert.EqualError(t, err, `OPERATOR_SCAN_DENY_REGISTRIES contains invalid pattern "[corp": syntax error in pattern`)
+	})
+}
+
+func TestGetOperatorConfig_ReportScope(t *testing.T) {
+	testCases := []struct {
+		name          string
+		value         *string
+		expectedScope etc.ReportScope
+		expectedError string
+	}{
+		{
+			name:          "Should default to owner scope",
+			expectedScope: etc.ReportScopeOwner,
+		},
+		{
+			name:          "Should accept pod scope",
+			value:         pointer.StringPtr("pod"),
+			expectedScope: etc.ReportScopePod,
+		},
+		{
+			name:          "Should return error when scope is unrecognized",
+			value:         pointer.StringPtr("container"),
+			expectedError: `unrecognized OPERATOR_REPORT_SCOPE: "container"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != nil {
+				require.NoError(t, os.Setenv("OPERATOR_REPORT_SCOPE", *tc.value))
+				defer func() {
+					_ = os.Unsetenv("OPERATOR_REPORT_SCOPE")
+				}()
+			}
+			config, err := etc.GetOperatorConfig()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedScope, config.Operator.ReportScope)
+		})
+	}
+}
+
+func TestOperator_GetScanLabelSelector(t *testing.T) {
+	t.Run("Should match everything when selector is not set", func(t *testing.T) {
+		selector, err := etc.Operator{}.GetScanLabelSelector()
+		require.NoError(t, err)
+		assert.True(t, selector.Empty())
+	})
+
+	t.Run("Should parse selector", func(t *testing.T) {
+		selector, err := etc.Operator{ScanLabelSelector: "scan=true"}.GetScanLabelSelector()
+		require.NoError(t, err)
+		assert.Equal(t, "scan=true", selector.String())
+	})
+
+	t.Run("Should return error when selector is malformed", func(t *testing.T) {
+		_, err := etc.Operator{ScanLabelSelector: "scan in true"}.GetScanLabelSelector()
+		assert.EqualError(t, err, "parsing OPERATOR_SCAN_LABEL_SELECTOR: unable to parse requirement: found 'true' expected: '('")
+	})
+}
+
+func TestOperator_GetTargetNamespaceSelector(t *testing.T) {
+	testCases := []struct {
+		name             string
+		operator         etc.Operator
+		expectedSelector string
+		expectedError    string
+	}{
+		{
+			name:     "Should return nil when selector is not set",
+			operator: etc.Operator{},
+		},
+		{
+			name: "Should parse equality based selector",
 			operator: etc.Operator{
-				Namespace:        "operators",
-				TargetNamespaces: "operators",
+				TargetNamespaceSelector: "starboard.aquasecurity.github.io/scan=true",
 			},
-			expectedInstallMode: etc.InstallModeOwnNamespace,
-			expectedError:       "",
+			expectedSelector: "starboard.aquasecurity.github.io/scan=true",
 		},
 		{
-			name: "Should resolve SingleNamespace",
+			name: "Should parse set based selector",
 			operator: etc.Operator{
-				Namespace:        "operators",
-				TargetNamespaces: "foo",
+				TargetNamespaceSelector: "env in (dev,staging),!legacy",
 			},
-			expectedInstallMode: etc.InstallModeSingleNamespace,
-			expectedError:       "",
+			expectedSelector: "env in (dev,staging),!legacy",
 		},
 		{
-			name: "Should resolve MultiNamespace",
+			name: "Should return error when selector is malformed",
 			operator: etc.Operator{
-				Namespace:        "operators",
-				TargetNamespaces: "foo,bar,baz",
+				TargetNamespaceSelector: "env in dev",
 			},
-			expectedInstallMode: etc.InstallModeMultiNamespace,
-			expectedError:       "",
+			expectedError: "parsing OPERATOR_TARGET_NAMESPACE_SELECTOR: unable to parse requirement: found 'dev' expected: '('",
 		},
 		{
-			name: "Should resolve AllNamespaces",
+			name: "Should return error when target namespaces are also set",
 			operator: etc.Operator{
-				Namespace:        "operators",
-				TargetNamespaces: "",
+				TargetNamespaces:        "default",
+				TargetNamespaceSelector: "env=dev",
 			},
-			expectedInstallMode: etc.InstallModeAllNamespaces,
-			expectedError:       "",
+			expectedError: "OPERATOR_TARGET_NAMESPACES and OPERATOR_TARGET_NAMESPACE_SELECTOR are mutually exclusive",
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			installMode, err := tc.operator.GetInstallMode()
+			selector, err := tc.operator.GetTargetNamespaceSelector()
+			switch {
+			case tc.expectedError != "":
+				require.EqualError(t, err, tc.expectedError)
+			case tc.expectedSelector == "":
+				require.NoError(t, err)
+				assert.Nil(t, selector)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedSelector, selector.String())
+			}
+		})
+	}
+}
+
+func TestOperator_GetScanJobImagePullPolicy(t *testing.T) {
+	testCases := []struct {
+		name           string
+		operator       etc.Operator
+		expectedPolicy corev1.PullPolicy
+		expectedError  string
+	}{
+		{
+			name:           "Should default to IfNotPresent",
+			operator:       etc.Operator{},
+			expectedPolicy: corev1.PullIfNotPresent,
+		},
+		{
+			name:           "Should return Always",
+			operator:       etc.Operator{ScanJobImagePullPolicy: "Always"},
+			expectedPolicy: corev1.PullAlways,
+		},
+		{
+			name:           "Should return Never",
+			operator:       etc.Operator{ScanJobImagePullPolicy: "Never"},
+			expectedPolicy: corev1.PullNever,
+		},
+		{
+			name:          "Should return error for unrecognized pull policy",
+			operator:      etc.Operator{ScanJobImagePullPolicy: "always"},
+			expectedError: `OPERATOR_SCAN_JOB_IMAGE_PULL_POLICY must be one of Always, IfNotPresent, or Never but got "always"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := tc.operator.GetScanJobImagePullPolicy()
 			switch tc.expectedError {
 			case "":
 				require.NoError(t, err)
-				assert.Equal(t, tc.expectedInstallMode, installMode)
+				assert.Equal(t, tc.expectedPolicy, policy)
 			default:
 				require.EqualError(t, err, tc.expectedError)
 			}
 		})
 	}
 }
+
+func TestOperator_GetRegistryRateLimit(t *testing.T) {
+	testCases := []struct {
+		name          string
+		operator      etc.Operator
+		expectedLimit *etc.RateLimit
+		expectedError string
+	}{
+		{
+			name:     "Should return nil when rate limit is not set",
+			operator: etc.Operator{},
+		},
+		{
+			name:          "Should return rate limit per minute",
+			operator:      etc.Operator{RegistryRateLimit: "10/m"},
+			expectedLimit: &etc.RateLimit{Count: 10, Interval: time.Minute},
+		},
+		{
+			name:          "Should return rate limit per interval",
+			operator:      etc.Operator{RegistryRateLimit: "100/6h"},
+			expectedLimit: &etc.RateLimit{Count: 100, Interval: 6 * time.Hour},
+		},
+		{
+			name:          "Should return error when interval is missing",
+			operator:      etc.Operator{RegistryRateLimit: "10"},
+			expectedError: `OPERATOR_REGISTRY_RATE_LIMIT must be in the <count>/<interval> format but got "10"`,
+		},
+		{
+			name:          "Should return error when count is invalid",
+			operator:      etc.Operator{RegistryRateLimit: "ten/m"},
+			expectedError: `parsing OPERATOR_REGISTRY_RATE_LIMIT: strconv.Atoi: parsing "ten": invalid syntax`,
+		},
+		{
+			name:          "Should return error when count is not positive",
+			operator:      etc.Operator{RegistryRateLimit: "0/m"},
+			expectedError: `OPERATOR_REGISTRY_RATE_LIMIT must have positive count and interval but got "0/m"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limit, err := tc.operator.GetRegistryRateLimit()
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedLimit, limit)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestOperator_GetReportAnnotations(t *testing.T) {
+	testCases := []struct {
+		name                string
+		operator            etc.Operator
+		expectedAnnotations map[string]string
+		expectedError       string
+	}{
+		{
+			name:     "Should return nil when annotations are not set",
+			operator: etc.Operator{},
+		},
+		{
+			name:     "Should return annotations",
+			operator: etc.Operator{ReportAnnotations: "team=payments, example.com/owner=jane@example.com,empty="},
+			expectedAnnotations: map[string]string{
+				"team":              "payments",
+				"example.com/owner": "jane@example.com",
+				"empty":             "",
+			},
+		},
+		{
+			name:          "Should return error when value is missing",
+			operator:      etc.Operator{ReportAnnotations: "team=payments,owner"},
+			expectedError: `OPERATOR_REPORT_ANNOTATIONS must be comma separated key=value pairs but got "owner"`,
+		},
+		{
+			name:          "Should return error when key is invalid",
+			operator:      etc.Operator{ReportAnnotations: "=payments"},
+			expectedError: `OPERATOR_REPORT_ANNOTATIONS must have valid annotation keys but got "": name part must be non-empty; name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')`,
+		},
+		{
+			name:          "Should return error when key has reserved prefix",
+			operator:      etc.Operator{ReportAnnotations: "starboard.aquasecurity.github.io/scanner-name=Grype"},
+			expectedError: `OPERATOR_REPORT_ANNOTATIONS must not have keys prefixed with starboard.aquasecurity.github.io/ but got "starboard.aquasecurity.github.io/scanner-name"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			annotations, err := tc.operator.GetReportAnnotations()
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedAnnotations, annotations)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestOperator_GetScanJobLabel(t *testing.T) {
+	testCases := []struct {
+		name          string
+		operator      etc.Operator
+		expectedKey   string
+		expectedValue string
+		expectedError string
+	}{
+		{
+			name:          "Should return default label when not set",
+			operator:      etc.Operator{},
+			expectedKey:   "app.kubernetes.io/managed-by",
+			expectedValue: "starboard-operator",
+		},
+		{
+			name:          "Should return configured label",
+			operator:      etc.Operator{ScanJobLabelKey: "example.com/scanner", ScanJobLabelValue: "tenant-a"},
+			expectedKey:   "example.com/scanner",
+			expectedValue: "tenant-a",
+		},
+		{
+			name:          "Should return error when key is invalid",
+			operator:      etc.Operator{ScanJobLabelKey: "example.com/scan job"},
+			expectedError: "OPERATOR_SCAN_JOB_LABEL_KEY must be a valid label key: name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')",
+		},
+		{
+			name:          "Should return error when value is invalid",
+			operator:      etc.Operator{ScanJobLabelValue: "tenant/a"},
+			expectedError: "OPERATOR_SCAN_JOB_LABEL_VALUE must be a valid label value: a valid label must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyValue',  or 'my_value',  or '12345', regex used for validation is '(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?')",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.operator.ValidateScanJobLabel()
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				key, value := tc.operator.GetScanJobLabel()
+				assert.Equal(t, tc.expectedKey, key)
+				assert.Equal(t, tc.expectedValue, value)
+				assert.Equal(t, map[string]string{tc.expectedKey: tc.expectedValue}, tc.operator.GetScanJobLabels())
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestOperator_GetScanJobServiceAccount(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		operator               etc.Operator
+		expectedServiceAccount string
+	}{
+		{
+			name: "Should return operator service account",
+			operator: etc.Operator{
+				ServiceAccount: "starboard-operator",
+			},
+			expectedServiceAccount: "starboard-operator",
+		},
+		{
+			name: "Should return scan job service account",
+			operator: etc.Operator{
+				ServiceAccount:        "starboard-operator",
+				ScanJobServiceAccount: "starboard-scanner",
+			},
+			expectedServiceAccount: "starboard-scanner",
+		},
+		{
+			name: "Should return default service account when scan jobs run in another namespace",
+			operator: etc.Operator{
+				Namespace:         "starboard-operator",
+				ServiceAccount:    "starboard-operator",
+				ScanJobsNamespace: "starboard-scans",
+			},
+			expectedServiceAccount: "",
+		},
+		{
+			name: "Should return scan job service account when scan jobs run in another namespace",
+			operator: etc.Operator{
+				Namespace:             "starboard-operator",
+				ServiceAccount:        "starboard-operator",
+				ScanJobServiceAccount: "starboard-scanner",
+				ScanJobsNamespace:     "starboard-scans",
+			},
+			expectedServiceAccount: "starboard-scanner",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedServiceAccount, tc.operator.GetScanJobServiceAccount())
+		})
+	}
+}
+
+func TestOperator_GetScanJobsNamespace(t *testing.T) {
+	t.Run("Should return operator namespace by default", func(t *testing.T) {
+		assert.Equal(t, "starboard-operator", etc.Operator{Namespace: "starboard-operator"}.GetScanJobsNamespace())
+	})
+
+	t.Run("Should return configured namespace", func(t *testing.T) {
+		assert.Equal(t, "starboard-scans", etc.Operator{
+			Namespace:         "starboard-operator",
+			ScanJobsNamespace: "starboard-scans",
+		}.GetScanJobsNamespace())
+	})
+}
+
+func TestOperator_GetInstallMode(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		operator            etc.Operator
+		expectedInstallMode etc.InstallMode
+		expectedError       string
+	}{
+		{
+			name: "Should resolve OwnNamespace",
+			operator: etc.Operator{
+				Namespace:        "operators",
+				TargetNamespaces: "operators",
+			},
+			expectedInstallMode: etc.InstallModeOwnNamespace,
+			expectedError:       "",
+		},
+		{
+			name: "Should resolve SingleNamespace",
+			operator: etc.Operator{
+				Namespace:        "operators",
+				TargetNamespaces: "foo",
+			},
+			expectedInstallMode: etc.InstallModeSingleNamespace,
+			expectedError:       "",
+		},
+		{
+			name: "Should resolve MultiNamespace",
+			operator: etc.Operator{
+				Namespace:        "operators",
+				TargetNamespaces: "foo,bar,baz",
+			},
+			expectedInstallMode: etc.InstallModeMultiNamespace,
+			expectedError:       "",
+		},
+		{
+			name: "Should resolve AllNamespaces",
+			operator: etc.Operator{
+				Namespace:        "operators",
+				TargetNamespaces: "",
+			},
+			expectedInstallMode: etc.InstallModeAllNamespaces,
+			expectedError:       "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			installMode, err := tc.operator.GetInstallMode()
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedInstallMode, installMode)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestOperator_GetScanJobResourceRequirements(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		operator             etc.Operator
+		expectedRequirements corev1.ResourceRequirements
+		expectedError        string
+	}{
+		{
+			name: "Should return configured requests and limits",
+			operator: etc.Operator{
+				ScanJobCPURequest:    "100m",
+				ScanJobMemoryRequest: "100M",
+				ScanJobCPULimit:      "1",
+				ScanJobMemoryLimit:   "1Gi",
+			},
+			expectedRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("100M"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		{
+			name: "Should omit blank quantities",
+			operator: etc.Operator{
+				ScanJobMemoryLimit: "500M",
+			},
+			expectedRequirements: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("500M"),
+				},
+			},
+		},
+		{
+			name: "Should return error when quantity is malformed",
+			operator: etc.Operator{
+				ScanJobCPULimit: "half a core",
+			},
+			expectedError: "parsing OPERATOR_SCAN_JOB_CPU_LIMIT: quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requirements, err := tc.operator.GetScanJobResourceRequirements()
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedRequirements, requirements)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestConfig_GetScannerName(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        etc.Config
+		expectedName  string
+		expectedError string
+	}{
+		{
+			name:         "Should return name of scanner enabled with flag",
+			config:       etc.Config{ScannerAquaCSP: etc.ScannerAquaCSP{Enabled: true}},
+			expectedName: etc.ScannerNameAquaCSP,
+		},
+		{
+			name: "Should return name set as OPERATOR_SCANNER regardless of flags",
+			config: etc.Config{
+				Operator:     etc.Operator{Scanner: "grype"},
+				ScannerTrivy: etc.ScannerTrivy{Enabled: true},
+			},
+			expectedName: etc.ScannerNameGrype,
+		},
+		{
+			name:          "Should return error when multiple names are set as OPERATOR_SCANNER",
+			config:        etc.Config{Operator: etc.Operator{Scanner: "trivy,grype"}},
+			expectedError: "multiple vulnerability scanners enabled: trivy,grype",
+		},
+		{
+			name: "Should return error when multiple scanners are enabled with flags",
+			config: etc.Config{
+				ScannerTrivy:   etc.ScannerTrivy{Enabled: true},
+				ScannerAquaCSP: etc.ScannerAquaCSP{Enabled: true},
+				ScannerGrype:   etc.ScannerGrype{Enabled: true},
+			},
+			expectedError: "multiple vulnerability scanners enabled: trivy,aqua-csp,grype",
+		},
+		{
+			name:          "Should return error when none scanner is enabled",
+			config:        etc.Config{},
+			expectedError: "none vulnerability scanner enabled",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, err := tc.config.GetScannerName()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedName, name)
+		})
+	}
+}
+
+func TestConfig_ValidateScanners(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        etc.Config
+		expectedError string
+	}{
+		{
+			name: "Should validate selected scanner",
+			config: etc.Config{
+				Operator:     etc.Operator{Scanner: "trivy"},
+				ScannerTrivy: etc.ScannerTrivy{ImageRef: "aquasec/Trivy:0.11.0", Mode: etc.TrivyModeStandalone},
+			},
+			expectedError: "parsing OPERATOR_SCANNER_TRIVY_IMAGE: could not parse reference: aquasec/Trivy:0.11.0",
+		},
+		{
+			name: "Should validate image reference of Grype",
+			config: etc.Config{
+				Operator:     etc.Operator{Scanner: "grype"},
+				ScannerGrype: etc.ScannerGrype{ImageRef: "anchore/Grype:v0.1.0"},
+			},
+			expectedError: "parsing OPERATOR_SCANNER_GRYPE_IMAGE: could not parse reference: anchore/Grype:v0.1.0",
+		},
+		{
+			name: "Should not validate scanners which are not selected",
+			config: etc.Config{
+				Operator:     etc.Operator{Scanner: "grype"},
+				ScannerTrivy: etc.ScannerTrivy{ImageRef: "aquasec/Trivy:0.11.0", Mode: etc.TrivyModeStandalone},
+				ScannerGrype: etc.ScannerGrype{ImageRef: "anchore/grype:v0.1.0"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.ValidateScanners()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("Should load config with invalid settings of scanner which is not selected", func(t *testing.T) {
+		require.NoError(t, os.Setenv("OPERATOR_SCANNER_TRIVY_IMAGE", "aquasec/Trivy:0.11.0"))
+		defer func() {
+			_ = os.Unsetenv("OPERATOR_SCANNER_TRIVY_IMAGE")
+		}()
+		_, err := etc.GetOperatorConfig()
+		require.NoError(t, err)
+	})
+}
+
+func TestScannerTrivy_Validate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        etc.ScannerTrivy
+		expectedError string
+	}{
+		{
+			name: "Should accept Standalone mode",
+			config: etc.ScannerTrivy{
+				Mode: etc.TrivyModeStandalone,
+			},
+		},
+		{
+			name: "Should accept ClientServer mode with server URL",
+			config: etc.ScannerTrivy{
+				Mode:      etc.TrivyModeClientServer,
+				ServerURL: "http://trivy.trivy:4954",
+			},
+		},
+		{
+			name: "Should return error when server URL is missing in ClientServer mode",
+			config: etc.ScannerTrivy{
+				Mode: etc.TrivyModeClientServer,
+			},
+			expectedError: "OPERATOR_SCANNER_TRIVY_SERVER_URL must be set in ClientServer mode",
+		},
+		{
+			name: "Should return error when mode is unrecognized",
+			config: etc.ScannerTrivy{
+				Mode: "Remote",
+			},
+			expectedError: `unrecognized OPERATOR_SCANNER_TRIVY_MODE: "Remote"`,
+		},
+		{
+			name: "Should accept rootfs scan type in Standalone mode",
+			config: etc.ScannerTrivy{
+				Mode:     etc.TrivyModeStandalone,
+				ScanType: etc.TrivyScanTypeRootfs,
+			},
+		},
+		{
+			name: "Should return error when rootfs scan type is set in ClientServer mode",
+			config: etc.ScannerTrivy{
+				Mode:      etc.TrivyModeClientServer,
+				ServerURL: "http://trivy.trivy:4954",
+				ScanType:  etc.TrivyScanTypeRootfs,
+			},
+			expectedError: "OPERATOR_SCANNER_TRIVY_SCAN_TYPE rootfs is supported only in Standalone mode",
+		},
+		{
+			name: "Should return error when scan type is unrecognized",
+			config: etc.ScannerTrivy{
+				Mode:     etc.TrivyModeStandalone,
+				ScanType: "repo",
+			},
+			expectedError: `unrecognized OPERATOR_SCANNER_TRIVY_SCAN_TYPE: "repo"`,
+		},
+		{
+			name: "Should return error when timeout is negative",
+			config: etc.ScannerTrivy{
+				Mode:    etc.TrivyModeStandalone,
+				Timeout: -time.Minute,
+			},
+			expectedError: "OPERATOR_SCANNER_TRIVY_TIMEOUT must not be negative",
+		},
+		{
+			name: "Should accept platform",
+			config: etc.ScannerTrivy{
+				Mode:     etc.TrivyModeStandalone,
+				Platform: "linux/arm/v7",
+			},
+		},
+		{
+			name: "Should return error when platform is invalid",
+			config: etc.ScannerTrivy{
+				Mode:     etc.TrivyModeStandalone,
+				Platform: "arm64",
+			},
+			expectedError: `OPERATOR_SCANNER_TRIVY_PLATFORM must be in the <os>/<arch>[/<variant>] format but got "arm64"`,
+		},
+		{
+			name: "Should return error when platform is set in rootfs scan type",
+			config: etc.ScannerTrivy{
+				Mode:     etc.TrivyModeStandalone,
+				ScanType: etc.TrivyScanTypeRootfs,
+				Platform: "linux/arm64",
+			},
+			expectedError: "OPERATOR_SCANNER_TRIVY_PLATFORM is not supported with OPERATOR_SCANNER_TRIVY_SCAN_TYPE rootfs",
+		},
+		{
+			name: "Should accept CycloneDX SBOM format",
+			config: etc.ScannerTrivy{
+				Mode:         etc.TrivyModeStandalone,
+				GenerateSBOM: true,
+				SBOMFormat:   etc.SBOMFormatCycloneDX,
+			},
+		},
+		{
+			name: "Should return error when SBOM format is unsupported",
+			config: etc.ScannerTrivy{
+				Mode:         etc.TrivyModeStandalone,
+				GenerateSBOM: true,
+				SBOMFormat:   "spdx",
+			},
+			expectedError: `unsupported OPERATOR_SCANNER_TRIVY_SBOM_FORMAT: "spdx"`,
+		},
+		{
+			name: "Should accept image reference with mirror registry",
+			config: etc.ScannerTrivy{
+				Mode:     etc.TrivyModeStandalone,
+				ImageRef: "registry.local:5000/aquasec/trivy:0.11.0",
+			},
+		},
+		{
+			name: "Should return error when image reference is invalid",
+			config: etc.ScannerTrivy{
+				Mode:     etc.TrivyModeStandalone,
+				ImageRef: "registry.local:5000/aquasec/Trivy:0.11.0",
+			},
+			expectedError: "parsing OPERATOR_SCANNER_TRIVY_IMAGE: could not parse reference: registry.local:5000/aquasec/Trivy:0.11.0",
+		},
+		{
+			name: "Should accept command and args",
+			config: etc.ScannerTrivy{
+				Mode:    etc.TrivyModeStandalone,
+				Command: `["/usr/local/bin/scan.sh"]`,
+				Args:    `["--format","json"]`,
+			},
+		},
+		{
+			name: "Should return error when command is not JSON array",
+			config: etc.ScannerTrivy{
+				Mode:    etc.TrivyModeStandalone,
+				Command: `/usr/local/bin/scan.sh`,
+			},
+			expectedError: "parsing OPERATOR_SCANNER_TRIVY_COMMAND: invalid character '/' looking for beginning of value",
+		},
+		{
+			name: "Should return error when args are not JSON array",
+			config: etc.ScannerTrivy{
+				Mode: etc.TrivyModeStandalone,
+				Args: `{"format":"json"}`,
+			},
+			expectedError: "parsing OPERATOR_SCANNER_TRIVY_ARGS: json: cannot unmarshal object into Go value of type []string",
+		},
+		{
+			name: "Should accept file output mode",
+			config: etc.ScannerTrivy{
+				Mode:       etc.TrivyModeStandalone,
+				OutputMode: etc.ScanOutputModeFile,
+			},
+		},
+		{
+			name: "Should return error when file output mode is used with rootfs scan type",
+			config: etc.ScannerTrivy{
+				Mode:       etc.TrivyModeStandalone,
+				ScanType:   etc.TrivyScanTypeRootfs,
+				OutputMode: etc.ScanOutputModeFile,
+			},
+			expectedError: "OPERATOR_SCANNER_TRIVY_SCAN_OUTPUT_MODE file is not supported with OPERATOR_SCANNER_TRIVY_SCAN_TYPE rootfs",
+		},
+		{
+			name: "Should return error when output mode is unrecognized",
+			config: etc.ScannerTrivy{
+				Mode:       etc.TrivyModeStandalone,
+				OutputMode: "stdout",
+			},
+			expectedError: `unrecognized OPERATOR_SCANNER_TRIVY_SCAN_OUTPUT_MODE: "stdout"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestScannerAquaCSP_Validate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        etc.ScannerAquaCSP
+		expectedError string
+	}{
+		{
+			name: "Should accept image references with mirror registry",
+			config: etc.ScannerAquaCSP{
+				ImageRef:        "registry.local:5000/aquasec/scanner:5.0",
+				WrapperImageRef: "registry.local:5000/aquasec/starboard-scanner-aqua:0.5.0",
+				Host:            "http://csp-console-svc.aqua:8080",
+				Version:         "5.0",
+			},
+		},
+		{
+			name: "Should accept registration in registry",
+			config: etc.ScannerAquaCSP{
+				ImageRef: "aquasec/scanner:5.0",
+				Host:     "https://aqua.example.com",
+				Version:  "5.0",
+				Registry: "Docker Hub",
+				Register: true,
+				Insecure: true,
+			},
+		},
+		{
+			name: "Should return error when host is not set",
+			config: etc.ScannerAquaCSP{
+				ImageRef: "aquasec/scanner:5.0",
+				Version:  "5.0",
+			},
+			expectedError: "OPERATOR_SCANNER_AQUA_CSP_HOST must be set",
+		},
+		{
+			name: "Should return error when host is not URL",
+			config: etc.ScannerAquaCSP{
+				ImageRef: "aquasec/scanner:5.0",
+				Host:     "csp-console-svc.aqua:8080",
+				Version:  "5.0",
+			},
+			expectedError: `OPERATOR_SCANNER_AQUA_CSP_HOST must be an http or https URL but got "csp-console-svc.aqua:8080"`,
+		},
+		{
+			name: "Should return error when version is not set",
+			config: etc.ScannerAquaCSP{
+				ImageRef: "aquasec/scanner:5.0",
+				Host:     "http://csp-console-svc.aqua:8080",
+			},
+			expectedError: "OPERATOR_SCANNER_AQUA_CSP_VERSION must be set",
+		},
+		{
+			name: "Should return error when registry is not set for registration",
+			config: etc.ScannerAquaCSP{
+				ImageRef: "aquasec/scanner:5.0",
+				Host:     "http://csp-console-svc.aqua:8080",
+				Version:  "5.0",
+				Register: true,
+			},
+			expectedError: "OPERATOR_SCANNER_AQUA_CSP_REGISTRY must be set when OPERATOR_SCANNER_AQUA_CSP_REGISTER is true",
+		},
+		{
+			name: "Should return error when image reference is invalid",
+			config: etc.ScannerAquaCSP{
+				ImageRef: "aquasec/scanner:5.0@sha256:bad",
+			},
+			expectedError: "parsing OPERATOR_SCANNER_AQUA_CSP_IMAGE: could not parse reference: aquasec/scanner:5.0@sha256:bad",
+		},
+		{
+			name: "Should return error when wrapper image reference is invalid",
+			config: etc.ScannerAquaCSP{
+				ImageRef:        "aquasec/scanner:5.0",
+				WrapperImageRef: "registry.local:5000/Aquasec/starboard-scanner-aqua",
+			},
+			expectedError: "parsing OPERATOR_SCANNER_AQUA_CSP_WRAPPER_IMAGE: could not parse reference: registry.local:5000/Aquasec/starboard-scanner-aqua",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestScannerAquaCSP_GetWrapperImageRef(t *testing.T) {
+	version := etc.VersionInfo{Version: "0.5.0"}
+	assert.Equal(t, "aquasec/starboard-scanner-aqua:0.5.0", etc.ScannerAquaCSP{}.GetWrapperImageRef(version))
+	assert.Equal(t, "registry.local:5000/aquasec/starboard-scanner-aqua:0.5.0", etc.ScannerAquaCSP{
+		WrapperImageRef: "registry.local:5000/aquasec/starboard-scanner-aqua:0.5.0",
+	}.GetWrapperImageRef(version))
+}
+
+func TestOperator_GetScanJobScheduling(t *testing.T) {
+	t.Run("Should return nil when scheduling constraints are not set", func(t *testing.T) {
+		operator := etc.Operator{}
+
+		nodeSelector, err := operator.GetScanJobNodeSelector()
+		require.NoError(t, err)
+		assert.Nil(t, nodeSelector)
+
+		tolerations, err := operator.GetScanJobTolerations()
+		require.NoError(t, err)
+		assert.Nil(t, tolerations)
+
+		affinity, err := operator.GetScanJobAffinity()
+		require.NoError(t, err)
+		assert.Nil(t, affinity)
+	})
+
+	t.Run("Should parse scheduling constraints", func(t *testing.T) {
+		operator := etc.Operator{
+			ScanJobNodeSelector: `{"node-pool":"scanners"}`,
+			ScanJobTolerations:  `[{"key":"dedicated","operator":"Equal","value":"scanners","effect":"NoSchedule"}]`,
+			ScanJobAffinity:     `{"nodeAffinity":{"requiredDuringSchedulingIgnoredDuringExecution":{"nodeSelectorTerms":[{"matchExpressions":[{"key":"kubernetes.io/os","operator":"In","values":["linux"]}]}]}}}`,
+		}
+
+		nodeSelector, err := operator.GetScanJobNodeSelector()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"node-pool": "scanners"}, nodeSelector)
+
+		tolerations, err := operator.GetScanJobTolerations()
+		require.NoError(t, err)
+		assert.Equal(t, []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "scanners", Effect: corev1.TaintEffectNoSchedule},
+		}, tolerations)
+
+		affinity, err := operator.GetScanJobAffinity()
+		require.NoError(t, err)
+		assert.Equal(t, &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{Key: "kubernetes.io/os", Operator: corev1.NodeSelectorOpIn, Values: []string{"linux"}},
+							},
+						},
+					},
+				},
+			},
+		}, affinity)
+	})
+
+	t.Run("Should return error when JSON is malformed", func(t *testing.T) {
+		operator := etc.Operator{
+			ScanJobNodeSelector: `node-pool=scanners`,
+			ScanJobTolerations:  `{"key":"dedicated"}`,
+			ScanJobAffinity:     `{"nodeAffinity":`,
+		}
+
+		_, err := operator.GetScanJobNodeSelector()
+		assert.EqualError(t, err, "parsing OPERATOR_SCAN_JOB_NODE_SELECTOR: invalid character 'o' in literal null (expecting 'u')")
+
+		_, err = operator.GetScanJobTolerations()
+		assert.EqualError(t, err, "parsing OPERATOR_SCAN_JOB_TOLERATIONS: json: cannot unmarshal object into Go value of type []v1.Toleration")
+
+		_, err = operator.GetScanJobAffinity()
+		assert.EqualError(t, err, "parsing OPERATOR_SCAN_JOB_AFFINITY: unexpected end of JSON input")
+	})
+}
+
+func TestOperator_GetScanJobHostAliasesAndDNSConfig(t *testing.T) {
+	t.Run("Should return nil when host aliases and DNS config are not set", func(t *testing.T) {
+		operator := etc.Operator{}
+
+		hostAliases, err := operator.GetScanJobHostAliases()
+		require.NoError(t, err)
+		assert.Nil(t, hostAliases)
+
+		dnsConfig, err := operator.GetScanJobDNSConfig()
+		require.NoError(t, err)
+		assert.Nil(t, dnsConfig)
+	})
+
+	t.Run("Should parse host aliases and DNS config", func(t *testing.T) {
+		operator := etc.Operator{
+			ScanJobHostAliases: `[{"ip":"10.0.0.10","hostnames":["registry.corp","mirror.corp"]}]`,
+			ScanJobDNSConfig:   `{"nameservers":["10.0.0.53"],"searches":["corp.local"],"options":[{"name":"ndots","value":"2"}]}`,
+		}
+
+		hostAliases, err := operator.GetScanJobHostAliases()
+		require.NoError(t, err)
+		assert.Equal(t, []corev1.HostAlias{
+			{IP: "10.0.0.10", Hostnames: []string{"registry.corp", "mirror.corp"}},
+		}, hostAliases)
+
+		dnsConfig, err := operator.GetScanJobDNSConfig()
+		require.NoError(t, err)
+		assert.Equal(t, &corev1.PodDNSConfig{
+			Nameservers: []string{"10.0.0.53"},
+			Searches:    []string{"corp.local"},
+			Options:     []corev1.PodDNSConfigOption{{Name: "ndots", Value: pointer.StringPtr("2")}},
+		}, dnsConfig)
+	})
+
+	t.Run("Should return error when JSON is malformed", func(t *testing.T) {
+		operator := etc.Operator{
+			ScanJobHostAliases: `{"ip":"10.0.0.10"}`,
+			ScanJobDNSConfig:   `{"searches":`,
+		}
+
+		_, err := operator.GetScanJobHostAliases()
+		assert.EqualError(t, err, "parsing OPERATOR_SCAN_JOB_HOST_ALIASES: json: cannot unmarshal object into Go value of type []v1.HostAlias")
+
+		_, err = operator.GetScanJobDNSConfig()
+		assert.EqualError(t, err, "parsing OPERATOR_SCAN_JOB_DNS_CONFIG: unexpected end of JSON input")
+	})
+}
+
+func TestOperator_InstanceID(t *testing.T) {
+	t.Run("Should select scan jobs by instance label when instance ID is set", func(t *testing.T) {
+		assert.Equal(t, map[string]string{
+			"app.kubernetes.io/managed-by": "starboard-operator",
+			etc.LabelInstanceID:            "tenant-a",
+		}, etc.Operator{InstanceID: "tenant-a"}.GetScanJobLabels())
+		assert.Equal(t, map[string]string{
+			"app.kubernetes.io/managed-by": "starboard-operator",
+		}, etc.Operator{}.GetScanJobLabels())
+	})
+
+	t.Run("Should tell objects owned by instance", func(t *testing.T) {
+		operator := etc.Operator{InstanceID: "tenant-a"}
+		assert.True(t, operator.IsOwnedByInstance(map[string]string{etc.LabelInstanceID: "tenant-a"}))
+		assert.False(t, operator.IsOwnedByInstance(map[string]string{etc.LabelInstanceID: "tenant-b"}))
+		assert.False(t, operator.IsOwnedByInstance(nil))
+		assert.True(t, etc.Operator{}.IsOwnedByInstance(nil))
+		assert.False(t, etc.Operator{}.IsOwnedByInstance(map[string]string{etc.LabelInstanceID: "tenant-a"}))
+	})
+
+	t.Run("Should return error when instance ID is not a valid label value", func(t *testing.T) {
+		require.NoError(t, os.Setenv("OPERATOR_INSTANCE_ID", "tenant a"))
+		defer func() {
+			_ = os.Unsetenv("OPERATOR_INSTANCE_ID")
+		}()
+		_, err := etc.GetOperatorConfig()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "OPERATOR_INSTANCE_ID must be a valid label value")
+	})
+}
+
+func TestOperator_ScanJobTTLSeconds(t *testing.T) {
+	t.Run("Should return nil TTL when not set", func(t *testing.T) {
+		assert.Nil(t, etc.Operator{}.GetScanJobTTLSecondsAfterFinished())
+		assert.Empty(t, etc.Operator{}.GetScanJobTTLWarning())
+	})
+
+	t.Run("Should return configured TTL", func(t *testing.T) {
+		assert.Equal(t, pointer.Int32Ptr(600), etc.Operator{ScanJobTTLSeconds: 600}.GetScanJobTTLSecondsAfterFinished())
+	})
+
+	testCases := []struct {
+		name            string
+		operator        etc.Operator
+		expectedWarning string
+	}{
+		{
+			name:     "Should not warn when TTL is longer than read window",
+			operator: etc.Operator{ScanJobTTLSeconds: 600, LogReadTimeout: time.Minute, ReconcileRequeueInterval: 30 * time.Second},
+		},
+		{
+			name:     "Should not warn when TTL equals read window",
+			operator: etc.Operator{ScanJobTTLSeconds: 60, LogReadTimeout: time.Minute},
+		},
+		{
+			name:     "Should warn when TTL is shorter than read window",
+			operator: etc.Operator{ScanJobTTLSeconds: 60, LogReadTimeout: time.Minute, ReconcileRequeueInterval: 30 * time.Second},
+			expectedWarning: "OPERATOR_SCAN_JOB_TTL_SECONDS of 60s is shorter than the read window of scan jobs 1m30s, " +
+				"finished scan jobs may be deleted before their logs are read",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedWarning, tc.operator.GetScanJobTTLWarning())
+		})
+	}
+
+	t.Run("Should return error when TTL is negative", func(t *testing.T) {
+		require.NoError(t, os.Setenv("OPERATOR_SCAN_JOB_TTL_SECONDS", "-1"))
+		defer func() {
+			_ = os.Unsetenv("OPERATOR_SCAN_JOB_TTL_SECONDS")
+		}()
+		_, err := etc.GetOperatorConfig()
+		require.EqualError(t, err, "OPERATOR_SCAN_JOB_TTL_SECONDS must not be negative")
+	})
+}
+
+func TestGetOperatorConfig_MetricsTLS(t *testing.T) {
+	testCases := []struct {
+		name               string
+		env                map[string]string
+		expectedTLSEnabled bool
+		expectedError      string
+	}{
+		{
+			name:               "Should serve metrics over plain HTTP by default",
+			expectedTLSEnabled: false,
+		},
+		{
+			name: "Should serve metrics over HTTPS when certificate and key are set",
+			env: map[string]string{
+				"OPERATOR_METRICS_TLS_CERT_FILE": "/etc/metrics/tls.crt",
+				"OPERATOR_METRICS_TLS_KEY_FILE":  "/etc/metrics/tls.key",
+				"OPERATOR_METRICS_AUTH_ENABLED":  "true",
+			},
+			expectedTLSEnabled: true,
+		},
+		{
+			name: "Should return error when only certificate is set",
+			env: map[string]string{
+				"OPERATOR_METRICS_TLS_CERT_FILE": "/etc/metrics/tls.crt",
+			},
+			expectedError: "OPERATOR_METRICS_TLS_CERT_FILE and OPERATOR_METRICS_TLS_KEY_FILE must be set together",
+		},
+		{
+			name: "Should return error when auth is enabled without TLS",
+			env: map[string]string{
+				"OPERATOR_METRICS_AUTH_ENABLED": "true",
+			},
+			expectedError: "OPERATOR_METRICS_TLS_CERT_FILE must be set when OPERATOR_METRICS_AUTH_ENABLED is true",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for key, value := range tc.env {
+				require.NoError(t, os.Setenv(key, value))
+			}
+			defer func() {
+				for key := range tc.env {
+					_ = os.Unsetenv(key)
+				}
+			}()
+			config, err := etc.GetOperatorConfig()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTLSEnabled, config.Operator.IsMetricsTLSEnabled())
+		})
+	}
+}
+
+func TestGetOperatorConfig_ScannedDigestCache(t *testing.T) {
+	testCases := []struct {
+		name          string
+		env           map[string]string
+		expectedError string
+	}{
+		{
+			name: "Should accept cache persisted to file",
+			env: map[string]string{
+				"OPERATOR_SCANNED_DIGEST_CACHE_SIZE": "1000",
+				"OPERATOR_SCANNED_DIGEST_CACHE_FILE": "/var/lib/starboard/digests.json",
+			},
+		},
+		{
+			name: "Should return error when size is negative",
+			env: map[string]string{
+				"OPERATOR_SCANNED_DIGEST_CACHE_SIZE": "-1",
+			},
+			expectedError: "OPERATOR_SCANNED_DIGEST_CACHE_SIZE must not be negative",
+		},
+		{
+			name: "Should return error when file is set without size",
+			env: map[string]string{
+				"OPERATOR_SCANNED_DIGEST_CACHE_FILE": "/var/lib/starboard/digests.json",
+			},
+			expectedError: "OPERATOR_SCANNED_DIGEST_CACHE_SIZE must be positive when OPERATOR_SCANNED_DIGEST_CACHE_FILE is set",
+		},
+		{
+			name: "Should return error when grace period is negative",
+			env: map[string]string{
+				"OPERATOR_SCANNED_DIGEST_GRACE_PERIOD": "-1m",
+			},
+			expectedError: "OPERATOR_SCANNED_DIGEST_GRACE_PERIOD must not be negative",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for key, value := range tc.env {
+				require.NoError(t, os.Setenv(key, value))
+			}
+			defer func() {
+				for key := range tc.env {
+					_ = os.Unsetenv(key)
+				}
+			}()
+			_, err := etc.GetOperatorConfig()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestOperator_GetScanJobVolumes(t *testing.T) {
+	t.Run("Should return nil when volumes and volume mounts are not set", func(t *testing.T) {
+		operator := etc.Operator{}
+
+		volumes, err := operator.GetScanJobVolumes()
+		require.NoError(t, err)
+		assert.Nil(t, volumes)
+
+		volumeMounts, err := operator.GetScanJobVolumeMounts()
+		require.NoError(t, err)
+		assert.Nil(t, volumeMounts)
+	})
+
+	t.Run("Should parse volumes and volume mounts", func(t *testing.T) {
+		operator := etc.Operator{
+			ScanJobVolumes:      `[{"name":"trivy-cache","persistentVolumeClaim":{"claimName":"trivy-cache"}}]`,
+			ScanJobVolumeMounts: `[{"name":"trivy-cache","mountPath":"/var/lib/trivy","readOnly":true}]`,
+		}
+
+		volumes, err := operator.GetScanJobVolumes()
+		require.NoError(t, err)
+		assert.Equal(t, []corev1.Volume{
+			{
+				Name: "trivy-cache",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "trivy-cache"},
+				},
+			},
+		}, volumes)
+
+		volumeMounts, err := operator.GetScanJobVolumeMounts()
+		require.NoError(t, err)
+		assert.Equal(t, []corev1.VolumeMount{
+			{Name: "trivy-cache", MountPath: "/var/lib/trivy", ReadOnly: true},
+		}, volumeMounts)
+	})
+}
+
+func TestOperator_ValidateScanJobVolumes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		operator      etc.Operator
+		expectedError string
+	}{
+		{
+			name: "Should accept volume mounts referring to volumes",
+			operator: etc.Operator{
+				ScanJobVolumes:      `[{"name":"trivy-cache","emptyDir":{}},{"name":"config","configMap":{"name":"scanner-config"}}]`,
+				ScanJobVolumeMounts: `[{"name":"config","mountPath":"/etc/scanner"}]`,
+			},
+		},
+		{
+			name:          "Should return error when volumes JSON is malformed",
+			operator:      etc.Operator{ScanJobVolumes: `{"name":"trivy-cache"}`},
+			expectedError: "parsing OPERATOR_SCAN_JOB_VOLUMES: json: cannot unmarshal object into Go value of type []v1.Volume",
+		},
+		{
+			name:          "Should return error when volume mounts JSON is malformed",
+			operator:      etc.Operator{ScanJobVolumeMounts: `[{"name":`},
+			expectedError: "parsing OPERATOR_SCAN_JOB_VOLUME_MOUNTS: unexpected end of JSON input",
+		},
+		{
+			name:          "Should return error when volume name is invalid",
+			operator:      etc.Operator{ScanJobVolumes: `[{"name":"Trivy_Cache","emptyDir":{}}]`},
+			expectedError: `OPERATOR_SCAN_JOB_VOLUMES must contain valid volume names: "Trivy_Cache": a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+		{
+			name:          "Should return error when volume names are duplicated",
+			operator:      etc.Operator{ScanJobVolumes: `[{"name":"trivy-cache","emptyDir":{}},{"name":"trivy-cache","emptyDir":{}}]`},
+			expectedError: `OPERATOR_SCAN_JOB_VOLUMES must not contain duplicate volume names: "trivy-cache"`,
+		},
+		{
+			name: "Should return error when volume mount refers to unknown volume",
+			operator: etc.Operator{
+				ScanJobVolumes:      `[{"name":"trivy-cache","emptyDir":{}}]`,
+				ScanJobVolumeMounts: `[{"name":"config","mountPath":"/etc/scanner"}]`,
+			},
+			expectedError: `OPERATOR_SCAN_JOB_VOLUME_MOUNTS must refer to volumes set in OPERATOR_SCAN_JOB_VOLUMES: "config"`,
+		},
+		{
+			name: "Should return error when volume mount path is not set",
+			operator: etc.Operator{
+				ScanJobVolumes:      `[{"name":"trivy-cache","emptyDir":{}}]`,
+				ScanJobVolumeMounts: `[{"name":"trivy-cache"}]`,
+			},
+			expectedError: `OPERATOR_SCAN_JOB_VOLUME_MOUNTS must set mount paths: "trivy-cache"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.operator.ValidateScanJobVolumes()
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestOperator_GetScanJobEnvFrom(t *testing.T) {
+	t.Run("Should return nil when env sources are not set", func(t *testing.T) {
+		envFrom, err := etc.Operator{}.GetScanJobEnvFrom()
+		require.NoError(t, err)
+		assert.Nil(t, envFrom)
+	})
+
+	t.Run("Should parse env sources", func(t *testing.T) {
+		envFrom, err := etc.Operator{
+			ScanJobEnvFrom: `[{"configMapRef":{"name":"scanner-config"}},{"prefix":"TRIVY_","secretRef":{"name":"scanner-env","optional":true}}]`,
+		}.GetScanJobEnvFrom()
+		require.NoError(t, err)
+		assert.Equal(t, []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "scanner-config"}}},
+			{Prefix: "TRIVY_", SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "scanner-env"},
+				Optional:             pointer.BoolPtr(true),
+			}},
+		}, envFrom)
+	})
+
+	t.Run("Should return error when JSON is malformed", func(t *testing.T) {
+		_, err := etc.Operator{ScanJobEnvFrom: `{"secretRef":{"name":"scanner-env"}}`}.GetScanJobEnvFrom()
+		assert.EqualError(t, err, "parsing OPERATOR_SCAN_JOB_ENV_FROM: json: cannot unmarshal object into Go value of type []v1.EnvFromSource")
+	})
+}
+
+func TestOperator_GetScanJobSecurityContext(t *testing.T) {
+	t.Run("Should return nil when security context is not set", func(t *testing.T) {
+		operator := etc.Operator{}
+
+		podSecurityContext, securityContext, err := operator.GetScanJobSecurityContext()
+		require.NoError(t, err)
+		assert.Nil(t, podSecurityContext)
+		assert.Nil(t, securityContext)
+		assert.Nil(t, operator.GetScanJobPodAnnotations())
+	})
+
+	t.Run("Should return restricted security context", func(t *testing.T) {
+		operator := etc.Operator{
+			ScanJobSecurityContext: etc.SecurityContextRestricted,
+		}
+
+		podSecurityContext, securityContext, err := operator.GetScanJobSecurityContext()
+		require.NoError(t, err)
+		assert.Equal(t, &corev1.PodSecurityContext{
+			RunAsNonRoot: pointer.BoolPtr(true),
+		}, podSecurityContext)
+		assert.Equal(t, &corev1.SecurityContext{
+			RunAsNonRoot:             pointer.BoolPtr(true),
+			AllowPrivilegeEscalation: pointer.BoolPtr(false),
+			Privileged:               pointer.BoolPtr(false),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
+		}, securityContext)
+		assert.Nil(t, securityContext.RunAsUser, "User ID is assigned by the platform")
+		assert.Equal(t, map[string]string{
+			"seccomp.security.alpha.kubernetes.io/pod": "runtime/default",
+		}, operator.GetScanJobPodAnnotations())
+	})
+
+	t.Run("Should return error when security context is unknown", func(t *testing.T) {
+		operator := etc.Operator{
+			ScanJobSecurityContext: "Privileged",
+		}
+
+		_, _, err := operator.GetScanJobSecurityContext()
+		assert.EqualError(t, err, `OPERATOR_SCAN_JOB_SECURITY_CONTEXT must be Restricted but got "Privileged"`)
+	})
+}
+
+func TestConfig_GetEnv(t *testing.T) {
+	envs := etc.Config{
+		Operator: etc.Operator{
+			Namespace:      "starboard-operator",
+			ScanJobTimeout: 5 * time.Minute,
+		},
+		ScannerTrivy: etc.ScannerTrivy{
+			Enabled: true,
+		},
+	}.GetEnv()
+
+	assert.Equal(t, "starboard-operator", envs["OPERATOR_NAMESPACE"])
+	assert.Equal(t, "5m0s", envs["OPERATOR_SCAN_JOB_TIMEOUT"])
+	assert.Equal(t, "true", envs["OPERATOR_SCANNER_TRIVY_ENABLED"])
+	assert.Equal(t, "false", envs["OPERATOR_SCANNER_GRYPE_ENABLED"])
+	assert.Contains(t, envs, "OPERATOR_SCANNER_AQUA_CSP_PASSWORD")
+}
+
+func TestRedactEnv(t *testing.T) {
+	envs := map[string]string{
+		"OPERATOR_NAMESPACE":                 "starboard-operator",
+		"OPERATOR_SCANNER_AQUA_CSP_USERNAME": "administrator",
+		"OPERATOR_SCANNER_AQUA_CSP_PASSWORD": "s3cret",
+		"OPERATOR_NOTIFY_WEBHOOK_SECRET":     "s3cret",
+		"OPERATOR_SCANNER_TRIVY_TOKEN":       "s3cret",
+		"OPERATOR_NOTIFY_WEBHOOK_URL":        "",
+		"OPERATOR_NOTIFY_SLACK_WEBHOOK_URL":  "https://hooks.slack.com/services/T0/B0/s3cret",
+	}
+
+	assert.Equal(t, map[string]string{
+		"OPERATOR_NAMESPACE":                 "starboard-operator",
+		"OPERATOR_SCANNER_AQUA_CSP_USERNAME": "administrator",
+		"OPERATOR_SCANNER_AQUA_CSP_PASSWORD": etc.RedactedValue,
+		"OPERATOR_NOTIFY_WEBHOOK_SECRET":     etc.RedactedValue,
+		"OPERATOR_SCANNER_TRIVY_TOKEN":       etc.RedactedValue,
+		"OPERATOR_NOTIFY_WEBHOOK_URL":        "",
+		"OPERATOR_NOTIFY_SLACK_WEBHOOK_URL":  etc.RedactedValue,
+	}, etc.RedactEnv(envs))
+	assert.Equal(t, "s3cret", envs["OPERATOR_SCANNER_AQUA_CSP_PASSWORD"], "input must not be modified")
+}
+
+func TestOperator_GetScanSchedule(t *testing.T) {
+	testCases := []struct {
+		name          string
+		scanCron      string
+		expectedNext  time.Time
+		expectedError string
+	}{
+		{
+			name: "Should return nil when schedule is not set",
+		},
+		{
+			name:         "Should parse cron expression",
+			scanCron:     "0 2 * * *",
+			expectedNext: time.Date(2020, 10, 2, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:         "Should parse descriptor",
+			scanCron:     "@hourly",
+			expectedNext: time.Date(2020, 10, 1, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Should return error when cron expression is malformed",
+			scanCron:      "0 2 * *",
+			expectedError: "parsing OPERATOR_SCAN_CRON: expected exactly 5 fields, found 4: [0 2 * *]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := etc.Operator{ScanCron: tc.scanCron}.GetScanSchedule()
+			switch {
+			case tc.expectedError != "":
+				require.EqualError(t, err, tc.expectedError)
+			case tc.expectedNext.IsZero():
+				require.NoError(t, err)
+				assert.Nil(t, schedule)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedNext, schedule.Next(time.Date(2020, 10, 1, 12, 30, 0, 0, time.UTC)))
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package grype
+
+// ScanReport represents the JSON document printed by `grype -o json`.
+type ScanReport struct {
+	Matches    []Match    `json:"matches"`
+	Source     Source     `json:"source"`
+	Descriptor Descriptor `json:"descriptor"`
+}
+
+type Match struct {
+	Vulnerability Vulnerability `json:"vulnerability"`
+	Artifact      Artifact      `json:"artifact"`
+}
+
+type Vulnerability struct {
+	ID          string   `json:"id"` // e.g. CVE-2020-3910
+	Severity    string   `json:"severity"`
+	Description string   `json:"description"`
+	URLs        []string `json:"urls"`
+	Fix         Fix      `json:"fix"`
+}
+
+type Fix struct {
+	Versions []string `json:"versions"`
+	State    string   `json:"state"` // e.g. fixed, not-fixed, wont-fix
+}
+
+type Artifact struct {
+	Name    string `json:"name"`    // e.g. libxml2
+	Version string `json:"version"` // e.g. 2.9.4+dfsg1-7+b3
+	Type    string `json:"type"`    // e.g. deb
+}
+
+type Source struct {
+	Type string `json:"type"`
+}
+
+type Descriptor struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
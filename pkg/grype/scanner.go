@@ -0,0 +1,270 @@
+package grype
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/scanners"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/uuid"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	dbCacheDir = "/var/lib/grype"
+)
+
+func init() {
+	scanner.Register(etc.ScannerNameGrype, func(config etc.Config, _ etc.VersionInfo) scanner.VulnerabilityScanner {
+		return NewScanner(config.ScannerGrype)
+	})
+}
+
+type grypeScanner struct {
+	config etc.ScannerGrype
+}
+
+func NewScanner(config etc.ScannerGrype) scanner.VulnerabilityScanner {
+	return &grypeScanner{
+		config: config,
+	}
+}
+
+func (s *grypeScanner) GetName() string {
+	return "Grype"
+}
+
+func (s *grypeScanner) NewScanJob(meta scanner.JobMeta, options scanner.Options, spec corev1.PodSpec) (*batchv1.Job, error) {
+	jobName := uuid.New().String()
+
+	initContainerName := jobName
+
+	// Download the vulnerability database once and share it with the scan containers,
+	// which are configured not to update it on their own.
+	initContainers := []corev1.Container{
+		{
+			Name:                     initContainerName,
+			Image:                    s.config.ImageRef,
+			ImagePullPolicy:          options.ScanJobImagePullPolicy,
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			SecurityContext:          options.ScanJobSecurityContext.DeepCopy(),
+			Env:                      s.newEnvVars(options),
+			EnvFrom:                  options.ScanJobEnvFrom,
+			Command: []string{
+				"/grype",
+			},
+			Args: []string{
+				"db",
+				"update",
+			},
+			VolumeMounts: append(append([]corev1.VolumeMount{
+				{
+					Name:      "data",
+					ReadOnly:  false,
+					MountPath: dbCacheDir,
+				},
+			}, scanner.NewCACertVolumeMounts(options)...), options.ScanJobVolumeMounts...),
+		},
+	}
+
+	containers := scanner.GetContainersToScan(spec)
+	scanJobContainers := make([]corev1.Container, len(containers))
+	for i, c := range containers {
+		scanJobContainers[i] = corev1.Container{
+			Name:                     c.Name,
+			Image:                    s.config.ImageRef,
+			ImagePullPolicy:          options.ScanJobImagePullPolicy,
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			SecurityContext:          options.ScanJobSecurityContext.DeepCopy(),
+			Env: append(s.newEnvVars(options),
+				scanner.NewRegistryCredentialsEnvVars(options, c.Name, "GRYPE_REGISTRY_AUTH_USERNAME", "GRYPE_REGISTRY_AUTH_PASSWORD")...),
+			EnvFrom: options.ScanJobEnvFrom,
+			Command: []string{
+				"/grype",
+			},
+			Args: []string{
+				"--quiet",
+				"--output",
+				"json",
+				c.Image,
+			},
+			Resources: options.ScanJobResources,
+			VolumeMounts: append(append([]corev1.VolumeMount{
+				{
+					Name:      "data",
+					ReadOnly:  false,
+					MountPath: dbCacheDir,
+				},
+			}, scanner.NewCACertVolumeMounts(options)...), options.ScanJobVolumeMounts...),
+		}
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        jobName,
+			Namespace:   options.Namespace,
+			Labels:      meta.Labels,
+			Annotations: meta.Annotations,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            pointer.Int32Ptr(options.ScanJobBackoffLimit),
+			Completions:             pointer.Int32Ptr(1),
+			ActiveDeadlineSeconds:   scanners.GetActiveDeadlineSeconds(options.ScanJobTimeout),
+			TTLSecondsAfterFinished: options.ScanJobTTLSecondsAfterFinished,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      meta.Labels,
+					Annotations: meta.Annotations,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					ServiceAccountName:           options.ServiceAccountName,
+					PriorityClassName:            options.PriorityClassName,
+					AutomountServiceAccountToken: pointer.BoolPtr(false),
+					NodeSelector:                 options.ScanJobNodeSelector,
+					Tolerations:                  options.ScanJobTolerations,
+					Affinity:                     options.ScanJobAffinity,
+					HostAliases:                  options.ScanJobHostAliases,
+					DNSConfig:                    options.ScanJobDNSConfig,
+					SecurityContext:              options.ScanJobPodSecurityContext,
+					Volumes: append(append([]corev1.Volume{
+						{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{
+									Medium: corev1.StorageMediumDefault,
+								},
+							},
+						},
+					}, scanner.NewCACertVolumes(options)...), options.ScanJobVolumes...),
+					InitContainers: initContainers,
+					Containers:     scanJobContainers,
+				},
+			},
+		},
+	}, nil
+}
+
+func (s *grypeScanner) newEnvVars(options scanner.Options) []corev1.EnvVar {
+	return append([]corev1.EnvVar{
+		{
+			Name:  "GRYPE_DB_CACHE_DIR",
+			Value: dbCacheDir,
+		},
+		{
+			Name:  "GRYPE_DB_AUTO_UPDATE",
+			Value: "false",
+		},
+	}, append(scanner.NewProxyEnvVars(options), scanner.NewCACertEnvVars(options)...)...)
+}
+
+func (s *grypeScanner) ParseVulnerabilityScanResult(imageRef string, logsReader io.ReadCloser) (v1alpha1.VulnerabilityScanResult, error) {
+	var report ScanReport
+	err := json.NewDecoder(logsReader).Decode(&report)
+	if err != nil {
+		return v1alpha1.VulnerabilityScanResult{}, fmt.Errorf("decoding grype report: %w", err)
+	}
+	return s.convert(imageRef, report)
+}
+
+func (s *grypeScanner) convert(imageRef string, report ScanReport) (v1alpha1.VulnerabilityScanResult, error) {
+	items := make([]v1alpha1.Vulnerability, 0)
+
+	for _, match := range report.Matches {
+		items = append(items, v1alpha1.Vulnerability{
+			VulnerabilityID:  match.Vulnerability.ID,
+			Resource:         match.Artifact.Name,
+			InstalledVersion: match.Artifact.Version,
+			FixedVersion:     strings.Join(match.Vulnerability.Fix.Versions, ", "),
+			Severity:         s.toSeverity(match.Vulnerability),
+			Description:      match.Vulnerability.Description,
+			Links:            s.toLinks(match.Vulnerability),
+		})
+	}
+
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return v1alpha1.VulnerabilityScanResult{}, err
+	}
+
+	artifact := v1alpha1.Artifact{
+		Repository: ref.Context().RepositoryStr(),
+	}
+	switch t := ref.(type) {
+	case name.Tag:
+		artifact.Tag = t.TagStr()
+	case name.Digest:
+		artifact.Digest = t.DigestStr()
+	}
+
+	version := s.config.Version
+	if report.Descriptor.Version != "" {
+		version = report.Descriptor.Version
+	}
+
+	return v1alpha1.VulnerabilityScanResult{
+		Scanner: v1alpha1.Scanner{
+			Name:    "Grype",
+			Vendor:  "Anchore Inc.",
+			Version: version,
+		},
+		Registry: v1alpha1.Registry{
+			Server: ref.Context().RegistryStr(),
+		},
+		Artifact:        artifact,
+		Summary:         s.toSummary(items),
+		Vulnerabilities: items,
+	}, nil
+}
+
+func (s *grypeScanner) toSeverity(v Vulnerability) v1alpha1.Severity {
+	switch severity := strings.ToLower(v.Severity); severity {
+	case "critical":
+		return v1alpha1.SeverityCritical
+	case "high":
+		return v1alpha1.SeverityHigh
+	case "medium":
+		return v1alpha1.SeverityMedium
+	case "low":
+		return v1alpha1.SeverityLow
+	case "negligible":
+		// TODO We should have severity None defined in k8s-security-crds
+		return v1alpha1.SeverityUnknown
+	default:
+		return v1alpha1.SeverityUnknown
+	}
+}
+
+func (s *grypeScanner) toLinks(v Vulnerability) []string {
+	if v.URLs == nil {
+		return []string{}
+	}
+	return v.URLs
+}
+
+func (s *grypeScanner) toSummary(items []v1alpha1.Vulnerability) v1alpha1.VulnerabilitySummary {
+	summary := v1alpha1.VulnerabilitySummary{}
+	for _, item := range items {
+		switch item.Severity {
+		case v1alpha1.SeverityCritical:
+			summary.CriticalCount++
+		case v1alpha1.SeverityHigh:
+			summary.HighCount++
+		case v1alpha1.SeverityMedium:
+			summary.MediumCount++
+		case v1alpha1.SeverityLow:
+			summary.LowCount++
+		default:
+			summary.UnknownCount++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,156 @@
+package grype_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/grype"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var config = etc.ScannerGrype{
+	Enabled:  true,
+	Version:  "0.1.0",
+	ImageRef: "anchore/grype:v0.1.0",
+}
+
+func TestGrypeScanner_NewScanJob(t *testing.T) {
+	job, err := grype.NewScanner(config).NewScanJob(scanner.JobMeta{
+		Labels: map[string]string{
+			"app.kubernetes.io/managed-by": "starboard-operator",
+		},
+	}, scanner.Options{
+		Namespace:              "starboard-operator",
+		ServiceAccountName:     "starboard-operator",
+		ScanJobTimeout:         5 * time.Minute,
+		ScanJobImagePullPolicy: corev1.PullAlways,
+		ScanJobResources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}, corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "nginx", Image: "nginx:1.16"},
+			{Name: "sidecar", Image: "busybox:1.32"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "starboard-operator", job.Namespace)
+	assert.Equal(t, "starboard-operator", job.Spec.Template.Spec.ServiceAccountName)
+	assert.Equal(t, int64(300), *job.Spec.ActiveDeadlineSeconds)
+	require.Len(t, job.Spec.Template.Spec.InitContainers, 1)
+	assert.Equal(t, []string{"db", "update"}, job.Spec.Template.Spec.InitContainers[0].Args)
+	require.Len(t, job.Spec.Template.Spec.Containers, 2)
+	assert.Equal(t, "nginx", job.Spec.Template.Spec.Containers[0].Name)
+	assert.Equal(t, "anchore/grype:v0.1.0", job.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, corev1.PullAlways, job.Spec.Template.Spec.Containers[0].ImagePullPolicy)
+	assert.Equal(t, []string{"--quiet", "--output", "json", "nginx:1.16"}, job.Spec.Template.Spec.Containers[0].Args)
+	assert.Equal(t, resource.MustParse("1Gi"), job.Spec.Template.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory])
+	assert.Equal(t, "sidecar", job.Spec.Template.Spec.Containers[1].Name)
+	assert.Equal(t, []string{"--quiet", "--output", "json", "busybox:1.32"}, job.Spec.Template.Spec.Containers[1].Args)
+}
+
+func TestGrypeScanner_ParseVulnerabilityScanResult(t *testing.T) {
+	testCases := []struct {
+		name           string
+		imageRef       string
+		inputFile      string
+		expectedReport v1alpha1.VulnerabilityScanResult
+	}{
+		{
+			name:      "Should convert report with vulnerabilities",
+			imageRef:  "core.harbor.domain/library/nginx:1.16",
+			inputFile: "testdata/with_vulnerabilities.json",
+			expectedReport: v1alpha1.VulnerabilityScanResult{
+				Scanner: v1alpha1.Scanner{
+					Name:    "Grype",
+					Vendor:  "Anchore Inc.",
+					Version: "0.1.0",
+				},
+				Registry: v1alpha1.Registry{
+					Server: "core.harbor.domain",
+				},
+				Artifact: v1alpha1.Artifact{
+					Repository: "library/nginx",
+					Tag:        "1.16",
+				},
+				Summary: v1alpha1.VulnerabilitySummary{
+					CriticalCount: 1,
+					HighCount:     1,
+					UnknownCount:  1,
+				},
+				Vulnerabilities: []v1alpha1.Vulnerability{
+					{
+						VulnerabilityID:  "CVE-2019-18276",
+						Resource:         "bash",
+						InstalledVersion: "5.0-4",
+						FixedVersion:     "",
+						Severity:         v1alpha1.SeverityHigh,
+						Description:      "An issue was discovered in disable_priv_mode in shell.c in GNU Bash through 5.0 patch 11.",
+						Links:            []string{"https://nvd.nist.gov/vuln/detail/CVE-2019-18276"},
+					},
+					{
+						VulnerabilityID:  "CVE-2020-1967",
+						Resource:         "libssl1.1",
+						InstalledVersion: "1.1.1d-r3",
+						FixedVersion:     "1.1.1g-r0",
+						Severity:         v1alpha1.SeverityCritical,
+						Description:      "Server or client applications that call the SSL_check_chain() function may crash.",
+						Links:            []string{},
+					},
+					{
+						VulnerabilityID:  "CVE-2011-3374",
+						Resource:         "apt",
+						InstalledVersion: "1.8.2",
+						FixedVersion:     "",
+						Severity:         v1alpha1.SeverityUnknown,
+						Links:            []string{},
+					},
+				},
+			},
+		},
+		{
+			name:      "Should convert report without vulnerabilities",
+			imageRef:  "nginx@sha256:2963fc49cc50883ba9af25f977a9997ff9af06b45c12d968b7985dc1e9254e4b",
+			inputFile: "testdata/without_vulnerabilities.json",
+			expectedReport: v1alpha1.VulnerabilityScanResult{
+				Scanner: v1alpha1.Scanner{
+					Name:    "Grype",
+					Vendor:  "Anchore Inc.",
+					Version: "0.1.0",
+				},
+				Registry: v1alpha1.Registry{
+					Server: "index.docker.io",
+				},
+				Artifact: v1alpha1.Artifact{
+					Repository: "library/nginx",
+					Digest:     "sha256:2963fc49cc50883ba9af25f977a9997ff9af06b45c12d968b7985dc1e9254e4b",
+				},
+				Vulnerabilities: []v1alpha1.Vulnerability{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := os.Open(tc.inputFile)
+			require.NoError(t, err)
+			defer func() {
+				_ = file.Close()
+			}()
+
+			report, err := grype.NewScanner(config).ParseVulnerabilityScanResult(tc.imageRef, file)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedReport, report)
+		})
+	}
+}
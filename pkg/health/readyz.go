@@ -0,0 +1,34 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// CacheSyncWaiter knows how to wait for the informers cache to be synced.
+// It's implemented by the cache of the controllers manager.
+type CacheSyncWaiter interface {
+	WaitForCacheSync(stop <-chan struct{}) bool
+}
+
+// NewReadyzCheck returns a healthz.Checker which reports the operator as ready
+// when the configuration of vulnerability scanners is valid and the informers
+// cache has been synced.
+func NewReadyzCheck(cache CacheSyncWaiter, config etc.Config) healthz.Checker {
+	return func(_ *http.Request) error {
+		if err := config.ValidateScanners(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		// Pass a closed channel so that we don't block the probe until the cache is synced.
+		stop := make(chan struct{})
+		close(stop)
+		if !cache.WaitForCacheSync(stop) {
+			return errors.New("informers cache is not synced")
+		}
+		return nil
+	}
+}
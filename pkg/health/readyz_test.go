@@ -0,0 +1,69 @@
+package health_test
+
+import (
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCache struct {
+	synced bool
+}
+
+func (c *fakeCache) WaitForCacheSync(_ <-chan struct{}) bool {
+	return c.synced
+}
+
+func TestNewReadyzCheck(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cacheSynced   bool
+		config        etc.Config
+		expectedError string
+	}{
+		{
+			name:        "Should be ready when cache is synced and config is valid",
+			cacheSynced: true,
+			config: etc.Config{
+				ScannerTrivy: etc.ScannerTrivy{Enabled: true, Mode: etc.TrivyModeStandalone},
+			},
+		},
+		{
+			name:        "Should not be ready when cache is not synced",
+			cacheSynced: false,
+			config: etc.Config{
+				ScannerTrivy: etc.ScannerTrivy{Enabled: true, Mode: etc.TrivyModeStandalone},
+			},
+			expectedError: "informers cache is not synced",
+		},
+		{
+			name:          "Should not be ready when none scanner is enabled",
+			cacheSynced:   true,
+			config:        etc.Config{},
+			expectedError: "invalid configuration: none vulnerability scanner enabled",
+		},
+		{
+			name:        "Should not be ready when Trivy config is invalid",
+			cacheSynced: true,
+			config: etc.Config{
+				ScannerTrivy: etc.ScannerTrivy{Enabled: true, Mode: etc.TrivyModeClientServer},
+			},
+			expectedError: "invalid configuration: OPERATOR_SCANNER_TRIVY_SERVER_URL must be set in ClientServer mode",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := health.NewReadyzCheck(&fakeCache{synced: tc.cacheSynced}, tc.config)(nil)
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+			default:
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package logs
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ScanError is a classified cause of a failed scan, which tells users whether to fix
+// the configuration, e.g. registry credentials, or to retry the scan.
+type ScanError struct {
+	// Reason is a machine readable CamelCase identifier of the error.
+	Reason  string
+	message string
+}
+
+func (e *ScanError) Error() string {
+	return e.message
+}
+
+var (
+	ErrImageNotFound = &ScanError{Reason: "ImageNotFound", message: "image not found"}
+	ErrAuthRequired  = &ScanError{Reason: "AuthRequired", message: "registry authentication required"}
+	ErrDBDownload    = &ScanError{Reason: "DBDownload", message: "vulnerability database download failed"}
+	ErrTimeout       = &ScanError{Reason: "Timeout", message: "scan timed out"}
+)
+
+// scanErrorPatterns maps typed errors to lower case fragments of scanner logs. The order
+// matters as logs of a failed database download may also mention a timeout, and registries
+// often respond with not found to unauthorized requests.
+var scanErrorPatterns = []struct {
+	err       *ScanError
+	fragments []string
+}{
+	{
+		err: ErrDBDownload,
+		fragments: []string{
+			"failed to download vulnerability db",
+			"vulnerability db initialize",
+			"db update error",
+			"failed to update vulnerability db",
+		},
+	},
+	{
+		err: ErrAuthRequired,
+		fragments: []string{
+			"unauthorized",
+			"authentication required",
+			"access denied",
+			"requested access to the resource is denied",
+			"incorrect username or password",
+		},
+	},
+	{
+		err: ErrImageNotFound,
+		fragments: []string{
+			"manifest unknown",
+			"name unknown",
+			"no such image",
+			"not found",
+			"does not exist",
+		},
+	},
+	{
+		err: ErrTimeout,
+		fragments: []string{
+			"deadline exceeded",
+			"timeout",
+			"timed out",
+		},
+	},
+}
+
+// ClassifyScanError returns the typed error matching the specified scanner logs,
+// or nil if the cause of the failure is not recognized.
+func ClassifyScanError(logs io.Reader) *ScanError {
+	var lines []string
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		lines = append(lines, strings.ToLower(scanner.Text()))
+	}
+	for _, pattern := range scanErrorPatterns {
+		for _, line := range lines {
+			for _, fragment := range pattern.fragments {
+				if strings.Contains(line, fragment) {
+					return pattern.err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package logs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/logs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyScanError(t *testing.T) {
+	testCases := []struct {
+		name          string
+		logs          string
+		expectedError *logs.ScanError
+	}{
+		{
+			name: "Should classify missing image",
+			logs: "2020-10-05T08:12:39.123Z\tFATAL\tunable to initialize a scanner: unable to initialize a docker scanner: " +
+				"GET https://index.docker.io/v2/library/nginx/manifests/1.999: MANIFEST_UNKNOWN: manifest unknown",
+			expectedError: logs.ErrImageNotFound,
+		},
+		{
+			name:          "Should classify missing image reported by Grype",
+			logs:          "failed to catalog: could not fetch image 'nginx:1.999': Error: No such image: nginx:1.999",
+			expectedError: logs.ErrImageNotFound,
+		},
+		{
+			name: "Should classify unauthorized registry access",
+			logs: "2020-10-05T08:12:39.123Z\tFATAL\tunable to initialize a scanner: unable to initialize a docker scanner: " +
+				"GET https://core.harbor.domain/v2/library/nginx/manifests/1.16: UNAUTHORIZED: authentication required",
+			expectedError: logs.ErrAuthRequired,
+		},
+		{
+			name:          "Should classify denied access before missing repository",
+			logs:          "pull access denied for private/nginx, repository does not exist or may require 'docker login': denied: requested access to the resource is denied",
+			expectedError: logs.ErrAuthRequired,
+		},
+		{
+			name: "Should classify failed database download before timeout",
+			logs: "2020-10-05T08:12:39.123Z\tFATAL\terror in vulnerability DB initialize: failed to download vulnerability DB: " +
+				"Get https://github.com/aquasecurity/trivy-db/releases: dial tcp: i/o timeout",
+			expectedError: logs.ErrDBDownload,
+		},
+		{
+			name:          "Should classify timeout",
+			logs:          "2020-10-05T08:12:39.123Z\tFATAL\tscan error: image scan failed: context deadline exceeded",
+			expectedError: logs.ErrTimeout,
+		},
+		{
+			name:          "Should not classify unrecognized error",
+			logs:          "2020-10-05T08:12:39.123Z\tFATAL\tunexpected error",
+			expectedError: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := logs.ClassifyScanError(strings.NewReader(tc.logs))
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
@@ -2,25 +2,125 @@ package logs
 
 import (
 	"context"
+	"errors"
 	"io"
+	"sync/atomic"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ErrReadTimeout is returned when Pod logs are not read within the timeout of the Reader, e.g. because
+// the stream of logs hangs. Unlike malformed logs, logs which timed out may be read when retried.
+var ErrReadTimeout = errors.New("timed out reading pod logs")
+
+// IsReadTimeout returns true if the specified error, or any error it wraps, is ErrReadTimeout.
+func IsReadTimeout(err error) bool {
+	return errors.Is(err, ErrReadTimeout)
+}
+
 // Reader wraps kubernetes.Interface to access Pod logs.
 type Reader struct {
 	clientset kubernetes.Interface
+	timeout   time.Duration
+	container string
 }
 
-// NewReader constructs a new Reader with the specified kubernetes.Interface.
-func NewReader(clientset kubernetes.Interface) *Reader {
+// NewReader constructs a new Reader with the specified kubernetes.Interface. Streams of Pod logs
+// fail with ErrReadTimeout once the timeout elapses, unless the timeout is 0. Logs of the specified
+// container, e.g. the scanner container, are read unless PodLogOptions name another container, so
+// that logs of scan Pods with init containers or sidecars are read from the right stream. The
+// Kubernetes API selects the container if it's blank, which fails for Pods with many containers.
+func NewReader(clientset kubernetes.Interface, timeout time.Duration, container string) *Reader {
 	return &Reader{
 		clientset: clientset,
+		timeout:   timeout,
+		container: container,
 	}
 }
 
+// GetLogsForPod returns the stream of logs of the container of the specified Pod named by PodLogOptions,
+// or of the container of the Reader if PodLogOptions do not name one.
 func (r *Reader) GetLogsForPod(ctx context.Context, key client.ObjectKey, options *corev1.PodLogOptions) (io.ReadCloser, error) {
-	return r.clientset.CoreV1().Pods(key.Namespace).GetLogs(key.Name, options).Stream(ctx)
+	if options.Container == "" && r.container != "" {
+		// Do not modify options of the caller.
+		options = options.DeepCopy()
+		options.Container = r.container
+	}
+	if r.timeout <= 0 {
+		return r.clientset.CoreV1().Pods(key.Namespace).GetLogs(key.Name, options).Stream(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	stream, err := r.clientset.CoreV1().Pods(key.Namespace).GetLogs(key.Name, options).Stream(ctx)
+	if err != nil {
+		cancel()
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, ErrReadTimeout
+		}
+		return nil, err
+	}
+	return newTimeoutReadCloser(ctx, cancel, stream, r.timeout), nil
+}
+
+// NewTimeoutReadCloser returns the ReadCloser which closes the specified stream once the timeout elapses,
+// so that pending and subsequent reads fail with ErrReadTimeout rather than block.
+func NewTimeoutReadCloser(stream io.ReadCloser, timeout time.Duration) io.ReadCloser {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	return newTimeoutReadCloser(ctx, cancel, stream, timeout)
+}
+
+// newTimeoutReadCloser wraps the specified stream bound by the given context, e.g. the body of a request,
+// which is also closed explicitly once the timeout elapses for streams that ignore the context.
+func newTimeoutReadCloser(ctx context.Context, cancel context.CancelFunc, stream io.ReadCloser, timeout time.Duration) io.ReadCloser {
+	r := &timeoutReadCloser{ctx: ctx, cancel: cancel, stream: stream}
+	r.timer = time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&r.timedOut, 1)
+		_ = stream.Close()
+	})
+	return r
+}
+
+type timeoutReadCloser struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stream   io.ReadCloser
+	timer    *time.Timer
+	timedOut int32
+}
+
+func (r *timeoutReadCloser) Read(p []byte) (int, error) {
+	n, err := r.stream.Read(p)
+	if err != nil && (atomic.LoadInt32(&r.timedOut) == 1 || r.ctx.Err() == context.DeadlineExceeded) {
+		return n, ErrReadTimeout
+	}
+	return n, err
+}
+
+func (r *timeoutReadCloser) Close() error {
+	r.timer.Stop()
+	defer r.cancel()
+	return r.stream.Close()
+}
+
+// scanErrorTailLines is the number of lines at the end of scanner logs that are parsed
+// to classify a scan failure.
+const scanErrorTailLines int64 = 100
+
+// GetScanErrorForPod classifies the failure of the specified scan Job container based on
+// the tail of its logs. Returns nil if the cause of the failure is not recognized.
+func (r *Reader) GetScanErrorForPod(ctx context.Context, key client.ObjectKey, container string) (*ScanError, error) {
+	tailLines := scanErrorTailLines
+	logsReader, err := r.GetLogsForPod(ctx, key, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tailLines,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = logsReader.Close()
+	}()
+	return ClassifyScanError(logsReader), nil
 }
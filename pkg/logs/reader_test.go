@@ -1 +1,151 @@
 package logs_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNewTimeoutReadCloser(t *testing.T) {
+	t.Run("Should fail blocked read once timeout elapses", func(t *testing.T) {
+		pipeReader, pipeWriter := io.Pipe()
+		defer func() {
+			_ = pipeWriter.Close()
+		}()
+		go func() {
+			_, _ = pipeWriter.Write([]byte("FATAL\t"))
+		}()
+
+		reader := logs.NewTimeoutReadCloser(pipeReader, 50*time.Millisecond)
+		defer func() {
+			_ = reader.Close()
+		}()
+		_, err := ioutil.ReadAll(reader)
+		assert.Equal(t, logs.ErrReadTimeout, err)
+		assert.True(t, logs.IsReadTimeout(fmt.Errorf("decoding report: %w", err)))
+	})
+
+	t.Run("Should read stream which completes before timeout", func(t *testing.T) {
+		pipeReader, pipeWriter := io.Pipe()
+		go func() {
+			_, _ = pipeWriter.Write([]byte("{}"))
+			_ = pipeWriter.Close()
+		}()
+
+		reader := logs.NewTimeoutReadCloser(pipeReader, time.Minute)
+		defer func() {
+			_ = reader.Close()
+		}()
+		data, err := ioutil.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "{}", string(data))
+	})
+}
+
+func TestReader_GetLogsForPod(t *testing.T) {
+	// The server streams the beginning of logs and hangs until the client gives up.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Vulnerabilities":[`))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	t.Run("Should return timeout error when logs stream hangs", func(t *testing.T) {
+		reader := logs.NewReader(clientset, 100*time.Millisecond, "")
+		stream, err := reader.GetLogsForPod(context.Background(), client.ObjectKey{Namespace: "starboard-operator", Name: "scan-job"},
+			&corev1.PodLogOptions{Container: "nginx", Follow: true})
+		require.NoError(t, err)
+		defer func() {
+			_ = stream.Close()
+		}()
+
+		done := make(chan error)
+		go func() {
+			_, err := ioutil.ReadAll(stream)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			assert.True(t, logs.IsReadTimeout(err), "Expected timeout error but got %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Reading logs did not time out")
+		}
+	})
+}
+
+func TestReader_GetLogsForPodContainer(t *testing.T) {
+	// The server responds with the name of the container whose logs are requested.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Query().Get("container")))
+	}))
+	defer server.Close()
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	readLogs := func(t *testing.T, reader *logs.Reader, options *corev1.PodLogOptions) string {
+		t.Helper()
+		stream, err := reader.GetLogsForPod(context.Background(), client.ObjectKey{Namespace: "starboard-operator", Name: "scan-job"}, options)
+		require.NoError(t, err)
+		defer func() {
+			_ = stream.Close()
+		}()
+		data, err := ioutil.ReadAll(stream)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	testCases := []struct {
+		name              string
+		container         string
+		options           *corev1.PodLogOptions
+		expectedContainer string
+	}{
+		{
+			name:              "Should read logs of container of reader",
+			container:         "polaris",
+			options:           &corev1.PodLogOptions{Follow: true},
+			expectedContainer: "polaris",
+		},
+		{
+			name:              "Should read logs of container named by options",
+			container:         "polaris",
+			options:           &corev1.PodLogOptions{Container: "nginx", Follow: true},
+			expectedContainer: "nginx",
+		},
+		{
+			name:              "Should read logs of container named by options when reader has no container",
+			options:           &corev1.PodLogOptions{Container: "nginx", Follow: true},
+			expectedContainer: "nginx",
+		},
+		{
+			name:              "Should not name container when neither reader nor options have one",
+			options:           &corev1.PodLogOptions{Follow: true},
+			expectedContainer: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, timeout := range []time.Duration{0, time.Minute} {
+				options := tc.options.DeepCopy()
+				assert.Equal(t, tc.expectedContainer, readLogs(t, logs.NewReader(clientset, timeout, tc.container), options))
+				assert.Equal(t, tc.options, options, "options of the caller must not be modified")
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace = "starboard"
+)
+
+// ScanJobResult represents the outcome of a scan Job.
+type ScanJobResult string
+
+const (
+	ScanJobResultComplete ScanJobResult = "complete"
+	ScanJobResultFailed   ScanJobResult = "failed"
+)
+
+var (
+	// ScanJobsTotal counts processed scan Jobs partitioned by the scanner and the result.
+	ScanJobsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scan_jobs_total",
+			Help:      "Total number of processed scan jobs.",
+		},
+		[]string{"scanner", "result"},
+	)
+
+	// ScanJobDurationSeconds observes how long scan Jobs run for partitioned by the scanner and the result.
+	ScanJobDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "scan_job_duration_seconds",
+			Help:      "Duration of scan jobs in seconds.",
+			Buckets:   []float64{5, 10, 30, 60, 120, 300, 600, 1200},
+		},
+		[]string{"scanner", "result"},
+	)
+
+	// VulnerabilityReports tracks the number of VulnerabilityReports stored in each namespace.
+	VulnerabilityReports = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "vulnerability_reports",
+			Help:      "Number of vulnerability reports stored in a namespace.",
+		},
+		[]string{"namespace"},
+	)
+
+	// Vulnerabilities tracks the number of vulnerabilities in VulnerabilityReports stored in each namespace
+	// partitioned by the severity.
+	Vulnerabilities = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "vulnerabilities",
+			Help:      "Number of vulnerabilities in vulnerability reports stored in a namespace.",
+		},
+		[]string{"namespace", "severity"},
+	)
+
+	// IgnoredVulnerabilitiesTotal counts vulnerabilities dropped from reports by the vulnerability policy
+	// partitioned by the namespace.
+	IgnoredVulnerabilitiesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ignored_vulnerabilities_total",
+			Help:      "Total number of vulnerabilities ignored by the vulnerability policy.",
+		},
+		[]string{"namespace"},
+	)
+
+	// DryRunScansTotal counts scan Jobs that would have been created in the dry-run mode partitioned by the scanner.
+	DryRunScansTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dry_run_scans_total",
+			Help:      "Total number of scan jobs that would have been created in the dry-run mode.",
+		},
+		[]string{"scanner"},
+	)
+)
+
+func init() {
+	// Register custom metrics with the global registry used by the controllers manager
+	// so that they're served on the existing metrics endpoint.
+	metrics.Registry.MustRegister(
+		ScanJobsTotal,
+		ScanJobDurationSeconds,
+		VulnerabilityReports,
+		Vulnerabilities,
+		IgnoredVulnerabilitiesTotal,
+		DryRunScansTotal,
+	)
+}
+
+// RecordScanJob records the result and the duration of a scan Job run by the given scanner.
+func RecordScanJob(scanner string, result ScanJobResult, duration time.Duration) {
+	ScanJobsTotal.WithLabelValues(scanner, string(result)).Inc()
+	ScanJobDurationSeconds.WithLabelValues(scanner, string(result)).Observe(duration.Seconds())
+}
+
+// SetVulnerabilityReports sets the number of VulnerabilityReports stored in the given namespace.
+func SetVulnerabilityReports(namespace string, count int) {
+	VulnerabilityReports.WithLabelValues(namespace).Set(float64(count))
+}
+
+// SetVulnerabilities sets the number of vulnerabilities of each severity in VulnerabilityReports stored in
+// the given namespace. Severities missing from the summary are set to zero, so that fixed vulnerabilities
+// and deleted reports are reflected.
+func SetVulnerabilities(namespace string, summary v1alpha1.VulnerabilitySummary) {
+	for severity, count := range map[v1alpha1.Severity]int{
+		v1alpha1.SeverityCritical: summary.CriticalCount,
+		v1alpha1.SeverityHigh:     summary.HighCount,
+		v1alpha1.SeverityMedium:   summary.MediumCount,
+		v1alpha1.SeverityLow:      summary.LowCount,
+		v1alpha1.SeverityUnknown:  summary.UnknownCount,
+	} {
+		Vulnerabilities.WithLabelValues(namespace, string(severity)).Set(float64(count))
+	}
+}
+
+// RecordIgnoredVulnerabilities records the number of vulnerabilities in the given namespace which were
+// ignored by the vulnerability policy.
+func RecordIgnoredVulnerabilities(namespace string, count int) {
+	IgnoredVulnerabilitiesTotal.WithLabelValues(namespace).Add(float64(count))
+}
+
+// RecordDryRunScan records a scan Job that would have been created for the given scanner in the dry-run mode.
+func RecordDryRunScan(scanner string) {
+	DryRunScansTotal.WithLabelValues(scanner).Inc()
+}
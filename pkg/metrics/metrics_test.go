@@ -0,0 +1,59 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/metrics"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordScanJob(t *testing.T) {
+	metrics.RecordScanJob("Trivy", metrics.ScanJobResultComplete, 10*time.Second)
+	metrics.RecordScanJob("Trivy", metrics.ScanJobResultComplete, 20*time.Second)
+	metrics.RecordScanJob("Trivy", metrics.ScanJobResultFailed, 5*time.Second)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.ScanJobsTotal.WithLabelValues("Trivy", "complete")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.ScanJobsTotal.WithLabelValues("Trivy", "failed")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.ScanJobsTotal.WithLabelValues("Aqua CSP", "complete")))
+
+	histogram := &dto.Metric{}
+	err := metrics.ScanJobDurationSeconds.WithLabelValues("Trivy", "complete").(prometheus.Histogram).Write(histogram)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), histogram.GetHistogram().GetSampleCount())
+	assert.Equal(t, float64(30), histogram.GetHistogram().GetSampleSum())
+}
+
+func TestSetVulnerabilityReports(t *testing.T) {
+	metrics.SetVulnerabilityReports("foo", 3)
+	metrics.SetVulnerabilityReports("bar", 1)
+	metrics.SetVulnerabilityReports("foo", 4)
+
+	assert.Equal(t, float64(4), testutil.ToFloat64(metrics.VulnerabilityReports.WithLabelValues("foo")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.VulnerabilityReports.WithLabelValues("bar")))
+}
+
+func TestSetVulnerabilities(t *testing.T) {
+	metrics.SetVulnerabilities("foo", v1alpha1.VulnerabilitySummary{CriticalCount: 2, HighCount: 5, LowCount: 1})
+	metrics.SetVulnerabilities("bar", v1alpha1.VulnerabilitySummary{MediumCount: 3})
+	metrics.SetVulnerabilities("foo", v1alpha1.VulnerabilitySummary{CriticalCount: 1, HighCount: 5})
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.Vulnerabilities.WithLabelValues("foo", "CRITICAL")))
+	assert.Equal(t, float64(5), testutil.ToFloat64(metrics.Vulnerabilities.WithLabelValues("foo", "HIGH")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.Vulnerabilities.WithLabelValues("foo", "LOW")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(metrics.Vulnerabilities.WithLabelValues("bar", "MEDIUM")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.Vulnerabilities.WithLabelValues("bar", "UNKNOWN")))
+}
+
+func TestRecordDryRunScan(t *testing.T) {
+	metrics.RecordDryRunScan("Trivy")
+	metrics.RecordDryRunScan("Trivy")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.DryRunScansTotal.WithLabelValues("Trivy")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.DryRunScansTotal.WithLabelValues("Grype")))
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// Path is the path which metrics are served at.
+	Path = "/metrics"
+)
+
+var (
+	log = ctrl.Log.WithName("metrics")
+)
+
+// SecureServer serves metrics of the specified registry over HTTPS instead of the plain HTTP metrics server of
+// the controllers manager. If a kubernetes.Interface is set, requests must bear a token of a user authorized to
+// get the /metrics non-resource URL, the same way as kube-rbac-proxy does. The token is authenticated with a
+// TokenReview, and the user is authorized with a SubjectAccessReview.
+type SecureServer struct {
+	BindAddress string
+	CertFile    string
+	KeyFile     string
+	Gatherer    prometheus.Gatherer
+	Clientset   kubernetes.Interface
+}
+
+// Start serves metrics until the stop channel is closed. The SecureServer implements manager.Runnable.
+func (s *SecureServer) Start(stop <-chan struct{}) error {
+	certificate, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading metrics server certificate: %w", err)
+	}
+	listener, err := net.Listen("tcp", s.BindAddress)
+	if err != nil {
+		return fmt.Errorf("listening on metrics bind address %s: %w", s.BindAddress, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, s.Handler())
+	server := &http.Server{
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{certificate},
+		},
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Info("Starting secure metrics server", "address", listener.Addr().String(), "path", Path,
+			"authentication", s.Clientset != nil)
+		errs <- server.ServeTLS(listener, "", "")
+	}()
+
+	select {
+	case err = <-errs:
+		return err
+	case <-stop:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
+
+// NeedLeaderElection returns false, so that metrics are served by every replica of the operator.
+func (s *SecureServer) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the handler of metrics requests, which authenticates and authorizes them if a
+// kubernetes.Interface is set.
+func (s *SecureServer) Handler() http.Handler {
+	handler := promhttp.HandlerFor(s.Gatherer, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.HTTPErrorOnError,
+	})
+	if s.Clientset == nil {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := s.authorize(r)
+		if err != nil {
+			log.V(1).Info("Denying metrics request", "status", status, "reason", err.Error())
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// authorize authenticates the bearer token of the specified request, and checks whether its user is allowed to
+// access the requested path. Returns the HTTP status of the response to the denied request and the reason.
+func (s *SecureServer) authorize(r *http.Request) (int, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return http.StatusUnauthorized, errors.New("missing bearer token")
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	if token == "" {
+		return http.StatusUnauthorized, errors.New("missing bearer token")
+	}
+
+	tokenReview, err := s.Clientset.AuthenticationV1().TokenReviews().Create(r.Context(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("creating token review: %w", err)
+	}
+	if !tokenReview.Status.Authenticated {
+		return http.StatusUnauthorized, fmt.Errorf("token not authenticated: %s", tokenReview.Status.Error)
+	}
+
+	user := tokenReview.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review, err := s.Clientset.AuthorizationV1().SubjectAccessReviews().Create(r.Context(), &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: r.URL.Path,
+				Verb: strings.ToLower(r.Method),
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("creating subject access review: %w", err)
+	}
+	if !review.Status.Allowed {
+		return http.StatusForbidden, fmt.Errorf("user %s not allowed to %s %s", user.Username,
+			strings.ToLower(r.Method), r.URL.Path)
+	}
+	return http.StatusOK, nil
+}
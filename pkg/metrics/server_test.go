@@ -0,0 +1,108 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestSecureServer_Handler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "starboard_test_total",
+		Help: "Test counter.",
+	}))
+
+	// newClientset returns the clientset which authenticates the prometheus-token token as the Prometheus
+	// service account, which is allowed to get metrics if allowed is true.
+	newClientset := func(allowed bool) *fake.Clientset {
+		clientset := fake.NewSimpleClientset()
+		clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+			if review.Spec.Token == "prometheus-token" {
+				review.Status = authenticationv1.TokenReviewStatus{
+					Authenticated: true,
+					User: authenticationv1.UserInfo{
+						Username: "system:serviceaccount:monitoring:prometheus",
+					},
+				}
+			}
+			return true, review, nil
+		})
+		clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+			review.Status.Allowed = allowed &&
+				review.Spec.User == "system:serviceaccount:monitoring:prometheus" &&
+				review.Spec.NonResourceAttributes.Path == "/metrics" &&
+				review.Spec.NonResourceAttributes.Verb == "get"
+			return true, review, nil
+		})
+		return clientset
+	}
+
+	testCases := []struct {
+		name           string
+		clientset      *fake.Clientset
+		token          string
+		expectedStatus int
+	}{
+		{
+			name:           "Should serve metrics without auth",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Should serve metrics to authorized user",
+			clientset:      newClientset(true),
+			token:          "prometheus-token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Should return unauthorized when token is missing",
+			clientset:      newClientset(true),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Should return unauthorized when token is not authenticated",
+			clientset:      newClientset(true),
+			token:          "invalid-token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Should return forbidden when user is not authorized",
+			clientset:      newClientset(false),
+			token:          "prometheus-token",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &metrics.SecureServer{Gatherer: registry}
+			if tc.clientset != nil {
+				server.Clientset = tc.clientset
+			}
+			request := httptest.NewRequest(http.MethodGet, metrics.Path, nil)
+			if tc.token != "" {
+				request.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			recorder := httptest.NewRecorder()
+
+			server.Handler().ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Contains(t, recorder.Body.String(), "starboard_test_total 0")
+			} else {
+				assert.NotContains(t, recorder.Body.String(), "starboard_test_total")
+			}
+		})
+	}
+}
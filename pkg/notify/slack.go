@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+)
+
+// severityColors maps severities to colors of Slack message attachments.
+var severityColors = map[v1alpha1.Severity]string{
+	v1alpha1.SeverityCritical: "#d50000",
+	v1alpha1.SeverityHigh:     "#ff6d00",
+	v1alpha1.SeverityMedium:   "#ffd600",
+	v1alpha1.SeverityLow:      "#2962ff",
+	v1alpha1.SeverityUnknown:  "#9e9e9e",
+}
+
+// SlackMessage is the message POSTed to a Slack incoming webhook.
+type SlackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []SlackAttachment `json:"attachments"`
+}
+
+// SlackAttachment is an attachment of a SlackMessage, which is colored by the maximum severity of vulnerabilities.
+type SlackAttachment struct {
+	Color    string       `json:"color"`
+	Fallback string       `json:"fallback"`
+	Fields   []SlackField `json:"fields"`
+	Footer   string       `json:"footer,omitempty"`
+}
+
+// SlackField is a field of a SlackAttachment rendered as a table.
+type SlackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// GetMaxSeverity returns the highest severity of vulnerabilities counted in the specified summary.
+// Returns v1alpha1.SeverityUnknown if there are no vulnerabilities.
+func GetMaxSeverity(summary Summary) v1alpha1.Severity {
+	switch {
+	case summary.Critical > 0:
+		return v1alpha1.SeverityCritical
+	case summary.High > 0:
+		return v1alpha1.SeverityHigh
+	case summary.Medium > 0:
+		return v1alpha1.SeverityMedium
+	case summary.Low > 0:
+		return v1alpha1.SeverityLow
+	default:
+		return v1alpha1.SeverityUnknown
+	}
+}
+
+// NewSlackMessage constructs a new SlackMessage for the specified payload.
+func NewSlackMessage(payload Payload) SlackMessage {
+	severity := GetMaxSeverity(payload.Summary)
+	text := fmt.Sprintf("Found %s vulnerabilities in image %s of %s/%s in namespace %s",
+		severity, payload.Image, payload.Workload.Kind, payload.Workload.Name, payload.Namespace)
+	return SlackMessage{
+		Text: text,
+		Attachments: []SlackAttachment{
+			{
+				Color:    severityColors[severity],
+				Fallback: text,
+				Fields: []SlackField{
+					{Title: "Image", Value: payload.Image, Short: false},
+					{Title: "Namespace", Value: payload.Namespace, Short: true},
+					{Title: "Workload", Value: fmt.Sprintf("%s/%s", payload.Workload.Kind, payload.Workload.Name), Short: true},
+					{Title: "Container", Value: payload.Container, Short: true},
+					{Title: "Scanner", Value: payload.Scanner, Short: true},
+					{Title: string(v1alpha1.SeverityCritical), Value: fmt.Sprint(payload.Summary.Critical), Short: true},
+					{Title: string(v1alpha1.SeverityHigh), Value: fmt.Sprint(payload.Summary.High), Short: true},
+					{Title: string(v1alpha1.SeverityMedium), Value: fmt.Sprint(payload.Summary.Medium), Short: true},
+					{Title: string(v1alpha1.SeverityLow), Value: fmt.Sprint(payload.Summary.Low), Short: true},
+					{Title: string(v1alpha1.SeverityUnknown), Value: fmt.Sprint(payload.Summary.Unknown), Short: true},
+				},
+				Footer: "Starboard Operator",
+			},
+		},
+	}
+}
+
+type slackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackNotifier constructs a new Notifier which POSTs payloads formatted as a SlackMessage
+// to the specified Slack incoming webhook URL.
+func NewSlackNotifier(url string) Notifier {
+	return &slackNotifier{
+		url: url,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (n *slackNotifier) Notify(ctx context.Context, payload Payload) error {
+	return postJSON(ctx, n.client, n.url, nil, NewSlackMessage(payload))
+}
@@ -0,0 +1,145 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/notify"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSlackMessage(t *testing.T) {
+	message := notify.NewSlackMessage(notify.Payload{
+		Image:     "nginx:1.16",
+		Namespace: "default",
+		Workload:  notify.Workload{Kind: "ReplicaSet", Name: "nginx-6d4cf56db6"},
+		Container: "nginx",
+		Scanner:   "Trivy",
+		Summary:   notify.Summary{Critical: 1, High: 2, Medium: 3, Low: 4, Unknown: 5},
+	})
+
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "text": "Found CRITICAL vulnerabilities in image nginx:1.16 of ReplicaSet/nginx-6d4cf56db6 in namespace default",
+  "attachments": [
+    {
+      "color": "#d50000",
+      "fallback": "Found CRITICAL vulnerabilities in image nginx:1.16 of ReplicaSet/nginx-6d4cf56db6 in namespace default",
+      "fields": [
+        {"title": "Image", "value": "nginx:1.16", "short": false},
+        {"title": "Namespace", "value": "default", "short": true},
+        {"title": "Workload", "value": "ReplicaSet/nginx-6d4cf56db6", "short": true},
+        {"title": "Container", "value": "nginx", "short": true},
+        {"title": "Scanner", "value": "Trivy", "short": true},
+        {"title": "CRITICAL", "value": "1", "short": true},
+        {"title": "HIGH", "value": "2", "short": true},
+        {"title": "MEDIUM", "value": "3", "short": true},
+        {"title": "LOW", "value": "4", "short": true},
+        {"title": "UNKNOWN", "value": "5", "short": true}
+      ],
+      "footer": "Starboard Operator"
+    }
+  ]
+}`, string(data))
+}
+
+func TestGetMaxSeverity(t *testing.T) {
+	testCases := []struct {
+		name             string
+		summary          notify.Summary
+		expectedSeverity v1alpha1.Severity
+		expectedColor    string
+	}{
+		{
+			name:             "Should return critical",
+			summary:          notify.Summary{Critical: 1, Low: 3},
+			expectedSeverity: v1alpha1.SeverityCritical,
+			expectedColor:    "#d50000",
+		},
+		{
+			name:             "Should return high",
+			summary:          notify.Summary{High: 1, Medium: 2},
+			expectedSeverity: v1alpha1.SeverityHigh,
+			expectedColor:    "#ff6d00",
+		},
+		{
+			name:             "Should return medium",
+			summary:          notify.Summary{Medium: 2, Unknown: 1},
+			expectedSeverity: v1alpha1.SeverityMedium,
+			expectedColor:    "#ffd600",
+		},
+		{
+			name:             "Should return low",
+			summary:          notify.Summary{Low: 4},
+			expectedSeverity: v1alpha1.SeverityLow,
+			expectedColor:    "#2962ff",
+		},
+		{
+			name:             "Should return unknown for empty summary",
+			summary:          notify.Summary{},
+			expectedSeverity: v1alpha1.SeverityUnknown,
+			expectedColor:    "#9e9e9e",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedSeverity, notify.GetMaxSeverity(tc.summary))
+			message := notify.NewSlackMessage(notify.Payload{Summary: tc.summary})
+			require.Len(t, message.Attachments, 1)
+			assert.Equal(t, tc.expectedColor, message.Attachments[0].Color)
+		})
+	}
+}
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	var received notify.SlackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	payload := notify.Payload{Image: "nginx:1.16", Namespace: "default", Summary: notify.Summary{High: 1}}
+	err := notify.NewSlackNotifier(server.URL).Notify(context.Background(), payload)
+	require.NoError(t, err)
+	assert.Equal(t, notify.NewSlackMessage(payload), received)
+}
+
+type fakeNotifier struct {
+	payloads []notify.Payload
+	err      error
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, payload notify.Payload) error {
+	n.payloads = append(n.payloads, payload)
+	return n.err
+}
+
+func TestMultiNotifier_Notify(t *testing.T) {
+	payload := notify.Payload{Image: "nginx:1.16"}
+
+	t.Run("Should notify all notifiers", func(t *testing.T) {
+		webhook, slack := &fakeNotifier{}, &fakeNotifier{}
+		err := notify.NewMultiNotifier(webhook, slack).Notify(context.Background(), payload)
+		require.NoError(t, err)
+		assert.Equal(t, []notify.Payload{payload}, webhook.payloads)
+		assert.Equal(t, []notify.Payload{payload}, slack.payloads)
+	})
+
+	t.Run("Should notify remaining notifiers when one fails", func(t *testing.T) {
+		webhook, slack := &fakeNotifier{err: errors.New("connection refused")}, &fakeNotifier{}
+		err := notify.NewMultiNotifier(webhook, slack).Notify(context.Background(), payload)
+		assert.EqualError(t, err, "notifying: connection refused")
+		assert.Equal(t, []notify.Payload{payload}, slack.payloads)
+	})
+}
@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/kube"
+)
+
+const (
+	// SecretHeader is the HTTP header carrying the shared secret of a webhook.
+	SecretHeader = "X-Starboard-Secret"
+)
+
+// Notifier sends notifications about vulnerabilities found in container images.
+type Notifier interface {
+	Notify(ctx context.Context, payload Payload) error
+}
+
+// Workload identifies the workload running a scanned container image.
+type Workload struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// Summary holds the number of vulnerabilities by severity.
+type Summary struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
+// Payload is the JSON summary of a vulnerability report POSTed to a webhook.
+type Payload struct {
+	Image     string   `json:"image"`
+	Namespace string   `json:"namespace"`
+	Workload  Workload `json:"workload"`
+	Container string   `json:"container"`
+	Scanner   string   `json:"scanner"`
+	Summary   Summary  `json:"summary"`
+}
+
+// NewPayload constructs a new Payload for the specified scan result of the image of the given workload container.
+func NewPayload(workload kube.Object, container, imageRef string, result v1alpha1.VulnerabilityScanResult) Payload {
+	return Payload{
+		Image:     imageRef,
+		Namespace: workload.Namespace,
+		Workload: Workload{
+			Kind: string(workload.Kind),
+			Name: workload.Name,
+		},
+		Container: container,
+		Scanner:   result.Scanner.Name,
+		Summary: Summary{
+			Critical: result.Summary.CriticalCount,
+			High:     result.Summary.HighCount,
+			Medium:   result.Summary.MediumCount,
+			Low:      result.Summary.LowCount,
+			Unknown:  result.Summary.UnknownCount,
+		},
+	}
+}
+
+// severities lists severities in ascending order.
+var severities = []v1alpha1.Severity{
+	v1alpha1.SeverityUnknown,
+	v1alpha1.SeverityLow,
+	v1alpha1.SeverityMedium,
+	v1alpha1.SeverityHigh,
+	v1alpha1.SeverityCritical,
+}
+
+// ParseSeverity returns the Severity for the specified case insensitive name.
+func ParseSeverity(name string) (v1alpha1.Severity, error) {
+	for _, severity := range severities {
+		if strings.EqualFold(name, string(severity)) {
+			return severity, nil
+		}
+	}
+	return "", fmt.Errorf("unrecognized severity: %q", name)
+}
+
+// HasVulnerabilitiesAtOrAbove returns true if the specified summary counts any vulnerabilities
+// with the severity at or above the given threshold, false otherwise.
+func HasVulnerabilitiesAtOrAbove(summary v1alpha1.VulnerabilitySummary, threshold v1alpha1.Severity) bool {
+	counts := map[v1alpha1.Severity]int{
+		v1alpha1.SeverityUnknown:  summary.UnknownCount,
+		v1alpha1.SeverityLow:      summary.LowCount,
+		v1alpha1.SeverityMedium:   summary.MediumCount,
+		v1alpha1.SeverityHigh:     summary.HighCount,
+		v1alpha1.SeverityCritical: summary.CriticalCount,
+	}
+	above := false
+	for _, severity := range severities {
+		if severity == threshold {
+			above = true
+		}
+		if above && counts[severity] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type webhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier constructs a new Notifier which POSTs payloads as JSON to the specified URL.
+// If the secret is not blank, it's sent in the SecretHeader header so that the receiver can
+// authenticate requests.
+func NewWebhookNotifier(url, secret string) Notifier {
+	return &webhookNotifier{
+		url:    url,
+		secret: secret,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, payload Payload) error {
+	headers := map[string]string{}
+	if n.secret != "" {
+		headers[SecretHeader] = n.secret
+	}
+	return postJSON(ctx, n.client, n.url, headers, payload)
+}
+
+// postJSON POSTs the specified value as JSON to the given URL with additional headers.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, value interface{}) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshalling payload: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("constructing request: %w", err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting payload: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("posting payload: unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type multiNotifier struct {
+	notifiers []Notifier
+}
+
+// NewMultiNotifier constructs a new Notifier which sends each payload with all the specified notifiers,
+// e.g. to both a generic webhook and Slack. A failure of one notifier does not prevent the others from
+// being notified, and the returned error combines errors of all failed notifiers.
+func NewMultiNotifier(notifiers ...Notifier) Notifier {
+	return &multiNotifier{
+		notifiers: notifiers,
+	}
+}
+
+func (n *multiNotifier) Notify(ctx context.Context, payload Payload) error {
+	var errs []string
+	for _, notifier := range n.notifiers {
+		if err := notifier.Notify(ctx, payload); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("notifying: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
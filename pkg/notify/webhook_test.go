@@ -0,0 +1,137 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/notify"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPayload(t *testing.T) {
+	payload := notify.NewPayload(kube.Object{
+		Kind:      kube.KindReplicaSet,
+		Name:      "nginx-6d4cf56db6",
+		Namespace: "default",
+	}, "nginx", "nginx:1.16", v1alpha1.VulnerabilityScanResult{
+		Scanner: v1alpha1.Scanner{Name: "Trivy"},
+		Summary: v1alpha1.VulnerabilitySummary{
+			CriticalCount: 1,
+			HighCount:     2,
+			MediumCount:   3,
+			LowCount:      4,
+			UnknownCount:  5,
+		},
+	})
+
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "image": "nginx:1.16",
+  "namespace": "default",
+  "workload": {"kind": "ReplicaSet", "name": "nginx-6d4cf56db6"},
+  "container": "nginx",
+  "scanner": "Trivy",
+  "summary": {"critical": 1, "high": 2, "medium": 3, "low": 4, "unknown": 5}
+}`, string(data))
+}
+
+func TestHasVulnerabilitiesAtOrAbove(t *testing.T) {
+	testCases := []struct {
+		name      string
+		summary   v1alpha1.VulnerabilitySummary
+		threshold v1alpha1.Severity
+		expected  bool
+	}{
+		{
+			name:      "Should match critical vulnerabilities with critical threshold",
+			summary:   v1alpha1.VulnerabilitySummary{CriticalCount: 1},
+			threshold: v1alpha1.SeverityCritical,
+			expected:  true,
+		},
+		{
+			name:      "Should not match high vulnerabilities with critical threshold",
+			summary:   v1alpha1.VulnerabilitySummary{HighCount: 10, MediumCount: 3},
+			threshold: v1alpha1.SeverityCritical,
+			expected:  false,
+		},
+		{
+			name:      "Should match critical vulnerabilities with medium threshold",
+			summary:   v1alpha1.VulnerabilitySummary{CriticalCount: 1},
+			threshold: v1alpha1.SeverityMedium,
+			expected:  true,
+		},
+		{
+			name:      "Should not match low vulnerabilities with medium threshold",
+			summary:   v1alpha1.VulnerabilitySummary{LowCount: 7, UnknownCount: 2},
+			threshold: v1alpha1.SeverityMedium,
+			expected:  false,
+		},
+		{
+			name:      "Should match unknown vulnerabilities with unknown threshold",
+			summary:   v1alpha1.VulnerabilitySummary{UnknownCount: 1},
+			threshold: v1alpha1.SeverityUnknown,
+			expected:  true,
+		},
+		{
+			name:      "Should not match empty summary",
+			summary:   v1alpha1.VulnerabilitySummary{},
+			threshold: v1alpha1.SeverityUnknown,
+			expected:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, notify.HasVulnerabilitiesAtOrAbove(tc.summary, tc.threshold))
+		})
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	severity, err := notify.ParseSeverity("high")
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.SeverityHigh, severity)
+
+	_, err = notify.ParseSeverity("severe")
+	assert.EqualError(t, err, `unrecognized severity: "severe"`)
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	t.Run("Should post payload with secret header", func(t *testing.T) {
+		var received notify.Payload
+		var secret, contentType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret = r.Header.Get(notify.SecretHeader)
+			contentType = r.Header.Get("Content-Type")
+			body, _ := ioutil.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &received)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		payload := notify.Payload{Image: "nginx:1.16", Namespace: "default", Summary: notify.Summary{Critical: 1}}
+		err := notify.NewWebhookNotifier(server.URL, "s3cret").Notify(context.Background(), payload)
+		require.NoError(t, err)
+		assert.Equal(t, payload, received)
+		assert.Equal(t, "s3cret", secret)
+		assert.Equal(t, "application/json", contentType)
+	})
+
+	t.Run("Should return error on unexpected status code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		err := notify.NewWebhookNotifier(server.URL, "").Notify(context.Background(), notify.Payload{})
+		assert.EqualError(t, err, "posting payload: unexpected status code: 500")
+	})
+}
@@ -0,0 +1,33 @@
+package polaris
+
+// Report represents the JSON document printed by `polaris audit --format json`.
+type Report struct {
+	PolarisOutputVersion string   `json:"PolarisOutputVersion"`
+	Results              []Result `json:"Results"`
+}
+
+type Result struct {
+	Name      string    `json:"Name"`
+	Namespace string    `json:"Namespace"`
+	Kind      string    `json:"Kind"`
+	PodResult PodResult `json:"PodResult"`
+}
+
+type PodResult struct {
+	Name             string            `json:"Name"`
+	Results          map[string]Check  `json:"Results"`
+	ContainerResults []ContainerResult `json:"ContainerResults"`
+}
+
+type ContainerResult struct {
+	Name    string           `json:"Name"`
+	Results map[string]Check `json:"Results"`
+}
+
+type Check struct {
+	ID       string `json:"ID"`
+	Message  string `json:"Message"`
+	Success  bool   `json:"Success"`
+	Severity string `json:"Severity"` // e.g. error, warning
+	Category string `json:"Category"`
+}
@@ -0,0 +1,214 @@
+package polaris
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/aquasecurity/starboard/pkg/scanners"
+	"github.com/google/uuid"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	containerName = "polaris"
+)
+
+// groupVersions maps workload kinds to API group versions used to identify audited resources.
+var groupVersions = map[kube.Kind]schema.GroupVersion{
+	kube.KindPod:                   {Version: "v1"},
+	kube.KindReplicationController: {Version: "v1"},
+	kube.KindReplicaSet:            {Group: "apps", Version: "v1"},
+	kube.KindDeployment:            {Group: "apps", Version: "v1"},
+	kube.KindStatefulSet:           {Group: "apps", Version: "v1"},
+	kube.KindDaemonSet:             {Group: "apps", Version: "v1"},
+	kube.KindJob:                   {Group: "batch", Version: "v1"},
+	kube.KindCronJob:               {Group: "batch", Version: "v1beta1"},
+}
+
+type polarisScanner struct {
+	config etc.ConfigAuditPolaris
+}
+
+func NewScanner(config etc.ConfigAuditPolaris) scanner.ConfigAuditScanner {
+	return &polarisScanner{
+		config: config,
+	}
+}
+
+func (s *polarisScanner) GetName() string {
+	return "Polaris"
+}
+
+func (s *polarisScanner) GetContainerName() string {
+	return containerName
+}
+
+// NewConfigAuditJob constructs a Job which runs Polaris against the specified workload.
+// Polaris reads the workload from the API server, therefore the service account token
+// is mounted to the Job's Pod.
+func (s *polarisScanner) NewConfigAuditJob(meta scanner.JobMeta, options scanner.Options, workload kube.Object) (*batchv1.Job, error) {
+	resource, err := GetResourceName(workload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        uuid.New().String(),
+			Namespace:   options.Namespace,
+			Labels:      meta.Labels,
+			Annotations: meta.Annotations,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          pointer.Int32Ptr(options.ScanJobBackoffLimit),
+			Completions:           pointer.Int32Ptr(1),
+			ActiveDeadlineSeconds: scanners.GetActiveDeadlineSeconds(options.ScanJobTimeout),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      meta.Labels,
+					Annotations: meta.Annotations,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					ServiceAccountName:           options.ServiceAccountName,
+					AutomountServiceAccountToken: pointer.BoolPtr(true),
+					NodeSelector:                 options.ScanJobNodeSelector,
+					Tolerations:                  options.ScanJobTolerations,
+					Affinity:                     options.ScanJobAffinity,
+					SecurityContext:              options.ScanJobPodSecurityContext,
+					Containers: []corev1.Container{
+						{
+							Name:                     containerName,
+							Image:                    s.config.ImageRef,
+							ImagePullPolicy:          options.ScanJobImagePullPolicy,
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+							SecurityContext:          options.ScanJobSecurityContext.DeepCopy(),
+							Env:                      scanner.NewProxyEnvVars(options),
+							Command: []string{
+								"polaris",
+							},
+							Args: []string{
+								"audit",
+								"--log-level",
+								"error",
+								"--format",
+								"json",
+								"--resource",
+								resource,
+							},
+							Resources: options.ScanJobResources,
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// GetResourceName returns the identifier of the specified workload accepted by the
+// `--resource` flag of `polaris audit`, e.g. `default/Deployment.apps/v1/nginx`.
+func GetResourceName(workload kube.Object) (string, error) {
+	gv, ok := groupVersions[workload.Kind]
+	if !ok {
+		return "", fmt.Errorf("unsupported workload kind: %s", workload.Kind)
+	}
+	kind := string(workload.Kind)
+	if gv.Group != "" {
+		kind = kind + "." + gv.Group
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", workload.Namespace, kind, gv.Version, workload.Name), nil
+}
+
+func (s *polarisScanner) ParseConfigAuditResult(logsReader io.ReadCloser) (v1alpha1.ConfigAudit, error) {
+	var report Report
+	err := json.NewDecoder(logsReader).Decode(&report)
+	if err != nil {
+		return v1alpha1.ConfigAudit{}, fmt.Errorf("decoding polaris report: %w", err)
+	}
+	if len(report.Results) != 1 {
+		return v1alpha1.ConfigAudit{}, fmt.Errorf("expected 1 result, but got %d", len(report.Results))
+	}
+	return s.convert(report.Results[0]), nil
+}
+
+func (s *polarisScanner) convert(result Result) v1alpha1.ConfigAudit {
+	podChecks := s.toChecks(result.PodResult.Results)
+	containerChecks := make(map[string][]v1alpha1.Check)
+	for _, containerResult := range result.PodResult.ContainerResults {
+		containerChecks[containerResult.Name] = s.toChecks(containerResult.Results)
+	}
+
+	summary := s.toSummary(podChecks)
+	for _, checks := range containerChecks {
+		containerSummary := s.toSummary(checks)
+		summary.DangerCount += containerSummary.DangerCount
+		summary.WarningCount += containerSummary.WarningCount
+	}
+
+	return v1alpha1.ConfigAudit{
+		Scanner: v1alpha1.Scanner{
+			Name:    "Polaris",
+			Vendor:  "Fairwinds Ops",
+			Version: s.config.Version,
+		},
+		Summary:         summary,
+		PodChecks:       podChecks,
+		ContainerChecks: containerChecks,
+	}
+}
+
+// toChecks converts the specified Polaris results to checks sorted by ID.
+func (s *polarisScanner) toChecks(results map[string]Check) []v1alpha1.Check {
+	checks := make([]v1alpha1.Check, 0, len(results))
+	for _, result := range results {
+		checks = append(checks, v1alpha1.Check{
+			ID:       result.ID,
+			Message:  result.Message,
+			Success:  result.Success,
+			Severity: s.toSeverity(result.Severity),
+			Category: result.Category,
+		})
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].ID < checks[j].ID
+	})
+	return checks
+}
+
+// toSeverity maps Polaris severities to the ones used by ConfigAuditReports.
+// Polaris 1.x reports the most severe failures as errors, whereas earlier
+// versions reported them as dangers.
+func (s *polarisScanner) toSeverity(severity string) string {
+	switch severity {
+	case "error", "danger":
+		return v1alpha1.ConfigAuditDangerSeverity
+	default:
+		return severity
+	}
+}
+
+func (s *polarisScanner) toSummary(checks []v1alpha1.Check) v1alpha1.ConfigAuditSummary {
+	summary := v1alpha1.ConfigAuditSummary{}
+	for _, check := range checks {
+		if check.Success {
+			continue
+		}
+		switch check.Severity {
+		case v1alpha1.ConfigAuditDangerSeverity:
+			summary.DangerCount++
+		case v1alpha1.ConfigAuditWarningSeverity:
+			summary.WarningCount++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,154 @@
+package polaris_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/polaris"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var config = etc.ConfigAuditPolaris{
+	Enabled:  true,
+	Version:  "1.2",
+	ImageRef: "quay.io/fairwinds/polaris:1.2",
+}
+
+func TestPolarisScanner_NewConfigAuditJob(t *testing.T) {
+	job, err := polaris.NewScanner(config).NewConfigAuditJob(scanner.JobMeta{
+		Labels: map[string]string{
+			"app.kubernetes.io/managed-by": "starboard-operator",
+		},
+	}, scanner.Options{
+		Namespace:              "starboard-operator",
+		ServiceAccountName:     "starboard-operator",
+		ScanJobTimeout:         5 * time.Minute,
+		ScanJobImagePullPolicy: corev1.PullIfNotPresent,
+	}, kube.Object{Kind: kube.KindDeployment, Name: "nginx", Namespace: "default"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "starboard-operator", job.Namespace)
+	assert.Equal(t, "starboard-operator", job.Spec.Template.Spec.ServiceAccountName)
+	assert.True(t, *job.Spec.Template.Spec.AutomountServiceAccountToken)
+	assert.Equal(t, int64(300), *job.Spec.ActiveDeadlineSeconds)
+	require.Len(t, job.Spec.Template.Spec.Containers, 1)
+	assert.Equal(t, "polaris", job.Spec.Template.Spec.Containers[0].Name)
+	assert.Equal(t, "quay.io/fairwinds/polaris:1.2", job.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, corev1.PullIfNotPresent, job.Spec.Template.Spec.Containers[0].ImagePullPolicy)
+	assert.Equal(t, []string{
+		"audit", "--log-level", "error", "--format", "json",
+		"--resource", "default/Deployment.apps/v1/nginx",
+	}, job.Spec.Template.Spec.Containers[0].Args)
+}
+
+func TestGetResourceName(t *testing.T) {
+	testCases := []struct {
+		name          string
+		workload      kube.Object
+		expectedName  string
+		expectedError string
+	}{
+		{
+			name:         "Should return name of Pod in core group",
+			workload:     kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"},
+			expectedName: "default/Pod/v1/nginx",
+		},
+		{
+			name:         "Should return name of ReplicaSet in apps group",
+			workload:     kube.Object{Kind: kube.KindReplicaSet, Name: "nginx-6d4cf56db6", Namespace: "default"},
+			expectedName: "default/ReplicaSet.apps/v1/nginx-6d4cf56db6",
+		},
+		{
+			name:         "Should return name of CronJob in batch group",
+			workload:     kube.Object{Kind: kube.KindCronJob, Name: "hello", Namespace: "default"},
+			expectedName: "default/CronJob.batch/v1beta1/hello",
+		},
+		{
+			name:          "Should return error for unsupported kind",
+			workload:      kube.Object{Kind: kube.Kind("Service"), Name: "nginx", Namespace: "default"},
+			expectedError: "unsupported workload kind: Service",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, err := polaris.GetResourceName(tc.workload)
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedName, name)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestPolarisScanner_ParseConfigAuditResult(t *testing.T) {
+	file, err := os.Open("testdata/report.json")
+	require.NoError(t, err)
+	defer func() {
+		_ = file.Close()
+	}()
+
+	result, err := polaris.NewScanner(config).ParseConfigAuditResult(file)
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.ConfigAudit{
+		Scanner: v1alpha1.Scanner{
+			Name:    "Polaris",
+			Vendor:  "Fairwinds Ops",
+			Version: "1.2",
+		},
+		Summary: v1alpha1.ConfigAuditSummary{
+			DangerCount:  1,
+			WarningCount: 2,
+		},
+		PodChecks: []v1alpha1.Check{
+			{
+				ID:       "hostIPCSet",
+				Message:  "Host IPC is not configured",
+				Success:  true,
+				Severity: "danger",
+				Category: "Security",
+			},
+			{
+				ID:       "hostNetworkSet",
+				Message:  "Host network is not configured",
+				Success:  true,
+				Severity: "warning",
+				Category: "Networking",
+			},
+		},
+		ContainerChecks: map[string][]v1alpha1.Check{
+			"nginx": {
+				{
+					ID:       "cpuLimitsMissing",
+					Message:  "CPU limits should be set",
+					Success:  false,
+					Severity: "warning",
+					Category: "Resources",
+				},
+				{
+					ID:       "privilegeEscalationAllowed",
+					Message:  "Privilege escalation should not be allowed",
+					Success:  false,
+					Severity: "danger",
+					Category: "Security",
+				},
+				{
+					ID:       "runAsRootAllowed",
+					Message:  "Should not be allowed to run as root",
+					Success:  false,
+					Severity: "warning",
+					Category: "Security",
+				},
+			},
+		},
+	}, result)
+}
@@ -0,0 +1,156 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/open-policy-agent/opa/rego"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Query is the Rego query evaluated for each vulnerability. A policy defines the ignore rule in the
+	// starboard.vulnerabilities package, which is true for vulnerabilities dropped from reports.
+	Query = "data.starboard.vulnerabilities.ignore"
+
+	// ConfigMapKey is the key of the policy ConfigMap holding the Rego source of the policy.
+	ConfigMapKey = "policy.rego"
+)
+
+// Namespace represents the namespace of the scanned workload in the policy input.
+type Namespace struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+}
+
+// Workload represents the scanned workload in the policy input.
+type Workload struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// Input is the input document of the policy, e.g.
+//
+//	{
+//	  "namespace": {"name": "dev", "labels": {"environment": "dev"}},
+//	  "workload": {"kind": "Deployment", "name": "nginx"},
+//	  "container": "nginx",
+//	  "image": "nginx:1.16",
+//	  "vulnerability": {"vulnerabilityID": "CVE-2020-1234", "severity": "LOW", ...}
+//	}
+type Input struct {
+	Namespace     Namespace              `json:"namespace"`
+	Workload      Workload               `json:"workload"`
+	Container     string                 `json:"container"`
+	Image         string                 `json:"image"`
+	Vulnerability v1alpha1.Vulnerability `json:"vulnerability"`
+}
+
+// Policy decides which vulnerabilities are ignored, i.e. dropped from reports before they're written.
+type Policy struct {
+	query rego.PreparedEvalQuery
+}
+
+// New compiles the specified Rego source into a Policy.
+func New(ctx context.Context, source string) (*Policy, error) {
+	query, err := rego.New(
+		rego.Query(Query),
+		rego.Module(ConfigMapKey, source),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("compiling policy: %w", err)
+	}
+	return &Policy{query: query}, nil
+}
+
+// Ignore returns true if the vulnerability of the specified input is ignored by the policy.
+// Vulnerabilities are not ignored if the ignore rule is undefined for the input.
+func (p *Policy) Ignore(ctx context.Context, input Input) (bool, error) {
+	results, err := p.query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return false, fmt.Errorf("evaluating policy: %w", err)
+	}
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return false, nil
+	}
+	ignore, ok := results[0].Expressions[0].Value.(bool)
+	if !ok {
+		return false, fmt.Errorf("evaluating policy: ignore rule must be boolean but got %T", results[0].Expressions[0].Value)
+	}
+	return ignore, nil
+}
+
+// Apply returns a copy of the specified scan result without vulnerabilities ignored by the policy, and the
+// number of ignored vulnerabilities. The Vulnerability of the input is set to each evaluated vulnerability.
+// The summary is recomputed to match the retained vulnerabilities.
+func (p *Policy) Apply(ctx context.Context, input Input, result v1alpha1.VulnerabilityScanResult) (v1alpha1.VulnerabilityScanResult, int, error) {
+	retained := []v1alpha1.Vulnerability{}
+	for _, vulnerability := range result.Vulnerabilities {
+		input.Vulnerability = vulnerability
+		ignore, err := p.Ignore(ctx, input)
+		if err != nil {
+			return result, 0, err
+		}
+		if !ignore {
+			retained = append(retained, vulnerability)
+		}
+	}
+	ignored := len(result.Vulnerabilities) - len(retained)
+	if ignored == 0 {
+		return result, 0, nil
+	}
+	filtered := result
+	filtered.Vulnerabilities = retained
+	filtered.Summary = reports.SummarizeVulnerabilities(retained)
+	return filtered, ignored, nil
+}
+
+// Loader loads the Policy from the ConfigMap with the specified name. The compiled Policy is cached
+// until the ConfigMap changes, so that updates of the policy take effect without restarting the operator.
+type Loader struct {
+	client client.Client
+	name   types.NamespacedName
+
+	mu              sync.Mutex
+	resourceVersion string
+	policy          *Policy
+}
+
+func NewLoader(client client.Client, name types.NamespacedName) *Loader {
+	return &Loader{
+		client: client,
+		name:   name,
+	}
+}
+
+// Load returns the Policy compiled from the ConfigMap. Returns an error if the ConfigMap does not exist,
+// does not hold the ConfigMapKey, or the policy cannot be compiled.
+func (l *Loader) Load(ctx context.Context) (*Policy, error) {
+	configMap := &corev1.ConfigMap{}
+	err := l.client.Get(ctx, l.name, configMap)
+	if err != nil {
+		return nil, fmt.Errorf("getting policy configmap %s: %w", l.name, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.policy != nil && l.resourceVersion == configMap.ResourceVersion {
+		return l.policy, nil
+	}
+	source, ok := configMap.Data[ConfigMapKey]
+	if !ok {
+		return nil, fmt.Errorf("policy configmap %s does not have the %s key", l.name, ConfigMapKey)
+	}
+	policy, err := New(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	l.resourceVersion = configMap.ResourceVersion
+	l.policy = policy
+	return policy, nil
+}
@@ -0,0 +1,149 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/policy"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// ignoreLowInDev ignores vulnerabilities of the low severity in namespaces labeled as dev.
+const ignoreLowInDev = `package starboard.vulnerabilities
+
+ignore {
+	input.namespace.labels.environment == "dev"
+	input.vulnerability.severity == "LOW"
+}
+`
+
+func newScanResult() v1alpha1.VulnerabilityScanResult {
+	return v1alpha1.VulnerabilityScanResult{
+		Summary: v1alpha1.VulnerabilitySummary{HighCount: 1, LowCount: 2},
+		Vulnerabilities: []v1alpha1.Vulnerability{
+			{VulnerabilityID: "CVE-2020-0001", Severity: v1alpha1.SeverityHigh},
+			{VulnerabilityID: "CVE-2020-0002", Severity: v1alpha1.SeverityLow},
+			{VulnerabilityID: "CVE-2020-0003", Severity: v1alpha1.SeverityLow},
+		},
+	}
+}
+
+func TestPolicy_Apply(t *testing.T) {
+	testCases := []struct {
+		name            string
+		source          string
+		namespaceLabels map[string]string
+		expectedIDs     []string
+		expectedSummary v1alpha1.VulnerabilitySummary
+		expectedIgnored int
+	}{
+		{
+			name:            "Should ignore low vulnerabilities in dev namespace",
+			source:          ignoreLowInDev,
+			namespaceLabels: map[string]string{"environment": "dev"},
+			expectedIDs:     []string{"CVE-2020-0001"},
+			expectedSummary: v1alpha1.VulnerabilitySummary{HighCount: 1},
+			expectedIgnored: 2,
+		},
+		{
+			name:            "Should retain low vulnerabilities in prod namespace",
+			source:          ignoreLowInDev,
+			namespaceLabels: map[string]string{"environment": "prod"},
+			expectedIDs:     []string{"CVE-2020-0001", "CVE-2020-0002", "CVE-2020-0003"},
+			expectedSummary: v1alpha1.VulnerabilitySummary{HighCount: 1, LowCount: 2},
+		},
+		{
+			name: "Should ignore vulnerabilities by ID",
+			source: `package starboard.vulnerabilities
+
+ignore {
+	input.vulnerability.vulnerabilityID == "CVE-2020-0003"
+}
+`,
+			expectedIDs:     []string{"CVE-2020-0001", "CVE-2020-0002"},
+			expectedSummary: v1alpha1.VulnerabilitySummary{HighCount: 1, LowCount: 1},
+			expectedIgnored: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vulnerabilityPolicy, err := policy.New(context.Background(), tc.source)
+			require.NoError(t, err)
+
+			result, ignored, err := vulnerabilityPolicy.Apply(context.Background(), policy.Input{
+				Namespace: policy.Namespace{Name: "default", Labels: tc.namespaceLabels},
+				Workload:  policy.Workload{Kind: "Deployment", Name: "nginx"},
+				Container: "nginx",
+				Image:     "nginx:1.16",
+			}, newScanResult())
+			require.NoError(t, err)
+
+			var ids []string
+			for _, vulnerability := range result.Vulnerabilities {
+				ids = append(ids, vulnerability.VulnerabilityID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+			assert.Equal(t, tc.expectedSummary, result.Summary)
+			assert.Equal(t, tc.expectedIgnored, ignored)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Should return error when policy cannot be compiled", func(t *testing.T) {
+		_, err := policy.New(context.Background(), "package starboard.vulnerabilities\n\nignore {")
+		assert.Error(t, err)
+	})
+}
+
+func TestLoader_Load(t *testing.T) {
+	name := types.NamespacedName{Namespace: "starboard-operator", Name: "vuln-policy"}
+	newConfigMap := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: name.Namespace, Name: name.Name},
+			Data:       data,
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		objects       []runtime.Object
+		expectedError string
+	}{
+		{
+			name:    "Should load policy from configmap",
+			objects: []runtime.Object{newConfigMap(map[string]string{policy.ConfigMapKey: ignoreLowInDev})},
+		},
+		{
+			name:          "Should return error when configmap does not exist",
+			expectedError: `getting policy configmap starboard-operator/vuln-policy: configmaps "vuln-policy" not found`,
+		},
+		{
+			name:          "Should return error when configmap does not have policy key",
+			objects:       []runtime.Object{newConfigMap(map[string]string{"foo.rego": ignoreLowInDev})},
+			expectedError: "policy configmap starboard-operator/vuln-policy does not have the policy.rego key",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loader := policy.NewLoader(fake.NewFakeClient(tc.objects...), name)
+			vulnerabilityPolicy, err := loader.Load(context.Background())
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				assert.NotNil(t, vulnerabilityPolicy)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package reports
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+)
+
+const (
+	// maxCompressedVulnerabilitiesSize is the maximum size of the annotation holding compressed vulnerabilities.
+	// The API server limits the total size of annotations to 256 KiB, so we leave room for other annotations.
+	maxCompressedVulnerabilitiesSize = 192 * 1024
+)
+
+// Compression configures compression of vulnerabilities stored in VulnerabilityReports.
+type Compression struct {
+	Enabled bool
+	// Threshold is the size in bytes of JSON encoded vulnerabilities above which they're compressed.
+	Threshold int
+}
+
+// CompressVulnerabilities moves vulnerabilities of the specified report to the
+// etc.AnnotationCompressedVulnerabilities annotation as gzipped and base64 encoded
+// JSON if their size exceeds the threshold. The summary is left intact.
+// Returns true if vulnerabilities were compressed, false otherwise.
+//
+// Vulnerabilities are left uncompressed if the compressed data would not fit in an annotation.
+func CompressVulnerabilities(report *starboardv1alpha1.VulnerabilityReport, threshold int) (bool, error) {
+	delete(report.Annotations, etc.AnnotationCompressedVulnerabilities)
+
+	data, err := json.Marshal(report.Report.Vulnerabilities)
+	if err != nil {
+		return false, fmt.Errorf("marshalling vulnerabilities: %w", err)
+	}
+	if len(data) <= threshold {
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err = writer.Write(data)
+	if err != nil {
+		return false, fmt.Errorf("compressing vulnerabilities: %w", err)
+	}
+	err = writer.Close()
+	if err != nil {
+		return false, fmt.Errorf("compressing vulnerabilities: %w", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	if len(encoded) > maxCompressedVulnerabilitiesSize {
+		return false, nil
+	}
+
+	if report.Annotations == nil {
+		report.Annotations = make(map[string]string)
+	}
+	report.Annotations[etc.AnnotationCompressedVulnerabilities] = encoded
+	report.Report.Vulnerabilities = []starboardv1alpha1.Vulnerability{}
+	return true, nil
+}
+
+// DecompressVulnerabilities restores vulnerabilities of the specified report from the
+// etc.AnnotationCompressedVulnerabilities annotation and removes the annotation.
+// Reports without the annotation are left intact.
+func DecompressVulnerabilities(report *starboardv1alpha1.VulnerabilityReport) error {
+	encoded, ok := report.Annotations[etc.AnnotationCompressedVulnerabilities]
+	if !ok {
+		return nil
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("decoding vulnerabilities: %w", err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("decompressing vulnerabilities: %w", err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("decompressing vulnerabilities: %w", err)
+	}
+
+	var vulnerabilities []starboardv1alpha1.Vulnerability
+	err = json.Unmarshal(data, &vulnerabilities)
+	if err != nil {
+		return fmt.Errorf("unmarshalling vulnerabilities: %w", err)
+	}
+
+	report.Report.Vulnerabilities = vulnerabilities
+	delete(report.Annotations, etc.AnnotationCompressedVulnerabilities)
+	return nil
+}
@@ -0,0 +1,93 @@
+package reports_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newVulnerabilities(count int) []starboardv1alpha1.Vulnerability {
+	vulnerabilities := make([]starboardv1alpha1.Vulnerability, count)
+	for i := 0; i < count; i++ {
+		vulnerabilities[i] = starboardv1alpha1.Vulnerability{
+			VulnerabilityID:  fmt.Sprintf("CVE-2020-%04d", i),
+			Resource:         "openssl",
+			InstalledVersion: "1.1.1d-r3",
+			FixedVersion:     "1.1.1g-r0",
+			Severity:         starboardv1alpha1.SeverityHigh,
+			Description:      "Server or client applications that call the SSL_check_chain() function may crash.",
+			Links:            []string{fmt.Sprintf("https://nvd.nist.gov/vuln/detail/CVE-2020-%04d", i)},
+		}
+	}
+	return vulnerabilities
+}
+
+func newVulnerabilityReport(vulnerabilities []starboardv1alpha1.Vulnerability) *starboardv1alpha1.VulnerabilityReport {
+	return &starboardv1alpha1.VulnerabilityReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "replicaset-nginx-6d4cf56db6-nginx",
+			Namespace: "default",
+		},
+		Report: starboardv1alpha1.VulnerabilityScanResult{
+			Summary: starboardv1alpha1.VulnerabilitySummary{
+				HighCount: len(vulnerabilities),
+			},
+			Vulnerabilities: vulnerabilities,
+		},
+	}
+}
+
+func TestCompressVulnerabilities(t *testing.T) {
+	t.Run("Should compress and decompress vulnerabilities", func(t *testing.T) {
+		vulnerabilities := newVulnerabilities(1000)
+		report := newVulnerabilityReport(vulnerabilities)
+
+		compressed, err := reports.CompressVulnerabilities(report, 1024)
+		require.NoError(t, err)
+		assert.True(t, compressed)
+		assert.Empty(t, report.Report.Vulnerabilities)
+		assert.Equal(t, 1000, report.Report.Summary.HighCount)
+		assert.Contains(t, report.Annotations, etc.AnnotationCompressedVulnerabilities)
+
+		err = reports.DecompressVulnerabilities(report)
+		require.NoError(t, err)
+		assert.Equal(t, vulnerabilities, report.Report.Vulnerabilities)
+		assert.NotContains(t, report.Annotations, etc.AnnotationCompressedVulnerabilities)
+	})
+
+	t.Run("Should not compress vulnerabilities below threshold", func(t *testing.T) {
+		vulnerabilities := newVulnerabilities(2)
+		report := newVulnerabilityReport(vulnerabilities)
+
+		compressed, err := reports.CompressVulnerabilities(report, 64*1024)
+		require.NoError(t, err)
+		assert.False(t, compressed)
+		assert.Equal(t, vulnerabilities, report.Report.Vulnerabilities)
+		assert.NotContains(t, report.Annotations, etc.AnnotationCompressedVulnerabilities)
+	})
+
+	t.Run("Should leave uncompressed report intact when decompressing", func(t *testing.T) {
+		vulnerabilities := newVulnerabilities(2)
+		report := newVulnerabilityReport(vulnerabilities)
+
+		err := reports.DecompressVulnerabilities(report)
+		require.NoError(t, err)
+		assert.Equal(t, vulnerabilities, report.Report.Vulnerabilities)
+	})
+
+	t.Run("Should return error when compressed vulnerabilities are malformed", func(t *testing.T) {
+		report := newVulnerabilityReport(nil)
+		report.Annotations = map[string]string{
+			etc.AnnotationCompressedVulnerabilities: "not base64",
+		}
+
+		err := reports.DecompressVulnerabilities(report)
+		assert.EqualError(t, err, "decoding vulnerabilities: illegal base64 data at input byte 3")
+	})
+}
@@ -0,0 +1,49 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// CheckCRDs returns an error if the CRD of any of the specified report types is not installed, or does not
+// serve the version of the type registered in the specified scheme. It's meant to be called at startup, so
+// that the operator fails with a clear error on mismatched installs rather than on the first write of a report.
+func CheckCRDs(scheme *runtime.Scheme, mapper meta.RESTMapper, reportTypes ...runtime.Object) error {
+	for _, reportType := range reportTypes {
+		err := checkCRD(scheme, mapper, reportType)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkCRD(scheme *runtime.Scheme, mapper meta.RESTMapper, reportType runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(reportType, scheme)
+	if runtime.IsNotRegisteredError(err) {
+		return fmt.Errorf("report type %T is not registered in the scheme", reportType)
+	}
+	if err != nil {
+		return fmt.Errorf("getting report kind: %w", err)
+	}
+	mappings, err := mapper.RESTMappings(gvk.GroupKind())
+	if meta.IsNoMatchError(err) {
+		return fmt.Errorf("%s CRD is not installed in the %s API group", gvk.Kind, gvk.Group)
+	}
+	if err != nil {
+		return fmt.Errorf("getting %s CRD versions: %w", gvk.Kind, err)
+	}
+	var versions []string
+	for _, mapping := range mappings {
+		if mapping.GroupVersionKind.Version == gvk.Version {
+			return nil
+		}
+		versions = append(versions, mapping.GroupVersionKind.Version)
+	}
+	return fmt.Errorf("%s CRD does not serve the %s version required by the operator, served versions: %s",
+		gvk.Kind, gvk.Version, strings.Join(versions, ", "))
+}
@@ -0,0 +1,77 @@
+package reports_test
+
+import (
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCheckCRDs(t *testing.T) {
+	starboardScheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(starboardScheme)
+	_ = starboardv1alpha1.AddToScheme(starboardScheme)
+
+	coreScheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(coreScheme)
+
+	// newMapper returns the RESTMapper of the API server serving the specified versions of VulnerabilityReports.
+	newMapper := func(versions ...string) meta.RESTMapper {
+		var groupVersions []schema.GroupVersion
+		for _, version := range versions {
+			groupVersions = append(groupVersions, schema.GroupVersion{Group: "aquasecurity.github.io", Version: version})
+		}
+		mapper := meta.NewDefaultRESTMapper(groupVersions)
+		for _, gv := range groupVersions {
+			mapper.Add(gv.WithKind("VulnerabilityReport"), meta.RESTScopeNamespace)
+		}
+		return mapper
+	}
+
+	testCases := []struct {
+		name          string
+		scheme        *runtime.Scheme
+		mapper        meta.RESTMapper
+		expectedError string
+	}{
+		{
+			name:   "Should accept installed CRD serving supported version",
+			scheme: starboardScheme,
+			mapper: newMapper("v1beta1", "v1alpha1"),
+		},
+		{
+			name:          "Should return error when CRD is not installed",
+			scheme:        starboardScheme,
+			mapper:        newMapper(),
+			expectedError: "VulnerabilityReport CRD is not installed in the aquasecurity.github.io API group",
+		},
+		{
+			name:          "Should return error when CRD does not serve supported version",
+			scheme:        starboardScheme,
+			mapper:        newMapper("v1beta1"),
+			expectedError: "VulnerabilityReport CRD does not serve the v1alpha1 version required by the operator, served versions: v1beta1",
+		},
+		{
+			name:          "Should return error when scheme is missing CRD",
+			scheme:        coreScheme,
+			mapper:        newMapper("v1alpha1"),
+			expectedError: "report type *v1alpha1.VulnerabilityReport is not registered in the scheme",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := reports.CheckCRDs(tc.scheme, tc.mapper, &starboardv1alpha1.VulnerabilityReport{})
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package reports
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+)
+
+var severities = []starboardv1alpha1.Severity{
+	starboardv1alpha1.SeverityCritical,
+	starboardv1alpha1.SeverityHigh,
+	starboardv1alpha1.SeverityMedium,
+	starboardv1alpha1.SeverityLow,
+	starboardv1alpha1.SeverityUnknown,
+}
+
+// ParseSeverities returns Severities for the specified comma separated and case insensitive names,
+// e.g. `CRITICAL,HIGH`. Returns nil if the value is blank, which means that vulnerabilities
+// of all severities are reported.
+func ParseSeverities(value string) ([]starboardv1alpha1.Severity, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var parsed []starboardv1alpha1.Severity
+	for _, name := range strings.Split(value, ",") {
+		severity, err := parseSeverity(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, severity)
+	}
+	return parsed, nil
+}
+
+func parseSeverity(name string) (starboardv1alpha1.Severity, error) {
+	for _, severity := range severities {
+		if strings.EqualFold(name, string(severity)) {
+			return severity, nil
+		}
+	}
+	return "", fmt.Errorf("unrecognized severity: %q", name)
+}
+
+// FilterVulnerabilities returns a copy of the specified scan result which contains only
+// vulnerabilities with one of the given severities. The summary is recomputed to match
+// the retained vulnerabilities. The result is returned unchanged if severities are empty.
+func FilterVulnerabilities(result starboardv1alpha1.VulnerabilityScanResult, severities []starboardv1alpha1.Severity) starboardv1alpha1.VulnerabilityScanResult {
+	if len(severities) == 0 {
+		return result
+	}
+	allowed := make(map[starboardv1alpha1.Severity]bool)
+	for _, severity := range severities {
+		allowed[severity] = true
+	}
+
+	filtered := result
+	filtered.Vulnerabilities = []starboardv1alpha1.Vulnerability{}
+	for _, vulnerability := range result.Vulnerabilities {
+		if !allowed[vulnerability.Severity] {
+			continue
+		}
+		filtered.Vulnerabilities = append(filtered.Vulnerabilities, vulnerability)
+	}
+	filtered.Summary = SummarizeVulnerabilities(filtered.Vulnerabilities)
+	return filtered
+}
+
+// SummarizeVulnerabilities returns the summary of the specified vulnerabilities, i.e. the number of
+// vulnerabilities of each severity. Vulnerabilities of unrecognized severities are counted as unknown.
+func SummarizeVulnerabilities(vulnerabilities []starboardv1alpha1.Vulnerability) starboardv1alpha1.VulnerabilitySummary {
+	var summary starboardv1alpha1.VulnerabilitySummary
+	for _, vulnerability := range vulnerabilities {
+		switch vulnerability.Severity {
+		case starboardv1alpha1.SeverityCritical:
+			summary.CriticalCount++
+		case starboardv1alpha1.SeverityHigh:
+			summary.HighCount++
+		case starboardv1alpha1.SeverityMedium:
+			summary.MediumCount++
+		case starboardv1alpha1.SeverityLow:
+			summary.LowCount++
+		default:
+			summary.UnknownCount++
+		}
+	}
+	return summary
+}
+
+// SummarizeReport recomputes the summary of the specified report from its vulnerabilities, so that the summary
+// matches the vulnerabilities regardless of the summary reported by the scanner, and records the total number
+// of vulnerabilities in the etc.AnnotationVulnerabilityCount annotation.
+func SummarizeReport(report *starboardv1alpha1.VulnerabilityReport) {
+	report.Report.Summary = SummarizeVulnerabilities(report.Report.Vulnerabilities)
+	if report.Annotations == nil {
+		report.Annotations = make(map[string]string)
+	}
+	report.Annotations[etc.AnnotationVulnerabilityCount] = strconv.Itoa(len(report.Report.Vulnerabilities))
+}
@@ -0,0 +1,149 @@
+package reports_test
+
+import (
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverities(t *testing.T) {
+	testCases := []struct {
+		name               string
+		value              string
+		expectedSeverities []starboardv1alpha1.Severity
+		expectedError      string
+	}{
+		{
+			name:  "Should return nil for blank value",
+			value: "",
+		},
+		{
+			name:               "Should parse case insensitive names",
+			value:              "critical, High",
+			expectedSeverities: []starboardv1alpha1.Severity{starboardv1alpha1.SeverityCritical, starboardv1alpha1.SeverityHigh},
+		},
+		{
+			name:          "Should return error for unrecognized name",
+			value:         "CRITICAL,INFO",
+			expectedError: `unrecognized severity: "INFO"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			severities, err := reports.ParseSeverities(tc.value)
+			switch tc.expectedError {
+			case "":
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedSeverities, severities)
+			default:
+				require.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestFilterVulnerabilities(t *testing.T) {
+	result := starboardv1alpha1.VulnerabilityScanResult{
+		Scanner: starboardv1alpha1.Scanner{Name: "Trivy"},
+		Summary: starboardv1alpha1.VulnerabilitySummary{
+			CriticalCount: 1,
+			HighCount:     1,
+			MediumCount:   1,
+			LowCount:      2,
+			UnknownCount:  1,
+		},
+		Vulnerabilities: []starboardv1alpha1.Vulnerability{
+			{VulnerabilityID: "CVE-2020-0001", Severity: starboardv1alpha1.SeverityLow},
+			{VulnerabilityID: "CVE-2020-0002", Severity: starboardv1alpha1.SeverityCritical},
+			{VulnerabilityID: "CVE-2020-0003", Severity: starboardv1alpha1.SeverityMedium},
+			{VulnerabilityID: "CVE-2020-0004", Severity: starboardv1alpha1.SeverityHigh},
+			{VulnerabilityID: "CVE-2020-0005", Severity: starboardv1alpha1.SeverityUnknown},
+			{VulnerabilityID: "CVE-2020-0006", Severity: starboardv1alpha1.SeverityLow},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		severities     []starboardv1alpha1.Severity
+		expectedResult starboardv1alpha1.VulnerabilityScanResult
+	}{
+		{
+			name:           "Should return result unchanged without severities",
+			expectedResult: result,
+		},
+		{
+			name:       "Should retain critical and high vulnerabilities",
+			severities: []starboardv1alpha1.Severity{starboardv1alpha1.SeverityCritical, starboardv1alpha1.SeverityHigh},
+			expectedResult: starboardv1alpha1.VulnerabilityScanResult{
+				Scanner: starboardv1alpha1.Scanner{Name: "Trivy"},
+				Summary: starboardv1alpha1.VulnerabilitySummary{
+					CriticalCount: 1,
+					HighCount:     1,
+				},
+				Vulnerabilities: []starboardv1alpha1.Vulnerability{
+					{VulnerabilityID: "CVE-2020-0002", Severity: starboardv1alpha1.SeverityCritical},
+					{VulnerabilityID: "CVE-2020-0004", Severity: starboardv1alpha1.SeverityHigh},
+				},
+			},
+		},
+		{
+			name:       "Should retain low and unknown vulnerabilities",
+			severities: []starboardv1alpha1.Severity{starboardv1alpha1.SeverityLow, starboardv1alpha1.SeverityUnknown},
+			expectedResult: starboardv1alpha1.VulnerabilityScanResult{
+				Scanner: starboardv1alpha1.Scanner{Name: "Trivy"},
+				Summary: starboardv1alpha1.VulnerabilitySummary{
+					LowCount:     2,
+					UnknownCount: 1,
+				},
+				Vulnerabilities: []starboardv1alpha1.Vulnerability{
+					{VulnerabilityID: "CVE-2020-0001", Severity: starboardv1alpha1.SeverityLow},
+					{VulnerabilityID: "CVE-2020-0005", Severity: starboardv1alpha1.SeverityUnknown},
+					{VulnerabilityID: "CVE-2020-0006", Severity: starboardv1alpha1.SeverityLow},
+				},
+			},
+		},
+		{
+			name:       "Should return empty vulnerabilities when none match",
+			severities: []starboardv1alpha1.Severity{starboardv1alpha1.SeverityNone},
+			expectedResult: starboardv1alpha1.VulnerabilityScanResult{
+				Scanner:         starboardv1alpha1.Scanner{Name: "Trivy"},
+				Vulnerabilities: []starboardv1alpha1.Vulnerability{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedResult, reports.FilterVulnerabilities(result, tc.severities))
+		})
+	}
+}
+
+func TestSummarizeReport(t *testing.T) {
+	t.Run("Should replace summary of scanner with counts of vulnerabilities", func(t *testing.T) {
+		report := newVulnerabilityReport(newMixedVulnerabilities(7))
+
+		reports.SummarizeReport(report)
+		assert.Equal(t, starboardv1alpha1.VulnerabilitySummary{
+			CriticalCount: 1,
+			HighCount:     1,
+			MediumCount:   1,
+			LowCount:      2,
+			UnknownCount:  2,
+		}, report.Report.Summary)
+		assert.Equal(t, "7", report.Annotations[etc.AnnotationVulnerabilityCount])
+	})
+
+	t.Run("Should summarize report without vulnerabilities", func(t *testing.T) {
+		report := newVulnerabilityReport(nil)
+
+		reports.SummarizeReport(report)
+		assert.Equal(t, starboardv1alpha1.VulnerabilitySummary{}, report.Report.Summary)
+		assert.Equal(t, "0", report.Annotations[etc.AnnotationVulnerabilityCount])
+	})
+}
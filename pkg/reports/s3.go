@@ -0,0 +1,125 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// S3Options are settings of the S3-compatible object store which reports are uploaded to.
+type S3Options struct {
+	// Endpoint is the host and optional port of the object store, e.g. s3.amazonaws.com.
+	Endpoint string
+	// Bucket is the name of the bucket which reports are uploaded to.
+	Bucket string
+	// Region is the region of the bucket.
+	Region string
+	// AccessKey is the access key ID of the credentials of the object store.
+	AccessKey string
+	// SecretKey is the secret access key of the credentials of the object store.
+	SecretKey string
+	// Insecure disables TLS, i.e. the object store is accessed over plain HTTP.
+	Insecure bool
+}
+
+// S3Report is the JSON document of the report of a workload container uploaded to an object store.
+type S3Report struct {
+	Namespace string                                    `json:"namespace"`
+	Kind      string                                    `json:"kind"`
+	Name      string                                    `json:"name"`
+	Container string                                    `json:"container"`
+	Digest    string                                    `json:"digest,omitempty"`
+	Hash      string                                    `json:"hash"`
+	UpdatedAt time.Time                                 `json:"updatedAt"`
+	Report    starboardv1alpha1.VulnerabilityScanResult `json:"report"`
+}
+
+// S3Writer is the Writer which uploads reports to an S3-compatible object store, in addition to writing
+// them with the wrapped Writer, e.g. as custom resources for long-term archival. Reports are read with the
+// wrapped Writer only.
+type S3Writer struct {
+	Writer
+	client *minio.Client
+	bucket string
+	clock  clock.Clock
+}
+
+// NewS3Writer constructs an S3Writer which wraps the specified Writer.
+func NewS3Writer(writer Writer, clock clock.Clock, options S3Options) (*S3Writer, error) {
+	client, err := minio.New(options.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(options.AccessKey, options.SecretKey, ""),
+		Secure:       !options.Insecure,
+		Region:       options.Region,
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("constructing S3 client: %w", err)
+	}
+	return &S3Writer{
+		Writer: writer,
+		client: client,
+		bucket: options.Bucket,
+		clock:  clock,
+	}, nil
+}
+
+// Write writes the report of the specified workload with the wrapped Writer, and uploads the report of each
+// container as JSON under the `<namespace>/<kind>-<name>/<digest>.json` key, or the
+// `<namespace>/<kind>-<name>/<container>-<hash>.json` key if the digest of the image is not known.
+func (w *S3Writer) Write(ctx context.Context, workload kube.Object, report WorkloadReport) error {
+	err := w.Writer.Write(ctx, workload, report)
+	if err != nil {
+		return err
+	}
+	updatedAt := w.clock.Now().UTC()
+	for containerName, result := range report.Vulnerabilities {
+		s3Report := S3Report{
+			Namespace: workload.Namespace,
+			Kind:      string(workload.Kind),
+			Name:      workload.Name,
+			Container: containerName,
+			Digest:    report.Digests[containerName],
+			Hash:      report.Hash,
+			UpdatedAt: updatedAt,
+			Report:    result,
+		}
+		err = w.upload(ctx, GetS3ReportKey(s3Report), s3Report)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *S3Writer) upload(ctx context.Context, key string, report S3Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshalling report: %w", err)
+	}
+	log.Info("Uploading VulnerabilityReport", "bucket", w.bucket, "key", key)
+	_, err = w.client.PutObject(ctx, w.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "application/json",
+	})
+	if err != nil {
+		return fmt.Errorf("uploading report %s to bucket %s: %w", key, w.bucket, err)
+	}
+	return nil
+}
+
+// GetS3ReportKey returns the key of the object holding the specified report.
+func GetS3ReportKey(report S3Report) string {
+	name := report.Digest
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", report.Container, report.Hash)
+	}
+	return fmt.Sprintf("%s/%s-%s/%s.json", report.Namespace, strings.ToLower(report.Kind), report.Name, name)
+}
@@ -3,18 +3,18 @@ package reports
 import (
 	"context"
 	"fmt"
-	"reflect"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/metrics"
+	"github.com/aquasecurity/starboard-operator/pkg/resources"
+	"github.com/aquasecurity/starboard-operator/pkg/sbom"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	batchv1 "k8s.io/api/batch/v1"
-	"k8s.io/api/batch/v1beta1"
-	corev1 "k8s.io/api/core/v1"
-
-	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -25,85 +25,445 @@ import (
 	"github.com/aquasecurity/starboard/pkg/kube"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
 
 var (
 	log = ctrl.Log.WithName("store")
 )
 
-type StoreInterface interface {
-	SaveVulnerabilityReports(ctx context.Context, owner kube.Object, hash string, reports vulnerabilities.WorkloadVulnerabilities) error
-	GetVulnerabilityReportsByOwnerAndHash(ctx context.Context, owner kube.Object, hash string) (vulnerabilities.WorkloadVulnerabilities, error)
-	HasVulnerabilityReports(ctx context.Context, owner kube.Object, hash string, containerImages kube.ContainerImages) (bool, error)
+type ConfigAuditStoreInterface interface {
+	SaveConfigAuditReport(ctx context.Context, owner kube.Object, hash string, report starboardv1alpha1.ConfigAudit) error
+	HasConfigAuditReport(ctx context.Context, owner kube.Object, hash string) (bool, error)
 }
 
+// Store is the default Writer, which stores reports as custom resources.
 type Store struct {
-	client client.Client
-	scheme *runtime.Scheme
+	client          client.Client
+	scheme          *runtime.Scheme
+	clock           clock.Clock
+	compression     Compression
+	maxItems        int
+	historyLimit    int
+	serverSideApply bool
+	annotations     map[string]string
+	instanceID      string
 }
 
-func NewStore(client client.Client, scheme *runtime.Scheme) *Store {
+// FieldManager is the name of the field manager of reports written with server-side apply. It must stay the
+// same across releases, otherwise fields applied by previous releases would be left intact.
+const FieldManager = "starboard-operator"
+
+// GetFieldManager returns the name of the field manager of reports written by the operator instance with the
+// specified ID, i.e. FieldManager suffixed with the ID, or FieldManager itself if the ID is blank.
+func GetFieldManager(instanceID string) string {
+	if instanceID == "" {
+		return FieldManager
+	}
+	return FieldManager + "-" + instanceID
+}
+
+// NewStore constructs a Store. VulnerabilityReports with more than maxItems vulnerabilities are truncated,
+// unless maxItems is 0. At most historyLimit VulnerabilityReports are kept per container, including the
+// current one, hence previous reports are kept as history only if historyLimit is greater than 1. If
+// serverSideApply is true, reports are written with server-side apply as FieldManager rather than created
+// or updated, so that concurrent writes of the same report do not conflict. The specified annotations are set
+// on every VulnerabilityReport in addition to the annotations set by the operator. Reports are labeled with
+// etc.LabelInstanceID set to the non-blank instanceID, which also suffixes the name of the field manager.
+func NewStore(client client.Client, scheme *runtime.Scheme, clock clock.Clock, compression Compression, maxItems, historyLimit int,
+	serverSideApply bool, annotations map[string]string, instanceID string) *Store {
 	return &Store{
-		client: client,
-		scheme: scheme,
+		client:          client,
+		scheme:          scheme,
+		clock:           clock,
+		compression:     compression,
+		maxItems:        maxItems,
+		historyLimit:    historyLimit,
+		serverSideApply: serverSideApply,
+		annotations:     annotations,
+		instanceID:      instanceID,
 	}
 }
 
-func (s *Store) SaveVulnerabilityReports(ctx context.Context, workload kube.Object, hash string, reports vulnerabilities.WorkloadVulnerabilities) error {
-	owner, err := s.getRuntimeObjectFor(ctx, workload)
+var (
+	_ Writer            = &Store{}
+	_ SBOMWriter        = &Store{}
+	_ DigestReader      = &Store{}
+	_ IncrementalWriter = &Store{}
+)
+
+// Write creates or updates VulnerabilityReports of the specified workload.
+func (s *Store) Write(ctx context.Context, workload kube.Object, report WorkloadReport) error {
+	return s.SaveVulnerabilityReports(ctx, workload, report.Hash, report.Vulnerabilities, report.InitContainers,
+		report.EphemeralContainers, report.Digests)
+}
+
+// HasReport checks whether there are VulnerabilityReports of all the specified containers.
+func (s *Store) HasReport(ctx context.Context, workload kube.Object, hash string, containerImages kube.ContainerImages) (bool, error) {
+	return s.HasVulnerabilityReports(ctx, workload, hash, containerImages)
+}
+
+// GetUpdateTime returns the time when the least recently updated VulnerabilityReport was written.
+func (s *Store) GetUpdateTime(ctx context.Context, workload kube.Object, hash string) (time.Time, error) {
+	return s.GetVulnerabilityReportsUpdateTime(ctx, workload, hash)
+}
+
+// SaveVulnerabilityReports creates or updates VulnerabilityReports of the specified workload.
+// Reports of containers listed in initContainers are labeled with etc.LabelInitContainer, and reports of containers
+// listed in ephemeralContainers are labeled with etc.LabelEphemeralContainer.
+// Reports of containers with known image digests are annotated with etc.AnnotationImageDigest.
+//
+// There's one VulnerabilityReport per container, hence reports of containers which are not specified,
+// e.g. written by another scan Job, are left intact. Each report is written with retries on conflicts
+// so that concurrent writes of the same report do not fail, and the last write wins.
+func (s *Store) SaveVulnerabilityReports(ctx context.Context, workload kube.Object, hash string, reports vulnerabilities.WorkloadVulnerabilities, initContainers, ephemeralContainers []string, digests kube.ContainerImages) error {
+	owner, err := resources.GetRuntimeObjectFor(ctx, s.client, workload)
 	if err != nil {
 		return err
 	}
 
+	isInitContainer := make(map[string]bool)
+	for _, name := range initContainers {
+		isInitContainer[name] = true
+	}
+	isEphemeralContainer := make(map[string]bool)
+	for _, name := range ephemeralContainers {
+		isEphemeralContainer[name] = true
+	}
+
 	for containerName, report := range reports {
-		reportName := fmt.Sprintf("%s-%s-%s", strings.ToLower(string(workload.Kind)),
-			workload.Name, containerName)
-
-		vulnerabilityReport := &starboardv1alpha1.VulnerabilityReport{}
-
-		err := s.client.Get(ctx, types.NamespacedName{Name: reportName, Namespace: workload.Namespace}, vulnerabilityReport)
-		if errors.IsNotFound(err) {
-			vulnerabilityReport = &starboardv1alpha1.VulnerabilityReport{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      reportName,
-					Namespace: workload.Namespace,
-					Labels: labels.Set{
-						kube.LabelResourceKind:      string(workload.Kind),
-						kube.LabelResourceName:      workload.Name,
-						kube.LabelResourceNamespace: workload.Namespace,
-						kube.LabelContainerName:     containerName,
-						etc.LabelPodSpecHash:        hash,
-					},
-				},
-				Report: report,
-			}
-			err = controllerutil.SetOwnerReference(owner, vulnerabilityReport, s.scheme)
-			if err != nil {
-				return err
-			}
-			log.Info("Creating VulnerabilityReport",
+		err = retry.OnError(retry.DefaultRetry, isWriteConflict, func() error {
+			return s.saveVulnerabilityReport(ctx, owner, workload, hash, containerName, report,
+				isInitContainer[containerName], isEphemeralContainer[containerName], digests)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.updateVulnerabilityReportsMetric(ctx, workload.Namespace)
+}
+
+// isWriteConflict returns true if the specified error was caused by a concurrent write of the same object,
+// in which case the write should be retried.
+func isWriteConflict(err error) bool {
+	return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+}
+
+// saveVulnerabilityReport creates or updates the VulnerabilityReport of the specified container.
+func (s *Store) saveVulnerabilityReport(ctx context.Context, owner metav1.Object, workload kube.Object, hash, containerName string,
+	report starboardv1alpha1.VulnerabilityScanResult, initContainer, ephemeralContainer bool, digests kube.ContainerImages) error {
+	reportName := fmt.Sprintf("%s-%s-%s", strings.ToLower(string(workload.Kind)),
+		workload.Name, containerName)
+	updatedAt := s.clock.Now().UTC().Format(time.RFC3339)
+
+	vulnerabilityReport := &starboardv1alpha1.VulnerabilityReport{}
+
+	err := s.client.Get(ctx, types.NamespacedName{Name: reportName, Namespace: workload.Namespace}, vulnerabilityReport)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+
+	if found && s.historyLimit > 1 {
+		err = s.archiveVulnerabilityReport(ctx, vulnerabilityReport)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !found || s.serverSideApply {
+		vulnerabilityReport, err = s.newVulnerabilityReport(owner, workload, reportName, hash, containerName, updatedAt,
+			report, initContainer, ephemeralContainer, digests)
+		if err != nil {
+			return err
+		}
+		if s.serverSideApply {
+			log.Info("Applying VulnerabilityReport",
 				"report", fmt.Sprintf("%s/%s", workload.Namespace, reportName),
 				"hash", hash)
-			err := s.client.Create(ctx, vulnerabilityReport)
-			if err != nil {
-				return err
-			}
-			return nil
+			return s.apply(ctx, vulnerabilityReport)
 		}
-
-		// Do not modify the object that might be cached.
-		cloned := vulnerabilityReport.DeepCopy()
-		cloned.Labels[etc.LabelPodSpecHash] = hash
-		cloned.Report = report
-		log.Info("Updating VulnerabilityReport",
+		log.Info("Creating VulnerabilityReport",
 			"report", fmt.Sprintf("%s/%s", workload.Namespace, reportName),
 			"hash", hash)
-		return s.client.Update(ctx, cloned)
+		return s.client.Create(ctx, vulnerabilityReport)
+	}
+
+	// Do not modify the object that might be cached.
+	cloned := vulnerabilityReport.DeepCopy()
+	if cloned.Labels == nil {
+		cloned.Labels = make(map[string]string)
+	}
+	if cloned.Annotations == nil {
+		cloned.Annotations = make(map[string]string)
+	}
+	cloned.Labels[etc.LabelPodSpecHash] = hash
+	s.labelInstance(cloned.Labels)
+	cloned.Annotations[etc.AnnotationReportUpdatedAt] = updatedAt
+	err = s.setOwner(owner, cloned)
+	if err != nil {
+		return err
+	}
+	if digest, ok := digests[containerName]; ok {
+		cloned.Annotations[etc.AnnotationImageDigest] = digest
+	} else {
+		delete(cloned.Annotations, etc.AnnotationImageDigest)
+	}
+	cloned.Report = report
+	s.annotate(cloned)
+	SummarizeReport(cloned)
+	s.truncate(cloned)
+	err = s.compress(cloned)
+	if err != nil {
+		return err
+	}
+	log.Info("Updating VulnerabilityReport",
+		"report", fmt.Sprintf("%s/%s", workload.Namespace, reportName),
+		"hash", hash)
+	return s.client.Update(ctx, cloned)
+}
+
+// newVulnerabilityReport constructs the VulnerabilityReport of the specified container, which is summarized,
+// truncated, compressed, and controlled by the specified owner.
+func (s *Store) newVulnerabilityReport(owner metav1.Object, workload kube.Object, reportName, hash, containerName, updatedAt string,
+	report starboardv1alpha1.VulnerabilityScanResult, initContainer, ephemeralContainer bool, digests kube.ContainerImages) (*starboardv1alpha1.VulnerabilityReport, error) {
+	vulnerabilityReport := &starboardv1alpha1.VulnerabilityReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reportName,
+			Namespace: workload.Namespace,
+			Labels: labels.Set{
+				kube.LabelResourceKind:      string(workload.Kind),
+				kube.LabelResourceName:      workload.Name,
+				kube.LabelResourceNamespace: workload.Namespace,
+				kube.LabelContainerName:     containerName,
+				etc.LabelPodSpecHash:        hash,
+			},
+			Annotations: map[string]string{
+				etc.AnnotationReportUpdatedAt: updatedAt,
+			},
+		},
+		Report: report,
+	}
+	if initContainer {
+		vulnerabilityReport.Labels[etc.LabelInitContainer] = "true"
+	}
+	if ephemeralContainer {
+		vulnerabilityReport.Labels[etc.LabelEphemeralContainer] = "true"
+	}
+	s.labelInstance(vulnerabilityReport.Labels)
+	if digest, ok := digests[containerName]; ok {
+		vulnerabilityReport.Annotations[etc.AnnotationImageDigest] = digest
+	}
+	s.annotate(vulnerabilityReport)
+	SummarizeReport(vulnerabilityReport)
+	s.truncate(vulnerabilityReport)
+	err := s.compress(vulnerabilityReport)
+	if err != nil {
+		return nil, err
+	}
+	err = s.setOwner(owner, vulnerabilityReport)
+	if err != nil {
+		return nil, err
+	}
+	return vulnerabilityReport, nil
+}
+
+// annotate sets the configured annotations, and annotations with the name and version of the scanner, on the
+// specified report.
+func (s *Store) annotate(report *starboardv1alpha1.VulnerabilityReport) {
+	for key, value := range s.annotations {
+		report.Annotations[key] = value
+	}
+	report.Annotations[etc.AnnotationScannerName] = report.Report.Scanner.Name
+	report.Annotations[etc.AnnotationScannerVersion] = report.Report.Scanner.Version
+}
+
+// labelInstance sets etc.LabelInstanceID to the configured instance ID in the specified labels of a report,
+// or removes it if the ID is blank.
+func (s *Store) labelInstance(reportLabels map[string]string) {
+	if s.instanceID == "" {
+		delete(reportLabels, etc.LabelInstanceID)
+		return
+	}
+	reportLabels[etc.LabelInstanceID] = s.instanceID
+}
+
+// apply writes the specified report with server-side apply as the field manager of the operator instance. The
+// ownership of conflicting fields is forced, e.g. of fields written by previous releases of the operator with the
+// update method.
+func (s *Store) apply(ctx context.Context, report runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(report, s.scheme)
+	if err != nil {
+		return err
+	}
+	report.GetObjectKind().SetGroupVersionKind(gvk)
+	return s.client.Patch(ctx, report, client.Apply, client.FieldOwner(GetFieldManager(s.instanceID)), client.ForceOwnership)
+}
+
+// archiveVulnerabilityReport copies the specified VulnerabilityReport, which is about to be overwritten, to
+// a history report named with the Unix time of its update. History reports are labeled with
+// etc.LabelReportHistory instead of etc.LabelPodSpecHash, and are not annotated with etc.AnnotationImageDigest,
+// so that they're never read as current reports. The oldest history reports of the same container are deleted
+// to keep at most the configured number of reports.
+func (s *Store) archiveVulnerabilityReport(ctx context.Context, report *starboardv1alpha1.VulnerabilityReport) error {
+	updateTime, err := getUpdateTime(*report)
+	if err != nil {
+		return err
+	}
+	archived := &starboardv1alpha1.VulnerabilityReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%d", report.Name, updateTime.Unix()),
+			Namespace:       report.Namespace,
+			Labels:          make(map[string]string),
+			Annotations:     make(map[string]string),
+			OwnerReferences: report.OwnerReferences,
+		},
+		Report: report.Report,
+	}
+	for key, value := range report.Labels {
+		archived.Labels[key] = value
+	}
+	for key, value := range report.Annotations {
+		archived.Annotations[key] = value
+	}
+	delete(archived.Labels, etc.LabelPodSpecHash)
+	delete(archived.Annotations, etc.AnnotationImageDigest)
+	archived.Labels[etc.LabelReportHistory] = "true"
+
+	log.Info("Archiving VulnerabilityReport",
+		"report", fmt.Sprintf("%s/%s", report.Namespace, archived.Name))
+	err = s.client.Create(ctx, archived)
+	// The report was already archived by a previous attempt of a write which conflicted.
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return s.pruneVulnerabilityReportHistory(ctx, archived)
+}
+
+// pruneVulnerabilityReportHistory deletes the least recently updated history reports of the workload container
+// of the specified report, which exceed the configured limit. The current report counts towards the limit.
+func (s *Store) pruneVulnerabilityReportHistory(ctx context.Context, report *starboardv1alpha1.VulnerabilityReport) error {
+	historyList := &starboardv1alpha1.VulnerabilityReportList{}
+	err := s.client.List(ctx, historyList, client.MatchingLabels{
+		kube.LabelResourceKind:      report.Labels[kube.LabelResourceKind],
+		kube.LabelResourceNamespace: report.Labels[kube.LabelResourceNamespace],
+		kube.LabelResourceName:      report.Labels[kube.LabelResourceName],
+		kube.LabelContainerName:     report.Labels[kube.LabelContainerName],
+		etc.LabelReportHistory:      "true",
+	}, client.InNamespace(report.Namespace))
+	if err != nil {
+		return fmt.Errorf("listing vulnerability report history: %w", err)
+	}
+	history := historyList.Items
+	if len(history) < s.historyLimit {
+		return nil
+	}
+
+	updateTimes := make(map[string]time.Time)
+	for _, item := range history {
+		updateTimes[item.Name], err = getUpdateTime(item)
+		if err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return updateTimes[history[i].Name].Before(updateTimes[history[j].Name])
+	})
+	for i := range history[:len(history)-s.historyLimit+1] {
+		log.Info("Deleting VulnerabilityReport exceeding history limit",
+			"report", fmt.Sprintf("%s/%s", history[i].Namespace, history[i].Name))
+		err = s.client.Delete(ctx, &history[i])
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// setOwner makes the specified workload the controller of the given report, so that the report is garbage
+// collected when the workload is deleted. The reference blocks the deletion of the workload in the foreground
+// until the report is deleted. Controller references of previous owners, e.g. a Pod which was deleted and
+// recreated with the same name, are replaced.
+func (s *Store) setOwner(owner metav1.Object, report metav1.Object) error {
+	var refs []metav1.OwnerReference
+	for _, ref := range report.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller && ref.UID != owner.GetUID() {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	report.SetOwnerReferences(refs)
+	return controllerutil.SetControllerReference(owner, report, s.scheme)
+}
+
+// truncate keeps at most the configured maximum number of vulnerabilities of the specified report.
+func (s *Store) truncate(report *starboardv1alpha1.VulnerabilityReport) {
+	if !TruncateVulnerabilities(report, s.maxItems) {
+		return
+	}
+	log.Info("Truncating vulnerabilities of VulnerabilityReport",
+		"report", fmt.Sprintf("%s/%s", report.Namespace, report.Name),
+		"maxItems", s.maxItems, "truncated", report.Annotations[etc.AnnotationTruncatedVulnerabilities])
+}
+
+// compress compresses vulnerabilities of the specified report if compression is enabled.
+// Otherwise, the report is stored in the uncompressed format for backward compatibility.
+func (s *Store) compress(report *starboardv1alpha1.VulnerabilityReport) error {
+	if !s.compression.Enabled {
+		delete(report.Annotations, etc.AnnotationCompressedVulnerabilities)
+		return nil
+	}
+	compressed, err := CompressVulnerabilities(report, s.compression.Threshold)
+	if err != nil {
+		return err
+	}
+	if compressed {
+		log.V(1).Info("Compressing vulnerabilities of VulnerabilityReport",
+			"report", fmt.Sprintf("%s/%s", report.Namespace, report.Name))
 	}
 	return nil
 }
 
+// updateVulnerabilityReportsMetric counts VulnerabilityReports stored in the given namespace, and
+// vulnerabilities of each severity in their summaries, and exposes the counts as metrics. The counts
+// are recomputed from scratch, so that deleted reports are reflected. History reports are not counted.
+func (s *Store) updateVulnerabilityReportsMetric(ctx context.Context, namespace string) error {
+	vulnerabilityList := &starboardv1alpha1.VulnerabilityReportList{}
+	err := s.client.List(ctx, vulnerabilityList, client.InNamespace(namespace))
+	if err != nil {
+		return fmt.Errorf("listing vulnerability reports: %w", err)
+	}
+	var current []starboardv1alpha1.VulnerabilityReport
+	for _, item := range vulnerabilityList.Items {
+		if item.Labels[etc.LabelReportHistory] != "true" {
+			current = append(current, item)
+		}
+	}
+	metrics.SetVulnerabilityReports(namespace, len(current))
+	metrics.SetVulnerabilities(namespace, SumVulnerabilitySummaries(current))
+	return nil
+}
+
+// SumVulnerabilitySummaries returns the summary of vulnerabilities of all the specified reports. Summaries
+// are summed rather than vulnerabilities, because they're left intact by compression and truncation.
+func SumVulnerabilitySummaries(items []starboardv1alpha1.VulnerabilityReport) starboardv1alpha1.VulnerabilitySummary {
+	var total starboardv1alpha1.VulnerabilitySummary
+	for _, item := range items {
+		summary := item.Report.Summary
+		total.CriticalCount += summary.CriticalCount
+		total.HighCount += summary.HighCount
+		total.MediumCount += summary.MediumCount
+		total.LowCount += summary.LowCount
+		total.NoneCount += summary.NoneCount
+		total.UnknownCount += summary.UnknownCount
+	}
+	return total
+}
+
 func (s *Store) GetVulnerabilityReportsByOwnerAndHash(ctx context.Context, workload kube.Object, hash string) (vulnerabilities.WorkloadVulnerabilities, error) {
 	vulnerabilityList := &starboardv1alpha1.VulnerabilityReportList{}
 
@@ -120,56 +480,392 @@ func (s *Store) GetVulnerabilityReportsByOwnerAndHash(ctx context.Context, workl
 	reports := make(map[string]starboardv1alpha1.VulnerabilityScanResult)
 	for _, item := range vulnerabilityList.Items {
 		if container, ok := item.Labels[kube.LabelContainerName]; ok {
-			reports[container] = item.Report
+			// Do not modify the object that might be cached.
+			report := item.DeepCopy()
+			err = DecompressVulnerabilities(report)
+			if err != nil {
+				return nil, fmt.Errorf("reading vulnerability report %s/%s: %w", item.Namespace, item.Name, err)
+			}
+			reports[container] = report.Report
 		}
 	}
 	return reports, nil
 }
 
-func (s *Store) getRuntimeObjectFor(ctx context.Context, workload kube.Object) (metav1.Object, error) {
-	var obj runtime.Object
-	switch workload.Kind {
-	case kube.KindPod:
-		obj = &corev1.Pod{}
-	case kube.KindReplicaSet:
-		obj = &appsv1.ReplicaSet{}
-	case kube.KindReplicationController:
-		obj = &corev1.ReplicationController{}
-	case kube.KindDeployment:
-		obj = &appsv1.Deployment{}
-	case kube.KindStatefulSet:
-		obj = &appsv1.StatefulSet{}
-	case kube.KindDaemonSet:
-		obj = &appsv1.DaemonSet{}
-	case kube.KindCronJob:
-		obj = &v1beta1.CronJob{}
-	case kube.KindJob:
-		obj = &batchv1.Job{}
-	default:
-		return nil, fmt.Errorf("unknown workload kind: %s", workload.Kind)
-	}
-	err := s.client.Get(ctx, types.NamespacedName{Name: workload.Name, Namespace: workload.Namespace}, obj)
+// GetVulnerabilityReportsUpdateTime returns the time when the least recently updated
+// VulnerabilityReport of the specified workload was written. Reports without the
+// AnnotationReportUpdatedAt annotation fall back to their creation timestamp.
+// Returns the zero time if there are no VulnerabilityReports.
+func (s *Store) GetVulnerabilityReportsUpdateTime(ctx context.Context, workload kube.Object, hash string) (time.Time, error) {
+	vulnerabilityList := &starboardv1alpha1.VulnerabilityReportList{}
+
+	err := s.client.List(ctx, vulnerabilityList, client.MatchingLabels{
+		kube.LabelResourceKind:      string(workload.Kind),
+		kube.LabelResourceNamespace: workload.Namespace,
+		kube.LabelResourceName:      workload.Name,
+		etc.LabelPodSpecHash:        hash,
+	}, client.InNamespace(workload.Namespace))
 	if err != nil {
-		return nil, err
+		return time.Time{}, err
 	}
-	return obj.(metav1.Object), nil
+
+	var updateTime time.Time
+	for _, item := range vulnerabilityList.Items {
+		itemUpdateTime, err := getUpdateTime(item)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if updateTime.IsZero() || itemUpdateTime.Before(updateTime) {
+			updateTime = itemUpdateTime
+		}
+	}
+	return updateTime, nil
 }
 
+// getUpdateTime returns the time when the specified VulnerabilityReport was written. Reports without the
+// AnnotationReportUpdatedAt annotation fall back to their creation timestamp.
+func getUpdateTime(report starboardv1alpha1.VulnerabilityReport) (time.Time, error) {
+	value, ok := report.Annotations[etc.AnnotationReportUpdatedAt]
+	if !ok {
+		return report.CreationTimestamp.Time, nil
+	}
+	updateTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing annotation %s: %w", etc.AnnotationReportUpdatedAt, err)
+	}
+	return updateTime, nil
+}
+
+// FindByDigest returns the most recently updated VulnerabilityReport, in any watched namespace,
+// annotated with the specified digest as etc.AnnotationImageDigest. Returns nil if there's no such report.
+func (s *Store) FindByDigest(ctx context.Context, digest string) (*DigestReport, error) {
+	vulnerabilityList := &starboardv1alpha1.VulnerabilityReportList{}
+	err := s.client.List(ctx, vulnerabilityList)
+	if err != nil {
+		return nil, fmt.Errorf("listing vulnerability reports: %w", err)
+	}
+
+	var found *starboardv1alpha1.VulnerabilityReport
+	var foundUpdateTime time.Time
+	for i, item := range vulnerabilityList.Items {
+		if item.Annotations[etc.AnnotationImageDigest] != digest {
+			continue
+		}
+		updateTime, err := getUpdateTime(item)
+		if err != nil {
+			return nil, err
+		}
+		if found == nil || updateTime.After(foundUpdateTime) {
+			found = &vulnerabilityList.Items[i]
+			foundUpdateTime = updateTime
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+
+	// Do not modify the object that might be cached.
+	report := found.DeepCopy()
+	err = DecompressVulnerabilities(report)
+	if err != nil {
+		return nil, fmt.Errorf("reading vulnerability report %s/%s: %w", found.Namespace, found.Name, err)
+	}
+	return &DigestReport{
+		Report:    report.Report,
+		UpdatedAt: foundUpdateTime,
+	}, nil
+}
+
+// HasVulnerabilityReports checks whether there are VulnerabilityReports of all the specified containers.
+// Reports of other containers, e.g. written by another scan Job of the same workload, are ignored.
 func (s *Store) HasVulnerabilityReports(ctx context.Context, owner kube.Object, hash string, containerImages kube.ContainerImages) (bool, error) {
 	vulnerabilityReports, err := s.GetVulnerabilityReportsByOwnerAndHash(ctx, owner, hash)
 	if err != nil {
 		return false, err
 	}
 
-	actual := map[string]bool{}
-	for containerName, _ := range vulnerabilityReports {
-		actual[containerName] = true
+	for containerName := range containerImages {
+		if _, ok := vulnerabilityReports[containerName]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// GetContainerReports returns current VulnerabilityReports of containers of the specified workload, i.e.
+// reports labeled with etc.LabelPodSpecHash of any value, keyed by container names. History reports are skipped.
+func (s *Store) GetContainerReports(ctx context.Context, workload kube.Object) (map[string]ContainerReport, error) {
+	vulnerabilityList, err := s.listCurrentVulnerabilityReports(ctx, workload)
+	if err != nil {
+		return nil, err
+	}
+	containerReports := make(map[string]ContainerReport)
+	for _, item := range vulnerabilityList.Items {
+		container, ok := item.Labels[kube.LabelContainerName]
+		if !ok {
+			continue
+		}
+		containerReports[container] = ContainerReport{
+			Hash:   item.Labels[etc.LabelPodSpecHash],
+			Digest: item.Annotations[etc.AnnotationImageDigest],
+		}
+	}
+	return containerReports, nil
+}
+
+// UpdateContainerReportsHash relabels current VulnerabilityReports of the specified containers with the given
+// hash as etc.LabelPodSpecHash. Reports are not annotated with a new update time, so that they still expire
+// after OPERATOR_SCAN_REPORT_TTL since they were written.
+func (s *Store) UpdateContainerReportsHash(ctx context.Context, workload kube.Object, hash string, containers []string) error {
+	vulnerabilityList, err := s.listCurrentVulnerabilityReports(ctx, workload)
+	if err != nil {
+		return err
+	}
+	selected := make(map[string]bool)
+	for _, container := range containers {
+		selected[container] = true
 	}
+	for _, item := range vulnerabilityList.Items {
+		if !selected[item.Labels[kube.LabelContainerName]] || item.Labels[etc.LabelPodSpecHash] == hash {
+			continue
+		}
+		// Do not modify the object that might be cached.
+		patched := item.DeepCopy()
+		patched.Labels[etc.LabelPodSpecHash] = hash
+		log.Info("Updating hash of VulnerabilityReport",
+			"report", fmt.Sprintf("%s/%s", item.Namespace, item.Name),
+			"hash", hash)
+		err = s.client.Patch(ctx, patched, client.MergeFrom(&item))
+		if err != nil {
+			return fmt.Errorf("updating hash of vulnerability report %s/%s: %w", item.Namespace, item.Name, err)
+		}
+	}
+	return nil
+}
 
-	expected := map[string]bool{}
-	for containerName, _ := range containerImages {
-		expected[containerName] = true
+// DeleteContainerReports deletes current VulnerabilityReports of the specified containers, e.g. removed from
+// the Pod spec of the workload. History reports are left intact.
+func (s *Store) DeleteContainerReports(ctx context.Context, workload kube.Object, containers []string) error {
+	vulnerabilityList, err := s.listCurrentVulnerabilityReports(ctx, workload)
+	if err != nil {
+		return err
+	}
+	selected := make(map[string]bool)
+	for _, container := range containers {
+		selected[container] = true
+	}
+	for i, item := range vulnerabilityList.Items {
+		if !selected[item.Labels[kube.LabelContainerName]] {
+			continue
+		}
+		log.Info("Deleting VulnerabilityReport of removed container",
+			"report", fmt.Sprintf("%s/%s", item.Namespace, item.Name))
+		err = s.client.Delete(ctx, &vulnerabilityList.Items[i])
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("deleting vulnerability report %s/%s: %w", item.Namespace, item.Name, err)
+		}
+	}
+	return s.updateVulnerabilityReportsMetric(ctx, workload.Namespace)
+}
+
+// listCurrentVulnerabilityReports lists VulnerabilityReports of the specified workload which are labeled with
+// etc.LabelPodSpecHash, i.e. without history reports.
+func (s *Store) listCurrentVulnerabilityReports(ctx context.Context, workload kube.Object) (*starboardv1alpha1.VulnerabilityReportList, error) {
+	hashExists, err := labels.NewRequirement(etc.LabelPodSpecHash, selection.Exists, nil)
+	if err != nil {
+		return nil, err
 	}
+	selector := labels.SelectorFromSet(labels.Set{
+		kube.LabelResourceKind:      string(workload.Kind),
+		kube.LabelResourceNamespace: workload.Namespace,
+		kube.LabelResourceName:      workload.Name,
+	}).Add(*hashExists)
 
-	return reflect.DeepEqual(actual, expected), nil
+	vulnerabilityList := &starboardv1alpha1.VulnerabilityReportList{}
+	err = s.client.List(ctx, vulnerabilityList, client.MatchingLabelsSelector{Selector: selector},
+		client.InNamespace(workload.Namespace))
+	if err != nil {
+		return nil, fmt.Errorf("listing vulnerability reports: %w", err)
+	}
+	return vulnerabilityList, nil
+}
+
+// SaveConfigAuditReport creates or updates the ConfigAuditReport of the specified workload.
+func (s *Store) SaveConfigAuditReport(ctx context.Context, workload kube.Object, hash string, report starboardv1alpha1.ConfigAudit) error {
+	owner, err := resources.GetRuntimeObjectFor(ctx, s.client, workload)
+	if err != nil {
+		return err
+	}
+
+	reportName := fmt.Sprintf("%s-%s", strings.ToLower(string(workload.Kind)), workload.Name)
+	updatedAt := s.clock.Now().UTC().Format(time.RFC3339)
+
+	if s.serverSideApply {
+		configAuditReport, err := s.newConfigAuditReport(owner, workload, reportName, hash, updatedAt, report)
+		if err != nil {
+			return err
+		}
+		log.Info("Applying ConfigAuditReport",
+			"report", fmt.Sprintf("%s/%s", workload.Namespace, reportName),
+			"hash", hash)
+		return s.apply(ctx, configAuditReport)
+	}
+
+	configAuditReport := &starboardv1alpha1.ConfigAuditReport{}
+	err = s.client.Get(ctx, types.NamespacedName{Name: reportName, Namespace: workload.Namespace}, configAuditReport)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if errors.IsNotFound(err) {
+		configAuditReport, err = s.newConfigAuditReport(owner, workload, reportName, hash, updatedAt, report)
+		if err != nil {
+			return err
+		}
+		log.Info("Creating ConfigAuditReport",
+			"report", fmt.Sprintf("%s/%s", workload.Namespace, reportName),
+			"hash", hash)
+		return s.client.Create(ctx, configAuditReport)
+	}
+
+	// Do not modify the object that might be cached.
+	cloned := configAuditReport.DeepCopy()
+	if cloned.Labels == nil {
+		cloned.Labels = make(map[string]string)
+	}
+	if cloned.Annotations == nil {
+		cloned.Annotations = make(map[string]string)
+	}
+	cloned.Labels[etc.LabelPodSpecHash] = hash
+	s.labelInstance(cloned.Labels)
+	cloned.Annotations[etc.AnnotationReportUpdatedAt] = updatedAt
+	cloned.Report = report
+	err = s.setOwner(owner, cloned)
+	if err != nil {
+		return err
+	}
+	log.Info("Updating ConfigAuditReport",
+		"report", fmt.Sprintf("%s/%s", workload.Namespace, reportName),
+		"hash", hash)
+	return s.client.Update(ctx, cloned)
+}
+
+// newConfigAuditReport constructs the ConfigAuditReport of the specified workload controlled by the specified owner.
+func (s *Store) newConfigAuditReport(owner metav1.Object, workload kube.Object, reportName, hash, updatedAt string,
+	report starboardv1alpha1.ConfigAudit) (*starboardv1alpha1.ConfigAuditReport, error) {
+	configAuditReport := &starboardv1alpha1.ConfigAuditReport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reportName,
+			Namespace: workload.Namespace,
+			Labels: labels.Set{
+				kube.LabelResourceKind:      string(workload.Kind),
+				kube.LabelResourceName:      workload.Name,
+				kube.LabelResourceNamespace: workload.Namespace,
+				etc.LabelPodSpecHash:        hash,
+			},
+			Annotations: map[string]string{
+				etc.AnnotationReportUpdatedAt: updatedAt,
+			},
+		},
+		Report: report,
+	}
+	s.labelInstance(configAuditReport.Labels)
+	err := s.setOwner(owner, configAuditReport)
+	if err != nil {
+		return nil, err
+	}
+	return configAuditReport, nil
+}
+
+// HasConfigAuditReport checks whether there is a ConfigAuditReport of the specified workload
+// labeled with the given Pod spec hash.
+func (s *Store) HasConfigAuditReport(ctx context.Context, workload kube.Object, hash string) (bool, error) {
+	configAuditList := &starboardv1alpha1.ConfigAuditReportList{}
+
+	err := s.client.List(ctx, configAuditList, client.MatchingLabels{
+		kube.LabelResourceKind:      string(workload.Kind),
+		kube.LabelResourceNamespace: workload.Namespace,
+		kube.LabelResourceName:      workload.Name,
+		etc.LabelPodSpecHash:        hash,
+	}, client.InNamespace(workload.Namespace))
+	if err != nil {
+		return false, err
+	}
+	return len(configAuditList.Items) > 0, nil
+}
+
+// SBOMDataKey is the key of the SBOM document in the ConfigMap written by Store.WriteSBOM.
+const SBOMDataKey = "bom.json"
+
+// WriteSBOM creates or updates the ConfigMap holding the SBOM of the image of the specified
+// workload container. The ConfigMap is named `sbom-<kind>-<name>-<container>` and is owned by
+// the workload. Note that ConfigMaps are limited to 1 MiB.
+func (s *Store) WriteSBOM(ctx context.Context, workload kube.Object, hash, containerName string, format etc.SBOMFormat, document sbom.Document) error {
+	owner, err := resources.GetRuntimeObjectFor(ctx, s.client, workload)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("sbom-%s-%s-%s", strings.ToLower(string(workload.Kind)), workload.Name, containerName)
+	updatedAt := s.clock.Now().UTC().Format(time.RFC3339)
+
+	configMap := &corev1.ConfigMap{}
+	err = s.client.Get(ctx, types.NamespacedName{Name: name, Namespace: workload.Namespace}, configMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if errors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: workload.Namespace,
+				Labels: labels.Set{
+					kube.LabelResourceKind:      string(workload.Kind),
+					kube.LabelResourceName:      workload.Name,
+					kube.LabelResourceNamespace: workload.Namespace,
+					kube.LabelContainerName:     containerName,
+					etc.LabelPodSpecHash:        hash,
+					etc.LabelSBOMFormat:         string(format),
+				},
+				Annotations: map[string]string{
+					etc.AnnotationReportUpdatedAt: updatedAt,
+				},
+			},
+			Data: map[string]string{
+				SBOMDataKey: string(document.Raw),
+			},
+		}
+		s.labelInstance(configMap.Labels)
+		err = s.setOwner(owner, configMap)
+		if err != nil {
+			return err
+		}
+		log.Info("Creating SBOM",
+			"configMap", fmt.Sprintf("%s/%s", workload.Namespace, name),
+			"hash", hash)
+		return s.client.Create(ctx, configMap)
+	}
+
+	// Do not modify the object that might be cached.
+	cloned := configMap.DeepCopy()
+	if cloned.Labels == nil {
+		cloned.Labels = make(map[string]string)
+	}
+	if cloned.Annotations == nil {
+		cloned.Annotations = make(map[string]string)
+	}
+	cloned.Labels[etc.LabelPodSpecHash] = hash
+	cloned.Labels[etc.LabelSBOMFormat] = string(format)
+	s.labelInstance(cloned.Labels)
+	cloned.Annotations[etc.AnnotationReportUpdatedAt] = updatedAt
+	err = s.setOwner(owner, cloned)
+	if err != nil {
+		return err
+	}
+	cloned.Data = map[string]string{
+		SBOMDataKey: string(document.Raw),
+	}
+	log.Info("Updating SBOM",
+		"configMap", fmt.Sprintf("%s/%s", workload.Namespace, name),
+		"hash", hash)
+	return s.client.Update(ctx, cloned)
 }
@@ -0,0 +1,888 @@
+package reports_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/metrics"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/find/vulnerabilities"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStore_SaveVulnerabilityReports(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+	vulnerabilityReports := vulnerabilities.WorkloadVulnerabilities{
+		"nginx": newVulnerabilityReport(newVulnerabilities(100)).Report,
+	}
+
+	testCases := []struct {
+		name               string
+		compression        reports.Compression
+		expectedCompressed bool
+	}{
+		{
+			name:               "Should store uncompressed report when compression is disabled",
+			compression:        reports.Compression{Enabled: false, Threshold: 1024},
+			expectedCompressed: false,
+		},
+		{
+			name:               "Should store compressed report when size exceeds threshold",
+			compression:        reports.Compression{Enabled: true, Threshold: 1024},
+			expectedCompressed: true,
+		},
+		{
+			name:               "Should store uncompressed report when size does not exceed threshold",
+			compression:        reports.Compression{Enabled: true, Threshold: 1024 * 1024},
+			expectedCompressed: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			fakeClient := fake.NewFakeClientWithScheme(scheme, pod)
+			store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, tc.compression, 0, 0, false, nil, "")
+
+			err := store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5", vulnerabilityReports, nil, nil, nil)
+			require.NoError(t, err)
+
+			stored := &starboardv1alpha1.VulnerabilityReport{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, stored)
+			require.NoError(t, err)
+			_, compressed := stored.Annotations[etc.AnnotationCompressedVulnerabilities]
+			assert.Equal(t, tc.expectedCompressed, compressed)
+
+			actual, err := store.GetVulnerabilityReportsByOwnerAndHash(ctx, workload, "7f8b9c6d5")
+			require.NoError(t, err)
+			assert.Equal(t, vulnerabilityReports, actual)
+		})
+	}
+}
+
+func TestStore_SaveVulnerabilityReportsWithImageDigests(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+	fakeClient := fake.NewFakeClientWithScheme(scheme, pod)
+	store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+	vulnerabilityReports := vulnerabilities.WorkloadVulnerabilities{
+		"nginx":   newVulnerabilityReport(newVulnerabilities(1)).Report,
+		"sidecar": newVulnerabilityReport(newVulnerabilities(1)).Report,
+	}
+
+	err := store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5", vulnerabilityReports, nil, nil, kube.ContainerImages{
+		"nginx": "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c",
+	})
+	require.NoError(t, err)
+
+	stored := &starboardv1alpha1.VulnerabilityReport{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, stored))
+	assert.Equal(t, "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c", stored.Annotations[etc.AnnotationImageDigest])
+
+	stored = &starboardv1alpha1.VulnerabilityReport{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-sidecar"}, stored))
+	assert.NotContains(t, stored.Annotations, etc.AnnotationImageDigest)
+}
+
+func TestStore_SaveVulnerabilityReportsOfInitAndEphemeralContainers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+	fakeClient := fake.NewFakeClientWithScheme(scheme, pod)
+	store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+	vulnerabilityReports := vulnerabilities.WorkloadVulnerabilities{
+		"init":     newVulnerabilityReport(newVulnerabilities(1)).Report,
+		"nginx":    newVulnerabilityReport(newVulnerabilities(1)).Report,
+		"debugger": newVulnerabilityReport(newVulnerabilities(1)).Report,
+	}
+
+	err := store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5", vulnerabilityReports,
+		[]string{"init"}, []string{"debugger"}, nil)
+	require.NoError(t, err)
+
+	stored := &starboardv1alpha1.VulnerabilityReport{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-init"}, stored))
+	assert.Equal(t, "true", stored.Labels[etc.LabelInitContainer])
+	assert.NotContains(t, stored.Labels, etc.LabelEphemeralContainer)
+
+	stored = &starboardv1alpha1.VulnerabilityReport{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-debugger"}, stored))
+	assert.Equal(t, "true", stored.Labels[etc.LabelEphemeralContainer])
+	assert.NotContains(t, stored.Labels, etc.LabelInitContainer)
+
+	stored = &starboardv1alpha1.VulnerabilityReport{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, stored))
+	assert.NotContains(t, stored.Labels, etc.LabelInitContainer)
+	assert.NotContains(t, stored.Labels, etc.LabelEphemeralContainer)
+}
+
+func TestStore_SaveVulnerabilityReportsWithAnnotations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+	fakeClient := fake.NewFakeClientWithScheme(scheme, pod)
+	fakeClock := clock.NewFakeClock(time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC))
+	store := reports.NewStore(fakeClient, scheme, fakeClock, reports.Compression{}, 0, 0, false, map[string]string{
+		"team":              "payments",
+		"example.com/owner": "jane@example.com",
+	}, "")
+
+	// save saves the report produced by the specified version of Trivy and returns the stored report.
+	save := func(version string) *starboardv1alpha1.VulnerabilityReport {
+		report := newVulnerabilityReport(newVulnerabilities(1)).Report
+		report.Scanner = starboardv1alpha1.Scanner{Name: "Trivy", Vendor: "Aqua Security", Version: version}
+		err := store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5", vulnerabilities.WorkloadVulnerabilities{
+			"nginx": report,
+		}, nil, nil, nil)
+		require.NoError(t, err)
+		stored := &starboardv1alpha1.VulnerabilityReport{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, stored))
+		return stored
+	}
+
+	stored := save("0.11.0")
+	assert.Equal(t, "payments", stored.Annotations["team"])
+	assert.Equal(t, "jane@example.com", stored.Annotations["example.com/owner"])
+	assert.Equal(t, "Trivy", stored.Annotations[etc.AnnotationScannerName])
+	assert.Equal(t, "0.11.0", stored.Annotations[etc.AnnotationScannerVersion])
+	assert.Equal(t, "2020-10-01T10:00:00Z", stored.Annotations[etc.AnnotationReportUpdatedAt])
+
+	fakeClock.Step(time.Hour)
+	stored = save("0.12.0")
+	assert.Equal(t, "payments", stored.Annotations["team"])
+	assert.Equal(t, "jane@example.com", stored.Annotations["example.com/owner"])
+	assert.Equal(t, "Trivy", stored.Annotations[etc.AnnotationScannerName])
+	assert.Equal(t, "0.12.0", stored.Annotations[etc.AnnotationScannerVersion])
+	assert.Equal(t, "2020-10-01T11:00:00Z", stored.Annotations[etc.AnnotationReportUpdatedAt])
+}
+
+func TestStore_SaveVulnerabilityReportsWithHistory(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+	digest := "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c"
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name            string
+		historyLimit    int
+		writes          int
+		expectedHistory []string
+	}{
+		{
+			name:         "Should keep only current report by default",
+			historyLimit: 1,
+			writes:       3,
+		},
+		{
+			name:         "Should keep previous reports up to the limit",
+			historyLimit: 3,
+			writes:       3,
+			expectedHistory: []string{
+				fmt.Sprintf("pod-nginx-nginx-%d", start.Unix()),
+				fmt.Sprintf("pod-nginx-nginx-%d", start.Add(time.Hour).Unix()),
+			},
+		},
+		{
+			name:         "Should prune the oldest report when the limit is exceeded",
+			historyLimit: 3,
+			writes:       4,
+			expectedHistory: []string{
+				fmt.Sprintf("pod-nginx-nginx-%d", start.Add(time.Hour).Unix()),
+				fmt.Sprintf("pod-nginx-nginx-%d", start.Add(2*time.Hour).Unix()),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClock := clock.NewFakeClock(start)
+			fakeClient := fake.NewFakeClientWithScheme(scheme, pod)
+			store := reports.NewStore(fakeClient, scheme, fakeClock, reports.Compression{}, 0, tc.historyLimit, false, nil, "")
+
+			for i := 1; i <= tc.writes; i++ {
+				err := store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5", vulnerabilities.WorkloadVulnerabilities{
+					"nginx": newVulnerabilityReport(newVulnerabilities(i)).Report,
+				}, nil, nil, kube.ContainerImages{"nginx": digest})
+				require.NoError(t, err)
+				fakeClock.Step(time.Hour)
+			}
+
+			list := &starboardv1alpha1.VulnerabilityReportList{}
+			require.NoError(t, fakeClient.List(ctx, list, client.MatchingLabels{etc.LabelReportHistory: "true"}))
+			var history []string
+			for _, item := range list.Items {
+				history = append(history, item.Name)
+				assert.NotContains(t, item.Labels, etc.LabelPodSpecHash)
+				assert.NotContains(t, item.Annotations, etc.AnnotationImageDigest)
+			}
+			assert.ElementsMatch(t, tc.expectedHistory, history)
+
+			current, err := store.GetVulnerabilityReportsByOwnerAndHash(ctx, workload, "7f8b9c6d5")
+			require.NoError(t, err)
+			require.Contains(t, current, "nginx")
+			assert.Len(t, current["nginx"].Vulnerabilities, tc.writes, "History reports are not read as current")
+			assert.Equal(t, float64(1), testutil.ToFloat64(metrics.VulnerabilityReports.WithLabelValues("default")))
+		})
+	}
+}
+
+func TestStore_SaveTruncatedVulnerabilityReports(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+	fakeClient := fake.NewFakeClientWithScheme(scheme, pod)
+	store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{Enabled: true, Threshold: 1024}, 100, 0, false, nil, "")
+
+	oversized := newVulnerabilityReport(newMixedVulnerabilities(5000)).Report
+	require.NoError(t, store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5",
+		vulnerabilities.WorkloadVulnerabilities{"nginx": oversized}, nil, nil, nil))
+
+	stored := &starboardv1alpha1.VulnerabilityReport{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, stored))
+	assert.Equal(t, "4900", stored.Annotations[etc.AnnotationTruncatedVulnerabilities])
+
+	actual, err := store.GetVulnerabilityReportsByOwnerAndHash(ctx, workload, "7f8b9c6d5")
+	require.NoError(t, err)
+	assert.Len(t, actual["nginx"].Vulnerabilities, 100)
+	assert.Equal(t, starboardv1alpha1.VulnerabilitySummary{
+		CriticalCount: 1000,
+		HighCount:     1000,
+		MediumCount:   1000,
+		LowCount:      1000,
+		UnknownCount:  1000,
+	}, actual["nginx"].Summary, "Summary counts all vulnerabilities")
+	assert.Equal(t, "5000", stored.Annotations[etc.AnnotationVulnerabilityCount])
+	for _, vulnerability := range actual["nginx"].Vulnerabilities {
+		assert.Equal(t, starboardv1alpha1.SeverityCritical, vulnerability.Severity)
+	}
+
+	// Annotation is removed once the report fits.
+	require.NoError(t, store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5",
+		vulnerabilities.WorkloadVulnerabilities{"nginx": newVulnerabilityReport(newMixedVulnerabilities(10)).Report}, nil, nil, nil))
+	updated := &starboardv1alpha1.VulnerabilityReport{}
+	require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, updated))
+	assert.NotContains(t, updated.Annotations, etc.AnnotationTruncatedVulnerabilities)
+}
+
+func TestStore_SaveVulnerabilityReportsSummary(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+	fakeClient := fake.NewFakeClientWithScheme(scheme, pod)
+	store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+
+	getStored := func(t *testing.T) *starboardv1alpha1.VulnerabilityReport {
+		t.Helper()
+		stored := &starboardv1alpha1.VulnerabilityReport{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, stored))
+		return stored
+	}
+
+	t.Run("Should summarize vulnerabilities of mixed severities when report is created", func(t *testing.T) {
+		// The summary reported by the scanner does not match the vulnerabilities.
+		report := starboardv1alpha1.VulnerabilityScanResult{
+			Summary:         starboardv1alpha1.VulnerabilitySummary{HighCount: 1},
+			Vulnerabilities: newMixedVulnerabilities(12),
+		}
+		report.Vulnerabilities[0].Severity = starboardv1alpha1.SeverityNone
+		require.NoError(t, store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5",
+			vulnerabilities.WorkloadVulnerabilities{"nginx": report}, nil, nil, nil))
+
+		stored := getStored(t)
+		assert.Equal(t, starboardv1alpha1.VulnerabilitySummary{
+			CriticalCount: 2,
+			HighCount:     2,
+			MediumCount:   2,
+			LowCount:      3,
+			UnknownCount:  3,
+		}, stored.Report.Summary, "Vulnerabilities of NONE severity are counted as unknown")
+		assert.Equal(t, "12", stored.Annotations[etc.AnnotationVulnerabilityCount])
+	})
+
+	t.Run("Should summarize vulnerabilities when report is updated", func(t *testing.T) {
+		report := starboardv1alpha1.VulnerabilityScanResult{
+			Vulnerabilities: []starboardv1alpha1.Vulnerability{
+				{VulnerabilityID: "CVE-2020-3810", Severity: starboardv1alpha1.SeverityCritical},
+			},
+		}
+		require.NoError(t, store.SaveVulnerabilityReports(ctx, workload, "6d5c4b3a2",
+			vulnerabilities.WorkloadVulnerabilities{"nginx": report}, nil, nil, nil))
+
+		stored := getStored(t)
+		assert.Equal(t, starboardv1alpha1.VulnerabilitySummary{CriticalCount: 1}, stored.Report.Summary)
+		assert.Equal(t, "1", stored.Annotations[etc.AnnotationVulnerabilityCount])
+	})
+}
+
+func TestStore_VulnerabilitiesMetric(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	newWorkload := func(name string) (kube.Object, *corev1.Pod) {
+		return kube.Object{Kind: kube.KindPod, Name: name, Namespace: "metrics"},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "metrics"}}
+	}
+	// newReport returns the scan result with vulnerabilities counted by the specified summary.
+	newReport := func(summary starboardv1alpha1.VulnerabilitySummary) starboardv1alpha1.VulnerabilityScanResult {
+		var items []starboardv1alpha1.Vulnerability
+		for severity, count := range map[starboardv1alpha1.Severity]int{
+			starboardv1alpha1.SeverityCritical: summary.CriticalCount,
+			starboardv1alpha1.SeverityHigh:     summary.HighCount,
+			starboardv1alpha1.SeverityMedium:   summary.MediumCount,
+			starboardv1alpha1.SeverityLow:      summary.LowCount,
+			starboardv1alpha1.SeverityUnknown:  summary.UnknownCount,
+		} {
+			for i := 0; i < count; i++ {
+				items = append(items, starboardv1alpha1.Vulnerability{Severity: severity})
+			}
+		}
+		return starboardv1alpha1.VulnerabilityScanResult{Summary: summary, Vulnerabilities: items}
+	}
+	nginx, nginxPod := newWorkload("nginx")
+	redis, redisPod := newWorkload("redis")
+	fakeClient := fake.NewFakeClientWithScheme(scheme, nginxPod, redisPod)
+	store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+
+	gauge := func(severity starboardv1alpha1.Severity) float64 {
+		return testutil.ToFloat64(metrics.Vulnerabilities.WithLabelValues("metrics", string(severity)))
+	}
+
+	require.NoError(t, store.SaveVulnerabilityReports(ctx, nginx, "7f8b9c6d5", vulnerabilities.WorkloadVulnerabilities{
+		"nginx":   newReport(starboardv1alpha1.VulnerabilitySummary{CriticalCount: 2, HighCount: 3}),
+		"sidecar": newReport(starboardv1alpha1.VulnerabilitySummary{HighCount: 1, LowCount: 4}),
+	}, nil, nil, nil))
+	require.NoError(t, store.SaveVulnerabilityReports(ctx, redis, "6d5c4b3a2", vulnerabilities.WorkloadVulnerabilities{
+		"redis": newReport(starboardv1alpha1.VulnerabilitySummary{CriticalCount: 1, UnknownCount: 2}),
+	}, nil, nil, nil))
+
+	assert.Equal(t, float64(3), gauge(starboardv1alpha1.SeverityCritical))
+	assert.Equal(t, float64(4), gauge(starboardv1alpha1.SeverityHigh))
+	assert.Equal(t, float64(0), gauge(starboardv1alpha1.SeverityMedium))
+	assert.Equal(t, float64(4), gauge(starboardv1alpha1.SeverityLow))
+	assert.Equal(t, float64(2), gauge(starboardv1alpha1.SeverityUnknown))
+
+	// Counts are recomputed, so that deleted reports and fixed vulnerabilities are reflected.
+	require.NoError(t, fakeClient.Delete(ctx, &starboardv1alpha1.VulnerabilityReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-redis-redis", Namespace: "metrics"},
+	}))
+	require.NoError(t, store.SaveVulnerabilityReports(ctx, nginx, "7f8b9c6d5", vulnerabilities.WorkloadVulnerabilities{
+		"nginx": newReport(starboardv1alpha1.VulnerabilitySummary{CriticalCount: 1}),
+	}, nil, nil, nil))
+
+	assert.Equal(t, float64(1), gauge(starboardv1alpha1.SeverityCritical))
+	assert.Equal(t, float64(1), gauge(starboardv1alpha1.SeverityHigh))
+	assert.Equal(t, float64(4), gauge(starboardv1alpha1.SeverityLow))
+	assert.Equal(t, float64(0), gauge(starboardv1alpha1.SeverityUnknown))
+}
+
+func TestStore_OwnerReferences(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	vulnerabilityReports := vulnerabilities.WorkloadVulnerabilities{
+		"nginx": newVulnerabilityReport(newVulnerabilities(1)).Report,
+	}
+	newControllerRef := func(uid types.UID) metav1.OwnerReference {
+		return metav1.OwnerReference{
+			APIVersion:         "v1",
+			Kind:               "Pod",
+			Name:               "nginx",
+			UID:                uid,
+			Controller:         pointer.BoolPtr(true),
+			BlockOwnerDeletion: pointer.BoolPtr(true),
+		}
+	}
+
+	t.Run("Should make workload the controller of its reports so that they're garbage collected with it", func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", UID: "8a5a7c8e"}}
+		fakeClient := fake.NewFakeClientWithScheme(scheme, pod)
+		store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+
+		require.NoError(t, store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5", vulnerabilityReports, nil, nil, nil))
+		require.NoError(t, store.SaveConfigAuditReport(ctx, workload, "7f8b9c6d5", starboardv1alpha1.ConfigAudit{}))
+
+		vulnerabilityReport := &starboardv1alpha1.VulnerabilityReport{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, vulnerabilityReport))
+		assert.Equal(t, []metav1.OwnerReference{newControllerRef("8a5a7c8e")}, vulnerabilityReport.OwnerReferences)
+
+		configAuditReport := &starboardv1alpha1.ConfigAuditReport{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx"}, configAuditReport))
+		assert.Equal(t, []metav1.OwnerReference{newControllerRef("8a5a7c8e")}, configAuditReport.OwnerReferences)
+	})
+
+	t.Run("Should replace owner reference of deleted workload with the same name", func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", UID: "b3c1d2e4"}}
+		report := newVulnerabilityReport(newVulnerabilities(1))
+		report.Name = "pod-nginx-nginx"
+		report.Namespace = "default"
+		report.OwnerReferences = []metav1.OwnerReference{newControllerRef("8a5a7c8e")}
+		fakeClient := fake.NewFakeClientWithScheme(scheme, pod, report)
+		store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+
+		require.NoError(t, store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5", vulnerabilityReports, nil, nil, nil))
+
+		stored := &starboardv1alpha1.VulnerabilityReport{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, stored))
+		assert.Equal(t, []metav1.OwnerReference{newControllerRef("b3c1d2e4")}, stored.OwnerReferences)
+	})
+
+	t.Run("Should upgrade owner reference of reports written by previous versions", func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", UID: "8a5a7c8e"}}
+		report := newVulnerabilityReport(newVulnerabilities(1))
+		report.Name = "pod-nginx-nginx"
+		report.Namespace = "default"
+		report.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "v1", Kind: "Pod", Name: "nginx", UID: "8a5a7c8e"},
+		}
+		fakeClient := fake.NewFakeClientWithScheme(scheme, pod, report)
+		store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+
+		require.NoError(t, store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5", vulnerabilityReports, nil, nil, nil))
+
+		stored := &starboardv1alpha1.VulnerabilityReport{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, stored))
+		assert.Equal(t, []metav1.OwnerReference{newControllerRef("8a5a7c8e")}, stored.OwnerReferences)
+	})
+}
+
+// staleClient is a client.Client which simulates a lagging cache: the first Get of each VulnerabilityReport
+// returns NotFound and the first Update of each VulnerabilityReport fails with a conflict.
+type staleClient struct {
+	client.Client
+	gets    map[client.ObjectKey]int
+	updates map[client.ObjectKey]int
+}
+
+func (c *staleClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if report, ok := obj.(*starboardv1alpha1.VulnerabilityReport); ok {
+		c.gets[key]++
+		if c.gets[key] == 1 {
+			return errors.NewNotFound(starboardv1alpha1.Resource("vulnerabilityreports"), report.Name)
+		}
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *staleClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if report, ok := obj.(*starboardv1alpha1.VulnerabilityReport); ok {
+		key := client.ObjectKey{Namespace: report.Namespace, Name: report.Name}
+		c.updates[key]++
+		if c.updates[key] == 1 {
+			return errors.NewConflict(starboardv1alpha1.Resource("vulnerabilityreports"), report.Name, fmt.Errorf("object has been modified"))
+		}
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// applyClient is a client.Client which records writes with server-side apply and emulates them, as the fake
+// client does not support the apply patch type. Applied objects replace existing ones. Applying an object with
+// the resourceVersion set fails, as it would be a read-modify-write prone to conflicts.
+type applyClient struct {
+	client.Client
+	applied []client.PatchOptions
+}
+
+func (c *applyClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	options := client.PatchOptions{}
+	options.ApplyOptions(opts)
+	c.applied = append(c.applied, options)
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if accessor.GetResourceVersion() != "" {
+		return errors.NewBadRequest("resourceVersion must not be set in applied object")
+	}
+	existing := obj.DeepCopyObject()
+	err = c.Client.Get(ctx, client.ObjectKey{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}, existing)
+	if errors.IsNotFound(err) {
+		return c.Client.Create(ctx, obj)
+	}
+	if err != nil {
+		return err
+	}
+	existingAccessor, err := meta.Accessor(existing)
+	if err != nil {
+		return err
+	}
+	accessor.SetResourceVersion(existingAccessor.GetResourceVersion())
+	return c.Client.Update(ctx, obj)
+}
+
+func TestStore_ServerSideApply(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", UID: "7b3a9c1e"}}
+
+	assertApplied := func(t *testing.T, applied []client.PatchOptions, count int) {
+		t.Helper()
+		require.Len(t, applied, count)
+		for _, options := range applied {
+			assert.Equal(t, "starboard-operator", options.FieldManager)
+			assert.Equal(t, pointer.BoolPtr(true), options.Force)
+		}
+	}
+
+	t.Run("Should apply VulnerabilityReports repeatedly", func(t *testing.T) {
+		fakeClient := &applyClient{Client: fake.NewFakeClientWithScheme(scheme, pod)}
+		store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, true, nil, "")
+
+		for i, hash := range []string{"7f8b9c6d5", "7f8b9c6d5", "5c6d7f8b9"} {
+			require.NoError(t, store.Write(ctx, workload, reports.WorkloadReport{
+				Hash:            hash,
+				Vulnerabilities: vulnerabilities.WorkloadVulnerabilities{"nginx": newVulnerabilityReport(newVulnerabilities(i + 1)).Report},
+			}))
+		}
+		assertApplied(t, fakeClient.applied, 3)
+
+		stored := &starboardv1alpha1.VulnerabilityReport{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, stored))
+		assert.Equal(t, "5c6d7f8b9", stored.Labels[etc.LabelPodSpecHash])
+		assert.Len(t, stored.Report.Vulnerabilities, 3)
+		owner := metav1.GetControllerOf(stored)
+		require.NotNil(t, owner)
+		assert.Equal(t, types.UID("7b3a9c1e"), owner.UID)
+	})
+
+	t.Run("Should archive previous VulnerabilityReport before applying current one", func(t *testing.T) {
+		fakeClock := clock.NewFakeClock(time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC))
+		fakeClient := &applyClient{Client: fake.NewFakeClientWithScheme(scheme, pod)}
+		store := reports.NewStore(fakeClient, scheme, fakeClock, reports.Compression{}, 0, 2, true, nil, "")
+
+		for i := 1; i <= 2; i++ {
+			require.NoError(t, store.Write(ctx, workload, reports.WorkloadReport{
+				Hash:            "7f8b9c6d5",
+				Vulnerabilities: vulnerabilities.WorkloadVulnerabilities{"nginx": newVulnerabilityReport(newVulnerabilities(i)).Report},
+			}))
+			fakeClock.Step(time.Hour)
+		}
+		assertApplied(t, fakeClient.applied, 2)
+
+		list := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, fakeClient.List(ctx, list, client.MatchingLabels{etc.LabelReportHistory: "true"}))
+		require.Len(t, list.Items, 1)
+		assert.Len(t, list.Items[0].Report.Vulnerabilities, 1)
+	})
+
+	t.Run("Should apply ConfigAuditReports repeatedly", func(t *testing.T) {
+		fakeClient := &applyClient{Client: fake.NewFakeClientWithScheme(scheme, pod)}
+		store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, true, nil, "")
+		report := starboardv1alpha1.ConfigAudit{
+			Summary: starboardv1alpha1.ConfigAuditSummary{DangerCount: 1},
+		}
+
+		require.NoError(t, store.SaveConfigAuditReport(ctx, workload, "7f8b9c6d5", report))
+		require.NoError(t, store.SaveConfigAuditReport(ctx, workload, "5c6d7f8b9", report))
+		assertApplied(t, fakeClient.applied, 2)
+
+		found, err := store.HasConfigAuditReport(ctx, workload, "5c6d7f8b9")
+		require.NoError(t, err)
+		assert.True(t, found)
+	})
+
+	t.Run("Should apply reports labeled with instance ID as field manager of instance", func(t *testing.T) {
+		fakeClient := &applyClient{Client: fake.NewFakeClientWithScheme(scheme, pod)}
+		store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, true, nil, "tenant-a")
+
+		require.NoError(t, store.Write(ctx, workload, reports.WorkloadReport{
+			Hash:            "7f8b9c6d5",
+			Vulnerabilities: vulnerabilities.WorkloadVulnerabilities{"nginx": newVulnerabilityReport(newVulnerabilities(1)).Report},
+		}))
+		require.NoError(t, store.SaveConfigAuditReport(ctx, workload, "7f8b9c6d5", starboardv1alpha1.ConfigAudit{}))
+		require.Len(t, fakeClient.applied, 2)
+		for _, options := range fakeClient.applied {
+			assert.Equal(t, "starboard-operator-tenant-a", options.FieldManager)
+		}
+
+		vulnerabilityReport := &starboardv1alpha1.VulnerabilityReport{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx-nginx"}, vulnerabilityReport))
+		assert.Equal(t, "tenant-a", vulnerabilityReport.Labels[etc.LabelInstanceID])
+		configAuditReport := &starboardv1alpha1.ConfigAuditReport{}
+		require.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx"}, configAuditReport))
+		assert.Equal(t, "tenant-a", configAuditReport.Labels[etc.LabelInstanceID])
+	})
+}
+
+func TestStore_WriteContainersOfSeparateScanJobs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "app", Namespace: "default"}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+	containerImages := kube.ContainerImages{"nginx": "nginx:1.16", "redis": "redis:5"}
+	nginxReport := newVulnerabilityReport(newVulnerabilities(1)).Report
+	redisReport := newVulnerabilityReport(newVulnerabilities(2)).Report
+
+	t.Run("Should merge reports of containers written by separate scan jobs", func(t *testing.T) {
+		fakeClient := fake.NewFakeClientWithScheme(scheme, pod)
+		store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+
+		require.NoError(t, store.Write(ctx, workload, reports.WorkloadReport{
+			Hash:            "7f8b9c6d5",
+			Vulnerabilities: vulnerabilities.WorkloadVulnerabilities{"nginx": nginxReport},
+		}))
+		hasReport, err := store.HasReport(ctx, workload, "7f8b9c6d5", containerImages)
+		require.NoError(t, err)
+		assert.False(t, hasReport, "Report of the redis container is not written yet")
+
+		require.NoError(t, store.Write(ctx, workload, reports.WorkloadReport{
+			Hash:            "7f8b9c6d5",
+			Vulnerabilities: vulnerabilities.WorkloadVulnerabilities{"redis": redisReport},
+		}))
+		hasReport, err = store.HasReport(ctx, workload, "7f8b9c6d5", containerImages)
+		require.NoError(t, err)
+		assert.True(t, hasReport)
+
+		actual, err := store.GetVulnerabilityReportsByOwnerAndHash(ctx, workload, "7f8b9c6d5")
+		require.NoError(t, err)
+		assert.Equal(t, vulnerabilities.WorkloadVulnerabilities{"nginx": nginxReport, "redis": redisReport}, actual)
+	})
+
+	t.Run("Should retry writes which conflict with concurrent scan jobs", func(t *testing.T) {
+		// The reports were just created by concurrent scan Jobs, but are not in the cache yet.
+		newExistingReport := func(container string) *starboardv1alpha1.VulnerabilityReport {
+			report := newVulnerabilityReport(newVulnerabilities(3))
+			report.Name = "pod-app-" + container
+			report.Namespace = "default"
+			report.Labels = map[string]string{
+				kube.LabelResourceKind:      "Pod",
+				kube.LabelResourceName:      "app",
+				kube.LabelResourceNamespace: "default",
+				kube.LabelContainerName:     container,
+				etc.LabelPodSpecHash:        "7f8b9c6d5",
+			}
+			return report
+		}
+		fakeClient := &staleClient{
+			Client:  fake.NewFakeClientWithScheme(scheme, pod, newExistingReport("nginx"), newExistingReport("redis")),
+			gets:    make(map[client.ObjectKey]int),
+			updates: make(map[client.ObjectKey]int),
+		}
+		store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+
+		require.NoError(t, store.Write(ctx, workload, reports.WorkloadReport{
+			Hash:            "7f8b9c6d5",
+			Vulnerabilities: vulnerabilities.WorkloadVulnerabilities{"nginx": nginxReport, "redis": redisReport},
+		}))
+
+		actual, err := store.GetVulnerabilityReportsByOwnerAndHash(ctx, workload, "7f8b9c6d5")
+		require.NoError(t, err)
+		assert.Equal(t, vulnerabilities.WorkloadVulnerabilities{"nginx": nginxReport, "redis": redisReport}, actual)
+		assert.Equal(t, 2, fakeClient.updates[client.ObjectKey{Namespace: "default", Name: "pod-app-nginx"}])
+		assert.Equal(t, 2, fakeClient.updates[client.ObjectKey{Namespace: "default", Name: "pod-app-redis"}])
+	})
+}
+
+func TestStore_FindByDigest(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	digest := "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c"
+	fakeClock := clock.NewFakeClock(time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC))
+	fakeClient := fake.NewFakeClientWithScheme(scheme,
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "staging"}},
+	)
+	store := reports.NewStore(fakeClient, scheme, fakeClock, reports.Compression{Enabled: true, Threshold: 1024}, 0, 0, false, nil, "")
+
+	older := newVulnerabilityReport(newVulnerabilities(1)).Report
+	newer := newVulnerabilityReport(newVulnerabilities(100)).Report
+	err := store.SaveVulnerabilityReports(ctx, kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}, "7f8b9c6d5",
+		vulnerabilities.WorkloadVulnerabilities{"nginx": older}, nil, nil, kube.ContainerImages{"nginx": digest})
+	require.NoError(t, err)
+	fakeClock.Step(time.Hour)
+	err = store.SaveVulnerabilityReports(ctx, kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "staging"}, "7f8b9c6d5",
+		vulnerabilities.WorkloadVulnerabilities{"nginx": newer}, nil, nil, kube.ContainerImages{"nginx": digest})
+	require.NoError(t, err)
+
+	t.Run("Should return the most recently updated report in any namespace", func(t *testing.T) {
+		report, err := store.FindByDigest(ctx, digest)
+		require.NoError(t, err)
+		require.NotNil(t, report)
+		assert.Equal(t, newer, report.Report, "Compressed vulnerabilities are decompressed")
+		assert.Equal(t, time.Date(2020, 10, 1, 13, 0, 0, 0, time.UTC), report.UpdatedAt)
+	})
+
+	t.Run("Should return nil when there is no report with the digest", func(t *testing.T) {
+		report, err := store.FindByDigest(ctx, "sha256:4cd8a8a3b2c5e1ce3e6e1a2c22b1c1b4c0a5d2a7b3d8c8a9a6b7b8e1e3c9d4f2")
+		require.NoError(t, err)
+		assert.Nil(t, report)
+	})
+}
+
+func TestStore_IncrementalWriter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	digest := "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c"
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	newStore := func(t *testing.T) (*reports.Store, client.Client) {
+		t.Helper()
+		fakeClock := clock.NewFakeClock(start)
+		fakeClient := fake.NewFakeClientWithScheme(scheme,
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}})
+		store := reports.NewStore(fakeClient, scheme, fakeClock, reports.Compression{}, 0, 2, false, nil, "")
+		for i := 1; i <= 2; i++ {
+			err := store.SaveVulnerabilityReports(ctx, workload, "7f8b9c6d5", vulnerabilities.WorkloadVulnerabilities{
+				"nginx":   newVulnerabilityReport(newVulnerabilities(i)).Report,
+				"sidecar": newVulnerabilityReport(newVulnerabilities(i)).Report,
+			}, nil, nil, kube.ContainerImages{"nginx": digest})
+			require.NoError(t, err)
+			fakeClock.Step(time.Hour)
+		}
+		return store, fakeClient
+	}
+
+	t.Run("Should return current reports of containers", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		containerReports, err := store.GetContainerReports(ctx, workload)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]reports.ContainerReport{
+			"nginx":   {Hash: "7f8b9c6d5", Digest: digest},
+			"sidecar": {Hash: "7f8b9c6d5"},
+		}, containerReports, "History reports are skipped")
+	})
+
+	t.Run("Should update hash of reports of specified containers", func(t *testing.T) {
+		store, _ := newStore(t)
+
+		err := store.UpdateContainerReportsHash(ctx, workload, "5d4c3b2a1", []string{"nginx"})
+		require.NoError(t, err)
+
+		containerReports, err := store.GetContainerReports(ctx, workload)
+		require.NoError(t, err)
+		assert.Equal(t, "5d4c3b2a1", containerReports["nginx"].Hash)
+		assert.Equal(t, "7f8b9c6d5", containerReports["sidecar"].Hash)
+
+		updateTime, err := store.GetUpdateTime(ctx, workload, "5d4c3b2a1")
+		require.NoError(t, err)
+		assert.Equal(t, start.Add(time.Hour), updateTime, "Update time is preserved")
+	})
+
+	t.Run("Should delete reports of specified containers", func(t *testing.T) {
+		store, fakeClient := newStore(t)
+
+		err := store.DeleteContainerReports(ctx, workload, []string{"sidecar"})
+		require.NoError(t, err)
+
+		containerReports, err := store.GetContainerReports(ctx, workload)
+		require.NoError(t, err)
+		assert.Contains(t, containerReports, "nginx")
+		assert.NotContains(t, containerReports, "sidecar")
+
+		list := &starboardv1alpha1.VulnerabilityReportList{}
+		require.NoError(t, fakeClient.List(ctx, list, client.MatchingLabels{
+			kube.LabelContainerName: "sidecar",
+			etc.LabelReportHistory:  "true",
+		}))
+		assert.Len(t, list.Items, 1, "History reports are left intact")
+	})
+}
+
+func TestStore_SaveConfigAuditReport(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = starboardv1alpha1.AddToScheme(scheme)
+
+	ctx := context.Background()
+	workload := kube.Object{Kind: kube.KindPod, Name: "nginx", Namespace: "default"}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+	fakeClient := fake.NewFakeClientWithScheme(scheme, pod)
+	store := reports.NewStore(fakeClient, scheme, clock.RealClock{}, reports.Compression{}, 0, 0, false, nil, "")
+
+	report := starboardv1alpha1.ConfigAudit{
+		Summary: starboardv1alpha1.ConfigAuditSummary{DangerCount: 1},
+	}
+	err := store.SaveConfigAuditReport(ctx, workload, "7f8b9c6d5", report)
+	require.NoError(t, err)
+
+	stored := &starboardv1alpha1.ConfigAuditReport{}
+	err = fakeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "pod-nginx"}, stored)
+	require.NoError(t, err)
+	assert.Equal(t, "7f8b9c6d5", stored.Labels[etc.LabelPodSpecHash])
+	assert.Equal(t, report, stored.Report)
+
+	found, err := store.HasConfigAuditReport(ctx, workload, "7f8b9c6d5")
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	err = store.SaveConfigAuditReport(ctx, workload, "5c6d7f8b9", report)
+	require.NoError(t, err)
+
+	found, err = store.HasConfigAuditReport(ctx, workload, "7f8b9c6d5")
+	require.NoError(t, err)
+	assert.False(t, found)
+	found, err = store.HasConfigAuditReport(ctx, workload, "5c6d7f8b9")
+	require.NoError(t, err)
+	assert.True(t, found)
+}
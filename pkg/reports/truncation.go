@@ -0,0 +1,45 @@
+package reports
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+)
+
+// TruncateVulnerabilities keeps at most maxItems vulnerabilities of the specified report, starting with
+// the highest severities, and records the number of dropped vulnerabilities in the
+// etc.AnnotationTruncatedVulnerabilities annotation. The summary is left intact, so it still counts all
+// vulnerabilities. Returns true if vulnerabilities were truncated, false otherwise or when maxItems is 0.
+func TruncateVulnerabilities(report *starboardv1alpha1.VulnerabilityReport, maxItems int) bool {
+	delete(report.Annotations, etc.AnnotationTruncatedVulnerabilities)
+	if maxItems <= 0 || len(report.Report.Vulnerabilities) <= maxItems {
+		return false
+	}
+
+	// Do not modify the slice that might be shared with the scan result.
+	vulnerabilities := make([]starboardv1alpha1.Vulnerability, len(report.Report.Vulnerabilities))
+	copy(vulnerabilities, report.Report.Vulnerabilities)
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		return severityRank(vulnerabilities[i].Severity) < severityRank(vulnerabilities[j].Severity)
+	})
+
+	if report.Annotations == nil {
+		report.Annotations = make(map[string]string)
+	}
+	report.Annotations[etc.AnnotationTruncatedVulnerabilities] = strconv.Itoa(len(vulnerabilities) - maxItems)
+	report.Report.Vulnerabilities = vulnerabilities[:maxItems]
+	return true
+}
+
+// severityRank returns the position of the specified severity in severities ordered from
+// the highest to the lowest. Unrecognized severities rank the lowest.
+func severityRank(severity starboardv1alpha1.Severity) int {
+	for i, s := range severities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(severities)
+}
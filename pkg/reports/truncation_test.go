@@ -0,0 +1,65 @@
+package reports_test
+
+import (
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/reports"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMixedVulnerabilities returns vulnerabilities with severities cycling from the lowest to the highest.
+func newMixedVulnerabilities(count int) []starboardv1alpha1.Vulnerability {
+	mixed := []starboardv1alpha1.Severity{
+		starboardv1alpha1.SeverityUnknown,
+		starboardv1alpha1.SeverityLow,
+		starboardv1alpha1.SeverityMedium,
+		starboardv1alpha1.SeverityHigh,
+		starboardv1alpha1.SeverityCritical,
+	}
+	vulnerabilities := newVulnerabilities(count)
+	for i := range vulnerabilities {
+		vulnerabilities[i].Severity = mixed[i%len(mixed)]
+	}
+	return vulnerabilities
+}
+
+func TestTruncateVulnerabilities(t *testing.T) {
+	t.Run("Should keep vulnerabilities with the highest severities", func(t *testing.T) {
+		vulnerabilities := newMixedVulnerabilities(1000)
+		report := newVulnerabilityReport(vulnerabilities)
+		summary := report.Report.Summary
+
+		truncated := reports.TruncateVulnerabilities(report, 300)
+		assert.True(t, truncated)
+		assert.Equal(t, "700", report.Annotations[etc.AnnotationTruncatedVulnerabilities])
+		assert.Equal(t, summary, report.Report.Summary, "Summary counts all vulnerabilities")
+		assert.Len(t, report.Report.Vulnerabilities, 300)
+		for i, vulnerability := range report.Report.Vulnerabilities {
+			if i < 200 {
+				assert.Equal(t, starboardv1alpha1.SeverityCritical, vulnerability.Severity)
+			} else {
+				assert.Equal(t, starboardv1alpha1.SeverityHigh, vulnerability.Severity)
+			}
+		}
+		assert.Equal(t, "CVE-2020-0004", report.Report.Vulnerabilities[0].VulnerabilityID, "Order of the same severity is kept")
+		assert.Equal(t, starboardv1alpha1.SeverityUnknown, vulnerabilities[0].Severity, "Input is not modified")
+	})
+
+	t.Run("Should not truncate vulnerabilities within the maximum", func(t *testing.T) {
+		report := newVulnerabilityReport(newMixedVulnerabilities(10))
+		report.Annotations = map[string]string{etc.AnnotationTruncatedVulnerabilities: "5"}
+
+		assert.False(t, reports.TruncateVulnerabilities(report, 10))
+		assert.Len(t, report.Report.Vulnerabilities, 10)
+		assert.NotContains(t, report.Annotations, etc.AnnotationTruncatedVulnerabilities)
+	})
+
+	t.Run("Should not truncate vulnerabilities when maximum is not set", func(t *testing.T) {
+		report := newVulnerabilityReport(newMixedVulnerabilities(10))
+
+		assert.False(t, reports.TruncateVulnerabilities(report, 0))
+		assert.Len(t, report.Report.Vulnerabilities, 10)
+	})
+}
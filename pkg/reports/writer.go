@@ -0,0 +1,89 @@
+package reports
+
+import (
+	"context"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/sbom"
+	starboardv1alpha1 "github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/find/vulnerabilities"
+	"github.com/aquasecurity/starboard/pkg/kube"
+)
+
+// WorkloadReport represents the result of scanning images of a workload, which is written
+// by a Writer.
+type WorkloadReport struct {
+	// Hash is the hash of the scanned Pod spec.
+	Hash string
+	// Vulnerabilities maps container names to vulnerabilities found in their images.
+	Vulnerabilities vulnerabilities.WorkloadVulnerabilities
+	// InitContainers holds names of the scanned init containers.
+	InitContainers []string
+	// EphemeralContainers holds names of the scanned ephemeral containers.
+	EphemeralContainers []string
+	// Digests maps container names to digests of their images, if known.
+	Digests kube.ContainerImages
+}
+
+// Writer is the interface of report backends. The default backend is Store,
+// which writes VulnerabilityReport custom resources. Other backends, e.g. shipping
+// reports to an external system, can be plugged in by implementing this interface.
+type Writer interface {
+	// Write writes the report of the specified workload.
+	Write(ctx context.Context, workload kube.Object, report WorkloadReport) error
+
+	// HasReport checks whether there is a report of the specified workload written for the
+	// given Pod spec hash, which covers all the specified containers.
+	HasReport(ctx context.Context, workload kube.Object, hash string, containerImages kube.ContainerImages) (bool, error)
+
+	// GetUpdateTime returns the time when the report of the specified workload was last written,
+	// or the zero time if there is no report.
+	GetUpdateTime(ctx context.Context, workload kube.Object, hash string) (time.Time, error)
+}
+
+// SBOMWriter is the interface of backends which store SBOMs of images of scanned workloads.
+type SBOMWriter interface {
+	// WriteSBOM writes the SBOM of the image of the specified workload container.
+	WriteSBOM(ctx context.Context, workload kube.Object, hash, containerName string, format etc.SBOMFormat, document sbom.Document) error
+}
+
+// ContainerReport describes the current report of a workload container.
+type ContainerReport struct {
+	// Hash is the Pod spec hash which the report was written for.
+	Hash string
+	// Digest is the digest of the scanned image, if known.
+	Digest string
+}
+
+// IncrementalWriter is the interface of backends which update reports of a workload incrementally when its
+// Pod spec changes, so that images of unchanged containers are not scanned again.
+type IncrementalWriter interface {
+	// GetContainerReports returns current reports of containers of the specified workload written for any
+	// Pod spec hash, keyed by container names.
+	GetContainerReports(ctx context.Context, workload kube.Object) (map[string]ContainerReport, error)
+
+	// UpdateContainerReportsHash marks current reports of the specified containers as written for the given
+	// Pod spec hash.
+	UpdateContainerReportsHash(ctx context.Context, workload kube.Object, hash string, containers []string) error
+
+	// DeleteContainerReports deletes current reports of the specified containers.
+	DeleteContainerReports(ctx context.Context, workload kube.Object, containers []string) error
+}
+
+// DigestReport is the report of an image with a given digest, which can be shared by all workloads
+// running the image.
+type DigestReport struct {
+	// Report holds vulnerabilities found in the image.
+	Report starboardv1alpha1.VulnerabilityScanResult
+	// UpdatedAt is the time when the report was last written.
+	UpdatedAt time.Time
+}
+
+// DigestReader is the interface of backends which look up reports by image digests, so that an image
+// run by multiple workloads is scanned only once.
+type DigestReader interface {
+	// FindByDigest returns the most recently written report of the image with the specified digest,
+	// or nil if there is no such report.
+	FindByDigest(ctx context.Context, digest string) (*DigestReport, error)
+}
@@ -1,23 +1,103 @@
 package resources
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/google/go-containerregistry/pkg/name"
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons of waiting states of containers whose images the kubelet fails to pull.
+const (
+	ReasonErrImagePull     = "ErrImagePull"
+	ReasonImagePullBackOff = "ImagePullBackOff"
 )
 
+// GetContainerImagesFromPodSpec returns the mapping from a container name to its image reference
+// for init containers, containers, and ephemeral containers of the specified PodSpec.
 func GetContainerImagesFromPodSpec(spec corev1.PodSpec) kube.ContainerImages {
 	images := kube.ContainerImages{}
+	for _, container := range spec.InitContainers {
+		images[container.Name] = container.Image
+	}
 	for _, container := range spec.Containers {
 		images[container.Name] = container.Image
 	}
+	for _, container := range spec.EphemeralContainers {
+		images[container.Name] = container.Image
+	}
 	return images
 }
 
+// GetEphemeralContainersFromPodSpec returns ephemeral containers of the specified PodSpec as regular containers,
+// so that they can be scanned the same way. Fields of ephemeral containers are the same as fields of containers.
+func GetEphemeralContainersFromPodSpec(spec corev1.PodSpec) []corev1.Container {
+	var containers []corev1.Container
+	for _, container := range spec.EphemeralContainers {
+		containers = append(containers, corev1.Container(container.EphemeralContainerCommon))
+	}
+	return containers
+}
+
+// GetPodSpecWithoutEphemeralContainers returns a copy of the specified PodSpec without ephemeral containers.
+func GetPodSpecWithoutEphemeralContainers(spec corev1.PodSpec) corev1.PodSpec {
+	copied := *spec.DeepCopy()
+	copied.EphemeralContainers = nil
+	return copied
+}
+
+// GetInitContainerNamesFromPodSpec returns names of init containers of the specified PodSpec.
+func GetInitContainerNamesFromPodSpec(spec corev1.PodSpec) []string {
+	var names []string
+	for _, container := range spec.InitContainers {
+		names = append(names, container.Name)
+	}
+	return names
+}
+
+// GetEphemeralContainerNamesFromPodSpec returns names of ephemeral containers of the specified PodSpec.
+func GetEphemeralContainerNamesFromPodSpec(spec corev1.PodSpec) []string {
+	var names []string
+	for _, container := range spec.EphemeralContainers {
+		names = append(names, container.Name)
+	}
+	return names
+}
+
+// GetInitContainerNamesFromJob returns names of the scanned init containers
+// stored as the etc.AnnotationInitContainerNames annotation of the specified scan Job.
+func GetInitContainerNamesFromJob(job *batchv1.Job) []string {
+	value, ok := job.Annotations[etc.AnnotationInitContainerNames]
+	if !ok || value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// GetEphemeralContainerNamesFromJob returns names of the scanned ephemeral containers
+// stored as the etc.AnnotationEphemeralContainerNames annotation of the specified scan Job.
+func GetEphemeralContainerNamesFromJob(job *batchv1.Job) []string {
+	value, ok := job.Annotations[etc.AnnotationEphemeralContainerNames]
+	if !ok || value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
 func GetContainerImagesFromJob(job *batchv1.Job) (kube.ContainerImages, error) {
 	var containerImagesAsJSON string
 	var ok bool
@@ -33,6 +113,70 @@ func GetContainerImagesFromJob(job *batchv1.Job) (kube.ContainerImages, error) {
 	return containerImages, nil
 }
 
+// GetContainerImageIDsFromPodStatus returns the mapping from a container name to the ID of the image
+// it runs, as reported by the kubelet, for init containers, containers, and ephemeral containers of the specified
+// PodStatus. Containers without reported image IDs are omitted.
+func GetContainerImageIDsFromPodStatus(status corev1.PodStatus) map[string]string {
+	imageIDs := make(map[string]string)
+	for _, statuses := range [][]corev1.ContainerStatus{status.InitContainerStatuses, status.ContainerStatuses, status.EphemeralContainerStatuses} {
+		for _, containerStatus := range statuses {
+			if containerStatus.ImageID != "" {
+				imageIDs[containerStatus.Name] = containerStatus.ImageID
+			}
+		}
+	}
+	return imageIDs
+}
+
+// GetPinnedContainerImagesFromPodSpec returns the mapping from a container name to the image reference for
+// init containers, containers, and ephemeral containers of the specified PodSpec whose images are pinned by
+// digest, e.g. `nginx@sha256:4cd8...`. Such references identify images as well as image IDs reported by the kubelet.
+func GetPinnedContainerImagesFromPodSpec(spec corev1.PodSpec) map[string]string {
+	images := make(map[string]string)
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers, GetEphemeralContainersFromPodSpec(spec)} {
+		for _, container := range containers {
+			if _, err := name.NewDigest(container.Image); err == nil {
+				images[container.Name] = container.Image
+			}
+		}
+	}
+	return images
+}
+
+// HasOnlyPinnedImages checks whether images of all init containers, containers, and ephemeral containers of the
+// specified PodSpec are pinned by digest, in which case the Pod can be scanned without waiting for the kubelet to
+// pull images.
+func HasOnlyPinnedImages(spec corev1.PodSpec) bool {
+	return len(GetPinnedContainerImagesFromPodSpec(spec)) == len(spec.InitContainers)+len(spec.Containers)+len(spec.EphemeralContainers)
+}
+
+// GetDigestFromImageID returns the digest of an image ID reported by the kubelet, e.g.
+// `sha256:4cd8...` for `docker-pullable://nginx@sha256:4cd8...`. Returns a blank string
+// if the image ID does not refer to a repository digest, e.g. for locally built images.
+func GetDigestFromImageID(imageID string) string {
+	index := strings.LastIndex(imageID, "@")
+	if index == -1 {
+		return ""
+	}
+	return imageID[index+1:]
+}
+
+// GetContainerImageDigestsFromJob returns digests of scanned images stored as the
+// etc.AnnotationContainerImageDigests annotation of the specified scan Job. Returns
+// an empty mapping for scan Jobs created before digests were recorded.
+func GetContainerImageDigestsFromJob(job *batchv1.Job) (kube.ContainerImages, error) {
+	digests := kube.ContainerImages{}
+	value, ok := job.Annotations[etc.AnnotationContainerImageDigests]
+	if !ok {
+		return digests, nil
+	}
+	err := digests.FromJSON(value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing job annotation: %s: %w", etc.AnnotationContainerImageDigests, err)
+	}
+	return digests, nil
+}
+
 // HasContainersReadyCondition iterates conditions of the specified Pod to check
 // whether all containers in the Pod are ready.
 func HasContainersReadyCondition(pod *corev1.Pod) bool {
@@ -44,6 +188,46 @@ func HasContainersReadyCondition(pod *corev1.Pod) bool {
 	return false
 }
 
+// HasRunningContainers checks whether the specified Pod is running and all its containers are running and ready.
+// Pods whose containers crash on startup, e.g. in the CrashLoopBackOff state, do not have running containers.
+func HasRunningContainers(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || len(pod.Status.ContainerStatuses) < len(pod.Spec.Containers) {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running == nil || !status.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// GetImagesFailingToPull returns images of init containers, containers, and ephemeral containers of the specified
+// Pod spec which the kubelet fails to pull, i.e. whose statuses are waiting with the ErrImagePull or ImagePullBackOff
+// reason, in order of appearance without duplicates. Statuses of containers which are not in the Pod spec are ignored.
+func GetImagesFailingToPull(pod *corev1.Pod) []string {
+	failingContainers := make(map[string]bool)
+	for _, status := range append(append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...), pod.Status.EphemeralContainerStatuses...) {
+		if status.State.Waiting == nil {
+			continue
+		}
+		switch status.State.Waiting.Reason {
+		case ReasonErrImagePull, ReasonImagePullBackOff:
+			failingContainers[status.Name] = true
+		}
+	}
+	var failing []string
+	seen := make(map[string]bool)
+	for _, container := range append(append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...), GetEphemeralContainersFromPodSpec(pod.Spec)...) {
+		if !failingContainers[container.Name] || seen[container.Image] {
+			continue
+		}
+		seen[container.Image] = true
+		failing = append(failing, container.Image)
+	}
+	return failing
+}
+
 // GetImmediateOwnerReference returns the immediate owner of the specified Pod.
 // For example, for a Pod controlled by a Deployment it will return the active ReplicaSet object,
 // whereas for an unmanaged Pod the immediate owner is the Pod itself.
@@ -62,3 +246,85 @@ func GetImmediateOwnerReference(pod *corev1.Pod) kube.Object {
 		Name:      pod.Name,
 	}
 }
+
+// GetOwnerWorkload returns the top-level workload controlling the specified Pod, which VulnerabilityReports
+// are attached to. The immediate owner is returned for ReplicaSets, ReplicationControllers, StatefulSets,
+// DaemonSets, and Jobs, except for Jobs controlled by CronJobs, in which case the CronJob is returned
+// so that there's one report for all Jobs scheduled by the CronJob. ReplicaSets are not resolved to
+// Deployments as each rollout of a Deployment creates a ReplicaSet with a different Pod template.
+// For an unmanaged Pod the owner is the Pod itself.
+func GetOwnerWorkload(ctx context.Context, c client.Client, pod *corev1.Pod) (kube.Object, error) {
+	owner := GetImmediateOwnerReference(pod)
+	if owner.Kind != kube.KindJob {
+		return owner, nil
+	}
+	job := &batchv1.Job{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: owner.Namespace, Name: owner.Name}, job)
+	if err != nil && errors.IsNotFound(err) {
+		return owner, nil
+	} else if err != nil {
+		return kube.Object{}, fmt.Errorf("getting job %s/%s: %w", owner.Namespace, owner.Name, err)
+	}
+	if ownerRef := metav1.GetControllerOf(job); ownerRef != nil && ownerRef.Kind == string(kube.KindCronJob) {
+		return kube.Object{
+			Namespace: job.Namespace,
+			Kind:      kube.KindCronJob,
+			Name:      ownerRef.Name,
+		}, nil
+	}
+	return owner, nil
+}
+
+// GetRuntimeObjectFor returns the Kubernetes object represented by the specified workload.
+func GetRuntimeObjectFor(ctx context.Context, c client.Client, workload kube.Object) (metav1.Object, error) {
+	var obj runtime.Object
+	switch workload.Kind {
+	case kube.KindPod:
+		obj = &corev1.Pod{}
+	case kube.KindReplicaSet:
+		obj = &appsv1.ReplicaSet{}
+	case kube.KindReplicationController:
+		obj = &corev1.ReplicationController{}
+	case kube.KindDeployment:
+		obj = &appsv1.Deployment{}
+	case kube.KindStatefulSet:
+		obj = &appsv1.StatefulSet{}
+	case kube.KindDaemonSet:
+		obj = &appsv1.DaemonSet{}
+	case kube.KindCronJob:
+		obj = &batchv1beta1.CronJob{}
+	case kube.KindJob:
+		obj = &batchv1.Job{}
+	default:
+		return nil, fmt.Errorf("unknown workload kind: %s", workload.Kind)
+	}
+	err := c.Get(ctx, types.NamespacedName{Name: workload.Name, Namespace: workload.Namespace}, obj)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(metav1.Object), nil
+}
+
+// SetWorkloadAnnotation sets the annotation of the specified workload to the given value, or removes
+// the annotation if the value is blank. The workload is not patched if the annotation is up to date.
+func SetWorkloadAnnotation(ctx context.Context, c client.Client, workload kube.Object, key, value string) error {
+	obj, err := GetRuntimeObjectFor(ctx, c, workload)
+	if err != nil {
+		return err
+	}
+	if current, ok := obj.GetAnnotations()[key]; current == value || (!ok && value == "") {
+		return nil
+	}
+	patch := client.MergeFrom(obj.(runtime.Object).DeepCopyObject())
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if value == "" {
+		delete(annotations, key)
+	} else {
+		annotations[key] = value
+	}
+	obj.SetAnnotations(annotations)
+	return c.Patch(ctx, obj.(runtime.Object), patch)
+}
@@ -0,0 +1,261 @@
+package resources_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/resources"
+	"github.com/aquasecurity/starboard/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newControllerRef(apiVersion, kind, name string) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		Controller: pointer.BoolPtr(true),
+	}
+}
+
+func TestGetOwnerWorkload(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ownerRefs     []metav1.OwnerReference
+		objects       []runtime.Object
+		expectedOwner kube.Object
+	}{
+		{
+			name:          "Should return Pod without owner",
+			expectedOwner: kube.Object{Namespace: "default", Kind: kube.KindPod, Name: "my-pod"},
+		},
+		{
+			name:          "Should return ReplicaSet",
+			ownerRefs:     []metav1.OwnerReference{newControllerRef("apps/v1", "ReplicaSet", "nginx-6d4cf56db6")},
+			expectedOwner: kube.Object{Namespace: "default", Kind: kube.KindReplicaSet, Name: "nginx-6d4cf56db6"},
+		},
+		{
+			name:          "Should return StatefulSet",
+			ownerRefs:     []metav1.OwnerReference{newControllerRef("apps/v1", "StatefulSet", "redis")},
+			expectedOwner: kube.Object{Namespace: "default", Kind: kube.KindStatefulSet, Name: "redis"},
+		},
+		{
+			name:          "Should return DaemonSet",
+			ownerRefs:     []metav1.OwnerReference{newControllerRef("apps/v1", "DaemonSet", "fluentd")},
+			expectedOwner: kube.Object{Namespace: "default", Kind: kube.KindDaemonSet, Name: "fluentd"},
+		},
+		{
+			name:      "Should return Job without owner",
+			ownerRefs: []metav1.OwnerReference{newControllerRef("batch/v1", "Job", "pi")},
+			objects: []runtime.Object{&batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pi"},
+			}},
+			expectedOwner: kube.Object{Namespace: "default", Kind: kube.KindJob, Name: "pi"},
+		},
+		{
+			name:      "Should return CronJob controlling Job",
+			ownerRefs: []metav1.OwnerReference{newControllerRef("batch/v1", "Job", "hello-1603791600")},
+			objects: []runtime.Object{&batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       "default",
+					Name:            "hello-1603791600",
+					OwnerReferences: []metav1.OwnerReference{newControllerRef("batch/v1beta1", "CronJob", "hello")},
+				},
+			}},
+			expectedOwner: kube.Object{Namespace: "default", Kind: kube.KindCronJob, Name: "hello"},
+		},
+		{
+			name:          "Should return Job when it's not found",
+			ownerRefs:     []metav1.OwnerReference{newControllerRef("batch/v1", "Job", "hello-1603791600")},
+			expectedOwner: kube.Object{Namespace: "default", Kind: kube.KindJob, Name: "hello-1603791600"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, corev1.AddToScheme(scheme))
+			require.NoError(t, batchv1.AddToScheme(scheme))
+			c := fake.NewFakeClientWithScheme(scheme, tc.objects...)
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       "default",
+					Name:            "my-pod",
+					OwnerReferences: tc.ownerRefs,
+				},
+			}
+
+			owner, err := resources.GetOwnerWorkload(context.Background(), c, pod)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOwner, owner)
+		})
+	}
+}
+
+func TestGetPinnedContainerImagesFromPodSpec(t *testing.T) {
+	const digest = "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c"
+
+	testCases := []struct {
+		name               string
+		spec               corev1.PodSpec
+		expectedImages     map[string]string
+		expectedOnlyPinned bool
+	}{
+		{
+			name: "Should return images referenced by digest",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init", Image: "busybox@" + digest}},
+				Containers:     []corev1.Container{{Name: "nginx", Image: "docker.io/library/nginx@" + digest}},
+			},
+			expectedImages: map[string]string{
+				"init":  "busybox@" + digest,
+				"nginx": "docker.io/library/nginx@" + digest,
+			},
+			expectedOnlyPinned: true,
+		},
+		{
+			name: "Should not return images referenced by tag",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "nginx", Image: "nginx@" + digest},
+					{Name: "redis", Image: "redis:5"},
+					{Name: "mysql", Image: "mysql"},
+				},
+			},
+			expectedImages: map[string]string{
+				"nginx": "nginx@" + digest,
+			},
+			expectedOnlyPinned: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedImages, resources.GetPinnedContainerImagesFromPodSpec(tc.spec))
+			assert.Equal(t, tc.expectedOnlyPinned, resources.HasOnlyPinnedImages(tc.spec))
+		})
+	}
+}
+
+func TestGetEphemeralContainersFromPodSpec(t *testing.T) {
+	spec := corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "nginx", Image: "nginx:1.16"}},
+		EphemeralContainers: []corev1.EphemeralContainer{
+			{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:  "debugger",
+					Image: "busybox:1.32",
+					Stdin: true,
+				},
+				TargetContainerName: "nginx",
+			},
+		},
+	}
+
+	assert.Equal(t, []corev1.Container{
+		{Name: "debugger", Image: "busybox:1.32", Stdin: true},
+	}, resources.GetEphemeralContainersFromPodSpec(spec))
+	assert.Equal(t, []string{"debugger"}, resources.GetEphemeralContainerNamesFromPodSpec(spec))
+	assert.Equal(t, kube.ContainerImages{
+		"nginx":    "nginx:1.16",
+		"debugger": "busybox:1.32",
+	}, resources.GetContainerImagesFromPodSpec(spec))
+
+	withoutEphemeralContainers := resources.GetPodSpecWithoutEphemeralContainers(spec)
+	assert.Nil(t, withoutEphemeralContainers.EphemeralContainers)
+	assert.Equal(t, spec.Containers, withoutEphemeralContainers.Containers)
+	assert.Len(t, spec.EphemeralContainers, 1, "the specified PodSpec must not be modified")
+}
+
+func TestGetDigestFromImageID(t *testing.T) {
+	testCases := []struct {
+		name           string
+		imageID        string
+		expectedDigest string
+	}{
+		{
+			name:           "Should return digest of Docker image ID",
+			imageID:        "docker-pullable://nginx@sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c",
+			expectedDigest: "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c",
+		},
+		{
+			name:           "Should return digest of containerd image ID",
+			imageID:        "docker.io/library/nginx@sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c",
+			expectedDigest: "sha256:d20aa6d1cae56fd17cd458f4807e0de462caf2336f0b70b5eeb69fcaaf30dd9c",
+		},
+		{
+			name:           "Should return blank digest of locally built image",
+			imageID:        "docker://sha256:0e5dfb4b4b2c0f8e0c2b5d5b3c6a0d1c9f1b5a0e2d3c4b5a6f7e8d9c0b1a2f3e",
+			expectedDigest: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedDigest, resources.GetDigestFromImageID(tc.imageID))
+		})
+	}
+}
+
+func TestGetImagesFailingToPull(t *testing.T) {
+	waiting := func(name, reason string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name:  name,
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+		}
+	}
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.32"}},
+		Containers: []corev1.Container{
+			{Name: "nginx", Image: "private.registry/nginx:1.16"},
+			{Name: "sidecar", Image: "private.registry/nginx:1.16"},
+			{Name: "redis", Image: "redis:5"},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		status         corev1.PodStatus
+		expectedImages []string
+	}{
+		{
+			name: "Should return images in ImagePullBackOff or ErrImagePull",
+			status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{waiting("init", "ErrImagePull")},
+				ContainerStatuses: []corev1.ContainerStatus{
+					waiting("nginx", "ImagePullBackOff"),
+					waiting("sidecar", "ImagePullBackOff"),
+					waiting("redis", "ContainerCreating"),
+				},
+			},
+			expectedImages: []string{"busybox:1.32", "private.registry/nginx:1.16"},
+		},
+		{
+			name: "Should not return images of containers waiting for other reasons",
+			status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					waiting("nginx", "CrashLoopBackOff"),
+					{Name: "redis", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				},
+			},
+		},
+		{
+			name: "Should ignore statuses of containers not in spec",
+			status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{waiting("debug", "ImagePullBackOff")},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: spec, Status: tc.status}
+			assert.Equal(t, tc.expectedImages, resources.GetImagesFailingToPull(pod))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// bomFormatCycloneDX is the value of the bomFormat property of CycloneDX documents.
+const bomFormatCycloneDX = "CycloneDX"
+
+// BOM represents the CycloneDX JSON document printed by `trivy --format cyclonedx`.
+// Only properties used by the operator are decoded.
+type BOM struct {
+	BOMFormat    string      `json:"bomFormat"`
+	SpecVersion  string      `json:"specVersion"`
+	SerialNumber string      `json:"serialNumber"`
+	Version      int         `json:"version"`
+	Metadata     Metadata    `json:"metadata"`
+	Components   []Component `json:"components"`
+}
+
+type Metadata struct {
+	Timestamp string     `json:"timestamp"`
+	Component *Component `json:"component"`
+}
+
+type Component struct {
+	BOMRef  string `json:"bom-ref"`
+	Type    string `json:"type"` // e.g. application, library, operating-system
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	PURL    string `json:"purl"`
+}
+
+// Document is an SBOM of an image in its original encoding along with the decoded BOM.
+type Document struct {
+	BOM BOM
+	Raw []byte
+}
+
+// ParseCycloneDX reads and decodes the CycloneDX JSON document from the specified reader.
+func ParseCycloneDX(reader io.Reader) (Document, error) {
+	raw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return Document{}, fmt.Errorf("reading cyclonedx document: %w", err)
+	}
+	var bom BOM
+	err = json.Unmarshal(raw, &bom)
+	if err != nil {
+		return Document{}, fmt.Errorf("decoding cyclonedx document: %w", err)
+	}
+	if bom.BOMFormat != bomFormatCycloneDX {
+		return Document{}, fmt.Errorf("expected bomFormat %s, but got %q", bomFormatCycloneDX, bom.BOMFormat)
+	}
+	return Document{BOM: bom, Raw: raw}, nil
+}
@@ -0,0 +1,65 @@
+package sbom_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCycloneDX(t *testing.T) {
+	t.Run("Should parse CycloneDX document", func(t *testing.T) {
+		file, err := os.Open("testdata/bom.json")
+		require.NoError(t, err)
+		defer func() {
+			_ = file.Close()
+		}()
+
+		document, err := sbom.ParseCycloneDX(file)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1.3", document.BOM.SpecVersion)
+		require.NotNil(t, document.BOM.Metadata.Component)
+		assert.Equal(t, "nginx:1.16", document.BOM.Metadata.Component.Name)
+		assert.Equal(t, []sbom.Component{
+			{
+				BOMRef:  "1b5e6c2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b",
+				Type:    "operating-system",
+				Name:    "debian",
+				Version: "10.3",
+			},
+			{
+				BOMRef:  "pkg:deb/debian/libc6@2.28-10?distro=debian-10.3",
+				Type:    "library",
+				Name:    "libc6",
+				Version: "2.28-10",
+				PURL:    "pkg:deb/debian/libc6@2.28-10?distro=debian-10.3",
+			},
+			{
+				BOMRef:  "pkg:deb/debian/openssl@1.1.1d-0+deb10u2?distro=debian-10.3",
+				Type:    "library",
+				Name:    "openssl",
+				Version: "1.1.1d-0+deb10u2",
+				PURL:    "pkg:deb/debian/openssl@1.1.1d-0+deb10u2?distro=debian-10.3",
+			},
+		}, document.BOM.Components)
+
+		raw, err := ioutil.ReadFile("testdata/bom.json")
+		require.NoError(t, err)
+		assert.Equal(t, raw, document.Raw)
+	})
+
+	t.Run("Should return error when document is not CycloneDX", func(t *testing.T) {
+		_, err := sbom.ParseCycloneDX(strings.NewReader(`{"spdxVersion":"SPDX-2.2"}`))
+		assert.EqualError(t, err, `expected bomFormat CycloneDX, but got ""`)
+	})
+
+	t.Run("Should return error when document is malformed", func(t *testing.T) {
+		_, err := sbom.ParseCycloneDX(strings.NewReader(`{"bomFormat":`))
+		assert.EqualError(t, err, "decoding cyclonedx document: unexpected end of JSON input")
+	})
+}
@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+)
+
+// Factory constructs a VulnerabilityScanner with the specified configuration of the operator.
+type Factory func(config etc.Config, version etc.VersionInfo) VulnerabilityScanner
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes the VulnerabilityScanner constructed by the specified factory available by the given name.
+// It's meant to be called from the init function of the package implementing the scanner, and panics if
+// the name is registered twice.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("vulnerability scanner %q is already registered", name))
+	}
+	registry[name] = factory
+}
+
+// Registered returns sorted names of registered vulnerability scanners.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New constructs the registered VulnerabilityScanner with the specified name.
+func New(name string, config etc.Config, version etc.VersionInfo) (VulnerabilityScanner, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown vulnerability scanner %q, registered scanners: %s", name,
+			strings.Join(Registered(), ", "))
+	}
+	return factory(config, version), nil
+}
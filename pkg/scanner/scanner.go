@@ -1,13 +1,19 @@
 package scanner
 
 import (
+	"fmt"
 	"io"
 	"time"
 
+	"github.com/aquasecurity/starboard-operator/pkg/docker"
+	"github.com/aquasecurity/starboard-operator/pkg/resources"
+
 	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/kube"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // Options are arguments passed to VulnerabilityScanner.NewScanJob constructor.
@@ -16,8 +22,200 @@ type Options struct {
 	Namespace string
 	// ServiceAccountName the name of the Service Account to run the Pod controlled by the scan Job.
 	ServiceAccountName string
+	// PriorityClassName the name of the PriorityClass of the Pod controlled by the scan Job.
+	PriorityClassName string
 	// ScanJobTimeout scan job timeout.
 	ScanJobTimeout time.Duration
+	// ScanJobBackoffLimit the number of retries of the Pod controlled by the scan Job before the Job is failed.
+	ScanJobBackoffLimit int32
+	// ScanJobTTLSecondsAfterFinished the number of seconds after which the finished scan Job is deleted by
+	// the TTL controller of Kubernetes. The scan Job is not deleted by the TTL controller if nil.
+	ScanJobTTLSecondsAfterFinished *int32
+	// ScanJobResources compute resources required by containers of the scan Job.
+	ScanJobResources corev1.ResourceRequirements
+	// RegistryCredentialsSecret the name of the Secret holding registry credentials for container images.
+	// The Secret has the `<container>.username` and `<container>.password` keys for each container
+	// listed in RegistryCredentials.
+	RegistryCredentialsSecret string
+	// RegistryCredentials maps container names to registry credentials for their images.
+	RegistryCredentials map[string]docker.Auth
+	// ScanJobNodeSelector node selector of the Pod controlled by the scan Job.
+	ScanJobNodeSelector map[string]string
+	// ScanJobTolerations tolerations of the Pod controlled by the scan Job.
+	ScanJobTolerations []corev1.Toleration
+	// ScanJobAffinity affinity of the Pod controlled by the scan Job.
+	ScanJobAffinity *corev1.Affinity
+	// ScanJobHostAliases host aliases added to the hosts file of the Pod controlled by the scan Job.
+	ScanJobHostAliases []corev1.HostAlias
+	// ScanJobDNSConfig DNS config of the Pod controlled by the scan Job.
+	ScanJobDNSConfig *corev1.PodDNSConfig
+	// Severities of vulnerabilities to be reported. Empty means all severities.
+	Severities []v1alpha1.Severity
+	// ScanJobHTTPProxy the URL of the proxy for HTTP requests sent by containers of the scan Job.
+	ScanJobHTTPProxy string
+	// ScanJobHTTPSProxy the URL of the proxy for HTTPS requests sent by containers of the scan Job.
+	ScanJobHTTPSProxy string
+	// ScanJobNoProxy comma separated hosts excluded from proxying.
+	ScanJobNoProxy string
+	// ScanJobEnvFrom sources of environment variables, i.e. ConfigMaps and Secrets, of containers of the
+	// scan Job which run the scanner. Variables set by the scanner itself take precedence over them.
+	ScanJobEnvFrom []corev1.EnvFromSource
+	// ScanJobVolumes additional volumes of the Pod controlled by the scan Job.
+	ScanJobVolumes []corev1.Volume
+	// ScanJobVolumeMounts additional volume mounts of containers of the scan Job which run the scanner.
+	// They refer to ScanJobVolumes.
+	ScanJobVolumeMounts []corev1.VolumeMount
+	// ScanJobImagePullPolicy the pull policy of scanner images run by containers of the scan Job.
+	ScanJobImagePullPolicy corev1.PullPolicy
+	// ScanJobCACertConfigMap the name of the ConfigMap in the operator namespace holding additional
+	// CA certificates trusted by containers of the scan Job.
+	ScanJobCACertConfigMap string
+	// IgnoreFileConfigMap the name of the ConfigMap in the operator namespace holding the `.trivyignore`
+	// file with IDs of vulnerabilities excluded from reports. It applies only to the Trivy scanner.
+	IgnoreFileConfigMap string
+	// ScanJobPodSecurityContext the security context of the Pod controlled by the scan Job.
+	ScanJobPodSecurityContext *corev1.PodSecurityContext
+	// ScanJobSecurityContext the security context of containers of the scan Job.
+	ScanJobSecurityContext *corev1.SecurityContext
+}
+
+// GetContainersToScan returns init containers, containers, and ephemeral containers of the
+// specified PodSpec whose images should be scanned. Containers that refer to an image already returned
+// for another container are skipped, so that each image is scanned only once.
+func GetContainersToScan(spec corev1.PodSpec) []corev1.Container {
+	var containers []corev1.Container
+	images := make(map[string]bool)
+	for _, container := range append(append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...),
+		resources.GetEphemeralContainersFromPodSpec(spec)...) {
+		if images[container.Image] {
+			continue
+		}
+		images[container.Image] = true
+		containers = append(containers, container)
+	}
+	return containers
+}
+
+// GetSBOMContainerName returns the name of the scan Job container which generates
+// the SBOM of the image scanned by the specified scan Job container.
+func GetSBOMContainerName(container string) string {
+	name := "sbom-" + container
+	if len(name) > validation.DNS1123LabelMaxLength {
+		name = name[:validation.DNS1123LabelMaxLength]
+	}
+	return name
+}
+
+// GetRegistryCredentialsSecretKeys returns the username and password keys of
+// the RegistryCredentialsSecret for the specified container.
+func GetRegistryCredentialsSecretKeys(container string) (string, string) {
+	return fmt.Sprintf("%s.username", container), fmt.Sprintf("%s.password", container)
+}
+
+// NewRegistryCredentialsEnvVars returns environment variables with registry credentials
+// for the specified container, which are sourced from the RegistryCredentialsSecret.
+// Returns nil if there are no credentials for the container.
+func NewRegistryCredentialsEnvVars(options Options, container, usernameEnvName, passwordEnvName string) []corev1.EnvVar {
+	if _, ok := options.RegistryCredentials[container]; !ok {
+		return nil
+	}
+	usernameKey, passwordKey := GetRegistryCredentialsSecretKeys(container)
+	return []corev1.EnvVar{
+		{
+			Name: usernameEnvName,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: options.RegistryCredentialsSecret,
+					},
+					Key: usernameKey,
+				},
+			},
+		},
+		{
+			Name: passwordEnvName,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: options.RegistryCredentialsSecret,
+					},
+					Key: passwordKey,
+				},
+			},
+		},
+	}
+}
+
+// NewProxyEnvVars returns the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables
+// for containers of a scan Job. Blank values in Options are omitted.
+func NewProxyEnvVars(options Options) []corev1.EnvVar {
+	var envs []corev1.EnvVar
+	for _, env := range []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: options.ScanJobHTTPProxy},
+		{Name: "HTTPS_PROXY", Value: options.ScanJobHTTPSProxy},
+		{Name: "NO_PROXY", Value: options.ScanJobNoProxy},
+	} {
+		if env.Value != "" {
+			envs = append(envs, env)
+		}
+	}
+	return envs
+}
+
+const (
+	// caCertVolumeName is the name of the scan Job volume holding additional CA certificates.
+	caCertVolumeName = "ca-certs"
+	// caCertMountPath is the path where additional CA certificates are mounted in scan Job containers.
+	caCertMountPath = "/etc/starboard/ca-certs"
+	// systemCertDir is the directory of CA certificates shipped with scanner images.
+	systemCertDir = "/etc/ssl/certs"
+)
+
+// NewCACertVolumes returns the volume of the CA certificates ConfigMap specified in Options,
+// or nil if it's not set.
+func NewCACertVolumes(options Options) []corev1.Volume {
+	if options.ScanJobCACertConfigMap == "" {
+		return nil
+	}
+	return []corev1.Volume{
+		{
+			Name: caCertVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: options.ScanJobCACertConfigMap,
+					},
+				},
+			},
+		},
+	}
+}
+
+// NewCACertVolumeMounts returns the read-only mount of the volume returned by NewCACertVolumes,
+// or nil if the CA certificates ConfigMap is not set.
+func NewCACertVolumeMounts(options Options) []corev1.VolumeMount {
+	if options.ScanJobCACertConfigMap == "" {
+		return nil
+	}
+	return []corev1.VolumeMount{
+		{
+			Name:      caCertVolumeName,
+			ReadOnly:  true,
+			MountPath: caCertMountPath,
+		},
+	}
+}
+
+// NewCACertEnvVars returns the SSL_CERT_DIR environment variable, which makes scanners trust
+// both system CA certificates and the ones mounted by NewCACertVolumeMounts. Returns nil if
+// the CA certificates ConfigMap is not set.
+func NewCACertEnvVars(options Options) []corev1.EnvVar {
+	if options.ScanJobCACertConfigMap == "" {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: "SSL_CERT_DIR", Value: systemCertDir + ":" + caCertMountPath},
+	}
 }
 
 type JobMeta struct {
@@ -27,10 +225,28 @@ type JobMeta struct {
 
 // VulnerabilityScanner defines vulnerability scanner interface.
 //
+// GetName returns the name of the scanner, which is used to label metrics and logs.
+//
 // NewScanJob constructs a new Job descriptor, which can be sent to Kubernetes API and scheduled to scan
 // the specified Kubernetes workload with the given Pod descriptor and Options.
 //
 type VulnerabilityScanner interface {
+	GetName() string
 	NewScanJob(meta JobMeta, options Options, spec corev1.PodSpec) (*batchv1.Job, error)
 	ParseVulnerabilityScanResult(imageRef string, logsReader io.ReadCloser) (v1alpha1.VulnerabilityScanResult, error)
 }
+
+// ConfigAuditScanner defines configuration audit scanner interface.
+//
+// GetName returns the name of the scanner, which is used in logs.
+//
+// NewConfigAuditJob constructs a new Job descriptor, which can be sent to Kubernetes API and scheduled to audit
+// the configuration of the specified Kubernetes workload with the given Options.
+//
+// ParseConfigAuditResult converts logs of the container returned by GetContainerName to the ConfigAudit report.
+type ConfigAuditScanner interface {
+	GetName() string
+	GetContainerName() string
+	NewConfigAuditJob(meta JobMeta, options Options, workload kube.Object) (*batchv1.Job, error)
+	ParseConfigAuditResult(logsReader io.ReadCloser) (v1alpha1.ConfigAudit, error)
+}
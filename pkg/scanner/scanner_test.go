@@ -0,0 +1,105 @@
+package scanner_test
+
+import (
+	"testing"
+
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetContainersToScan(t *testing.T) {
+	testCases := []struct {
+		name               string
+		spec               corev1.PodSpec
+		expectedContainers []string
+	}{
+		{
+			name: "Should return containers",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "nginx", Image: "nginx:1.16"},
+					{Name: "sidecar", Image: "busybox:1.32"},
+				},
+			},
+			expectedContainers: []string{"nginx", "sidecar"},
+		},
+		{
+			name: "Should return init containers followed by containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: "migrate", Image: "flyway/flyway:7.0"},
+				},
+				Containers: []corev1.Container{
+					{Name: "nginx", Image: "nginx:1.16"},
+				},
+			},
+			expectedContainers: []string{"migrate", "nginx"},
+		},
+		{
+			name: "Should skip containers with images of init containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: "init", Image: "busybox:1.32"},
+				},
+				Containers: []corev1.Container{
+					{Name: "nginx", Image: "nginx:1.16"},
+					{Name: "sidecar", Image: "busybox:1.32"},
+					{Name: "proxy", Image: "nginx:1.16"},
+				},
+			},
+			expectedContainers: []string{"init", "nginx"},
+		},
+		{
+			name: "Should return ephemeral containers after containers",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "nginx", Image: "nginx:1.16"},
+				},
+				EphemeralContainers: []corev1.EphemeralContainer{
+					{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox:1.32"}},
+					{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger-nginx", Image: "nginx:1.16"}},
+				},
+			},
+			expectedContainers: []string{"nginx", "debugger"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var names []string
+			for _, container := range scanner.GetContainersToScan(tc.spec) {
+				names = append(names, container.Name)
+			}
+			assert.Equal(t, tc.expectedContainers, names)
+		})
+	}
+}
+
+func TestNewProxyEnvVars(t *testing.T) {
+	testCases := []struct {
+		name         string
+		options      scanner.Options
+		expectedEnvs []corev1.EnvVar
+	}{
+		{
+			name:    "Should return nil without proxy",
+			options: scanner.Options{},
+		},
+		{
+			name: "Should omit blank values",
+			options: scanner.Options{
+				ScanJobHTTPSProxy: "http://proxy.corp:3128",
+			},
+			expectedEnvs: []corev1.EnvVar{
+				{Name: "HTTPS_PROXY", Value: "http://proxy.corp:3128"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedEnvs, scanner.NewProxyEnvVars(tc.options))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package scout
+
+// ScanReport represents the SARIF document printed by `docker-scout cves --format sarif`.
+type ScanReport struct {
+	Runs []Run `json:"runs"`
+}
+
+type Run struct {
+	Tool Tool `json:"tool"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name    string `json:"name"`    // e.g. docker scout
+	Version string `json:"version"` // e.g. 1.0.9
+	Rules   []Rule `json:"rules"`
+}
+
+// Rule describes a vulnerability and the packages affected by it.
+type Rule struct {
+	ID               string         `json:"id"` // e.g. CVE-2020-3910
+	ShortDescription Message        `json:"shortDescription"`
+	Help             Message        `json:"help"`
+	HelpURI          string         `json:"helpUri"`
+	Properties       RuleProperties `json:"properties"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type RuleProperties struct {
+	AffectedVersion string   `json:"affected_version"` // e.g. <1.1.1n-0+deb11u4
+	FixedVersion    string   `json:"fixed_version"`    // e.g. 1.1.1n-0+deb11u4, or "not fixed"
+	CVSSv3Severity  string   `json:"cvssV3_severity"`  // e.g. HIGH
+	PURLs           []string `json:"purls"`            // e.g. pkg:deb/debian/openssl@1.1.1n-0+deb11u3?os_distro=bullseye
+	Tags            []string `json:"tags"`             // e.g. HIGH
+}
@@ -0,0 +1,309 @@
+package scout
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/aquasecurity/starboard/pkg/scanners"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/uuid"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	cacheDir = "/var/lib/docker-scout"
+)
+
+// Keys of the Secret set as OPERATOR_SCANNER_SCOUT_TOKEN_SECRET, which hold the Docker Hub user and its
+// personal access token used by Docker Scout.
+const (
+	SecretKeyHubUser     = "DOCKER_SCOUT_HUB_USER"
+	SecretKeyHubPassword = "DOCKER_SCOUT_HUB_PASSWORD"
+)
+
+func init() {
+	scanner.Register(etc.ScannerNameScout, func(config etc.Config, _ etc.VersionInfo) scanner.VulnerabilityScanner {
+		return NewScanner(config.ScannerScout)
+	})
+}
+
+type scoutScanner struct {
+	config etc.ScannerScout
+}
+
+func NewScanner(config etc.ScannerScout) scanner.VulnerabilityScanner {
+	return &scoutScanner{
+		config: config,
+	}
+}
+
+func (s *scoutScanner) GetName() string {
+	return "Docker Scout"
+}
+
+func (s *scoutScanner) NewScanJob(meta scanner.JobMeta, options scanner.Options, spec corev1.PodSpec) (*batchv1.Job, error) {
+	jobName := uuid.New().String()
+
+	containers := scanner.GetContainersToScan(spec)
+	scanJobContainers := make([]corev1.Container, len(containers))
+	for i, c := range containers {
+		scanJobContainers[i] = corev1.Container{
+			Name:                     c.Name,
+			Image:                    s.config.ImageRef,
+			ImagePullPolicy:          options.ScanJobImagePullPolicy,
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			SecurityContext:          options.ScanJobSecurityContext.DeepCopy(),
+			Env: append(s.newEnvVars(options),
+				scanner.NewRegistryCredentialsEnvVars(options, c.Name, "DOCKER_SCOUT_REGISTRY_USER", "DOCKER_SCOUT_REGISTRY_PASSWORD")...),
+			EnvFrom: options.ScanJobEnvFrom,
+			Command: []string{
+				"/docker-scout",
+			},
+			Args: []string{
+				"cves",
+				"--format",
+				"sarif",
+				// Pull the image from the registry, as there's no Docker daemon in scan Jobs.
+				"registry://" + c.Image,
+			},
+			Resources: options.ScanJobResources,
+			VolumeMounts: append(append([]corev1.VolumeMount{
+				{
+					Name:      "cache",
+					ReadOnly:  false,
+					MountPath: cacheDir,
+				},
+			}, scanner.NewCACertVolumeMounts(options)...), options.ScanJobVolumeMounts...),
+		}
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        jobName,
+			Namespace:   options.Namespace,
+			Labels:      meta.Labels,
+			Annotations: meta.Annotations,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            pointer.Int32Ptr(options.ScanJobBackoffLimit),
+			Completions:             pointer.Int32Ptr(1),
+			ActiveDeadlineSeconds:   scanners.GetActiveDeadlineSeconds(options.ScanJobTimeout),
+			TTLSecondsAfterFinished: options.ScanJobTTLSecondsAfterFinished,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      meta.Labels,
+					Annotations: meta.Annotations,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					ServiceAccountName:           options.ServiceAccountName,
+					PriorityClassName:            options.PriorityClassName,
+					AutomountServiceAccountToken: pointer.BoolPtr(false),
+					NodeSelector:                 options.ScanJobNodeSelector,
+					Tolerations:                  options.ScanJobTolerations,
+					Affinity:                     options.ScanJobAffinity,
+					HostAliases:                  options.ScanJobHostAliases,
+					DNSConfig:                    options.ScanJobDNSConfig,
+					SecurityContext:              options.ScanJobPodSecurityContext,
+					Volumes: append(append([]corev1.Volume{
+						{
+							Name: "cache",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{
+									Medium: corev1.StorageMediumDefault,
+								},
+							},
+						},
+					}, scanner.NewCACertVolumes(options)...), options.ScanJobVolumes...),
+					Containers: scanJobContainers,
+				},
+			},
+		},
+	}, nil
+}
+
+// newEnvVars returns environment variables of scan containers. The Docker Hub credentials are sourced from the
+// token Secret with secretKeyRef, so that they never appear in specs of scan Jobs.
+func (s *scoutScanner) newEnvVars(options scanner.Options) []corev1.EnvVar {
+	envs := []corev1.EnvVar{
+		{
+			Name:  "DOCKER_SCOUT_CACHE_DIR",
+			Value: cacheDir,
+		},
+	}
+	for _, key := range []string{SecretKeyHubUser, SecretKeyHubPassword} {
+		envs = append(envs, corev1.EnvVar{
+			Name: key,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: s.config.TokenSecret,
+					},
+					Key: key,
+				},
+			},
+		})
+	}
+	return append(envs, append(scanner.NewProxyEnvVars(options), scanner.NewCACertEnvVars(options)...)...)
+}
+
+func (s *scoutScanner) ParseVulnerabilityScanResult(imageRef string, logsReader io.ReadCloser) (v1alpha1.VulnerabilityScanResult, error) {
+	logs, err := ioutil.ReadAll(logsReader)
+	if err != nil {
+		return v1alpha1.VulnerabilityScanResult{}, fmt.Errorf("reading docker scout report: %w", err)
+	}
+	// Progress messages are printed to stderr, which is interleaved with the report in container logs.
+	start := bytes.Index(logs, []byte("\n{"))
+	if bytes.HasPrefix(logs, []byte("{")) {
+		start = 0
+	} else if start < 0 {
+		return v1alpha1.VulnerabilityScanResult{}, errors.New("decoding docker scout report: no JSON document in logs")
+	}
+	var report ScanReport
+	err = json.NewDecoder(bytes.NewReader(logs[start:])).Decode(&report)
+	if err != nil {
+		return v1alpha1.VulnerabilityScanResult{}, fmt.Errorf("decoding docker scout report: %w", err)
+	}
+	return s.convert(imageRef, report)
+}
+
+func (s *scoutScanner) convert(imageRef string, report ScanReport) (v1alpha1.VulnerabilityScanResult, error) {
+	items := make([]v1alpha1.Vulnerability, 0)
+	version := s.config.Version
+
+	for _, run := range report.Runs {
+		if run.Tool.Driver.Version != "" {
+			version = run.Tool.Driver.Version
+		}
+		for _, rule := range run.Tool.Driver.Rules {
+			// A vulnerability affecting multiple packages is reported once per package.
+			for _, purl := range rule.Properties.PURLs {
+				resource, installedVersion := parsePURL(purl)
+				items = append(items, v1alpha1.Vulnerability{
+					VulnerabilityID:  rule.ID,
+					Resource:         resource,
+					InstalledVersion: installedVersion,
+					FixedVersion:     s.toFixedVersion(rule.Properties),
+					Severity:         s.toSeverity(rule.Properties),
+					Title:            rule.ShortDescription.Text,
+					Description:      rule.Help.Text,
+					Links:            s.toLinks(rule),
+				})
+			}
+		}
+	}
+
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return v1alpha1.VulnerabilityScanResult{}, err
+	}
+
+	artifact := v1alpha1.Artifact{
+		Repository: ref.Context().RepositoryStr(),
+	}
+	switch t := ref.(type) {
+	case name.Tag:
+		artifact.Tag = t.TagStr()
+	case name.Digest:
+		artifact.Digest = t.DigestStr()
+	}
+
+	return v1alpha1.VulnerabilityScanResult{
+		Scanner: v1alpha1.Scanner{
+			Name:    "Docker Scout",
+			Vendor:  "Docker Inc.",
+			Version: version,
+		},
+		Registry: v1alpha1.Registry{
+			Server: ref.Context().RegistryStr(),
+		},
+		Artifact:        artifact,
+		Summary:         s.toSummary(items),
+		Vulnerabilities: items,
+	}, nil
+}
+
+// parsePURL returns the name and the version of the package identified by the specified package URL, e.g.
+// `openssl` and `1.1.1n-0+deb11u3` for `pkg:deb/debian/openssl@1.1.1n-0+deb11u3?os_distro=bullseye`.
+func parsePURL(purl string) (string, string) {
+	purl = strings.TrimPrefix(purl, "pkg:")
+	if i := strings.IndexAny(purl, "?#"); i >= 0 {
+		purl = purl[:i]
+	}
+	var version string
+	if i := strings.LastIndex(purl, "@"); i >= 0 {
+		purl, version = purl[:i], purl[i+1:]
+	}
+	packageName := purl[strings.LastIndex(purl, "/")+1:]
+	if unescaped, err := url.PathUnescape(packageName); err == nil {
+		packageName = unescaped
+	}
+	if unescaped, err := url.PathUnescape(version); err == nil {
+		version = unescaped
+	}
+	return packageName, version
+}
+
+func (s *scoutScanner) toFixedVersion(properties RuleProperties) string {
+	if strings.EqualFold(properties.FixedVersion, "not fixed") {
+		return ""
+	}
+	return properties.FixedVersion
+}
+
+func (s *scoutScanner) toSeverity(properties RuleProperties) v1alpha1.Severity {
+	severity := properties.CVSSv3Severity
+	if severity == "" && len(properties.Tags) > 0 {
+		severity = properties.Tags[0]
+	}
+	switch strings.ToLower(severity) {
+	case "critical":
+		return v1alpha1.SeverityCritical
+	case "high":
+		return v1alpha1.SeverityHigh
+	case "medium":
+		return v1alpha1.SeverityMedium
+	case "low":
+		return v1alpha1.SeverityLow
+	default:
+		return v1alpha1.SeverityUnknown
+	}
+}
+
+func (s *scoutScanner) toLinks(rule Rule) []string {
+	if rule.HelpURI == "" {
+		return []string{}
+	}
+	return []string{rule.HelpURI}
+}
+
+func (s *scoutScanner) toSummary(items []v1alpha1.Vulnerability) v1alpha1.VulnerabilitySummary {
+	summary := v1alpha1.VulnerabilitySummary{}
+	for _, item := range items {
+		switch item.Severity {
+		case v1alpha1.SeverityCritical:
+			summary.CriticalCount++
+		case v1alpha1.SeverityHigh:
+			summary.HighCount++
+		case v1alpha1.SeverityMedium:
+			summary.MediumCount++
+		case v1alpha1.SeverityLow:
+			summary.LowCount++
+		default:
+			summary.UnknownCount++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,201 @@
+package scout_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	"github.com/aquasecurity/starboard-operator/pkg/scout"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var config = etc.ScannerScout{
+	Enabled:     true,
+	Version:     "1.0.9",
+	ImageRef:    "docker/scout-cli:1.0.9",
+	TokenSecret: "scout-token",
+}
+
+func TestScoutScanner_NewScanJob(t *testing.T) {
+	job, err := scout.NewScanner(config).NewScanJob(scanner.JobMeta{
+		Labels: map[string]string{
+			"app.kubernetes.io/managed-by": "starboard-operator",
+		},
+	}, scanner.Options{
+		Namespace:              "starboard-operator",
+		ServiceAccountName:     "starboard-operator",
+		ScanJobTimeout:         5 * time.Minute,
+		ScanJobImagePullPolicy: corev1.PullAlways,
+	}, corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "nginx", Image: "nginx:1.16"},
+			{Name: "sidecar", Image: "busybox:1.32"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "starboard-operator", job.Namespace)
+	assert.Equal(t, int64(300), *job.Spec.ActiveDeadlineSeconds)
+	require.Len(t, job.Spec.Template.Spec.Containers, 2)
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "nginx", container.Name)
+	assert.Equal(t, "docker/scout-cli:1.0.9", container.Image)
+	assert.Equal(t, corev1.PullAlways, container.ImagePullPolicy)
+	assert.Equal(t, []string{"/docker-scout"}, container.Command)
+	assert.Equal(t, []string{"cves", "--format", "sarif", "registry://nginx:1.16"}, container.Args)
+	assert.Contains(t, container.Env, corev1.EnvVar{
+		Name: "DOCKER_SCOUT_HUB_PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "scout-token"},
+				Key:                  "DOCKER_SCOUT_HUB_PASSWORD",
+			},
+		},
+	})
+	assert.Equal(t, []string{"cves", "--format", "sarif", "registry://busybox:1.32"}, job.Spec.Template.Spec.Containers[1].Args)
+}
+
+func TestScoutScanner_ParseVulnerabilityScanResult(t *testing.T) {
+	testCases := []struct {
+		name           string
+		imageRef       string
+		inputFile      string
+		progress       string
+		expectedReport v1alpha1.VulnerabilityScanResult
+	}{
+		{
+			name:      "Should convert report with vulnerabilities",
+			imageRef:  "core.harbor.domain/library/nginx:1.16",
+			inputFile: "testdata/with_vulnerabilities.json",
+			expectedReport: v1alpha1.VulnerabilityScanResult{
+				Scanner: v1alpha1.Scanner{
+					Name:    "Docker Scout",
+					Vendor:  "Docker Inc.",
+					Version: "1.0.9",
+				},
+				Registry: v1alpha1.Registry{
+					Server: "core.harbor.domain",
+				},
+				Artifact: v1alpha1.Artifact{
+					Repository: "library/nginx",
+					Tag:        "1.16",
+				},
+				Summary: v1alpha1.VulnerabilitySummary{
+					CriticalCount: 2,
+					HighCount:     1,
+					LowCount:      1,
+				},
+				Vulnerabilities: []v1alpha1.Vulnerability{
+					{
+						VulnerabilityID:  "CVE-2022-2068",
+						Resource:         "openssl",
+						InstalledVersion: "1.1.1n-0+deb11u2",
+						FixedVersion:     "1.1.1n-0+deb11u3",
+						Severity:         v1alpha1.SeverityCritical,
+						Title:            "CVE-2022-2068",
+						Description:      "In addition to the c_rehash shell command injection identified in CVE-2022-1292, further circumstances where the c_rehash script does not properly sanitise shell metacharacters were found.",
+						Links:            []string{"https://scout.docker.com/v/CVE-2022-2068"},
+					},
+					{
+						VulnerabilityID:  "CVE-2022-2068",
+						Resource:         "libssl1.1",
+						InstalledVersion: "1.1.1n-0+deb11u2",
+						FixedVersion:     "1.1.1n-0+deb11u3",
+						Severity:         v1alpha1.SeverityCritical,
+						Title:            "CVE-2022-2068",
+						Description:      "In addition to the c_rehash shell command injection identified in CVE-2022-1292, further circumstances where the c_rehash script does not properly sanitise shell metacharacters were found.",
+						Links:            []string{"https://scout.docker.com/v/CVE-2022-2068"},
+					},
+					{
+						VulnerabilityID:  "CVE-2011-3374",
+						Resource:         "apt",
+						InstalledVersion: "2.2.4",
+						FixedVersion:     "",
+						Severity:         v1alpha1.SeverityLow,
+						Title:            "CVE-2011-3374",
+						Description:      "It was found that apt-key in apt, all versions, do not correctly validate gpg keys with the master keyring, leading to a potential man-in-the-middle attack.",
+						Links:            []string{"https://scout.docker.com/v/CVE-2011-3374"},
+					},
+					{
+						VulnerabilityID:  "GHSA-jfh8-c2jp-5v3q",
+						Resource:         "log4j-core",
+						InstalledVersion: "2.14.1",
+						FixedVersion:     "2.15.0",
+						Severity:         v1alpha1.SeverityHigh,
+						Title:            "Remote code injection in Log4j",
+						Description:      "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP and other JNDI related endpoints.",
+						Links:            []string{},
+					},
+				},
+			},
+		},
+		{
+			name:      "Should convert report without vulnerabilities printed after progress messages",
+			imageRef:  "nginx@sha256:2963fc49cc50883ba9af25f977a9997ff9af06b45c12d968b7985dc1e9254e4b",
+			inputFile: "testdata/without_vulnerabilities.json",
+			progress:  "    ✓ SBOM of image already cached, 143 packages indexed\n    ✓ No vulnerable package detected\n",
+			expectedReport: v1alpha1.VulnerabilityScanResult{
+				Scanner: v1alpha1.Scanner{
+					Name:    "Docker Scout",
+					Vendor:  "Docker Inc.",
+					Version: "1.0.9",
+				},
+				Registry: v1alpha1.Registry{
+					Server: "index.docker.io",
+				},
+				Artifact: v1alpha1.Artifact{
+					Repository: "library/nginx",
+					Digest:     "sha256:2963fc49cc50883ba9af25f977a9997ff9af06b45c12d968b7985dc1e9254e4b",
+				},
+				Vulnerabilities: []v1alpha1.Vulnerability{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(tc.inputFile)
+			require.NoError(t, err)
+
+			report, err := scout.NewScanner(config).ParseVulnerabilityScanResult(tc.imageRef,
+				ioutil.NopCloser(strings.NewReader(tc.progress+string(data))))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedReport, report)
+		})
+	}
+
+	t.Run("Should return error when logs do not contain report", func(t *testing.T) {
+		_, err := scout.NewScanner(config).ParseVulnerabilityScanResult("nginx:1.16",
+			ioutil.NopCloser(strings.NewReader("ERROR: user not logged in\n")))
+		require.EqualError(t, err, "decoding docker scout report: no JSON document in logs")
+	})
+}
+
+func TestScannerScout_Validate(t *testing.T) {
+	t.Run("Should return error when token secret is not set", func(t *testing.T) {
+		err := etc.ScannerScout{ImageRef: "docker/scout-cli:1.0.9"}.Validate()
+		require.EqualError(t, err, "OPERATOR_SCANNER_SCOUT_TOKEN_SECRET must be set")
+	})
+
+	t.Run("Should select scanner by name", func(t *testing.T) {
+		require.NoError(t, os.Setenv("OPERATOR_SCANNER", "scout"))
+		defer func() {
+			_ = os.Unsetenv("OPERATOR_SCANNER")
+		}()
+		cfg, err := etc.GetOperatorConfig()
+		require.NoError(t, err)
+		cfg.ScannerScout.TokenSecret = "scout-token"
+		require.NoError(t, cfg.ValidateScanners())
+
+		vulnerabilityScanner, err := scanner.New(etc.ScannerNameScout, cfg, etc.VersionInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, "Docker Scout", vulnerabilityScanner.GetName())
+	})
+}
@@ -0,0 +1,81 @@
+package trivy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// outputVolumeName is the name of the scan Job volume holding output files in the file output mode.
+	outputVolumeName = "scan-output"
+	// outputMountPath is the path where output files are written by scan containers in the file output mode.
+	outputMountPath = "/var/starboard/output"
+
+	// OutputMarker is the line printed by scan containers in the file output mode before the output file,
+	// which tells the output apart from diagnostics of Trivy.
+	OutputMarker = "---starboard-output---"
+
+	// printOutputScriptFormat is the shell script which runs the command passed as positional parameters
+	// and, once the command succeeds, prints the marker followed by the output file gzipped and base64
+	// encoded on a single line. The exit code of a failed command is preserved.
+	printOutputScriptFormat = `"$0" "$@" && echo "%s" && gzip -c "%s" | base64 -w 0`
+)
+
+// newOutputVolume returns the emptyDir volume shared by scan containers in the file output mode.
+func newOutputVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: outputVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				Medium: corev1.StorageMediumDefault,
+			},
+		},
+	}
+}
+
+// applyFileOutput makes the specified scan container write the output of Trivy to a file named after the
+// container, which is printed by the shell once Trivy exits. The output flag is inserted before the scan
+// target, i.e. the last argument, as Trivy does not accept flags after positional arguments.
+func applyFileOutput(container *corev1.Container) {
+	file := fmt.Sprintf("%s/%s.json", outputMountPath, container.Name)
+	target := container.Args[len(container.Args)-1]
+	args := append(append([]string{}, container.Args[:len(container.Args)-1]...), "--output", file, target)
+
+	container.Args = append(append([]string{fmt.Sprintf(printOutputScriptFormat, OutputMarker, file)},
+		container.Command...), args...)
+	container.Command = []string{"sh", "-c"}
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      outputVolumeName,
+		MountPath: outputMountPath,
+	})
+}
+
+// ReadFileOutput returns the output file printed by a scan container in the file output mode, i.e. the
+// gzipped and base64 encoded line after the last OutputMarker in the specified logs. Errors of reading
+// logs are wrapped, so that timeouts can be told apart from malformed output.
+func ReadFileOutput(logs io.Reader) (io.Reader, error) {
+	data, err := ioutil.ReadAll(logs)
+	if err != nil {
+		return nil, fmt.Errorf("reading output: %w", err)
+	}
+	i := bytes.LastIndex(data, []byte(OutputMarker+"\n"))
+	if i < 0 {
+		return nil, errors.New("output marker not found")
+	}
+	compressed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data[i+len(OutputMarker)+1:])))
+	if err != nil {
+		return nil, fmt.Errorf("decoding output: %w", err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing output: %w", err)
+	}
+	return reader, nil
+}
@@ -0,0 +1,112 @@
+package trivy_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/logs"
+	"github.com/aquasecurity/starboard-operator/pkg/trivy"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scanReport = `[{"Target":"nginx:1.16 (debian 10.3)","Vulnerabilities":[` +
+	`{"VulnerabilityID":"CVE-2020-3810","PkgName":"apt","InstalledVersion":"1.8.2","FixedVersion":"1.8.2.1","Severity":"MEDIUM"}]}]`
+
+// encodeOutput returns the output file gzipped and base64 encoded as printed by scan containers.
+func encodeOutput(t *testing.T, output string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(output))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestReadFileOutput(t *testing.T) {
+	testCases := []struct {
+		name           string
+		logs           string
+		expectedOutput string
+		expectedError  string
+	}{
+		{
+			name:           "Should read output after marker",
+			logs:           trivy.OutputMarker + "\n" + encodeOutput(t, scanReport),
+			expectedOutput: scanReport,
+		},
+		{
+			name: "Should skip diagnostics printed before marker",
+			logs: "2020-10-05T08:12:39.123Z\tWARN\tThis OS version is no longer supported by the distribution\n" +
+				trivy.OutputMarker + "\n" + encodeOutput(t, scanReport) + "\n",
+			expectedOutput: scanReport,
+		},
+		{
+			name:          "Should return error when marker is missing",
+			logs:          scanReport,
+			expectedError: "output marker not found",
+		},
+		{
+			name:          "Should return error when output is not base64 encoded",
+			logs:          trivy.OutputMarker + "\n" + scanReport,
+			expectedError: "decoding output: illegal base64 data at input byte 0",
+		},
+		{
+			name:          "Should return error when output is not gzipped",
+			logs:          trivy.OutputMarker + "\n" + base64.StdEncoding.EncodeToString([]byte(scanReport)),
+			expectedError: "decompressing output: gzip: invalid header",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := trivy.ReadFileOutput(strings.NewReader(tc.logs))
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			data, err := ioutil.ReadAll(output)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOutput, string(data))
+		})
+	}
+
+	t.Run("Should wrap timeout of reading logs", func(t *testing.T) {
+		// The pipe blocks reads until it's closed by the timeout of the wrapping reader.
+		pipeReader, pipeWriter := io.Pipe()
+		defer func() {
+			_ = pipeWriter.Close()
+		}()
+		_, err := trivy.ReadFileOutput(logs.NewTimeoutReadCloser(pipeReader, 10*time.Millisecond))
+		assert.True(t, logs.IsReadTimeout(err))
+	})
+}
+
+func TestTrivyScanner_ParseVulnerabilityScanResult(t *testing.T) {
+	t.Run("Should parse output printed in file output mode", func(t *testing.T) {
+		result, err := trivy.NewScanner(etc.ScannerTrivy{Version: "0.11.0", OutputMode: etc.ScanOutputModeFile}).
+			ParseVulnerabilityScanResult("nginx:1.16", ioutil.NopCloser(strings.NewReader(
+				"2020-10-05T08:12:39.123Z\tINFO\tDetecting Debian vulnerabilities...\n"+trivy.OutputMarker+"\n"+encodeOutput(t, scanReport))))
+		require.NoError(t, err)
+		assert.Equal(t, "0.11.0", result.Scanner.Version)
+		require.Len(t, result.Vulnerabilities, 1)
+		assert.Equal(t, "CVE-2020-3810", result.Vulnerabilities[0].VulnerabilityID)
+		assert.Equal(t, v1alpha1.SeverityMedium, result.Vulnerabilities[0].Severity)
+	})
+
+	t.Run("Should return error when output is missing in file output mode", func(t *testing.T) {
+		_, err := trivy.NewScanner(etc.ScannerTrivy{OutputMode: etc.ScanOutputModeFile}).
+			ParseVulnerabilityScanResult("nginx:1.16", ioutil.NopCloser(strings.NewReader(scanReport)))
+		require.EqualError(t, err, "output marker not found")
+	})
+}
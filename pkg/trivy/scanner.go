@@ -3,6 +3,7 @@ package trivy
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/aquasecurity/starboard-operator/pkg/etc"
 
@@ -15,11 +16,38 @@ import (
 	"github.com/google/uuid"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 )
 
+const (
+	secretName = "starboard-operator"
+
+	// defaultCacheDir is the directory which Trivy downloads the vulnerability database to,
+	// unless configured otherwise.
+	defaultCacheDir = "/var/lib/trivy"
+
+	// ignoreFileVolumeName is the name of the scan Job volume holding the `.trivyignore` file.
+	ignoreFileVolumeName = "ignore-file"
+	// ignoreFileMountPath is the path where the `.trivyignore` file is mounted in scan containers.
+	ignoreFileMountPath = "/etc/starboard/trivy"
+	// IgnoreFileKey is the key of the ignore file ConfigMap holding the `.trivyignore` file.
+	IgnoreFileKey = ".trivyignore"
+
+	// binVolumeName is the name of the scan Job volume holding the Trivy binary in the rootfs scan type.
+	binVolumeName = "scanner-bin"
+	// binMountPath is the path where the Trivy binary is copied to and run from in the rootfs scan type.
+	binMountPath = "/var/starboard"
+	// rootfsScanTarget is the directory scanned in the rootfs scan type.
+	rootfsScanTarget = "/"
+)
+
+func init() {
+	scanner.Register(etc.ScannerNameTrivy, func(config etc.Config, _ etc.VersionInfo) scanner.VulnerabilityScanner {
+		return NewScanner(config.ScannerTrivy)
+	})
+}
+
 type trivyScanner struct {
 	config etc.ScannerTrivy
 }
@@ -30,74 +58,87 @@ func NewScanner(config etc.ScannerTrivy) scanner.VulnerabilityScanner {
 	}
 }
 
+func (s *trivyScanner) GetName() string {
+	return "Trivy"
+}
+
 func (s *trivyScanner) NewScanJob(meta scanner.JobMeta, options scanner.Options, spec corev1.PodSpec) (*batchv1.Job, error) {
 	jobName := fmt.Sprintf(uuid.New().String())
 
-	initContainerName := jobName
-
-	initContainers := []corev1.Container{
-		{
-			Name:                     initContainerName,
-			Image:                    s.config.ImageRef,
-			ImagePullPolicy:          corev1.PullIfNotPresent,
-			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
-			Command: []string{
-				"trivy",
-			},
-			Args: []string{
-				"--download-db-only",
-				"--cache-dir",
-				"/var/lib/trivy",
-			},
-			VolumeMounts: []corev1.VolumeMount{
-				{
-					Name:      "data",
-					ReadOnly:  false,
-					MountPath: "/var/lib/trivy",
-				},
-			},
-		},
-	}
-
-	scanJobContainers := make([]corev1.Container, len(spec.Containers))
-	for i, c := range spec.Containers {
-		var envs []corev1.EnvVar
-
-		scanJobContainers[i] = corev1.Container{
-			Name:                     c.Name,
-			Image:                    s.config.ImageRef,
-			ImagePullPolicy:          corev1.PullIfNotPresent,
-			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
-			Env:                      envs,
-			Command: []string{
-				"trivy",
-			},
-			Args: []string{
-				"--skip-update",
-				"--cache-dir",
-				"/var/lib/trivy",
-				"--no-progress",
-				"--format",
-				"json",
-				c.Image,
-			},
-			Resources: corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceCPU:    resource.MustParse("100m"),
-					corev1.ResourceMemory: resource.MustParse("100M"),
+	var initContainers []corev1.Container
+	var volumes []corev1.Volume
+	if s.config.Mode != etc.TrivyModeClientServer {
+		initContainers = []corev1.Container{
+			{
+				Name:                     jobName,
+				Image:                    s.config.ImageRef,
+				ImagePullPolicy:          options.ScanJobImagePullPolicy,
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				SecurityContext:          options.ScanJobSecurityContext.DeepCopy(),
+				Env: append(append(scanner.NewProxyEnvVars(options), scanner.NewCACertEnvVars(options)...),
+					s.newCacheEnvVars()...),
+				EnvFrom: options.ScanJobEnvFrom,
+				Command: []string{
+					"trivy",
 				},
-				Limits: corev1.ResourceList{
-					corev1.ResourceCPU:    resource.MustParse("500m"),
-					corev1.ResourceMemory: resource.MustParse("500M"),
+				Args: []string{
+					"--download-db-only",
+					"--cache-dir",
+					s.getCacheDir(),
 				},
+				VolumeMounts: append(append([]corev1.VolumeMount{
+					s.newCacheVolumeMount(),
+				}, scanner.NewCACertVolumeMounts(options)...), options.ScanJobVolumeMounts...),
 			},
-			VolumeMounts: []corev1.VolumeMount{
-				{
-					Name:      "data",
-					ReadOnly:  false,
-					MountPath: "/var/lib/trivy",
+		}
+		volumes = []corev1.Volume{
+			s.newCacheVolume(),
+		}
+	}
+	if s.config.ScanType == etc.TrivyScanTypeRootfs {
+		initContainers = append(initContainers, s.newCopyBinaryContainer(options))
+		volumes = append(volumes, corev1.Volume{
+			Name: binVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMediumDefault,
 				},
 			},
+		})
+	}
+	volumes = append(volumes, scanner.NewCACertVolumes(options)...)
+	volumes = append(volumes, newIgnoreFileVolumes(options)...)
+	volumes = append(volumes, options.ScanJobVolumes...)
+
+	containers := scanner.GetContainersToScan(spec)
+	scanJobContainers := make([]corev1.Container, len(containers))
+	for i, c := range containers {
+		switch s.config.Mode {
+		case etc.TrivyModeClientServer:
+			scanJobContainers[i] = s.newClientScanJobContainer(c, options)
+		default:
+			scanJobContainers[i] = s.newStandaloneScanJobContainer(c, options)
+		}
+	}
+	err := s.overrideCommandAndArgs(scanJobContainers, containers)
+	if err != nil {
+		return nil, err
+	}
+	// Additional volumes are mounted before SBOM containers are derived from scan containers, so that both share them.
+	for i := range scanJobContainers {
+		scanJobContainers[i].VolumeMounts = append(scanJobContainers[i].VolumeMounts, options.ScanJobVolumeMounts...)
+	}
+	if s.config.GenerateSBOM {
+		for i, c := range containers {
+			scanJobContainers = append(scanJobContainers, s.newSBOMScanJobContainer(scanJobContainers[i], c))
+		}
+	}
+	// SBOM containers are derived from scan containers before the output is redirected, hence they print
+	// SBOMs to logs regardless of the output mode.
+	if s.config.OutputMode == etc.ScanOutputModeFile {
+		volumes = append(volumes, newOutputVolume())
+		for i := range containers {
+			applyFileOutput(&scanJobContainers[i])
 		}
 	}
 
@@ -109,9 +150,10 @@ func (s *trivyScanner) NewScanJob(meta scanner.JobMeta, options scanner.Options,
 			Annotations: meta.Annotations,
 		},
 		Spec: batchv1.JobSpec{
-			BackoffLimit:          pointer.Int32Ptr(0),
-			Completions:           pointer.Int32Ptr(1),
-			ActiveDeadlineSeconds: scanners.GetActiveDeadlineSeconds(options.ScanJobTimeout),
+			BackoffLimit:            pointer.Int32Ptr(options.ScanJobBackoffLimit),
+			Completions:             pointer.Int32Ptr(1),
+			ActiveDeadlineSeconds:   scanners.GetActiveDeadlineSeconds(options.ScanJobTimeout),
+			TTLSecondsAfterFinished: options.ScanJobTTLSecondsAfterFinished,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      meta.Labels,
@@ -120,27 +162,383 @@ func (s *trivyScanner) NewScanJob(meta scanner.JobMeta, options scanner.Options,
 				Spec: corev1.PodSpec{
 					RestartPolicy:                corev1.RestartPolicyNever,
 					ServiceAccountName:           options.ServiceAccountName,
+					PriorityClassName:            options.PriorityClassName,
 					AutomountServiceAccountToken: pointer.BoolPtr(false),
-					Volumes: []corev1.Volume{
-						{
-							Name: "data",
-							VolumeSource: corev1.VolumeSource{
-								EmptyDir: &corev1.EmptyDirVolumeSource{
-									Medium: corev1.StorageMediumDefault,
-								},
-							},
-						},
-					},
-					InitContainers: initContainers,
-					Containers:     scanJobContainers,
+					NodeSelector:                 options.ScanJobNodeSelector,
+					Tolerations:                  options.ScanJobTolerations,
+					Affinity:                     options.ScanJobAffinity,
+					HostAliases:                  options.ScanJobHostAliases,
+					DNSConfig:                    options.ScanJobDNSConfig,
+					SecurityContext:              options.ScanJobPodSecurityContext,
+					Volumes:                      volumes,
+					InitContainers:               initContainers,
+					Containers:                   scanJobContainers,
 				},
 			},
 		},
 	}, nil
 }
 
+// newCopyBinaryContainer returns the init container which copies the Trivy binary to the volume shared with
+// scan containers in the rootfs scan type, which run scanned images rather than the Trivy image.
+func (s *trivyScanner) newCopyBinaryContainer(options scanner.Options) corev1.Container {
+	return corev1.Container{
+		Name:                     "copy-trivy",
+		Image:                    s.config.ImageRef,
+		ImagePullPolicy:          options.ScanJobImagePullPolicy,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		SecurityContext:          options.ScanJobSecurityContext.DeepCopy(),
+		Command: []string{
+			"cp",
+		},
+		Args: []string{
+			"-v",
+			"/usr/local/bin/trivy",
+			binMountPath + "/trivy",
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      binVolumeName,
+				MountPath: binMountPath,
+			},
+		},
+	}
+}
+
+// newStandaloneScanJobContainer returns the container which scans the image of the specified
+// container with the vulnerability database downloaded by the init container of a scan Job.
+func (s *trivyScanner) newStandaloneScanJobContainer(c corev1.Container, options scanner.Options) corev1.Container {
+	if s.config.ScanType == etc.TrivyScanTypeRootfs {
+		return s.newRootfsScanJobContainer(c, options)
+	}
+	return corev1.Container{
+		Name:                     c.Name,
+		Image:                    s.config.ImageRef,
+		ImagePullPolicy:          options.ScanJobImagePullPolicy,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		SecurityContext:          options.ScanJobSecurityContext.DeepCopy(),
+		Env: append(append(s.newScanEnvVars(options), s.newCacheEnvVars()...),
+			scanner.NewRegistryCredentialsEnvVars(options, c.Name, "TRIVY_USERNAME", "TRIVY_PASSWORD")...),
+		EnvFrom: options.ScanJobEnvFrom,
+		Command: []string{
+			"trivy",
+		},
+		Args: s.appendScanArgs([]string{
+			"--skip-update",
+			"--cache-dir",
+			s.getCacheDir(),
+			"--no-progress",
+			"--format",
+			"json",
+		}, c.Image, options),
+		Resources: options.ScanJobResources,
+		VolumeMounts: append(append([]corev1.VolumeMount{
+			s.newCacheVolumeMount(),
+		}, scanner.NewCACertVolumeMounts(options)...), newIgnoreFileVolumeMounts(options)...),
+	}
+}
+
+// newRootfsScanJobContainer returns the container which runs the image of the specified container and
+// scans its root filesystem with the Trivy binary copied by the init container. The image is pulled
+// by the kubelet, so Trivy does not need registry credentials.
+func (s *trivyScanner) newRootfsScanJobContainer(c corev1.Container, options scanner.Options) corev1.Container {
+	return corev1.Container{
+		Name:                     c.Name,
+		Image:                    c.Image,
+		ImagePullPolicy:          options.ScanJobImagePullPolicy,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		SecurityContext:          options.ScanJobSecurityContext.DeepCopy(),
+		Env:                      append(s.newScanEnvVars(options), s.newCacheEnvVars()...),
+		EnvFrom:                  options.ScanJobEnvFrom,
+		Command: []string{
+			binMountPath + "/trivy",
+		},
+		Args:      s.appendScanArgs(s.newRootfsArgs("json"), c.Image, options),
+		Resources: options.ScanJobResources,
+		VolumeMounts: append(append([]corev1.VolumeMount{
+			s.newCacheVolumeMount(),
+			{
+				Name:      binVolumeName,
+				ReadOnly:  true,
+				MountPath: binMountPath,
+			},
+		}, scanner.NewCACertVolumeMounts(options)...), newIgnoreFileVolumeMounts(options)...),
+	}
+}
+
+// newRootfsArgs returns arguments of the Trivy rootfs subcommand with the specified output format.
+// Directories of volumes mounted by the operator are skipped, as they're not part of scanned images.
+func (s *trivyScanner) newRootfsArgs(format string) []string {
+	return []string{
+		"--cache-dir",
+		s.getCacheDir(),
+		"rootfs",
+		"--skip-update",
+		"--skip-dirs",
+		strings.Join([]string{binMountPath, s.getCacheDir()}, ","),
+		"--no-progress",
+		"--format",
+		format,
+	}
+}
+
+// newClientScanJobContainer returns the container which scans the image of the specified
+// container with the remote Trivy server. The optional server token is read from the
+// operator's Secret so that it never shows up in the scan Job spec.
+func (s *trivyScanner) newClientScanJobContainer(c corev1.Container, options scanner.Options) corev1.Container {
+	envs := []corev1.EnvVar{
+		{
+			Name: "TRIVY_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: secretName,
+					},
+					Key:      "OPERATOR_SCANNER_TRIVY_SERVER_TOKEN",
+					Optional: pointer.BoolPtr(true),
+				},
+			},
+		},
+	}
+	envs = append(envs, s.newScanEnvVars(options)...)
+	envs = append(envs, scanner.NewRegistryCredentialsEnvVars(options, c.Name, "TRIVY_USERNAME", "TRIVY_PASSWORD")...)
+
+	return corev1.Container{
+		Name:                     c.Name,
+		Image:                    s.config.ImageRef,
+		ImagePullPolicy:          options.ScanJobImagePullPolicy,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		SecurityContext:          options.ScanJobSecurityContext.DeepCopy(),
+		Env:                      envs,
+		EnvFrom:                  options.ScanJobEnvFrom,
+		Command: []string{
+			"trivy",
+		},
+		Args: s.appendScanArgs([]string{
+			"client",
+			"--remote",
+			s.config.ServerURL,
+			"--format",
+			"json",
+		}, c.Image, options),
+		Resources:    options.ScanJobResources,
+		VolumeMounts: append(scanner.NewCACertVolumeMounts(options), newIgnoreFileVolumeMounts(options)...),
+	}
+}
+
+// newSBOMScanJobContainer returns the container which generates the SBOM of the image of the
+// specified container. It's run alongside the specified scan container with the same settings,
+// but in the configured SBOM format and without flags filtering vulnerabilities.
+func (s *trivyScanner) newSBOMScanJobContainer(scanContainer corev1.Container, c corev1.Container) corev1.Container {
+	sbomContainer := *scanContainer.DeepCopy()
+	sbomContainer.Name = scanner.GetSBOMContainerName(c.Name)
+	switch s.config.Mode {
+	case etc.TrivyModeClientServer:
+		sbomContainer.Args = []string{
+			"client",
+			"--remote",
+			s.config.ServerURL,
+		}
+	default:
+		if s.config.ScanType == etc.TrivyScanTypeRootfs {
+			sbomContainer.Args = append(append(s.newRootfsArgs(string(s.config.SBOMFormat)), s.newTimeoutArgs()...),
+				rootfsScanTarget)
+			return sbomContainer
+		}
+		sbomContainer.Args = []string{
+			"--skip-update",
+			"--cache-dir",
+			s.getCacheDir(),
+			"--no-progress",
+		}
+	}
+	sbomContainer.Args = append(append(append(sbomContainer.Args, s.newTimeoutArgs()...), s.newPlatformArgs()...),
+		"--format", string(s.config.SBOMFormat), c.Image)
+	return sbomContainer
+}
+
+// overrideCommandAndArgs replaces the built-in command and arguments of the specified scan containers
+// with the configured ones, e.g. to run a custom wrapper of Trivy. The scan target, i.e. the reference of
+// the image or the root directory, is always appended to overridden arguments, and the output must remain
+// compatible with Trivy JSON.
+func (s *trivyScanner) overrideCommandAndArgs(scanJobContainers, containers []corev1.Container) error {
+	command, err := s.config.GetCommand()
+	if err != nil {
+		return err
+	}
+	args, err := s.config.GetArgs()
+	if err != nil {
+		return err
+	}
+	for i, c := range containers {
+		if len(command) > 0 {
+			scanJobContainers[i].Command = append([]string{}, command...)
+		}
+		if len(args) > 0 {
+			scanJobContainers[i].Args = append(append([]string{}, args...), s.getScanTarget(c.Image))
+		}
+	}
+	return nil
+}
+
+// getScanTarget returns the argument of the Trivy command which specifies what to scan, i.e. the specified
+// image reference, or the root directory in the rootfs scan type.
+func (s *trivyScanner) getScanTarget(imageRef string) string {
+	if s.config.ScanType == etc.TrivyScanTypeRootfs {
+		return rootfsScanTarget
+	}
+	return imageRef
+}
+
+// getCacheDir returns the directory which the vulnerability database is downloaded to
+// and read from in Standalone mode.
+func (s *trivyScanner) getCacheDir() string {
+	if s.config.CacheDir != "" {
+		return s.config.CacheDir
+	}
+	return defaultCacheDir
+}
+
+// newCacheVolume returns the volume holding the vulnerability database in Standalone mode.
+// It's backed by the configured PersistentVolumeClaim, which can be shared by scan Jobs so that
+// the database is not downloaded by each of them, or by an emptyDir otherwise.
+func (s *trivyScanner) newCacheVolume() corev1.Volume {
+	if s.config.CachePVC != "" {
+		return corev1.Volume{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: s.config.CachePVC,
+				},
+			},
+		}
+	}
+	return corev1.Volume{
+		Name: "data",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				Medium: corev1.StorageMediumDefault,
+			},
+		},
+	}
+}
+
+func (s *trivyScanner) newCacheVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      "data",
+		ReadOnly:  false,
+		MountPath: s.getCacheDir(),
+	}
+}
+
+// newIgnoreFileVolumes returns the volume of the ignore file ConfigMap specified in Options, or nil
+// if it's not set. The ConfigMap is optional, so that a missing one does not prevent scanning.
+func newIgnoreFileVolumes(options scanner.Options) []corev1.Volume {
+	if options.IgnoreFileConfigMap == "" {
+		return nil
+	}
+	return []corev1.Volume{
+		{
+			Name: ignoreFileVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: options.IgnoreFileConfigMap,
+					},
+					Optional: pointer.BoolPtr(true),
+				},
+			},
+		},
+	}
+}
+
+// newIgnoreFileVolumeMounts returns the read-only mount of the volume returned by newIgnoreFileVolumes,
+// or nil if the ignore file ConfigMap is not set.
+func newIgnoreFileVolumeMounts(options scanner.Options) []corev1.VolumeMount {
+	if options.IgnoreFileConfigMap == "" {
+		return nil
+	}
+	return []corev1.VolumeMount{
+		{
+			Name:      ignoreFileVolumeName,
+			ReadOnly:  true,
+			MountPath: ignoreFileMountPath,
+		},
+	}
+}
+
+// newCacheEnvVars returns the TRIVY_CACHE_DIR environment variable if the cache is configured.
+func (s *trivyScanner) newCacheEnvVars() []corev1.EnvVar {
+	if s.config.CachePVC == "" && s.config.CacheDir == "" {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: "TRIVY_CACHE_DIR", Value: s.getCacheDir()},
+	}
+}
+
+// newScanEnvVars returns environment variables of scan containers, which configure the proxy
+// and trusted CA certificates. TRIVY_INSECURE skips verification of registry certificates
+// altogether and is meant as a fallback when CA certificates cannot be provided.
+func (s *trivyScanner) newScanEnvVars(options scanner.Options) []corev1.EnvVar {
+	envs := append(scanner.NewProxyEnvVars(options), scanner.NewCACertEnvVars(options)...)
+	if s.config.Insecure {
+		envs = append(envs, corev1.EnvVar{Name: "TRIVY_INSECURE", Value: "true"})
+	}
+	return envs
+}
+
+// appendScanArgs appends the optional timeout, platform, filtering flags, and the scan target to the specified
+// arguments of the Trivy command.
+func (s *trivyScanner) appendScanArgs(args []string, imageRef string, options scanner.Options) []string {
+	args = append(args, s.newTimeoutArgs()...)
+	args = append(args, s.newPlatformArgs()...)
+	if s.config.IgnoreUnfixed {
+		args = append(args, "--ignore-unfixed")
+	}
+	if options.IgnoreFileConfigMap != "" {
+		args = append(args, "--ignorefile", ignoreFileMountPath+"/"+IgnoreFileKey)
+	}
+	if len(options.Severities) > 0 {
+		names := make([]string, len(options.Severities))
+		for i, severity := range options.Severities {
+			names[i] = string(severity)
+		}
+		args = append(args, "--severity", strings.Join(names, ","))
+	}
+	return append(args, s.getScanTarget(imageRef))
+}
+
+// newTimeoutArgs returns the flag of the configured timeout of a scan, which is enforced by Trivy itself
+// regardless of the deadline of the scan Job. Returns nil if the timeout is not set, in which case the
+// default timeout of Trivy applies.
+func (s *trivyScanner) newTimeoutArgs() []string {
+	if s.config.Timeout <= 0 {
+		return nil
+	}
+	return []string{"--timeout", s.config.Timeout.String()}
+}
+
+// newPlatformArgs returns the flag of the configured platform, e.g. linux/arm64, which selects the variant
+// of multi-arch images to be scanned. Returns nil if the platform is not set, in which case Trivy selects
+// the variant of the platform it runs on.
+func (s *trivyScanner) newPlatformArgs() []string {
+	if s.config.Platform == "" {
+		return nil
+	}
+	return []string{"--platform", s.config.Platform}
+}
+
 func (s *trivyScanner) ParseVulnerabilityScanResult(imageRef string, logsReader io.ReadCloser) (v1alpha1.VulnerabilityScanResult, error) {
-	result, err := trivy.DefaultConverter.Convert(imageRef, logsReader)
+	var output io.Reader = logsReader
+	if s.config.OutputMode == etc.ScanOutputModeFile {
+		var err error
+		output, err = ReadFileOutput(logsReader)
+		if err != nil {
+			return v1alpha1.VulnerabilityScanResult{}, err
+		}
+	}
+	result, err := trivy.DefaultConverter.Convert(imageRef, output)
 	if err != nil {
 		return v1alpha1.VulnerabilityScanResult{}, err
 	}
@@ -0,0 +1,726 @@
+package trivy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/starboard-operator/pkg/etc"
+	"github.com/aquasecurity/starboard-operator/pkg/scanner"
+	"github.com/aquasecurity/starboard-operator/pkg/trivy"
+	"github.com/aquasecurity/starboard/pkg/apis/aquasecurity/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestTrivyScanner_NewScanJob(t *testing.T) {
+	options := scanner.Options{
+		Namespace:          "starboard-operator",
+		ServiceAccountName: "starboard-operator",
+		ScanJobTimeout:     5 * time.Minute,
+	}
+	spec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "nginx", Image: "nginx:1.16"},
+		},
+	}
+
+	t.Run("Should download vulnerability database in Standalone mode", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		assert.Equal(t, "starboard-operator", job.Spec.Template.Spec.ServiceAccountName)
+		require.NotNil(t, job.Spec.ActiveDeadlineSeconds)
+		assert.Equal(t, int64(300), *job.Spec.ActiveDeadlineSeconds)
+		require.Len(t, job.Spec.Template.Spec.InitContainers, 1)
+		assert.Equal(t, []string{"trivy"}, job.Spec.Template.Spec.InitContainers[0].Command)
+		assert.Equal(t, []string{"--download-db-only", "--cache-dir", "/var/lib/trivy"}, job.Spec.Template.Spec.InitContainers[0].Args)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, []string{"trivy"}, job.Spec.Template.Spec.Containers[0].Command)
+		assert.Equal(t, []string{"--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "json", "nginx:1.16"},
+			job.Spec.Template.Spec.Containers[0].Args)
+		assert.Empty(t, job.Spec.Template.Spec.Containers[0].Env)
+	})
+
+	t.Run("Should scan with remote server in ClientServer mode", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:  "aquasec/trivy:0.11.0",
+			Mode:      etc.TrivyModeClientServer,
+			ServerURL: "http://trivy.trivy:4954",
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		assert.Empty(t, job.Spec.Template.Spec.InitContainers)
+		assert.Empty(t, job.Spec.Template.Spec.Volumes)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, []string{"trivy"}, job.Spec.Template.Spec.Containers[0].Command)
+		assert.Equal(t, []string{"client", "--remote", "http://trivy.trivy:4954", "--format", "json", "nginx:1.16"},
+			job.Spec.Template.Spec.Containers[0].Args)
+		require.Len(t, job.Spec.Template.Spec.Containers[0].Env, 1)
+		env := job.Spec.Template.Spec.Containers[0].Env[0]
+		assert.Equal(t, "TRIVY_TOKEN", env.Name)
+		assert.Empty(t, env.Value)
+		require.NotNil(t, env.ValueFrom)
+		require.NotNil(t, env.ValueFrom.SecretKeyRef)
+		assert.Equal(t, "starboard-operator", env.ValueFrom.SecretKeyRef.Name)
+		assert.Equal(t, "OPERATOR_SCANNER_TRIVY_SERVER_TOKEN", env.ValueFrom.SecretKeyRef.Key)
+	})
+
+	t.Run("Should scan root filesystem with rootfs scan type", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:      "aquasec/trivy:0.11.0",
+			Mode:          etc.TrivyModeStandalone,
+			ScanType:      etc.TrivyScanTypeRootfs,
+			IgnoreUnfixed: true,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		require.Len(t, job.Spec.Template.Spec.InitContainers, 2)
+		assert.Equal(t, []string{"--download-db-only", "--cache-dir", "/var/lib/trivy"}, job.Spec.Template.Spec.InitContainers[0].Args)
+		assert.Equal(t, "aquasec/trivy:0.11.0", job.Spec.Template.Spec.InitContainers[1].Image)
+		assert.Equal(t, []string{"cp"}, job.Spec.Template.Spec.InitContainers[1].Command)
+		assert.Equal(t, []string{"-v", "/usr/local/bin/trivy", "/var/starboard/trivy"}, job.Spec.Template.Spec.InitContainers[1].Args)
+
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		container := job.Spec.Template.Spec.Containers[0]
+		assert.Equal(t, "nginx:1.16", container.Image)
+		assert.Equal(t, []string{"/var/starboard/trivy"}, container.Command)
+		assert.Equal(t, []string{"--cache-dir", "/var/lib/trivy", "rootfs", "--skip-update", "--skip-dirs", "/var/starboard,/var/lib/trivy",
+			"--no-progress", "--format", "json", "--ignore-unfixed", "/"}, container.Args)
+		assert.Equal(t, []corev1.VolumeMount{
+			{Name: "data", MountPath: "/var/lib/trivy"},
+			{Name: "scanner-bin", ReadOnly: true, MountPath: "/var/starboard"},
+		}, container.VolumeMounts)
+		assert.Contains(t, job.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: "scanner-bin",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumDefault},
+			},
+		})
+	})
+
+	t.Run("Should generate SBOM of root filesystem with rootfs scan type", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:     "aquasec/trivy:0.11.0",
+			Mode:         etc.TrivyModeStandalone,
+			ScanType:     etc.TrivyScanTypeRootfs,
+			GenerateSBOM: true,
+			SBOMFormat:   etc.SBOMFormatCycloneDX,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		require.Len(t, job.Spec.Template.Spec.Containers, 2)
+		assert.Equal(t, "nginx:1.16", job.Spec.Template.Spec.Containers[1].Image)
+		assert.Equal(t, []string{"--cache-dir", "/var/lib/trivy", "rootfs", "--skip-update", "--skip-dirs", "/var/starboard,/var/lib/trivy",
+			"--no-progress", "--format", "cyclonedx", "/"}, job.Spec.Template.Spec.Containers[1].Args)
+	})
+
+	t.Run("Should append root directory to overridden args with rootfs scan type", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+			ScanType: etc.TrivyScanTypeRootfs,
+			Args:     `["rootfs","--format","json"]`,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"rootfs", "--format", "json", "/"}, job.Spec.Template.Spec.Containers[0].Args)
+	})
+
+	t.Run("Should generate SBOM only when enabled", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:      "aquasec/trivy:0.11.0",
+			Mode:          etc.TrivyModeStandalone,
+			IgnoreUnfixed: true,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		assert.Len(t, job.Spec.Template.Spec.Containers, 1)
+
+		job, err = trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:      "aquasec/trivy:0.11.0",
+			Mode:          etc.TrivyModeStandalone,
+			IgnoreUnfixed: true,
+			GenerateSBOM:  true,
+			SBOMFormat:    etc.SBOMFormatCycloneDX,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		require.Len(t, job.Spec.Template.Spec.Containers, 2)
+		assert.Equal(t, "nginx", job.Spec.Template.Spec.Containers[0].Name)
+		assert.Equal(t, []string{"--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "json", "--ignore-unfixed", "nginx:1.16"},
+			job.Spec.Template.Spec.Containers[0].Args)
+		assert.Equal(t, "sbom-nginx", job.Spec.Template.Spec.Containers[1].Name)
+		assert.Equal(t, []string{"trivy"}, job.Spec.Template.Spec.Containers[1].Command)
+		assert.Equal(t, []string{"--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "cyclonedx", "nginx:1.16"},
+			job.Spec.Template.Spec.Containers[1].Args)
+		assert.Equal(t, job.Spec.Template.Spec.Containers[0].VolumeMounts, job.Spec.Template.Spec.Containers[1].VolumeMounts)
+	})
+
+	t.Run("Should generate SBOM with remote server in ClientServer mode", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:     "aquasec/trivy:0.11.0",
+			Mode:         etc.TrivyModeClientServer,
+			ServerURL:    "http://trivy.trivy:4954",
+			GenerateSBOM: true,
+			SBOMFormat:   etc.SBOMFormatCycloneDX,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		require.Len(t, job.Spec.Template.Spec.Containers, 2)
+		assert.Equal(t, "sbom-nginx", job.Spec.Template.Spec.Containers[1].Name)
+		assert.Equal(t, []string{"client", "--remote", "http://trivy.trivy:4954", "--format", "cyclonedx", "nginx:1.16"},
+			job.Spec.Template.Spec.Containers[1].Args)
+		assert.Equal(t, job.Spec.Template.Spec.Containers[0].Env, job.Spec.Template.Spec.Containers[1].Env)
+	})
+
+	t.Run("Should cache vulnerability database in persistent volume claim", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+			CachePVC: "trivy-cache",
+			CacheDir: "/var/cache/trivy",
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		require.Len(t, job.Spec.Template.Spec.Volumes, 1)
+		assert.Equal(t, corev1.Volume{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "trivy-cache",
+				},
+			},
+		}, job.Spec.Template.Spec.Volumes[0])
+
+		for _, c := range append(job.Spec.Template.Spec.InitContainers, job.Spec.Template.Spec.Containers...) {
+			assert.Equal(t, []corev1.VolumeMount{
+				{Name: "data", MountPath: "/var/cache/trivy"},
+			}, c.VolumeMounts, c.Name)
+			assert.Equal(t, []corev1.EnvVar{
+				{Name: "TRIVY_CACHE_DIR", Value: "/var/cache/trivy"},
+			}, c.Env, c.Name)
+		}
+		assert.Equal(t, []string{"--download-db-only", "--cache-dir", "/var/cache/trivy"}, job.Spec.Template.Spec.InitContainers[0].Args)
+		assert.Equal(t, []string{"--skip-update", "--cache-dir", "/var/cache/trivy", "--no-progress", "--format", "json", "nginx:1.16"},
+			job.Spec.Template.Spec.Containers[0].Args)
+	})
+
+	t.Run("Should cache vulnerability database in emptyDir by default", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		require.Len(t, job.Spec.Template.Spec.Volumes, 1)
+		assert.NotNil(t, job.Spec.Template.Spec.Volumes[0].EmptyDir)
+		assert.Equal(t, []corev1.VolumeMount{
+			{Name: "data", MountPath: "/var/lib/trivy"},
+		}, job.Spec.Template.Spec.Containers[0].VolumeMounts)
+		assert.Empty(t, job.Spec.Template.Spec.InitContainers[0].Env)
+	})
+
+	t.Run("Should apply scheduling constraints", func(t *testing.T) {
+		options := options
+		options.ScanJobNodeSelector = map[string]string{"node-pool": "scanners"}
+		options.ScanJobTolerations = []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "scanners", Effect: corev1.TaintEffectNoSchedule},
+		}
+		options.ScanJobAffinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+					{
+						Weight: 1,
+						Preference: corev1.NodeSelectorTerm{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{Key: "kubernetes.io/os", Operator: corev1.NodeSelectorOpIn, Values: []string{"linux"}},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		assert.Equal(t, options.ScanJobNodeSelector, job.Spec.Template.Spec.NodeSelector)
+		assert.Equal(t, options.ScanJobTolerations, job.Spec.Template.Spec.Tolerations)
+		assert.Equal(t, options.ScanJobAffinity, job.Spec.Template.Spec.Affinity)
+	})
+
+	t.Run("Should set host aliases and DNS config", func(t *testing.T) {
+		options := options
+		options.ScanJobHostAliases = []corev1.HostAlias{
+			{IP: "10.0.0.10", Hostnames: []string{"registry.corp"}},
+		}
+		options.ScanJobDNSConfig = &corev1.PodDNSConfig{
+			Nameservers: []string{"10.0.0.53"},
+			Searches:    []string{"corp.local"},
+		}
+
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		assert.Equal(t, options.ScanJobHostAliases, job.Spec.Template.Spec.HostAliases)
+		assert.Equal(t, options.ScanJobDNSConfig, job.Spec.Template.Spec.DNSConfig)
+	})
+
+	t.Run("Should add volumes and mount them into containers running Trivy", func(t *testing.T) {
+		options := options
+		options.ScanJobVolumes = []corev1.Volume{
+			{
+				Name: "scanner-config",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "scanner-config"},
+					},
+				},
+			},
+		}
+		options.ScanJobVolumeMounts = []corev1.VolumeMount{
+			{Name: "scanner-config", MountPath: "/etc/scanner", ReadOnly: true},
+		}
+
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:     "aquasec/trivy:0.11.0",
+			Mode:         etc.TrivyModeStandalone,
+			GenerateSBOM: true,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		assert.Contains(t, job.Spec.Template.Spec.Volumes, options.ScanJobVolumes[0])
+		require.Len(t, job.Spec.Template.Spec.InitContainers, 1)
+		assert.Contains(t, job.Spec.Template.Spec.InitContainers[0].VolumeMounts, options.ScanJobVolumeMounts[0])
+		require.NotEmpty(t, job.Spec.Template.Spec.Containers)
+		for _, container := range job.Spec.Template.Spec.Containers {
+			assert.Contains(t, container.VolumeMounts, options.ScanJobVolumeMounts[0], container.Name)
+		}
+	})
+
+	t.Run("Should override command and args when set", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:     "example.com/trivy-wrapper:1.0",
+			Mode:         etc.TrivyModeStandalone,
+			GenerateSBOM: true,
+			SBOMFormat:   etc.SBOMFormatCycloneDX,
+			Command:      `["/usr/local/bin/scan.sh"]`,
+			Args:         `["--skip-update","--format","json"]`,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		require.Len(t, job.Spec.Template.Spec.Containers, 2)
+		assert.Equal(t, []string{"trivy"}, job.Spec.Template.Spec.InitContainers[0].Command,
+			"Vulnerability database is downloaded with the built-in command")
+		assert.Equal(t, []string{"/usr/local/bin/scan.sh"}, job.Spec.Template.Spec.Containers[0].Command)
+		assert.Equal(t, []string{"--skip-update", "--format", "json", "nginx:1.16"}, job.Spec.Template.Spec.Containers[0].Args)
+		assert.Equal(t, []string{"/usr/local/bin/scan.sh"}, job.Spec.Template.Spec.Containers[1].Command)
+		assert.Equal(t, []string{"--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "cyclonedx", "nginx:1.16"},
+			job.Spec.Template.Spec.Containers[1].Args)
+	})
+
+	t.Run("Should override only command when args are not set", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:  "example.com/trivy-wrapper:1.0",
+			Mode:      etc.TrivyModeClientServer,
+			ServerURL: "http://trivy.trivy:4954",
+			Command:   `["/usr/local/bin/scan.sh"]`,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/usr/local/bin/scan.sh"}, job.Spec.Template.Spec.Containers[0].Command)
+		assert.Equal(t, []string{"client", "--remote", "http://trivy.trivy:4954", "--format", "json", "nginx:1.16"},
+			job.Spec.Template.Spec.Containers[0].Args)
+	})
+
+	t.Run("Should return error when command is malformed", func(t *testing.T) {
+		_, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+			Command:  `/usr/local/bin/scan.sh`,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		assert.EqualError(t, err, "parsing OPERATOR_SCANNER_TRIVY_COMMAND: invalid character '/' looking for beginning of value")
+	})
+
+	t.Run("Should use configured image from mirror registry", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "registry.local:5000/aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		assert.Equal(t, "registry.local:5000/aquasec/trivy:0.11.0", job.Spec.Template.Spec.InitContainers[0].Image)
+		assert.Equal(t, "registry.local:5000/aquasec/trivy:0.11.0", job.Spec.Template.Spec.Containers[0].Image)
+	})
+
+	t.Run("Should apply security context", func(t *testing.T) {
+		options := options
+		options.ScanJobPodSecurityContext = &corev1.PodSecurityContext{
+			RunAsNonRoot: pointer.BoolPtr(true),
+		}
+		options.ScanJobSecurityContext = &corev1.SecurityContext{
+			RunAsNonRoot:             pointer.BoolPtr(true),
+			AllowPrivilegeEscalation: pointer.BoolPtr(false),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
+		}
+
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:     "aquasec/trivy:0.11.0",
+			Mode:         etc.TrivyModeStandalone,
+			GenerateSBOM: true,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		assert.Equal(t, options.ScanJobPodSecurityContext, job.Spec.Template.Spec.SecurityContext)
+		require.Len(t, job.Spec.Template.Spec.InitContainers, 1)
+		require.Len(t, job.Spec.Template.Spec.Containers, 2)
+		for _, container := range append(job.Spec.Template.Spec.InitContainers, job.Spec.Template.Spec.Containers...) {
+			assert.Equal(t, options.ScanJobSecurityContext, container.SecurityContext, container.Name)
+		}
+	})
+
+	t.Run("Should not set security context by default", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		assert.Nil(t, job.Spec.Template.Spec.SecurityContext)
+		assert.Nil(t, job.Spec.Template.Spec.InitContainers[0].SecurityContext)
+		assert.Nil(t, job.Spec.Template.Spec.Containers[0].SecurityContext)
+	})
+
+	t.Run("Should ignore unfixed vulnerabilities only when enabled", func(t *testing.T) {
+		testCases := []struct {
+			name          string
+			mode          etc.TrivyMode
+			ignoreUnfixed bool
+			expectedArgs  []string
+		}{
+			{
+				name:         "Standalone mode",
+				mode:         etc.TrivyModeStandalone,
+				expectedArgs: []string{"--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "json", "nginx:1.16"},
+			},
+			{
+				name:          "Standalone mode with ignore unfixed",
+				mode:          etc.TrivyModeStandalone,
+				ignoreUnfixed: true,
+				expectedArgs:  []string{"--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "json", "--ignore-unfixed", "nginx:1.16"},
+			},
+			{
+				name:          "ClientServer mode with ignore unfixed",
+				mode:          etc.TrivyModeClientServer,
+				ignoreUnfixed: true,
+				expectedArgs:  []string{"client", "--remote", "http://trivy.trivy:4954", "--format", "json", "--ignore-unfixed", "nginx:1.16"},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				job, err := trivy.NewScanner(etc.ScannerTrivy{
+					ImageRef:      "aquasec/trivy:0.11.0",
+					Mode:          tc.mode,
+					ServerURL:     "http://trivy.trivy:4954",
+					IgnoreUnfixed: tc.ignoreUnfixed,
+				}).NewScanJob(scanner.JobMeta{}, options, spec)
+				require.NoError(t, err)
+
+				require.Len(t, job.Spec.Template.Spec.Containers, 1)
+				assert.Equal(t, tc.expectedArgs, job.Spec.Template.Spec.Containers[0].Args)
+			})
+		}
+	})
+
+	t.Run("Should pass timeout", func(t *testing.T) {
+		testCases := []struct {
+			name         string
+			config       etc.ScannerTrivy
+			expectedArgs []string
+		}{
+			{
+				name:         "Standalone mode",
+				config:       etc.ScannerTrivy{ImageRef: "aquasec/trivy:0.11.0", Mode: etc.TrivyModeStandalone, Timeout: 10 * time.Minute},
+				expectedArgs: []string{"--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "json", "--timeout", "10m0s", "nginx:1.16"},
+			},
+			{
+				name: "ClientServer mode",
+				config: etc.ScannerTrivy{ImageRef: "aquasec/trivy:0.11.0", Mode: etc.TrivyModeClientServer,
+					ServerURL: "http://trivy.trivy:4954", Timeout: 10 * time.Minute},
+				expectedArgs: []string{"client", "--remote", "http://trivy.trivy:4954", "--format", "json", "--timeout", "10m0s", "nginx:1.16"},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				job, err := trivy.NewScanner(tc.config).NewScanJob(scanner.JobMeta{}, options, spec)
+				require.NoError(t, err)
+
+				require.Len(t, job.Spec.Template.Spec.Containers, 1)
+				assert.Equal(t, tc.expectedArgs, job.Spec.Template.Spec.Containers[0].Args)
+				assert.Equal(t, options.ScanJobTimeout, time.Duration(*job.Spec.ActiveDeadlineSeconds)*time.Second)
+			})
+		}
+	})
+
+	t.Run("Should pass platform", func(t *testing.T) {
+		testCases := []struct {
+			name         string
+			config       etc.ScannerTrivy
+			expectedArgs []string
+		}{
+			{
+				name:         "Standalone mode",
+				config:       etc.ScannerTrivy{ImageRef: "aquasec/trivy:0.11.0", Mode: etc.TrivyModeStandalone, Platform: "linux/arm64"},
+				expectedArgs: []string{"--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "json", "--platform", "linux/arm64", "nginx:1.16"},
+			},
+			{
+				name: "ClientServer mode",
+				config: etc.ScannerTrivy{ImageRef: "aquasec/trivy:0.11.0", Mode: etc.TrivyModeClientServer,
+					ServerURL: "http://trivy.trivy:4954", Platform: "linux/arm/v7"},
+				expectedArgs: []string{"client", "--remote", "http://trivy.trivy:4954", "--format", "json", "--platform", "linux/arm/v7", "nginx:1.16"},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				job, err := trivy.NewScanner(tc.config).NewScanJob(scanner.JobMeta{}, options, spec)
+				require.NoError(t, err)
+
+				require.Len(t, job.Spec.Template.Spec.Containers, 1)
+				assert.Equal(t, tc.expectedArgs, job.Spec.Template.Spec.Containers[0].Args)
+			})
+		}
+	})
+
+	t.Run("Should pass severities", func(t *testing.T) {
+		options := options
+		options.Severities = []v1alpha1.Severity{v1alpha1.SeverityCritical, v1alpha1.SeverityHigh}
+
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, []string{"--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "json", "--severity", "CRITICAL,HIGH", "nginx:1.16"},
+			job.Spec.Template.Spec.Containers[0].Args)
+	})
+
+	t.Run("Should set proxy environment variables", func(t *testing.T) {
+		options := options
+		options.ScanJobHTTPProxy = "http://proxy.corp:3128"
+		options.ScanJobHTTPSProxy = "http://proxy.corp:3128"
+		options.ScanJobNoProxy = "localhost,.svc"
+		expectedEnv := []corev1.EnvVar{
+			{Name: "HTTP_PROXY", Value: "http://proxy.corp:3128"},
+			{Name: "HTTPS_PROXY", Value: "http://proxy.corp:3128"},
+			{Name: "NO_PROXY", Value: "localhost,.svc"},
+		}
+
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		require.Len(t, job.Spec.Template.Spec.InitContainers, 1)
+		assert.Equal(t, expectedEnv, job.Spec.Template.Spec.InitContainers[0].Env)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, expectedEnv, job.Spec.Template.Spec.Containers[0].Env)
+	})
+
+	t.Run("Should set environment variables from configmaps and secrets", func(t *testing.T) {
+		options := options
+		options.ScanJobEnvFrom = []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "trivy-config"}}},
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "trivy-github-token"}}},
+		}
+		testCases := []struct {
+			name   string
+			config etc.ScannerTrivy
+		}{
+			{
+				name:   "Standalone mode",
+				config: etc.ScannerTrivy{ImageRef: "aquasec/trivy:0.11.0", Mode: etc.TrivyModeStandalone},
+			},
+			{
+				name:   "ClientServer mode",
+				config: etc.ScannerTrivy{ImageRef: "aquasec/trivy:0.11.0", Mode: etc.TrivyModeClientServer, ServerURL: "http://trivy.trivy:4954"},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				job, err := trivy.NewScanner(tc.config).NewScanJob(scanner.JobMeta{}, options, spec)
+				require.NoError(t, err)
+				require.Len(t, job.Spec.Template.Spec.Containers, 1)
+				assert.Equal(t, options.ScanJobEnvFrom, job.Spec.Template.Spec.Containers[0].EnvFrom)
+				for _, container := range job.Spec.Template.Spec.InitContainers {
+					assert.Equal(t, options.ScanJobEnvFrom, container.EnvFrom, container.Name)
+				}
+			})
+		}
+	})
+
+	t.Run("Should set backoff limit", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		require.NotNil(t, job.Spec.BackoffLimit)
+		assert.Equal(t, int32(0), *job.Spec.BackoffLimit)
+
+		options := options
+		options.ScanJobBackoffLimit = 2
+
+		job, err = trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+		require.NotNil(t, job.Spec.BackoffLimit)
+		assert.Equal(t, int32(2), *job.Spec.BackoffLimit)
+	})
+
+	t.Run("Should set image pull policy", func(t *testing.T) {
+		options := options
+		options.ScanJobImagePullPolicy = corev1.PullNever
+
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		require.Len(t, job.Spec.Template.Spec.InitContainers, 1)
+		assert.Equal(t, corev1.PullNever, job.Spec.Template.Spec.InitContainers[0].ImagePullPolicy)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, corev1.PullNever, job.Spec.Template.Spec.Containers[0].ImagePullPolicy)
+	})
+
+	t.Run("Should mount CA certificates", func(t *testing.T) {
+		options := options
+		options.ScanJobCACertConfigMap = "registry-ca"
+		expectedMount := corev1.VolumeMount{Name: "ca-certs", ReadOnly: true, MountPath: "/etc/starboard/ca-certs"}
+		expectedEnv := corev1.EnvVar{Name: "SSL_CERT_DIR", Value: "/etc/ssl/certs:/etc/starboard/ca-certs"}
+
+		for _, mode := range []etc.TrivyMode{etc.TrivyModeStandalone, etc.TrivyModeClientServer} {
+			job, err := trivy.NewScanner(etc.ScannerTrivy{
+				ImageRef:  "aquasec/trivy:0.11.0",
+				Mode:      mode,
+				ServerURL: "http://trivy.trivy:4954",
+			}).NewScanJob(scanner.JobMeta{}, options, spec)
+			require.NoError(t, err)
+
+			assert.Contains(t, job.Spec.Template.Spec.Volumes, corev1.Volume{
+				Name: "ca-certs",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "registry-ca"},
+					},
+				},
+			}, "mode %s", mode)
+			for _, container := range append(job.Spec.Template.Spec.InitContainers, job.Spec.Template.Spec.Containers...) {
+				assert.Contains(t, container.VolumeMounts, expectedMount, "mode %s, container %s", mode, container.Name)
+				assert.Contains(t, container.Env, expectedEnv, "mode %s, container %s", mode, container.Name)
+				assert.NotContains(t, container.Env, corev1.EnvVar{Name: "TRIVY_INSECURE", Value: "true"})
+			}
+		}
+	})
+
+	t.Run("Should mount ignore file", func(t *testing.T) {
+		options := options
+		options.IgnoreFileConfigMap = "trivyignore"
+		expectedMount := corev1.VolumeMount{Name: "ignore-file", ReadOnly: true, MountPath: "/etc/starboard/trivy"}
+
+		for _, mode := range []etc.TrivyMode{etc.TrivyModeStandalone, etc.TrivyModeClientServer} {
+			job, err := trivy.NewScanner(etc.ScannerTrivy{
+				ImageRef:  "aquasec/trivy:0.11.0",
+				Mode:      mode,
+				ServerURL: "http://trivy.trivy:4954",
+			}).NewScanJob(scanner.JobMeta{}, options, spec)
+			require.NoError(t, err)
+
+			assert.Contains(t, job.Spec.Template.Spec.Volumes, corev1.Volume{
+				Name: "ignore-file",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "trivyignore"},
+						Optional:             pointer.BoolPtr(true),
+					},
+				},
+			}, "mode %s", mode)
+			require.Len(t, job.Spec.Template.Spec.Containers, 1)
+			container := job.Spec.Template.Spec.Containers[0]
+			assert.Contains(t, container.VolumeMounts, expectedMount, "mode %s", mode)
+			assert.Subset(t, container.Args, []string{"--ignorefile", "/etc/starboard/trivy/.trivyignore"}, "mode %s", mode)
+			assert.Equal(t, "nginx:1.16", container.Args[len(container.Args)-1], "mode %s", mode)
+		}
+	})
+
+	t.Run("Should not mount ignore file when not configured", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.NotContains(t, job.Spec.Template.Spec.Containers[0].Args, "--ignorefile")
+		for _, volume := range job.Spec.Template.Spec.Volumes {
+			assert.NotEqual(t, "ignore-file", volume.Name)
+		}
+	})
+
+	t.Run("Should skip verification of registry certificates when insecure", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef: "aquasec/trivy:0.11.0",
+			Mode:     etc.TrivyModeStandalone,
+			Insecure: true,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		assert.Len(t, job.Spec.Template.Spec.Volumes, 1)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "TRIVY_INSECURE", Value: "true"})
+	})
+
+	t.Run("Should write output to file in file output mode", func(t *testing.T) {
+		job, err := trivy.NewScanner(etc.ScannerTrivy{
+			ImageRef:     "aquasec/trivy:0.11.0",
+			Mode:         etc.TrivyModeStandalone,
+			OutputMode:   etc.ScanOutputModeFile,
+			GenerateSBOM: true,
+			SBOMFormat:   etc.SBOMFormatCycloneDX,
+		}).NewScanJob(scanner.JobMeta{}, options, spec)
+		require.NoError(t, err)
+
+		assert.Contains(t, job.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: "scan-output",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumDefault},
+			},
+		})
+		require.Len(t, job.Spec.Template.Spec.Containers, 2)
+		container := job.Spec.Template.Spec.Containers[0]
+		assert.Equal(t, []string{"sh", "-c"}, container.Command)
+		require.Len(t, container.Args, 11)
+		assert.Contains(t, container.Args[0], `"$0" "$@"`)
+		assert.Equal(t, []string{"trivy", "--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "json",
+			"--output", "/var/starboard/output/nginx.json", "nginx:1.16"}, container.Args[1:])
+		assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{Name: "scan-output", MountPath: "/var/starboard/output"})
+
+		sbomContainer := job.Spec.Template.Spec.Containers[1]
+		assert.Equal(t, []string{"trivy"}, sbomContainer.Command)
+		assert.Equal(t, []string{"--skip-update", "--cache-dir", "/var/lib/trivy", "--no-progress", "--format", "cyclonedx", "nginx:1.16"},
+			sbomContainer.Args)
+		assert.NotContains(t, sbomContainer.VolumeMounts, corev1.VolumeMount{Name: "scan-output", MountPath: "/var/starboard/output"})
+	})
+}